@@ -119,6 +119,59 @@ func (FailurePolicy) EnumDescriptor() ([]byte, []int) {
 	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{1}
 }
 
+// WatchEventType 与 K8s watch 事件类型一致
+type WatchEventType int32
+
+const (
+	WatchEventType_ADDED    WatchEventType = 0
+	WatchEventType_MODIFIED WatchEventType = 1
+	WatchEventType_DELETED  WatchEventType = 2
+	WatchEventType_BOOKMARK WatchEventType = 3 // 不携带 sandbox，只通告当前 resource_version
+)
+
+// Enum value maps for WatchEventType.
+var (
+	WatchEventType_name = map[int32]string{
+		0: "ADDED",
+		1: "MODIFIED",
+		2: "DELETED",
+		3: "BOOKMARK",
+	}
+	WatchEventType_value = map[string]int32{
+		"ADDED":    0,
+		"MODIFIED": 1,
+		"DELETED":  2,
+		"BOOKMARK": 3,
+	}
+)
+
+func (x WatchEventType) Enum() *WatchEventType {
+	p := new(WatchEventType)
+	*p = x
+	return p
+}
+
+func (x WatchEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_v1_fastpath_proto_enumTypes[2].Descriptor()
+}
+
+func (WatchEventType) Type() protoreflect.EnumType {
+	return &file_api_proto_v1_fastpath_proto_enumTypes[2]
+}
+
+func (x WatchEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchEventType.Descriptor instead.
+func (WatchEventType) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{2}
+}
+
 type ListRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
@@ -260,17 +313,26 @@ func (x *GetRequest) GetNamespace() string {
 }
 
 type SandboxInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`       // container ID (md5 hash or UID)
-	SandboxName   string                 `protobuf:"bytes,8,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
-	Phase         string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`
-	AgentPod      string                 `protobuf:"bytes,3,opt,name=agent_pod,json=agentPod,proto3" json:"agent_pod,omitempty"`
-	Endpoints     []string               `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Image         string                 `protobuf:"bytes,6,opt,name=image,proto3" json:"image,omitempty"`
-	PoolRef       string                 `protobuf:"bytes,7,opt,name=pool_ref,json=poolRef,proto3" json:"pool_ref,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId   string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`       // container ID (md5 hash or UID)
+	SandboxName string                 `protobuf:"bytes,8,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Phase       string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`
+	AgentPod    string                 `protobuf:"bytes,3,opt,name=agent_pod,json=agentPod,proto3" json:"agent_pod,omitempty"`
+	Endpoints   []string               `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	CreatedAt   int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Image       string                 `protobuf:"bytes,6,opt,name=image,proto3" json:"image,omitempty"`
+	PoolRef     string                 `protobuf:"bytes,7,opt,name=pool_ref,json=poolRef,proto3" json:"pool_ref,omitempty"`
+	// 主进程最近一次退出的信息，未退出时 finished_at 为 0
+	ExitCode       int32  `protobuf:"varint,9,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	FinishedAt     int64  `protobuf:"varint,10,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Reason         string `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"` // Completed / Error / OOMKilled
+	RestartCount   int32  `protobuf:"varint,12,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"`
+	Namespace      string `protobuf:"bytes,13,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Priority       int32  `protobuf:"varint,14,opt,name=priority,proto3" json:"priority,omitempty"`
+	LastActivityAt int64  `protobuf:"varint,15,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"` // Agent 观察到的最近活动，分钟级精度，未知为 0
+	Ready          bool   `protobuf:"varint,16,opt,name=ready,proto3" json:"ready,omitempty"`                                           // Ready condition 为 True
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SandboxInfo) Reset() {
@@ -359,6 +421,62 @@ func (x *SandboxInfo) GetPoolRef() string {
 	return ""
 }
 
+func (x *SandboxInfo) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *SandboxInfo) GetFinishedAt() int64 {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return 0
+}
+
+func (x *SandboxInfo) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SandboxInfo) GetRestartCount() int32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+func (x *SandboxInfo) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *SandboxInfo) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *SandboxInfo) GetLastActivityAt() int64 {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return 0
+}
+
+func (x *SandboxInfo) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
 type CreateRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Image           string                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
@@ -371,8 +489,26 @@ type CreateRequest struct {
 	Name            string                 `protobuf:"bytes,8,opt,name=name,proto3" json:"name,omitempty"`                                                                                // 可选，指定沙箱名称（用于测试故障注入）
 	Envs            map[string]string      `protobuf:"bytes,9,rep,name=envs,proto3" json:"envs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`      // 环境变量
 	WorkingDir      string                 `protobuf:"bytes,10,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`                                                 // 工作目录
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	Tty             bool                   `protobuf:"varint,11,opt,name=tty,proto3" json:"tty,omitempty"`                                                                                // 为主进程分配伪终端
+	Stdin           bool                   `protobuf:"varint,12,opt,name=stdin,proto3" json:"stdin,omitempty"`                                                                            // 保持主进程 stdin 打开，供 attach 使用
+	Resources       *ResourceRequirements  `protobuf:"bytes,13,opt,name=resources,proto3" json:"resources,omitempty"`                                                                     // CPU/内存 requests 与 limits
+	RestartPolicy   string                 `protobuf:"bytes,14,opt,name=restart_policy,json=restartPolicy,proto3" json:"restart_policy,omitempty"`                                        // 可选，主进程退出后的重启策略：Never（默认）/OnFailure/Always
+	// 可选，同一 namespace 内相同 key 的请求在去重窗口内只创建一次，重试返回首次创建的结果
+	IdempotencyKey string `protobuf:"bytes,15,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// 可选，>0 时池内容量不足不会立即失败，而是排队等待扩容，最多等待该秒数（仅 CreateSandbox 生效）
+	WaitTimeoutSeconds int32 `protobuf:"varint,16,opt,name=wait_timeout_seconds,json=waitTimeoutSeconds,proto3" json:"wait_timeout_seconds,omitempty"`
+	// 可选，优先级，默认 0。池满时可抢占同池内优先级更低的沙箱，被抢占者进入 Preempted 阶段
+	Priority int32 `protobuf:"varint,17,opt,name=priority,proto3" json:"priority,omitempty"`
+	// 可选，>0 时沙箱空闲（无 exec/attach/日志会话、暴露端口无流量、CPU 空闲）超过该秒数后执行 idle_policy，最小按 60 秒计
+	IdleTimeoutSeconds int32  `protobuf:"varint,18,opt,name=idle_timeout_seconds,json=idleTimeoutSeconds,proto3" json:"idle_timeout_seconds,omitempty"`
+	IdlePolicy         string `protobuf:"bytes,19,opt,name=idle_policy,json=idlePolicy,proto3" json:"idle_policy,omitempty"` // 可选，空闲后的动作：Pause（默认）/Expire/Delete
+	// 可选，沙箱进入终态（Succeeded/Failed/Expired/Preempted）该秒数后删除，0 表示立即删除，不设置则一直保留
+	TtlSecondsAfterFinished *int32          `protobuf:"varint,20,opt,name=ttl_seconds_after_finished,json=ttlSecondsAfterFinished,proto3,oneof" json:"ttl_seconds_after_finished,omitempty"`
+	ReadinessProbe          *ReadinessProbe `protobuf:"bytes,21,opt,name=readiness_probe,json=readinessProbe,proto3" json:"readiness_probe,omitempty"` // 可选，就绪探测，不设置时主进程运行即就绪
+	// 可选，>0 时 CreateSandbox 等待沙箱就绪后才返回，最多等待该秒数；超时返回 DEADLINE_EXCEEDED，沙箱保留（仅 CreateSandbox 生效）
+	ReadyTimeoutSeconds int32 `protobuf:"varint,22,opt,name=ready_timeout_seconds,json=readyTimeoutSeconds,proto3" json:"ready_timeout_seconds,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *CreateRequest) Reset() {
@@ -475,30 +611,119 @@ func (x *CreateRequest) GetWorkingDir() string {
 	return ""
 }
 
-type CreateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`       // container ID (md5 hash or UID)
-	SandboxName   string                 `protobuf:"bytes,4,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
-	AgentPod      string                 `protobuf:"bytes,2,opt,name=agent_pod,json=agentPod,proto3" json:"agent_pod,omitempty"`
-	Endpoints     []string               `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"` // IP:Port 列表
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *CreateRequest) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
 }
 
-func (x *CreateResponse) Reset() {
-	*x = CreateResponse{}
+func (x *CreateRequest) GetStdin() bool {
+	if x != nil {
+		return x.Stdin
+	}
+	return false
+}
+
+func (x *CreateRequest) GetResources() *ResourceRequirements {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetRestartPolicy() string {
+	if x != nil {
+		return x.RestartPolicy
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetWaitTimeoutSeconds() int32 {
+	if x != nil {
+		return x.WaitTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetIdleTimeoutSeconds() int32 {
+	if x != nil {
+		return x.IdleTimeoutSeconds
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetIdlePolicy() string {
+	if x != nil {
+		return x.IdlePolicy
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetTtlSecondsAfterFinished() int32 {
+	if x != nil && x.TtlSecondsAfterFinished != nil {
+		return *x.TtlSecondsAfterFinished
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetReadinessProbe() *ReadinessProbe {
+	if x != nil {
+		return x.ReadinessProbe
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetReadyTimeoutSeconds() int32 {
+	if x != nil {
+		return x.ReadyTimeoutSeconds
+	}
+	return 0
+}
+
+// ReadinessProbe 由 Agent 执行，exec、http_get、tcp_port 三选一，端口为 Agent Pod 上的端口
+type ReadinessProbe struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Exec                []string               `protobuf:"bytes,1,rep,name=exec,proto3" json:"exec,omitempty"`                       // 在沙箱内执行的命令，退出码为 0 即成功
+	HttpGet             *HTTPGetProbe          `protobuf:"bytes,2,opt,name=http_get,json=httpGet,proto3" json:"http_get,omitempty"`  // HTTP 状态码 200-399 即成功
+	TcpPort             int32                  `protobuf:"varint,3,opt,name=tcp_port,json=tcpPort,proto3" json:"tcp_port,omitempty"` // 能建立 TCP 连接即成功
+	InitialDelaySeconds int32                  `protobuf:"varint,4,opt,name=initial_delay_seconds,json=initialDelaySeconds,proto3" json:"initial_delay_seconds,omitempty"`
+	PeriodSeconds       int32                  `protobuf:"varint,5,opt,name=period_seconds,json=periodSeconds,proto3" json:"period_seconds,omitempty"`          // 默认 1
+	TimeoutSeconds      int32                  `protobuf:"varint,6,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`       // 默认 1
+	SuccessThreshold    int32                  `protobuf:"varint,7,opt,name=success_threshold,json=successThreshold,proto3" json:"success_threshold,omitempty"` // 默认 1
+	FailureThreshold    int32                  `protobuf:"varint,8,opt,name=failure_threshold,json=failureThreshold,proto3" json:"failure_threshold,omitempty"` // 默认 3
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ReadinessProbe) Reset() {
+	*x = ReadinessProbe{}
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateResponse) String() string {
+func (x *ReadinessProbe) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateResponse) ProtoMessage() {}
+func (*ReadinessProbe) ProtoMessage() {}
 
-func (x *CreateResponse) ProtoReflect() protoreflect.Message {
+func (x *ReadinessProbe) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -510,61 +735,91 @@ func (x *CreateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
-func (*CreateResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReadinessProbe.ProtoReflect.Descriptor instead.
+func (*ReadinessProbe) Descriptor() ([]byte, []int) {
 	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *CreateResponse) GetSandboxId() string {
+func (x *ReadinessProbe) GetExec() []string {
 	if x != nil {
-		return x.SandboxId
+		return x.Exec
 	}
-	return ""
+	return nil
 }
 
-func (x *CreateResponse) GetSandboxName() string {
+func (x *ReadinessProbe) GetHttpGet() *HTTPGetProbe {
 	if x != nil {
-		return x.SandboxName
+		return x.HttpGet
 	}
-	return ""
+	return nil
 }
 
-func (x *CreateResponse) GetAgentPod() string {
+func (x *ReadinessProbe) GetTcpPort() int32 {
 	if x != nil {
-		return x.AgentPod
+		return x.TcpPort
 	}
-	return ""
+	return 0
 }
 
-func (x *CreateResponse) GetEndpoints() []string {
+func (x *ReadinessProbe) GetInitialDelaySeconds() int32 {
 	if x != nil {
-		return x.Endpoints
+		return x.InitialDelaySeconds
 	}
-	return nil
+	return 0
 }
 
-type DeleteRequest struct {
+func (x *ReadinessProbe) GetPeriodSeconds() int32 {
+	if x != nil {
+		return x.PeriodSeconds
+	}
+	return 0
+}
+
+func (x *ReadinessProbe) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ReadinessProbe) GetSuccessThreshold() int32 {
+	if x != nil {
+		return x.SuccessThreshold
+	}
+	return 0
+}
+
+func (x *ReadinessProbe) GetFailureThreshold() int32 {
+	if x != nil {
+		return x.FailureThreshold
+	}
+	return 0
+}
+
+type HTTPGetProbe struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SandboxName   string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
-	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`                        // 可选，默认为 "default"
+	Port          int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Scheme        string                 `protobuf:"bytes,3,opt,name=scheme,proto3" json:"scheme,omitempty"` // HTTP（默认）/HTTPS
+	Headers       map[string]string      `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteRequest) Reset() {
-	*x = DeleteRequest{}
+func (x *HTTPGetProbe) Reset() {
+	*x = HTTPGetProbe{}
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteRequest) String() string {
+func (x *HTTPGetProbe) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteRequest) ProtoMessage() {}
+func (*HTTPGetProbe) ProtoMessage() {}
 
-func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+func (x *HTTPGetProbe) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -576,46 +831,62 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use HTTPGetProbe.ProtoReflect.Descriptor instead.
+func (*HTTPGetProbe) Descriptor() ([]byte, []int) {
 	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *DeleteRequest) GetSandboxName() string {
+func (x *HTTPGetProbe) GetPort() int32 {
 	if x != nil {
-		return x.SandboxName
+		return x.Port
+	}
+	return 0
+}
+
+func (x *HTTPGetProbe) GetPath() string {
+	if x != nil {
+		return x.Path
 	}
 	return ""
 }
 
-func (x *DeleteRequest) GetNamespace() string {
+func (x *HTTPGetProbe) GetScheme() string {
 	if x != nil {
-		return x.Namespace
+		return x.Scheme
 	}
 	return ""
 }
 
-type DeleteResponse struct {
+func (x *HTTPGetProbe) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+// ResourceRequirements 与 K8s 一致，key 为 "cpu"/"memory"，value 为 Quantity 字符串（如 "500m"、"256Mi"）
+type ResourceRequirements struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Requests      map[string]string      `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Limits        map[string]string      `protobuf:"bytes,2,rep,name=limits,proto3" json:"limits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteResponse) Reset() {
-	*x = DeleteResponse{}
+func (x *ResourceRequirements) Reset() {
+	*x = ResourceRequirements{}
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteResponse) String() string {
+func (x *ResourceRequirements) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteResponse) ProtoMessage() {}
+func (*ResourceRequirements) ProtoMessage() {}
 
-func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+func (x *ResourceRequirements) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -627,51 +898,49 @@ func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
-func (*DeleteResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ResourceRequirements.ProtoReflect.Descriptor instead.
+func (*ResourceRequirements) Descriptor() ([]byte, []int) {
 	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *DeleteResponse) GetSuccess() bool {
+func (x *ResourceRequirements) GetRequests() map[string]string {
 	if x != nil {
-		return x.Success
+		return x.Requests
 	}
-	return false
+	return nil
 }
 
-type UpdateRequest struct {
-	state       protoimpl.MessageState `protogen:"open.v1"`
-	SandboxName string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
-	Namespace   string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
-	// 可更新字段 (only one at a time, except labels)
-	//
-	// Types that are valid to be assigned to Update:
-	//
-	//	*UpdateRequest_ExpireTimeSeconds
-	//	*UpdateRequest_ResetRevision
-	//	*UpdateRequest_FailurePolicy
-	//	*UpdateRequest_RecoveryTimeoutSeconds
-	Update isUpdateRequest_Update `protobuf_oneof:"update"`
-	// 标签更新 (可以与其他字段同时更新)
-	Labels        map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+func (x *ResourceRequirements) GetLimits() map[string]string {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type CreateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`       // container ID (md5 hash or UID)
+	SandboxName   string                 `protobuf:"bytes,4,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	AgentPod      string                 `protobuf:"bytes,2,opt,name=agent_pod,json=agentPod,proto3" json:"agent_pod,omitempty"`
+	Endpoints     []string               `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"` // IP:Port 列表
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateRequest) Reset() {
-	*x = UpdateRequest{}
+func (x *CreateResponse) Reset() {
+	*x = CreateResponse{}
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateRequest) String() string {
+func (x *CreateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateRequest) ProtoMessage() {}
+func (*CreateResponse) ProtoMessage() {}
 
-func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+func (x *CreateResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_v1_fastpath_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -683,127 +952,1738 @@ func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
-func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
+func (*CreateResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateResponse) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *CreateResponse) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *CreateResponse) GetAgentPod() string {
+	if x != nil {
+		return x.AgentPod
+	}
+	return ""
+}
+
+func (x *CreateResponse) GetEndpoints() []string {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type CreateSandboxesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*CreateRequest       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	AllOrNothing  bool                   `protobuf:"varint,2,opt,name=all_or_nothing,json=allOrNothing,proto3" json:"all_or_nothing,omitempty"` // 任一条失败时回滚已创建的沙箱，整批失败
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSandboxesRequest) Reset() {
+	*x = CreateSandboxesRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSandboxesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSandboxesRequest) ProtoMessage() {}
+
+func (x *CreateSandboxesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSandboxesRequest.ProtoReflect.Descriptor instead.
+func (*CreateSandboxesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateSandboxesRequest) GetItems() []*CreateRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CreateSandboxesRequest) GetAllOrNothing() bool {
+	if x != nil {
+		return x.AllOrNothing
+	}
+	return false
+}
+
+type CreateSandboxesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*CreateResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 与 items 顺序一一对应
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSandboxesResponse) Reset() {
+	*x = CreateSandboxesResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSandboxesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSandboxesResponse) ProtoMessage() {}
+
+func (x *CreateSandboxesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSandboxesResponse.ProtoReflect.Descriptor instead.
+func (*CreateSandboxesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateSandboxesResponse) GetResults() []*CreateResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type CreateResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Sandbox       *CreateResponse        `protobuf:"bytes,2,opt,name=sandbox,proto3" json:"sandbox,omitempty"` // 成功时返回
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`     // 失败原因
+	Code          int32                  `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`      // 失败时的 gRPC 状态码
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateResult) Reset() {
+	*x = CreateResult{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateResult) ProtoMessage() {}
+
+func (x *CreateResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateResult.ProtoReflect.Descriptor instead.
+func (*CreateResult) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CreateResult) GetSandbox() *CreateResponse {
+	if x != nil {
+		return x.Sandbox
+	}
+	return nil
+}
+
+func (x *CreateResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CreateResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName   string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`                        // 可选，默认为 "default"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteRequest) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *DeleteRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type UpdateRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Namespace   string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// 可更新字段 (only one at a time, except labels)
+	//
+	// Types that are valid to be assigned to Update:
+	//
+	//	*UpdateRequest_ExpireTimeSeconds
+	//	*UpdateRequest_ResetRevision
+	//	*UpdateRequest_FailurePolicy
+	//	*UpdateRequest_RecoveryTimeoutSeconds
+	//	*UpdateRequest_Paused
+	//	*UpdateRequest_IdleTimeoutSeconds
+	Update isUpdateRequest_Update `protobuf_oneof:"update"`
+	// 标签更新 (可以与其他字段同时更新)
+	Labels        map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateRequest) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetUpdate() isUpdateRequest_Update {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetExpireTimeSeconds() int64 {
+	if x != nil {
+		if x, ok := x.Update.(*UpdateRequest_ExpireTimeSeconds); ok {
+			return x.ExpireTimeSeconds
+		}
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetResetRevision() string {
+	if x != nil {
+		if x, ok := x.Update.(*UpdateRequest_ResetRevision); ok {
+			return x.ResetRevision
+		}
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetFailurePolicy() FailurePolicy {
+	if x != nil {
+		if x, ok := x.Update.(*UpdateRequest_FailurePolicy); ok {
+			return x.FailurePolicy
+		}
+	}
+	return FailurePolicy_MANUAL
+}
+
+func (x *UpdateRequest) GetRecoveryTimeoutSeconds() int32 {
+	if x != nil {
+		if x, ok := x.Update.(*UpdateRequest_RecoveryTimeoutSeconds); ok {
+			return x.RecoveryTimeoutSeconds
+		}
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetPaused() bool {
+	if x != nil {
+		if x, ok := x.Update.(*UpdateRequest_Paused); ok {
+			return x.Paused
+		}
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetIdleTimeoutSeconds() int32 {
+	if x != nil {
+		if x, ok := x.Update.(*UpdateRequest_IdleTimeoutSeconds); ok {
+			return x.IdleTimeoutSeconds
+		}
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type isUpdateRequest_Update interface {
+	isUpdateRequest_Update()
+}
+
+type UpdateRequest_ExpireTimeSeconds struct {
+	ExpireTimeSeconds int64 `protobuf:"varint,3,opt,name=expire_time_seconds,json=expireTimeSeconds,proto3,oneof"` // Unix timestamp, 0 = remove expiry
+}
+
+type UpdateRequest_ResetRevision struct {
+	ResetRevision string `protobuf:"bytes,4,opt,name=reset_revision,json=resetRevision,proto3,oneof"` // ISO8601 timestamp, 触发重启
+}
+
+type UpdateRequest_FailurePolicy struct {
+	FailurePolicy FailurePolicy `protobuf:"varint,5,opt,name=failure_policy,json=failurePolicy,proto3,enum=fastpath.v1.FailurePolicy,oneof"` // 更新故障策略
+}
+
+type UpdateRequest_RecoveryTimeoutSeconds struct {
+	RecoveryTimeoutSeconds int32 `protobuf:"varint,6,opt,name=recovery_timeout_seconds,json=recoveryTimeoutSeconds,proto3,oneof"`
+}
+
+type UpdateRequest_Paused struct {
+	Paused bool `protobuf:"varint,8,opt,name=paused,proto3,oneof"` // true 冻结沙箱（保留槽位与端口），false 恢复
+}
+
+type UpdateRequest_IdleTimeoutSeconds struct {
+	IdleTimeoutSeconds int32 `protobuf:"varint,9,opt,name=idle_timeout_seconds,json=idleTimeoutSeconds,proto3,oneof"` // 空闲超时，0 关闭
+}
+
+func (*UpdateRequest_ExpireTimeSeconds) isUpdateRequest_Update() {}
+
+func (*UpdateRequest_ResetRevision) isUpdateRequest_Update() {}
+
+func (*UpdateRequest_FailurePolicy) isUpdateRequest_Update() {}
+
+func (*UpdateRequest_RecoveryTimeoutSeconds) isUpdateRequest_Update() {}
+
+func (*UpdateRequest_Paused) isUpdateRequest_Update() {}
+
+func (*UpdateRequest_IdleTimeoutSeconds) isUpdateRequest_Update() {}
+
+type UpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Sandbox       *SandboxInfo           `protobuf:"bytes,3,opt,name=sandbox,proto3" json:"sandbox,omitempty"` // 更新后的状态
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateResponse) Reset() {
+	*x = UpdateResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateResponse) ProtoMessage() {}
+
+func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateResponse) GetSandbox() *SandboxInfo {
+	if x != nil {
+		return x.Sandbox
+	}
+	return nil
+}
+
+// ExecRequest 第一条消息必须是 start，之后的消息传输 stdin
+type ExecRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExecRequest_Start
+	//	*ExecRequest_Stdin
+	//	*ExecRequest_CloseStdin
+	//	*ExecRequest_Resize
+	Payload       isExecRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRequest) ProtoMessage() {}
+
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExecRequest) GetPayload() isExecRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetStart() *ExecStart {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetStdin() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_Stdin); ok {
+			return x.Stdin
+		}
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetCloseStdin() bool {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_CloseStdin); ok {
+			return x.CloseStdin
+		}
+	}
+	return false
+}
+
+func (x *ExecRequest) GetResize() *TerminalSize {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecRequest_Resize); ok {
+			return x.Resize
+		}
+	}
+	return nil
+}
+
+type isExecRequest_Payload interface {
+	isExecRequest_Payload()
+}
+
+type ExecRequest_Start struct {
+	Start *ExecStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type ExecRequest_Stdin struct {
+	Stdin []byte `protobuf:"bytes,2,opt,name=stdin,proto3,oneof"`
+}
+
+type ExecRequest_CloseStdin struct {
+	CloseStdin bool `protobuf:"varint,3,opt,name=close_stdin,json=closeStdin,proto3,oneof"` // stdin 结束（EOF）
+}
+
+type ExecRequest_Resize struct {
+	Resize *TerminalSize `protobuf:"bytes,4,opt,name=resize,proto3,oneof"` // 终端窗口大小变化（仅 tty）
+}
+
+func (*ExecRequest_Start) isExecRequest_Payload() {}
+
+func (*ExecRequest_Stdin) isExecRequest_Payload() {}
+
+func (*ExecRequest_CloseStdin) isExecRequest_Payload() {}
+
+func (*ExecRequest_Resize) isExecRequest_Payload() {}
+
+type ExecStart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName   string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`                        // optional, default "default"
+	Command       []string               `protobuf:"bytes,3,rep,name=command,proto3" json:"command,omitempty"`
+	Envs          map[string]string      `protobuf:"bytes,4,rep,name=envs,proto3" json:"envs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	WorkingDir    string                 `protobuf:"bytes,5,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	Stdin         bool                   `protobuf:"varint,6,opt,name=stdin,proto3" json:"stdin,omitempty"` // 是否保持 stdin 打开
+	Tty           bool                   `protobuf:"varint,7,opt,name=tty,proto3" json:"tty,omitempty"`     // 分配伪终端，stderr 合并到 stdout
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecStart) Reset() {
+	*x = ExecStart{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecStart) ProtoMessage() {}
+
+func (x *ExecStart) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecStart.ProtoReflect.Descriptor instead.
+func (*ExecStart) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExecStart) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *ExecStart) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ExecStart) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *ExecStart) GetEnvs() map[string]string {
+	if x != nil {
+		return x.Envs
+	}
+	return nil
+}
+
+func (x *ExecStart) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *ExecStart) GetStdin() bool {
+	if x != nil {
+		return x.Stdin
+	}
+	return false
+}
+
+func (x *ExecStart) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
+}
+
+type TerminalSize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Width         uint32                 `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height        uint32                 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminalSize) Reset() {
+	*x = TerminalSize{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminalSize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminalSize) ProtoMessage() {}
+
+func (x *TerminalSize) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminalSize.ProtoReflect.Descriptor instead.
+func (*TerminalSize) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TerminalSize) GetWidth() uint32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *TerminalSize) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// AttachRequest 第一条消息必须是 start，之后的消息传输 stdin 和窗口大小
+type AttachRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*AttachRequest_Start
+	//	*AttachRequest_Stdin
+	//	*AttachRequest_CloseStdin
+	//	*AttachRequest_Resize
+	Payload       isAttachRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachRequest) Reset() {
+	*x = AttachRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachRequest) ProtoMessage() {}
+
+func (x *AttachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachRequest.ProtoReflect.Descriptor instead.
+func (*AttachRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AttachRequest) GetPayload() isAttachRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *AttachRequest) GetStart() *AttachStart {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachRequest_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *AttachRequest) GetStdin() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachRequest_Stdin); ok {
+			return x.Stdin
+		}
+	}
+	return nil
+}
+
+func (x *AttachRequest) GetCloseStdin() bool {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachRequest_CloseStdin); ok {
+			return x.CloseStdin
+		}
+	}
+	return false
+}
+
+func (x *AttachRequest) GetResize() *TerminalSize {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachRequest_Resize); ok {
+			return x.Resize
+		}
+	}
+	return nil
+}
+
+type isAttachRequest_Payload interface {
+	isAttachRequest_Payload()
+}
+
+type AttachRequest_Start struct {
+	Start *AttachStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type AttachRequest_Stdin struct {
+	Stdin []byte `protobuf:"bytes,2,opt,name=stdin,proto3,oneof"`
+}
+
+type AttachRequest_CloseStdin struct {
+	CloseStdin bool `protobuf:"varint,3,opt,name=close_stdin,json=closeStdin,proto3,oneof"` // 仅断开 stdin，不关闭主进程的 stdin
+}
+
+type AttachRequest_Resize struct {
+	Resize *TerminalSize `protobuf:"bytes,4,opt,name=resize,proto3,oneof"`
+}
+
+func (*AttachRequest_Start) isAttachRequest_Payload() {}
+
+func (*AttachRequest_Stdin) isAttachRequest_Payload() {}
+
+func (*AttachRequest_CloseStdin) isAttachRequest_Payload() {}
+
+func (*AttachRequest_Resize) isAttachRequest_Payload() {}
+
+type AttachStart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName   string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`                        // optional, default "default"
+	Stdin         bool                   `protobuf:"varint,3,opt,name=stdin,proto3" json:"stdin,omitempty"`                               // 需要沙箱创建时开启 stdin
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachStart) Reset() {
+	*x = AttachStart{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachStart) ProtoMessage() {}
+
+func (x *AttachStart) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachStart.ProtoReflect.Descriptor instead.
+func (*AttachStart) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AttachStart) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *AttachStart) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *AttachStart) GetStdin() bool {
+	if x != nil {
+		return x.Stdin
+	}
+	return false
+}
+
+type ExecResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExecResponse_Stdout
+	//	*ExecResponse_Stderr
+	//	*ExecResponse_Exit
+	Payload       isExecResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecResponse) Reset() {
+	*x = ExecResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecResponse) ProtoMessage() {}
+
+func (x *ExecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
+func (*ExecResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ExecResponse) GetPayload() isExecResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetStdout() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_Stdout); ok {
+			return x.Stdout
+		}
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetStderr() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_Stderr); ok {
+			return x.Stderr
+		}
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetExit() *ExecExit {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecResponse_Exit); ok {
+			return x.Exit
+		}
+	}
+	return nil
+}
+
+type isExecResponse_Payload interface {
+	isExecResponse_Payload()
+}
+
+type ExecResponse_Stdout struct {
+	Stdout []byte `protobuf:"bytes,1,opt,name=stdout,proto3,oneof"`
+}
+
+type ExecResponse_Stderr struct {
+	Stderr []byte `protobuf:"bytes,2,opt,name=stderr,proto3,oneof"`
+}
+
+type ExecResponse_Exit struct {
+	Exit *ExecExit `protobuf:"bytes,3,opt,name=exit,proto3,oneof"` // 最后一条消息
+}
+
+func (*ExecResponse_Stdout) isExecResponse_Payload() {}
+
+func (*ExecResponse_Stderr) isExecResponse_Payload() {}
+
+func (*ExecResponse_Exit) isExecResponse_Payload() {}
+
+type ExecExit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"` // 非空表示执行失败（而非命令本身返回非零）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecExit) Reset() {
+	*x = ExecExit{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecExit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecExit) ProtoMessage() {}
+
+func (x *ExecExit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecExit.ProtoReflect.Descriptor instead.
+func (*ExecExit) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ExecExit) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecExit) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CopyTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName   string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`                        // optional, default "default"
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`                                  // 沙箱内的绝对路径
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyTarget) Reset() {
+	*x = CopyTarget{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyTarget) ProtoMessage() {}
+
+func (x *CopyTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyTarget.ProtoReflect.Descriptor instead.
+func (*CopyTarget) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CopyTarget) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *CopyTarget) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *CopyTarget) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// CopyToRequest 第一条消息必须是 target（解压目录），之后的消息传输 tar 数据
+type CopyToRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*CopyToRequest_Target
+	//	*CopyToRequest_Data
+	Payload       isCopyToRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyToRequest) Reset() {
+	*x = CopyToRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyToRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyToRequest) ProtoMessage() {}
+
+func (x *CopyToRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyToRequest.ProtoReflect.Descriptor instead.
+func (*CopyToRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CopyToRequest) GetPayload() isCopyToRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *CopyToRequest) GetTarget() *CopyTarget {
+	if x != nil {
+		if x, ok := x.Payload.(*CopyToRequest_Target); ok {
+			return x.Target
+		}
+	}
+	return nil
+}
+
+func (x *CopyToRequest) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*CopyToRequest_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+type isCopyToRequest_Payload interface {
+	isCopyToRequest_Payload()
+}
+
+type CopyToRequest_Target struct {
+	Target *CopyTarget `protobuf:"bytes,1,opt,name=target,proto3,oneof"`
+}
+
+type CopyToRequest_Data struct {
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*CopyToRequest_Target) isCopyToRequest_Payload() {}
+
+func (*CopyToRequest_Data) isCopyToRequest_Payload() {}
+
+type CopyToResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyToResponse) Reset() {
+	*x = CopyToResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyToResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyToResponse) ProtoMessage() {}
+
+func (x *CopyToResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyToResponse.ProtoReflect.Descriptor instead.
+func (*CopyToResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CopyToResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type CopyFromRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        *CopyTarget            `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"` // tar 条目以 path 的最后一级为根
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyFromRequest) Reset() {
+	*x = CopyFromRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyFromRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyFromRequest) ProtoMessage() {}
+
+func (x *CopyFromRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyFromRequest.ProtoReflect.Descriptor instead.
+func (*CopyFromRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CopyFromRequest) GetTarget() *CopyTarget {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+type CopyFromResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyFromResponse) Reset() {
+	*x = CopyFromResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyFromResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyFromResponse) ProtoMessage() {}
+
+func (x *CopyFromResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyFromResponse.ProtoReflect.Descriptor instead.
+func (*CopyFromResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CopyFromResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type LogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName   string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // CRD name (user-provided)
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`                        // optional, default "default"
+	Follow        bool                   `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`                             // 持续跟踪新日志
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogsRequest) Reset() {
+	*x = LogsRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogsRequest) ProtoMessage() {}
+
+func (x *LogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogsRequest.ProtoReflect.Descriptor instead.
+func (*LogsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *LogsRequest) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *LogsRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *LogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+type LogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogsResponse) Reset() {
+	*x = LogsResponse{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogsResponse) ProtoMessage() {}
+
+func (x *LogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogsResponse.ProtoReflect.Descriptor instead.
+func (*LogsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *LogsResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`                              // 可选，为空时 watch 所有 namespace
+	PoolRef       string                 `protobuf:"bytes,2,opt,name=pool_ref,json=poolRef,proto3" json:"pool_ref,omitempty"`                   // 可选，只推送该池的沙箱
+	LabelSelector string                 `protobuf:"bytes,3,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"` // 可选，K8s label selector，如 "app=web,tier!=db"
+	SandboxName   string                 `protobuf:"bytes,4,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"`       // 可选，只推送该名称的沙箱
+	// 可选，从该版本之后续传；为空时先以 ADDED 推送当前全部沙箱，再以 BOOKMARK 标记初始列表结束
+	ResourceVersion string `protobuf:"bytes,5,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *UpdateRequest) GetSandboxName() string {
+func (x *WatchRequest) GetNamespace() string {
 	if x != nil {
-		return x.SandboxName
+		return x.Namespace
 	}
 	return ""
 }
 
-func (x *UpdateRequest) GetNamespace() string {
+func (x *WatchRequest) GetPoolRef() string {
 	if x != nil {
-		return x.Namespace
+		return x.PoolRef
 	}
 	return ""
 }
 
-func (x *UpdateRequest) GetUpdate() isUpdateRequest_Update {
+func (x *WatchRequest) GetLabelSelector() string {
 	if x != nil {
-		return x.Update
+		return x.LabelSelector
 	}
-	return nil
+	return ""
 }
 
-func (x *UpdateRequest) GetExpireTimeSeconds() int64 {
+func (x *WatchRequest) GetSandboxName() string {
 	if x != nil {
-		if x, ok := x.Update.(*UpdateRequest_ExpireTimeSeconds); ok {
-			return x.ExpireTimeSeconds
-		}
+		return x.SandboxName
 	}
-	return 0
+	return ""
 }
 
-func (x *UpdateRequest) GetResetRevision() string {
+func (x *WatchRequest) GetResourceVersion() string {
 	if x != nil {
-		if x, ok := x.Update.(*UpdateRequest_ResetRevision); ok {
-			return x.ResetRevision
-		}
+		return x.ResourceVersion
 	}
 	return ""
 }
 
-func (x *UpdateRequest) GetFailurePolicy() FailurePolicy {
+type WatchEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Type            WatchEventType         `protobuf:"varint,1,opt,name=type,proto3,enum=fastpath.v1.WatchEventType" json:"type,omitempty"`
+	Sandbox         *SandboxInfo           `protobuf:"bytes,2,opt,name=sandbox,proto3" json:"sandbox,omitempty"`
+	ResourceVersion string                 `protobuf:"bytes,3,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"` // 断线后作为 WatchRequest.resource_version 续传
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[31]
 	if x != nil {
-		if x, ok := x.Update.(*UpdateRequest_FailurePolicy); ok {
-			return x.FailurePolicy
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return FailurePolicy_MANUAL
+	return mi.MessageOf(x)
 }
 
-func (x *UpdateRequest) GetRecoveryTimeoutSeconds() int32 {
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *WatchEvent) GetType() WatchEventType {
 	if x != nil {
-		if x, ok := x.Update.(*UpdateRequest_RecoveryTimeoutSeconds); ok {
-			return x.RecoveryTimeoutSeconds
-		}
+		return x.Type
 	}
-	return 0
+	return WatchEventType_ADDED
 }
 
-func (x *UpdateRequest) GetLabels() map[string]string {
+func (x *WatchEvent) GetSandbox() *SandboxInfo {
 	if x != nil {
-		return x.Labels
+		return x.Sandbox
 	}
 	return nil
 }
 
-type isUpdateRequest_Update interface {
-	isUpdateRequest_Update()
+func (x *WatchEvent) GetResourceVersion() string {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return ""
 }
 
-type UpdateRequest_ExpireTimeSeconds struct {
-	ExpireTimeSeconds int64 `protobuf:"varint,3,opt,name=expire_time_seconds,json=expireTimeSeconds,proto3,oneof"` // Unix timestamp, 0 = remove expiry
+type CheckpointRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SandboxName    string                 `protobuf:"bytes,1,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"` // 要 checkpoint 的沙箱
+	Namespace      string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name           string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                                           // 可选，SandboxCheckpoint 名称，默认 <sandbox_name>-<unix 时间戳>
+	Image          string                 `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`                                         // 可选，推送到的 OCI 镜像；为空时保存在沙箱所在节点，只能在该节点恢复
+	RegistrySecret string                 `protobuf:"bytes,5,opt,name=registry_secret,json=registrySecret,proto3" json:"registry_secret,omitempty"` // 可选，同 namespace 中 image 仓库的 kubernetes.io/dockerconfigjson Secret，推送与恢复时使用
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-type UpdateRequest_ResetRevision struct {
-	ResetRevision string `protobuf:"bytes,4,opt,name=reset_revision,json=resetRevision,proto3,oneof"` // ISO8601 timestamp, 触发重启
+func (x *CheckpointRequest) Reset() {
+	*x = CheckpointRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type UpdateRequest_FailurePolicy struct {
-	FailurePolicy FailurePolicy `protobuf:"varint,5,opt,name=failure_policy,json=failurePolicy,proto3,enum=fastpath.v1.FailurePolicy,oneof"` // 更新故障策略
+func (x *CheckpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type UpdateRequest_RecoveryTimeoutSeconds struct {
-	RecoveryTimeoutSeconds int32 `protobuf:"varint,6,opt,name=recovery_timeout_seconds,json=recoveryTimeoutSeconds,proto3,oneof"`
+func (*CheckpointRequest) ProtoMessage() {}
+
+func (x *CheckpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (*UpdateRequest_ExpireTimeSeconds) isUpdateRequest_Update() {}
+// Deprecated: Use CheckpointRequest.ProtoReflect.Descriptor instead.
+func (*CheckpointRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{32}
+}
 
-func (*UpdateRequest_ResetRevision) isUpdateRequest_Update() {}
+func (x *CheckpointRequest) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
 
-func (*UpdateRequest_FailurePolicy) isUpdateRequest_Update() {}
+func (x *CheckpointRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
 
-func (*UpdateRequest_RecoveryTimeoutSeconds) isUpdateRequest_Update() {}
+func (x *CheckpointRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
 
-type UpdateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Sandbox       *SandboxInfo           `protobuf:"bytes,3,opt,name=sandbox,proto3" json:"sandbox,omitempty"` // 更新后的状态
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *CheckpointRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
 }
 
-func (x *UpdateResponse) Reset() {
-	*x = UpdateResponse{}
-	mi := &file_api_proto_v1_fastpath_proto_msgTypes[9]
+func (x *CheckpointRequest) GetRegistrySecret() string {
+	if x != nil {
+		return x.RegistrySecret
+	}
+	return ""
+}
+
+type CheckpointInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace      string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	SandboxName    string                 `protobuf:"bytes,3,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"`
+	Phase          string                 `protobuf:"bytes,4,opt,name=phase,proto3" json:"phase,omitempty"` // Ready / Failed
+	Message        string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Ref            string                 `protobuf:"bytes,6,opt,name=ref,proto3" json:"ref,omitempty"`                           // checkpoint 镜像，推送时即 image
+	NodeName       string                 `protobuf:"bytes,7,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"` // checkpoint 所在节点
+	PoolRef        string                 `protobuf:"bytes,8,opt,name=pool_ref,json=poolRef,proto3" json:"pool_ref,omitempty"`    // 恢复出的沙箱使用同一个池
+	CheckpointedAt int64                  `protobuf:"varint,9,opt,name=checkpointed_at,json=checkpointedAt,proto3" json:"checkpointed_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CheckpointInfo) Reset() {
+	*x = CheckpointInfo{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateResponse) String() string {
+func (x *CheckpointInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateResponse) ProtoMessage() {}
+func (*CheckpointInfo) ProtoMessage() {}
 
-func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_fastpath_proto_msgTypes[9]
+func (x *CheckpointInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -814,30 +2694,140 @@ func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
-func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use CheckpointInfo.ProtoReflect.Descriptor instead.
+func (*CheckpointInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *UpdateResponse) GetSuccess() bool {
+func (x *CheckpointInfo) GetName() string {
 	if x != nil {
-		return x.Success
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *UpdateResponse) GetMessage() string {
+func (x *CheckpointInfo) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *CheckpointInfo) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *CheckpointInfo) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *CheckpointInfo) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdateResponse) GetSandbox() *SandboxInfo {
+func (x *CheckpointInfo) GetRef() string {
 	if x != nil {
-		return x.Sandbox
+		return x.Ref
 	}
-	return nil
+	return ""
+}
+
+func (x *CheckpointInfo) GetNodeName() string {
+	if x != nil {
+		return x.NodeName
+	}
+	return ""
+}
+
+func (x *CheckpointInfo) GetPoolRef() string {
+	if x != nil {
+		return x.PoolRef
+	}
+	return ""
+}
+
+func (x *CheckpointInfo) GetCheckpointedAt() int64 {
+	if x != nil {
+		return x.CheckpointedAt
+	}
+	return 0
+}
+
+type RestoreRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CheckpointName  string                 `protobuf:"bytes,1,opt,name=checkpoint_name,json=checkpointName,proto3" json:"checkpoint_name,omitempty"`
+	Namespace       string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name            string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                                                                                // 可选，新沙箱名称
+	ConsistencyMode ConsistencyMode        `protobuf:"varint,4,opt,name=consistency_mode,json=consistencyMode,proto3,enum=fastpath.v1.ConsistencyMode" json:"consistency_mode,omitempty"` // 可选，默认使用 Controller 配置
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_fastpath_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_fastpath_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RestoreRequest) GetCheckpointName() string {
+	if x != nil {
+		return x.CheckpointName
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetConsistencyMode() ConsistencyMode {
+	if x != nil {
+		return x.ConsistencyMode
+	}
+	return ConsistencyMode_FAST
 }
 
 var File_api_proto_v1_fastpath_proto protoreflect.FileDescriptor
@@ -852,7 +2842,7 @@ const file_api_proto_v1_fastpath_proto_rawDesc = "" +
 	"\n" +
 	"GetRequest\x12!\n" +
 	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
-	"\tnamespace\x18\x02 \x01(\tR\tnamespace\"\xf0\x01\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\"\xe5\x03\n" +
 	"\vSandboxInfo\x12\x1d\n" +
 	"\n" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12!\n" +
@@ -863,7 +2853,17 @@ const file_api_proto_v1_fastpath_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x14\n" +
 	"\x05image\x18\x06 \x01(\tR\x05image\x12\x19\n" +
-	"\bpool_ref\x18\a \x01(\tR\apoolRef\"\xa2\x03\n" +
+	"\bpool_ref\x18\a \x01(\tR\apoolRef\x12\x1b\n" +
+	"\texit_code\x18\t \x01(\x05R\bexitCode\x12\x1f\n" +
+	"\vfinished_at\x18\n" +
+	" \x01(\x03R\n" +
+	"finishedAt\x12\x16\n" +
+	"\x06reason\x18\v \x01(\tR\x06reason\x12#\n" +
+	"\rrestart_count\x18\f \x01(\x05R\frestartCount\x12\x1c\n" +
+	"\tnamespace\x18\r \x01(\tR\tnamespace\x12\x1a\n" +
+	"\bpriority\x18\x0e \x01(\x05R\bpriority\x12(\n" +
+	"\x10last_activity_at\x18\x0f \x01(\x03R\x0elastActivityAt\x12\x14\n" +
+	"\x05ready\x18\x10 \x01(\bR\x05ready\"\xd7\a\n" +
 	"\rCreateRequest\x12\x14\n" +
 	"\x05image\x18\x01 \x01(\tR\x05image\x12\x19\n" +
 	"\bpool_ref\x18\x02 \x01(\tR\apoolRef\x12#\n" +
@@ -876,28 +2876,80 @@ const file_api_proto_v1_fastpath_proto_rawDesc = "" +
 	"\x04envs\x18\t \x03(\v2$.fastpath.v1.CreateRequest.EnvsEntryR\x04envs\x12\x1f\n" +
 	"\vworking_dir\x18\n" +
 	" \x01(\tR\n" +
-	"workingDir\x1a7\n" +
+	"workingDir\x12\x10\n" +
+	"\x03tty\x18\v \x01(\bR\x03tty\x12\x14\n" +
+	"\x05stdin\x18\f \x01(\bR\x05stdin\x12?\n" +
+	"\tresources\x18\r \x01(\v2!.fastpath.v1.ResourceRequirementsR\tresources\x12%\n" +
+	"\x0erestart_policy\x18\x0e \x01(\tR\rrestartPolicy\x12'\n" +
+	"\x0fidempotency_key\x18\x0f \x01(\tR\x0eidempotencyKey\x120\n" +
+	"\x14wait_timeout_seconds\x18\x10 \x01(\x05R\x12waitTimeoutSeconds\x12\x1a\n" +
+	"\bpriority\x18\x11 \x01(\x05R\bpriority\x120\n" +
+	"\x14idle_timeout_seconds\x18\x12 \x01(\x05R\x12idleTimeoutSeconds\x12\x1f\n" +
+	"\vidle_policy\x18\x13 \x01(\tR\n" +
+	"idlePolicy\x12@\n" +
+	"\x1attl_seconds_after_finished\x18\x14 \x01(\x05H\x00R\x17ttlSecondsAfterFinished\x88\x01\x01\x12D\n" +
+	"\x0freadiness_probe\x18\x15 \x01(\v2\x1b.fastpath.v1.ReadinessProbeR\x0ereadinessProbe\x122\n" +
+	"\x15ready_timeout_seconds\x18\x16 \x01(\x05R\x13readyTimeoutSeconds\x1a7\n" +
 	"\tEnvsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x1d\n" +
+	"\x1b_ttl_seconds_after_finished\"\xd3\x02\n" +
+	"\x0eReadinessProbe\x12\x12\n" +
+	"\x04exec\x18\x01 \x03(\tR\x04exec\x124\n" +
+	"\bhttp_get\x18\x02 \x01(\v2\x19.fastpath.v1.HTTPGetProbeR\ahttpGet\x12\x19\n" +
+	"\btcp_port\x18\x03 \x01(\x05R\atcpPort\x122\n" +
+	"\x15initial_delay_seconds\x18\x04 \x01(\x05R\x13initialDelaySeconds\x12%\n" +
+	"\x0eperiod_seconds\x18\x05 \x01(\x05R\rperiodSeconds\x12'\n" +
+	"\x0ftimeout_seconds\x18\x06 \x01(\x05R\x0etimeoutSeconds\x12+\n" +
+	"\x11success_threshold\x18\a \x01(\x05R\x10successThreshold\x12+\n" +
+	"\x11failure_threshold\x18\b \x01(\x05R\x10failureThreshold\"\xcc\x01\n" +
+	"\fHTTPGetProbe\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06scheme\x18\x03 \x01(\tR\x06scheme\x12@\n" +
+	"\aheaders\x18\x04 \x03(\v2&.fastpath.v1.HTTPGetProbe.HeadersEntryR\aheaders\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa2\x02\n" +
+	"\x14ResourceRequirements\x12K\n" +
+	"\brequests\x18\x01 \x03(\v2/.fastpath.v1.ResourceRequirements.RequestsEntryR\brequests\x12E\n" +
+	"\x06limits\x18\x02 \x03(\v2-.fastpath.v1.ResourceRequirements.LimitsEntryR\x06limits\x1a;\n" +
+	"\rRequestsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8d\x01\n" +
 	"\x0eCreateResponse\x12\x1d\n" +
 	"\n" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12!\n" +
 	"\fsandbox_name\x18\x04 \x01(\tR\vsandboxName\x12\x1b\n" +
 	"\tagent_pod\x18\x02 \x01(\tR\bagentPod\x12\x1c\n" +
-	"\tendpoints\x18\x03 \x03(\tR\tendpoints\"P\n" +
+	"\tendpoints\x18\x03 \x03(\tR\tendpoints\"p\n" +
+	"\x16CreateSandboxesRequest\x120\n" +
+	"\x05items\x18\x01 \x03(\v2\x1a.fastpath.v1.CreateRequestR\x05items\x12$\n" +
+	"\x0eall_or_nothing\x18\x02 \x01(\bR\fallOrNothing\"N\n" +
+	"\x17CreateSandboxesResponse\x123\n" +
+	"\aresults\x18\x01 \x03(\v2\x19.fastpath.v1.CreateResultR\aresults\"\x89\x01\n" +
+	"\fCreateResult\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x125\n" +
+	"\asandbox\x18\x02 \x01(\v2\x1b.fastpath.v1.CreateResponseR\asandbox\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\x05R\x04code\"P\n" +
 	"\rDeleteRequest\x12!\n" +
 	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
 	"\tnamespace\x18\x02 \x01(\tR\tnamespace\"*\n" +
 	"\x0eDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xb1\x03\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xff\x03\n" +
 	"\rUpdateRequest\x12!\n" +
 	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
 	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x120\n" +
 	"\x13expire_time_seconds\x18\x03 \x01(\x03H\x00R\x11expireTimeSeconds\x12'\n" +
 	"\x0ereset_revision\x18\x04 \x01(\tH\x00R\rresetRevision\x12C\n" +
 	"\x0efailure_policy\x18\x05 \x01(\x0e2\x1a.fastpath.v1.FailurePolicyH\x00R\rfailurePolicy\x12:\n" +
-	"\x18recovery_timeout_seconds\x18\x06 \x01(\x05H\x00R\x16recoveryTimeoutSeconds\x12>\n" +
+	"\x18recovery_timeout_seconds\x18\x06 \x01(\x05H\x00R\x16recoveryTimeoutSeconds\x12\x18\n" +
+	"\x06paused\x18\b \x01(\bH\x00R\x06paused\x122\n" +
+	"\x14idle_timeout_seconds\x18\t \x01(\x05H\x00R\x12idleTimeoutSeconds\x12>\n" +
 	"\x06labels\x18\a \x03(\v2&.fastpath.v1.UpdateRequest.LabelsEntryR\x06labels\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
@@ -906,7 +2958,101 @@ const file_api_proto_v1_fastpath_proto_rawDesc = "" +
 	"\x0eUpdateResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x122\n" +
-	"\asandbox\x18\x03 \x01(\v2\x18.fastpath.v1.SandboxInfoR\asandbox*'\n" +
+	"\asandbox\x18\x03 \x01(\v2\x18.fastpath.v1.SandboxInfoR\asandbox\"\xb8\x01\n" +
+	"\vExecRequest\x12.\n" +
+	"\x05start\x18\x01 \x01(\v2\x16.fastpath.v1.ExecStartH\x00R\x05start\x12\x16\n" +
+	"\x05stdin\x18\x02 \x01(\fH\x00R\x05stdin\x12!\n" +
+	"\vclose_stdin\x18\x03 \x01(\bH\x00R\n" +
+	"closeStdin\x123\n" +
+	"\x06resize\x18\x04 \x01(\v2\x19.fastpath.v1.TerminalSizeH\x00R\x06resizeB\t\n" +
+	"\apayload\"\x9e\x02\n" +
+	"\tExecStart\x12!\n" +
+	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x18\n" +
+	"\acommand\x18\x03 \x03(\tR\acommand\x124\n" +
+	"\x04envs\x18\x04 \x03(\v2 .fastpath.v1.ExecStart.EnvsEntryR\x04envs\x12\x1f\n" +
+	"\vworking_dir\x18\x05 \x01(\tR\n" +
+	"workingDir\x12\x14\n" +
+	"\x05stdin\x18\x06 \x01(\bR\x05stdin\x12\x10\n" +
+	"\x03tty\x18\a \x01(\bR\x03tty\x1a7\n" +
+	"\tEnvsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"<\n" +
+	"\fTerminalSize\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\rR\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\rR\x06height\"\xbc\x01\n" +
+	"\rAttachRequest\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x18.fastpath.v1.AttachStartH\x00R\x05start\x12\x16\n" +
+	"\x05stdin\x18\x02 \x01(\fH\x00R\x05stdin\x12!\n" +
+	"\vclose_stdin\x18\x03 \x01(\bH\x00R\n" +
+	"closeStdin\x123\n" +
+	"\x06resize\x18\x04 \x01(\v2\x19.fastpath.v1.TerminalSizeH\x00R\x06resizeB\t\n" +
+	"\apayload\"d\n" +
+	"\vAttachStart\x12!\n" +
+	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x14\n" +
+	"\x05stdin\x18\x03 \x01(\bR\x05stdin\"z\n" +
+	"\fExecResponse\x12\x18\n" +
+	"\x06stdout\x18\x01 \x01(\fH\x00R\x06stdout\x12\x18\n" +
+	"\x06stderr\x18\x02 \x01(\fH\x00R\x06stderr\x12+\n" +
+	"\x04exit\x18\x03 \x01(\v2\x15.fastpath.v1.ExecExitH\x00R\x04exitB\t\n" +
+	"\apayload\"A\n" +
+	"\bExecExit\x12\x1b\n" +
+	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"a\n" +
+	"\n" +
+	"CopyTarget\x12!\n" +
+	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\"c\n" +
+	"\rCopyToRequest\x121\n" +
+	"\x06target\x18\x01 \x01(\v2\x17.fastpath.v1.CopyTargetH\x00R\x06target\x12\x14\n" +
+	"\x04data\x18\x02 \x01(\fH\x00R\x04dataB\t\n" +
+	"\apayload\"*\n" +
+	"\x0eCopyToResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"B\n" +
+	"\x0fCopyFromRequest\x12/\n" +
+	"\x06target\x18\x01 \x01(\v2\x17.fastpath.v1.CopyTargetR\x06target\"&\n" +
+	"\x10CopyFromResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"f\n" +
+	"\vLogsRequest\x12!\n" +
+	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x16\n" +
+	"\x06follow\x18\x03 \x01(\bR\x06follow\"\"\n" +
+	"\fLogsResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\xbc\x01\n" +
+	"\fWatchRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x19\n" +
+	"\bpool_ref\x18\x02 \x01(\tR\apoolRef\x12%\n" +
+	"\x0elabel_selector\x18\x03 \x01(\tR\rlabelSelector\x12!\n" +
+	"\fsandbox_name\x18\x04 \x01(\tR\vsandboxName\x12)\n" +
+	"\x10resource_version\x18\x05 \x01(\tR\x0fresourceVersion\"\x9c\x01\n" +
+	"\n" +
+	"WatchEvent\x12/\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1b.fastpath.v1.WatchEventTypeR\x04type\x122\n" +
+	"\asandbox\x18\x02 \x01(\v2\x18.fastpath.v1.SandboxInfoR\asandbox\x12)\n" +
+	"\x10resource_version\x18\x03 \x01(\tR\x0fresourceVersion\"\xa7\x01\n" +
+	"\x11CheckpointRequest\x12!\n" +
+	"\fsandbox_name\x18\x01 \x01(\tR\vsandboxName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
+	"\x05image\x18\x04 \x01(\tR\x05image\x12'\n" +
+	"\x0fregistry_secret\x18\x05 \x01(\tR\x0eregistrySecret\"\x88\x02\n" +
+	"\x0eCheckpointInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12!\n" +
+	"\fsandbox_name\x18\x03 \x01(\tR\vsandboxName\x12\x14\n" +
+	"\x05phase\x18\x04 \x01(\tR\x05phase\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12\x10\n" +
+	"\x03ref\x18\x06 \x01(\tR\x03ref\x12\x1b\n" +
+	"\tnode_name\x18\a \x01(\tR\bnodeName\x12\x19\n" +
+	"\bpool_ref\x18\b \x01(\tR\apoolRef\x12'\n" +
+	"\x0fcheckpointed_at\x18\t \x01(\x03R\x0echeckpointedAt\"\xb4\x01\n" +
+	"\x0eRestoreRequest\x12'\n" +
+	"\x0fcheckpoint_name\x18\x01 \x01(\tR\x0echeckpointName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12G\n" +
+	"\x10consistency_mode\x18\x04 \x01(\x0e2\x1c.fastpath.v1.ConsistencyModeR\x0fconsistencyMode*'\n" +
 	"\x0fConsistencyMode\x12\b\n" +
 	"\x04FAST\x10\x00\x12\n" +
 	"\n" +
@@ -914,14 +3060,29 @@ const file_api_proto_v1_fastpath_proto_rawDesc = "" +
 	"\rFailurePolicy\x12\n" +
 	"\n" +
 	"\x06MANUAL\x10\x00\x12\x11\n" +
-	"\rAUTO_RECREATE\x10\x012\xf6\x02\n" +
+	"\rAUTO_RECREATE\x10\x01*D\n" +
+	"\x0eWatchEventType\x12\t\n" +
+	"\x05ADDED\x10\x00\x12\f\n" +
+	"\bMODIFIED\x10\x01\x12\v\n" +
+	"\aDELETED\x10\x02\x12\f\n" +
+	"\bBOOKMARK\x10\x032\xb1\b\n" +
 	"\x0fFastPathService\x12H\n" +
-	"\rCreateSandbox\x12\x1a.fastpath.v1.CreateRequest\x1a\x1b.fastpath.v1.CreateResponse\x12H\n" +
+	"\rCreateSandbox\x12\x1a.fastpath.v1.CreateRequest\x1a\x1b.fastpath.v1.CreateResponse\x12\\\n" +
+	"\x0fCreateSandboxes\x12#.fastpath.v1.CreateSandboxesRequest\x1a$.fastpath.v1.CreateSandboxesResponse\x12H\n" +
 	"\rDeleteSandbox\x12\x1a.fastpath.v1.DeleteRequest\x1a\x1b.fastpath.v1.DeleteResponse\x12H\n" +
 	"\rUpdateSandbox\x12\x1a.fastpath.v1.UpdateRequest\x1a\x1b.fastpath.v1.UpdateResponse\x12D\n" +
 	"\rListSandboxes\x12\x18.fastpath.v1.ListRequest\x1a\x19.fastpath.v1.ListResponse\x12?\n" +
 	"\n" +
-	"GetSandbox\x12\x17.fastpath.v1.GetRequest\x1a\x18.fastpath.v1.SandboxInfoB&Z$fast-sandbox/api/proto/v1;fastpathv1b\x06proto3"
+	"GetSandbox\x12\x17.fastpath.v1.GetRequest\x1a\x18.fastpath.v1.SandboxInfo\x12F\n" +
+	"\vExecSandbox\x12\x18.fastpath.v1.ExecRequest\x1a\x19.fastpath.v1.ExecResponse(\x010\x01\x12J\n" +
+	"\rAttachSandbox\x12\x1a.fastpath.v1.AttachRequest\x1a\x19.fastpath.v1.ExecResponse(\x010\x01\x12J\n" +
+	"\rCopyToSandbox\x12\x1a.fastpath.v1.CopyToRequest\x1a\x1b.fastpath.v1.CopyToResponse(\x01\x12P\n" +
+	"\x0fCopyFromSandbox\x12\x1c.fastpath.v1.CopyFromRequest\x1a\x1d.fastpath.v1.CopyFromResponse0\x01\x12C\n" +
+	"\n" +
+	"StreamLogs\x12\x18.fastpath.v1.LogsRequest\x1a\x19.fastpath.v1.LogsResponse0\x01\x12F\n" +
+	"\x0eWatchSandboxes\x12\x19.fastpath.v1.WatchRequest\x1a\x17.fastpath.v1.WatchEvent0\x01\x12P\n" +
+	"\x11CheckpointSandbox\x12\x1e.fastpath.v1.CheckpointRequest\x1a\x1b.fastpath.v1.CheckpointInfo\x12J\n" +
+	"\x0eRestoreSandbox\x12\x1b.fastpath.v1.RestoreRequest\x1a\x1b.fastpath.v1.CreateResponseB&Z$fast-sandbox/api/proto/v1;fastpathv1b\x06proto3"
 
 var (
 	file_api_proto_v1_fastpath_proto_rawDescOnce sync.Once
@@ -935,46 +3096,114 @@ func file_api_proto_v1_fastpath_proto_rawDescGZIP() []byte {
 	return file_api_proto_v1_fastpath_proto_rawDescData
 }
 
-var file_api_proto_v1_fastpath_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_api_proto_v1_fastpath_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_api_proto_v1_fastpath_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_api_proto_v1_fastpath_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_api_proto_v1_fastpath_proto_goTypes = []any{
-	(ConsistencyMode)(0),   // 0: fastpath.v1.ConsistencyMode
-	(FailurePolicy)(0),     // 1: fastpath.v1.FailurePolicy
-	(*ListRequest)(nil),    // 2: fastpath.v1.ListRequest
-	(*ListResponse)(nil),   // 3: fastpath.v1.ListResponse
-	(*GetRequest)(nil),     // 4: fastpath.v1.GetRequest
-	(*SandboxInfo)(nil),    // 5: fastpath.v1.SandboxInfo
-	(*CreateRequest)(nil),  // 6: fastpath.v1.CreateRequest
-	(*CreateResponse)(nil), // 7: fastpath.v1.CreateResponse
-	(*DeleteRequest)(nil),  // 8: fastpath.v1.DeleteRequest
-	(*DeleteResponse)(nil), // 9: fastpath.v1.DeleteResponse
-	(*UpdateRequest)(nil),  // 10: fastpath.v1.UpdateRequest
-	(*UpdateResponse)(nil), // 11: fastpath.v1.UpdateResponse
-	nil,                    // 12: fastpath.v1.CreateRequest.EnvsEntry
-	nil,                    // 13: fastpath.v1.UpdateRequest.LabelsEntry
+	(ConsistencyMode)(0),            // 0: fastpath.v1.ConsistencyMode
+	(FailurePolicy)(0),              // 1: fastpath.v1.FailurePolicy
+	(WatchEventType)(0),             // 2: fastpath.v1.WatchEventType
+	(*ListRequest)(nil),             // 3: fastpath.v1.ListRequest
+	(*ListResponse)(nil),            // 4: fastpath.v1.ListResponse
+	(*GetRequest)(nil),              // 5: fastpath.v1.GetRequest
+	(*SandboxInfo)(nil),             // 6: fastpath.v1.SandboxInfo
+	(*CreateRequest)(nil),           // 7: fastpath.v1.CreateRequest
+	(*ReadinessProbe)(nil),          // 8: fastpath.v1.ReadinessProbe
+	(*HTTPGetProbe)(nil),            // 9: fastpath.v1.HTTPGetProbe
+	(*ResourceRequirements)(nil),    // 10: fastpath.v1.ResourceRequirements
+	(*CreateResponse)(nil),          // 11: fastpath.v1.CreateResponse
+	(*CreateSandboxesRequest)(nil),  // 12: fastpath.v1.CreateSandboxesRequest
+	(*CreateSandboxesResponse)(nil), // 13: fastpath.v1.CreateSandboxesResponse
+	(*CreateResult)(nil),            // 14: fastpath.v1.CreateResult
+	(*DeleteRequest)(nil),           // 15: fastpath.v1.DeleteRequest
+	(*DeleteResponse)(nil),          // 16: fastpath.v1.DeleteResponse
+	(*UpdateRequest)(nil),           // 17: fastpath.v1.UpdateRequest
+	(*UpdateResponse)(nil),          // 18: fastpath.v1.UpdateResponse
+	(*ExecRequest)(nil),             // 19: fastpath.v1.ExecRequest
+	(*ExecStart)(nil),               // 20: fastpath.v1.ExecStart
+	(*TerminalSize)(nil),            // 21: fastpath.v1.TerminalSize
+	(*AttachRequest)(nil),           // 22: fastpath.v1.AttachRequest
+	(*AttachStart)(nil),             // 23: fastpath.v1.AttachStart
+	(*ExecResponse)(nil),            // 24: fastpath.v1.ExecResponse
+	(*ExecExit)(nil),                // 25: fastpath.v1.ExecExit
+	(*CopyTarget)(nil),              // 26: fastpath.v1.CopyTarget
+	(*CopyToRequest)(nil),           // 27: fastpath.v1.CopyToRequest
+	(*CopyToResponse)(nil),          // 28: fastpath.v1.CopyToResponse
+	(*CopyFromRequest)(nil),         // 29: fastpath.v1.CopyFromRequest
+	(*CopyFromResponse)(nil),        // 30: fastpath.v1.CopyFromResponse
+	(*LogsRequest)(nil),             // 31: fastpath.v1.LogsRequest
+	(*LogsResponse)(nil),            // 32: fastpath.v1.LogsResponse
+	(*WatchRequest)(nil),            // 33: fastpath.v1.WatchRequest
+	(*WatchEvent)(nil),              // 34: fastpath.v1.WatchEvent
+	(*CheckpointRequest)(nil),       // 35: fastpath.v1.CheckpointRequest
+	(*CheckpointInfo)(nil),          // 36: fastpath.v1.CheckpointInfo
+	(*RestoreRequest)(nil),          // 37: fastpath.v1.RestoreRequest
+	nil,                             // 38: fastpath.v1.CreateRequest.EnvsEntry
+	nil,                             // 39: fastpath.v1.HTTPGetProbe.HeadersEntry
+	nil,                             // 40: fastpath.v1.ResourceRequirements.RequestsEntry
+	nil,                             // 41: fastpath.v1.ResourceRequirements.LimitsEntry
+	nil,                             // 42: fastpath.v1.UpdateRequest.LabelsEntry
+	nil,                             // 43: fastpath.v1.ExecStart.EnvsEntry
 }
 var file_api_proto_v1_fastpath_proto_depIdxs = []int32{
-	5,  // 0: fastpath.v1.ListResponse.items:type_name -> fastpath.v1.SandboxInfo
+	6,  // 0: fastpath.v1.ListResponse.items:type_name -> fastpath.v1.SandboxInfo
 	0,  // 1: fastpath.v1.CreateRequest.consistency_mode:type_name -> fastpath.v1.ConsistencyMode
-	12, // 2: fastpath.v1.CreateRequest.envs:type_name -> fastpath.v1.CreateRequest.EnvsEntry
-	1,  // 3: fastpath.v1.UpdateRequest.failure_policy:type_name -> fastpath.v1.FailurePolicy
-	13, // 4: fastpath.v1.UpdateRequest.labels:type_name -> fastpath.v1.UpdateRequest.LabelsEntry
-	5,  // 5: fastpath.v1.UpdateResponse.sandbox:type_name -> fastpath.v1.SandboxInfo
-	6,  // 6: fastpath.v1.FastPathService.CreateSandbox:input_type -> fastpath.v1.CreateRequest
-	8,  // 7: fastpath.v1.FastPathService.DeleteSandbox:input_type -> fastpath.v1.DeleteRequest
-	10, // 8: fastpath.v1.FastPathService.UpdateSandbox:input_type -> fastpath.v1.UpdateRequest
-	2,  // 9: fastpath.v1.FastPathService.ListSandboxes:input_type -> fastpath.v1.ListRequest
-	4,  // 10: fastpath.v1.FastPathService.GetSandbox:input_type -> fastpath.v1.GetRequest
-	7,  // 11: fastpath.v1.FastPathService.CreateSandbox:output_type -> fastpath.v1.CreateResponse
-	9,  // 12: fastpath.v1.FastPathService.DeleteSandbox:output_type -> fastpath.v1.DeleteResponse
-	11, // 13: fastpath.v1.FastPathService.UpdateSandbox:output_type -> fastpath.v1.UpdateResponse
-	3,  // 14: fastpath.v1.FastPathService.ListSandboxes:output_type -> fastpath.v1.ListResponse
-	5,  // 15: fastpath.v1.FastPathService.GetSandbox:output_type -> fastpath.v1.SandboxInfo
-	11, // [11:16] is the sub-list for method output_type
-	6,  // [6:11] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	38, // 2: fastpath.v1.CreateRequest.envs:type_name -> fastpath.v1.CreateRequest.EnvsEntry
+	10, // 3: fastpath.v1.CreateRequest.resources:type_name -> fastpath.v1.ResourceRequirements
+	8,  // 4: fastpath.v1.CreateRequest.readiness_probe:type_name -> fastpath.v1.ReadinessProbe
+	9,  // 5: fastpath.v1.ReadinessProbe.http_get:type_name -> fastpath.v1.HTTPGetProbe
+	39, // 6: fastpath.v1.HTTPGetProbe.headers:type_name -> fastpath.v1.HTTPGetProbe.HeadersEntry
+	40, // 7: fastpath.v1.ResourceRequirements.requests:type_name -> fastpath.v1.ResourceRequirements.RequestsEntry
+	41, // 8: fastpath.v1.ResourceRequirements.limits:type_name -> fastpath.v1.ResourceRequirements.LimitsEntry
+	7,  // 9: fastpath.v1.CreateSandboxesRequest.items:type_name -> fastpath.v1.CreateRequest
+	14, // 10: fastpath.v1.CreateSandboxesResponse.results:type_name -> fastpath.v1.CreateResult
+	11, // 11: fastpath.v1.CreateResult.sandbox:type_name -> fastpath.v1.CreateResponse
+	1,  // 12: fastpath.v1.UpdateRequest.failure_policy:type_name -> fastpath.v1.FailurePolicy
+	42, // 13: fastpath.v1.UpdateRequest.labels:type_name -> fastpath.v1.UpdateRequest.LabelsEntry
+	6,  // 14: fastpath.v1.UpdateResponse.sandbox:type_name -> fastpath.v1.SandboxInfo
+	20, // 15: fastpath.v1.ExecRequest.start:type_name -> fastpath.v1.ExecStart
+	21, // 16: fastpath.v1.ExecRequest.resize:type_name -> fastpath.v1.TerminalSize
+	43, // 17: fastpath.v1.ExecStart.envs:type_name -> fastpath.v1.ExecStart.EnvsEntry
+	23, // 18: fastpath.v1.AttachRequest.start:type_name -> fastpath.v1.AttachStart
+	21, // 19: fastpath.v1.AttachRequest.resize:type_name -> fastpath.v1.TerminalSize
+	25, // 20: fastpath.v1.ExecResponse.exit:type_name -> fastpath.v1.ExecExit
+	26, // 21: fastpath.v1.CopyToRequest.target:type_name -> fastpath.v1.CopyTarget
+	26, // 22: fastpath.v1.CopyFromRequest.target:type_name -> fastpath.v1.CopyTarget
+	2,  // 23: fastpath.v1.WatchEvent.type:type_name -> fastpath.v1.WatchEventType
+	6,  // 24: fastpath.v1.WatchEvent.sandbox:type_name -> fastpath.v1.SandboxInfo
+	0,  // 25: fastpath.v1.RestoreRequest.consistency_mode:type_name -> fastpath.v1.ConsistencyMode
+	7,  // 26: fastpath.v1.FastPathService.CreateSandbox:input_type -> fastpath.v1.CreateRequest
+	12, // 27: fastpath.v1.FastPathService.CreateSandboxes:input_type -> fastpath.v1.CreateSandboxesRequest
+	15, // 28: fastpath.v1.FastPathService.DeleteSandbox:input_type -> fastpath.v1.DeleteRequest
+	17, // 29: fastpath.v1.FastPathService.UpdateSandbox:input_type -> fastpath.v1.UpdateRequest
+	3,  // 30: fastpath.v1.FastPathService.ListSandboxes:input_type -> fastpath.v1.ListRequest
+	5,  // 31: fastpath.v1.FastPathService.GetSandbox:input_type -> fastpath.v1.GetRequest
+	19, // 32: fastpath.v1.FastPathService.ExecSandbox:input_type -> fastpath.v1.ExecRequest
+	22, // 33: fastpath.v1.FastPathService.AttachSandbox:input_type -> fastpath.v1.AttachRequest
+	27, // 34: fastpath.v1.FastPathService.CopyToSandbox:input_type -> fastpath.v1.CopyToRequest
+	29, // 35: fastpath.v1.FastPathService.CopyFromSandbox:input_type -> fastpath.v1.CopyFromRequest
+	31, // 36: fastpath.v1.FastPathService.StreamLogs:input_type -> fastpath.v1.LogsRequest
+	33, // 37: fastpath.v1.FastPathService.WatchSandboxes:input_type -> fastpath.v1.WatchRequest
+	35, // 38: fastpath.v1.FastPathService.CheckpointSandbox:input_type -> fastpath.v1.CheckpointRequest
+	37, // 39: fastpath.v1.FastPathService.RestoreSandbox:input_type -> fastpath.v1.RestoreRequest
+	11, // 40: fastpath.v1.FastPathService.CreateSandbox:output_type -> fastpath.v1.CreateResponse
+	13, // 41: fastpath.v1.FastPathService.CreateSandboxes:output_type -> fastpath.v1.CreateSandboxesResponse
+	16, // 42: fastpath.v1.FastPathService.DeleteSandbox:output_type -> fastpath.v1.DeleteResponse
+	18, // 43: fastpath.v1.FastPathService.UpdateSandbox:output_type -> fastpath.v1.UpdateResponse
+	4,  // 44: fastpath.v1.FastPathService.ListSandboxes:output_type -> fastpath.v1.ListResponse
+	6,  // 45: fastpath.v1.FastPathService.GetSandbox:output_type -> fastpath.v1.SandboxInfo
+	24, // 46: fastpath.v1.FastPathService.ExecSandbox:output_type -> fastpath.v1.ExecResponse
+	24, // 47: fastpath.v1.FastPathService.AttachSandbox:output_type -> fastpath.v1.ExecResponse
+	28, // 48: fastpath.v1.FastPathService.CopyToSandbox:output_type -> fastpath.v1.CopyToResponse
+	30, // 49: fastpath.v1.FastPathService.CopyFromSandbox:output_type -> fastpath.v1.CopyFromResponse
+	32, // 50: fastpath.v1.FastPathService.StreamLogs:output_type -> fastpath.v1.LogsResponse
+	34, // 51: fastpath.v1.FastPathService.WatchSandboxes:output_type -> fastpath.v1.WatchEvent
+	36, // 52: fastpath.v1.FastPathService.CheckpointSandbox:output_type -> fastpath.v1.CheckpointInfo
+	11, // 53: fastpath.v1.FastPathService.RestoreSandbox:output_type -> fastpath.v1.CreateResponse
+	40, // [40:54] is the sub-list for method output_type
+	26, // [26:40] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_v1_fastpath_proto_init() }
@@ -982,19 +3211,43 @@ func file_api_proto_v1_fastpath_proto_init() {
 	if File_api_proto_v1_fastpath_proto != nil {
 		return
 	}
-	file_api_proto_v1_fastpath_proto_msgTypes[8].OneofWrappers = []any{
+	file_api_proto_v1_fastpath_proto_msgTypes[4].OneofWrappers = []any{}
+	file_api_proto_v1_fastpath_proto_msgTypes[14].OneofWrappers = []any{
 		(*UpdateRequest_ExpireTimeSeconds)(nil),
 		(*UpdateRequest_ResetRevision)(nil),
 		(*UpdateRequest_FailurePolicy)(nil),
 		(*UpdateRequest_RecoveryTimeoutSeconds)(nil),
+		(*UpdateRequest_Paused)(nil),
+		(*UpdateRequest_IdleTimeoutSeconds)(nil),
+	}
+	file_api_proto_v1_fastpath_proto_msgTypes[16].OneofWrappers = []any{
+		(*ExecRequest_Start)(nil),
+		(*ExecRequest_Stdin)(nil),
+		(*ExecRequest_CloseStdin)(nil),
+		(*ExecRequest_Resize)(nil),
+	}
+	file_api_proto_v1_fastpath_proto_msgTypes[19].OneofWrappers = []any{
+		(*AttachRequest_Start)(nil),
+		(*AttachRequest_Stdin)(nil),
+		(*AttachRequest_CloseStdin)(nil),
+		(*AttachRequest_Resize)(nil),
+	}
+	file_api_proto_v1_fastpath_proto_msgTypes[21].OneofWrappers = []any{
+		(*ExecResponse_Stdout)(nil),
+		(*ExecResponse_Stderr)(nil),
+		(*ExecResponse_Exit)(nil),
+	}
+	file_api_proto_v1_fastpath_proto_msgTypes[24].OneofWrappers = []any{
+		(*CopyToRequest_Target)(nil),
+		(*CopyToRequest_Data)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_v1_fastpath_proto_rawDesc), len(file_api_proto_v1_fastpath_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   12,
+			NumEnums:      3,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
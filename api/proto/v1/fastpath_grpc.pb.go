@@ -19,11 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	FastPathService_CreateSandbox_FullMethodName = "/fastpath.v1.FastPathService/CreateSandbox"
-	FastPathService_DeleteSandbox_FullMethodName = "/fastpath.v1.FastPathService/DeleteSandbox"
-	FastPathService_UpdateSandbox_FullMethodName = "/fastpath.v1.FastPathService/UpdateSandbox"
-	FastPathService_ListSandboxes_FullMethodName = "/fastpath.v1.FastPathService/ListSandboxes"
-	FastPathService_GetSandbox_FullMethodName    = "/fastpath.v1.FastPathService/GetSandbox"
+	FastPathService_CreateSandbox_FullMethodName     = "/fastpath.v1.FastPathService/CreateSandbox"
+	FastPathService_CreateSandboxes_FullMethodName   = "/fastpath.v1.FastPathService/CreateSandboxes"
+	FastPathService_DeleteSandbox_FullMethodName     = "/fastpath.v1.FastPathService/DeleteSandbox"
+	FastPathService_UpdateSandbox_FullMethodName     = "/fastpath.v1.FastPathService/UpdateSandbox"
+	FastPathService_ListSandboxes_FullMethodName     = "/fastpath.v1.FastPathService/ListSandboxes"
+	FastPathService_GetSandbox_FullMethodName        = "/fastpath.v1.FastPathService/GetSandbox"
+	FastPathService_ExecSandbox_FullMethodName       = "/fastpath.v1.FastPathService/ExecSandbox"
+	FastPathService_AttachSandbox_FullMethodName     = "/fastpath.v1.FastPathService/AttachSandbox"
+	FastPathService_CopyToSandbox_FullMethodName     = "/fastpath.v1.FastPathService/CopyToSandbox"
+	FastPathService_CopyFromSandbox_FullMethodName   = "/fastpath.v1.FastPathService/CopyFromSandbox"
+	FastPathService_StreamLogs_FullMethodName        = "/fastpath.v1.FastPathService/StreamLogs"
+	FastPathService_WatchSandboxes_FullMethodName    = "/fastpath.v1.FastPathService/WatchSandboxes"
+	FastPathService_CheckpointSandbox_FullMethodName = "/fastpath.v1.FastPathService/CheckpointSandbox"
+	FastPathService_RestoreSandbox_FullMethodName    = "/fastpath.v1.FastPathService/RestoreSandbox"
 )
 
 // FastPathServiceClient is the client API for FastPathService service.
@@ -32,6 +41,8 @@ const (
 type FastPathServiceClient interface {
 	// CreateSandbox 极速创建沙箱，绕过 K8s 控制面延迟
 	CreateSandbox(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	// CreateSandboxes 批量创建沙箱：一次完成全部调度，再并发调用 Agent，逐条返回结果
+	CreateSandboxes(ctx context.Context, in *CreateSandboxesRequest, opts ...grpc.CallOption) (*CreateSandboxesResponse, error)
 	// DeleteSandbox 极速删除沙箱
 	DeleteSandbox(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	// UpdateSandbox 更新沙箱配置（过期时间、重启、策略等）
@@ -40,6 +51,22 @@ type FastPathServiceClient interface {
 	ListSandboxes(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	// GetSandbox 获取沙箱详情
 	GetSandbox(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*SandboxInfo, error)
+	// ExecSandbox 在运行中的沙箱内执行命令，双向流传输 stdin/stdout/stderr
+	ExecSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error)
+	// AttachSandbox 连接沙箱主进程的 stdin/stdout/stderr，复用 ExecResponse 返回输出
+	AttachSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AttachRequest, ExecResponse], error)
+	// CopyToSandbox 上传 tar 流并解压到沙箱内的目录，第一条消息必须是 target
+	CopyToSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CopyToRequest, CopyToResponse], error)
+	// CopyFromSandbox 以 tar 流下载沙箱内的文件或目录
+	CopyFromSandbox(ctx context.Context, in *CopyFromRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CopyFromResponse], error)
+	// StreamLogs 通过 Controller 代理读取沙箱日志，无需直连 Agent
+	StreamLogs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogsResponse], error)
+	// WatchSandboxes 推送沙箱的增删改，基于 Controller 的 informer 缓存，可从 resource_version 续传
+	WatchSandboxes(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+	// CheckpointSandbox 为运行中或已暂停的沙箱创建 SandboxCheckpoint，等待其 Ready 或 Failed 后返回
+	CheckpointSandbox(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointInfo, error)
+	// RestoreSandbox 从 Ready 的 SandboxCheckpoint 恢复出新沙箱，沿用 checkpoint 时沙箱的配置
+	RestoreSandbox(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*CreateResponse, error)
 }
 
 type fastPathServiceClient struct {
@@ -60,6 +87,16 @@ func (c *fastPathServiceClient) CreateSandbox(ctx context.Context, in *CreateReq
 	return out, nil
 }
 
+func (c *fastPathServiceClient) CreateSandboxes(ctx context.Context, in *CreateSandboxesRequest, opts ...grpc.CallOption) (*CreateSandboxesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSandboxesResponse)
+	err := c.cc.Invoke(ctx, FastPathService_CreateSandboxes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *fastPathServiceClient) DeleteSandbox(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteResponse)
@@ -100,12 +137,130 @@ func (c *fastPathServiceClient) GetSandbox(ctx context.Context, in *GetRequest,
 	return out, nil
 }
 
+func (c *fastPathServiceClient) ExecSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FastPathService_ServiceDesc.Streams[0], FastPathService_ExecSandbox_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecRequest, ExecResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_ExecSandboxClient = grpc.BidiStreamingClient[ExecRequest, ExecResponse]
+
+func (c *fastPathServiceClient) AttachSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AttachRequest, ExecResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FastPathService_ServiceDesc.Streams[1], FastPathService_AttachSandbox_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AttachRequest, ExecResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_AttachSandboxClient = grpc.BidiStreamingClient[AttachRequest, ExecResponse]
+
+func (c *fastPathServiceClient) CopyToSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CopyToRequest, CopyToResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FastPathService_ServiceDesc.Streams[2], FastPathService_CopyToSandbox_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CopyToRequest, CopyToResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_CopyToSandboxClient = grpc.ClientStreamingClient[CopyToRequest, CopyToResponse]
+
+func (c *fastPathServiceClient) CopyFromSandbox(ctx context.Context, in *CopyFromRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CopyFromResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FastPathService_ServiceDesc.Streams[3], FastPathService_CopyFromSandbox_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CopyFromRequest, CopyFromResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_CopyFromSandboxClient = grpc.ServerStreamingClient[CopyFromResponse]
+
+func (c *fastPathServiceClient) StreamLogs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FastPathService_ServiceDesc.Streams[4], FastPathService_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogsRequest, LogsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_StreamLogsClient = grpc.ServerStreamingClient[LogsResponse]
+
+func (c *fastPathServiceClient) WatchSandboxes(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FastPathService_ServiceDesc.Streams[5], FastPathService_WatchSandboxes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_WatchSandboxesClient = grpc.ServerStreamingClient[WatchEvent]
+
+func (c *fastPathServiceClient) CheckpointSandbox(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckpointInfo)
+	err := c.cc.Invoke(ctx, FastPathService_CheckpointSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fastPathServiceClient) RestoreSandbox(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, FastPathService_RestoreSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FastPathServiceServer is the server API for FastPathService service.
 // All implementations must embed UnimplementedFastPathServiceServer
 // for forward compatibility.
 type FastPathServiceServer interface {
 	// CreateSandbox 极速创建沙箱，绕过 K8s 控制面延迟
 	CreateSandbox(context.Context, *CreateRequest) (*CreateResponse, error)
+	// CreateSandboxes 批量创建沙箱：一次完成全部调度，再并发调用 Agent，逐条返回结果
+	CreateSandboxes(context.Context, *CreateSandboxesRequest) (*CreateSandboxesResponse, error)
 	// DeleteSandbox 极速删除沙箱
 	DeleteSandbox(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	// UpdateSandbox 更新沙箱配置（过期时间、重启、策略等）
@@ -114,6 +269,22 @@ type FastPathServiceServer interface {
 	ListSandboxes(context.Context, *ListRequest) (*ListResponse, error)
 	// GetSandbox 获取沙箱详情
 	GetSandbox(context.Context, *GetRequest) (*SandboxInfo, error)
+	// ExecSandbox 在运行中的沙箱内执行命令，双向流传输 stdin/stdout/stderr
+	ExecSandbox(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error
+	// AttachSandbox 连接沙箱主进程的 stdin/stdout/stderr，复用 ExecResponse 返回输出
+	AttachSandbox(grpc.BidiStreamingServer[AttachRequest, ExecResponse]) error
+	// CopyToSandbox 上传 tar 流并解压到沙箱内的目录，第一条消息必须是 target
+	CopyToSandbox(grpc.ClientStreamingServer[CopyToRequest, CopyToResponse]) error
+	// CopyFromSandbox 以 tar 流下载沙箱内的文件或目录
+	CopyFromSandbox(*CopyFromRequest, grpc.ServerStreamingServer[CopyFromResponse]) error
+	// StreamLogs 通过 Controller 代理读取沙箱日志，无需直连 Agent
+	StreamLogs(*LogsRequest, grpc.ServerStreamingServer[LogsResponse]) error
+	// WatchSandboxes 推送沙箱的增删改，基于 Controller 的 informer 缓存，可从 resource_version 续传
+	WatchSandboxes(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error
+	// CheckpointSandbox 为运行中或已暂停的沙箱创建 SandboxCheckpoint，等待其 Ready 或 Failed 后返回
+	CheckpointSandbox(context.Context, *CheckpointRequest) (*CheckpointInfo, error)
+	// RestoreSandbox 从 Ready 的 SandboxCheckpoint 恢复出新沙箱，沿用 checkpoint 时沙箱的配置
+	RestoreSandbox(context.Context, *RestoreRequest) (*CreateResponse, error)
 	mustEmbedUnimplementedFastPathServiceServer()
 }
 
@@ -127,6 +298,9 @@ type UnimplementedFastPathServiceServer struct{}
 func (UnimplementedFastPathServiceServer) CreateSandbox(context.Context, *CreateRequest) (*CreateResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateSandbox not implemented")
 }
+func (UnimplementedFastPathServiceServer) CreateSandboxes(context.Context, *CreateSandboxesRequest) (*CreateSandboxesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateSandboxes not implemented")
+}
 func (UnimplementedFastPathServiceServer) DeleteSandbox(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method DeleteSandbox not implemented")
 }
@@ -139,6 +313,30 @@ func (UnimplementedFastPathServiceServer) ListSandboxes(context.Context, *ListRe
 func (UnimplementedFastPathServiceServer) GetSandbox(context.Context, *GetRequest) (*SandboxInfo, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSandbox not implemented")
 }
+func (UnimplementedFastPathServiceServer) ExecSandbox(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error {
+	return status.Error(codes.Unimplemented, "method ExecSandbox not implemented")
+}
+func (UnimplementedFastPathServiceServer) AttachSandbox(grpc.BidiStreamingServer[AttachRequest, ExecResponse]) error {
+	return status.Error(codes.Unimplemented, "method AttachSandbox not implemented")
+}
+func (UnimplementedFastPathServiceServer) CopyToSandbox(grpc.ClientStreamingServer[CopyToRequest, CopyToResponse]) error {
+	return status.Error(codes.Unimplemented, "method CopyToSandbox not implemented")
+}
+func (UnimplementedFastPathServiceServer) CopyFromSandbox(*CopyFromRequest, grpc.ServerStreamingServer[CopyFromResponse]) error {
+	return status.Error(codes.Unimplemented, "method CopyFromSandbox not implemented")
+}
+func (UnimplementedFastPathServiceServer) StreamLogs(*LogsRequest, grpc.ServerStreamingServer[LogsResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamLogs not implemented")
+}
+func (UnimplementedFastPathServiceServer) WatchSandboxes(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchSandboxes not implemented")
+}
+func (UnimplementedFastPathServiceServer) CheckpointSandbox(context.Context, *CheckpointRequest) (*CheckpointInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckpointSandbox not implemented")
+}
+func (UnimplementedFastPathServiceServer) RestoreSandbox(context.Context, *RestoreRequest) (*CreateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreSandbox not implemented")
+}
 func (UnimplementedFastPathServiceServer) mustEmbedUnimplementedFastPathServiceServer() {}
 func (UnimplementedFastPathServiceServer) testEmbeddedByValue()                         {}
 
@@ -178,6 +376,24 @@ func _FastPathService_CreateSandbox_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FastPathService_CreateSandboxes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSandboxesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FastPathServiceServer).CreateSandboxes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FastPathService_CreateSandboxes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FastPathServiceServer).CreateSandboxes(ctx, req.(*CreateSandboxesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _FastPathService_DeleteSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteRequest)
 	if err := dec(in); err != nil {
@@ -250,6 +466,96 @@ func _FastPathService_GetSandbox_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FastPathService_ExecSandbox_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FastPathServiceServer).ExecSandbox(&grpc.GenericServerStream[ExecRequest, ExecResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_ExecSandboxServer = grpc.BidiStreamingServer[ExecRequest, ExecResponse]
+
+func _FastPathService_AttachSandbox_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FastPathServiceServer).AttachSandbox(&grpc.GenericServerStream[AttachRequest, ExecResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_AttachSandboxServer = grpc.BidiStreamingServer[AttachRequest, ExecResponse]
+
+func _FastPathService_CopyToSandbox_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FastPathServiceServer).CopyToSandbox(&grpc.GenericServerStream[CopyToRequest, CopyToResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_CopyToSandboxServer = grpc.ClientStreamingServer[CopyToRequest, CopyToResponse]
+
+func _FastPathService_CopyFromSandbox_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CopyFromRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FastPathServiceServer).CopyFromSandbox(m, &grpc.GenericServerStream[CopyFromRequest, CopyFromResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_CopyFromSandboxServer = grpc.ServerStreamingServer[CopyFromResponse]
+
+func _FastPathService_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FastPathServiceServer).StreamLogs(m, &grpc.GenericServerStream[LogsRequest, LogsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_StreamLogsServer = grpc.ServerStreamingServer[LogsResponse]
+
+func _FastPathService_WatchSandboxes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FastPathServiceServer).WatchSandboxes(m, &grpc.GenericServerStream[WatchRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FastPathService_WatchSandboxesServer = grpc.ServerStreamingServer[WatchEvent]
+
+func _FastPathService_CheckpointSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FastPathServiceServer).CheckpointSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FastPathService_CheckpointSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FastPathServiceServer).CheckpointSandbox(ctx, req.(*CheckpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FastPathService_RestoreSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FastPathServiceServer).RestoreSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FastPathService_RestoreSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FastPathServiceServer).RestoreSandbox(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FastPathService_ServiceDesc is the grpc.ServiceDesc for FastPathService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -261,6 +567,10 @@ var FastPathService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateSandbox",
 			Handler:    _FastPathService_CreateSandbox_Handler,
 		},
+		{
+			MethodName: "CreateSandboxes",
+			Handler:    _FastPathService_CreateSandboxes_Handler,
+		},
 		{
 			MethodName: "DeleteSandbox",
 			Handler:    _FastPathService_DeleteSandbox_Handler,
@@ -277,7 +587,48 @@ var FastPathService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSandbox",
 			Handler:    _FastPathService_GetSandbox_Handler,
 		},
+		{
+			MethodName: "CheckpointSandbox",
+			Handler:    _FastPathService_CheckpointSandbox_Handler,
+		},
+		{
+			MethodName: "RestoreSandbox",
+			Handler:    _FastPathService_RestoreSandbox_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecSandbox",
+			Handler:       _FastPathService_ExecSandbox_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "AttachSandbox",
+			Handler:       _FastPathService_AttachSandbox_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "CopyToSandbox",
+			Handler:       _FastPathService_CopyToSandbox_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "CopyFromSandbox",
+			Handler:       _FastPathService_CopyFromSandbox_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _FastPathService_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSandboxes",
+			Handler:       _FastPathService_WatchSandboxes_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/v1/fastpath.proto",
 }
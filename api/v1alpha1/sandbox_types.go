@@ -24,8 +24,34 @@ const (
 	FailurePolicyAutoRecreate FailurePolicy = "AutoRecreate"
 )
 
+// RestartPolicy defines when the Agent restarts the sandbox main process after it exits.
+// +kubebuilder:validation:Enum=Never;OnFailure;Always
+type RestartPolicy string
+
+const (
+	// RestartPolicyNever keeps the sandbox stopped after the main process exits.
+	RestartPolicyNever RestartPolicy = "Never"
+	// RestartPolicyOnFailure restarts the main process only after a non-zero exit code.
+	RestartPolicyOnFailure RestartPolicy = "OnFailure"
+	// RestartPolicyAlways restarts the main process after every exit.
+	RestartPolicyAlways RestartPolicy = "Always"
+)
+
+// IdlePolicy defines what the controller does with a sandbox that stayed idle for its IdleTimeoutSeconds.
+// +kubebuilder:validation:Enum=Pause;Expire;Delete
+type IdlePolicy string
+
+const (
+	// IdlePolicyPause freezes the idle sandbox, it keeps its slot and resumes when Paused is set back to false.
+	IdlePolicyPause IdlePolicy = "Pause"
+	// IdlePolicyExpire deletes the idle sandbox from its Agent and keeps the CR as Expired.
+	IdlePolicyExpire IdlePolicy = "Expire"
+	// IdlePolicyDelete deletes the idle Sandbox CR.
+	IdlePolicyDelete IdlePolicy = "Delete"
+)
+
 // SandboxPhase defines the lifecycle phase of a Sandbox in the Controller.
-// +kubebuilder:validation:Enum=Pending;Bound;Running;Terminating;Expired;Failed;Lost
+// +kubebuilder:validation:Enum=Pending;Bound;Running;Paused;Succeeded;Terminating;Expired;Preempted;Failed;Lost
 type SandboxPhase string
 
 const (
@@ -35,16 +61,25 @@ const (
 	PhaseBound SandboxPhase = "Bound"
 	// PhaseRunning - Container is running on the Agent (synced from Agent status).
 	PhaseRunning SandboxPhase = "Running"
+	// PhasePaused - All processes are frozen on the Agent, the sandbox keeps its slot and ports.
+	PhasePaused SandboxPhase = "Paused"
+	// PhaseSucceeded - Main process exited with code 0 and will not be restarted.
+	PhaseSucceeded SandboxPhase = "Succeeded"
 	// PhaseTerminating - Sandbox is being deleted, waiting for Agent to confirm cleanup.
 	PhaseTerminating SandboxPhase = "Terminating"
 	// PhaseExpired - Sandbox has expired, runtime resources cleaned but CRD preserved.
 	PhaseExpired SandboxPhase = "Expired"
-	// PhaseFailed - Sandbox creation or operation failed.
+	// PhasePreempted - Sandbox was evicted for a higher-priority sandbox, runtime resources cleaned but CRD preserved.
+	PhasePreempted SandboxPhase = "Preempted"
+	// PhaseFailed - Sandbox creation failed, or the main process exited non-zero and will not be restarted.
 	PhaseFailed SandboxPhase = "Failed"
 	// PhaseLost - Agent Pod was lost under Manual failure policy, waiting for user intervention.
 	PhaseLost SandboxPhase = "Lost"
 )
 
+// SandboxConditionReady is True while the sandbox runs and passes its readiness probe.
+const SandboxConditionReady = "Ready"
+
 // AgentSandboxPhase defines the lifecycle phase reported by the Agent.
 type AgentSandboxPhase string
 
@@ -53,9 +88,15 @@ const (
 	AgentPhaseCreating AgentSandboxPhase = "creating"
 	// AgentPhaseRunning - Container is running.
 	AgentPhaseRunning AgentSandboxPhase = "running"
+	// AgentPhaseRestarting - Main process exited and waits for its restart backoff.
+	AgentPhaseRestarting AgentSandboxPhase = "restarting"
+	// AgentPhasePaused - All processes are frozen by the cgroup freezer.
+	AgentPhasePaused AgentSandboxPhase = "paused"
+	// AgentPhaseSucceeded - Main process exited with code 0 and will not be restarted.
+	AgentPhaseSucceeded AgentSandboxPhase = "succeeded"
 	// AgentPhaseStopped - Container has stopped.
 	AgentPhaseStopped AgentSandboxPhase = "stopped"
-	// AgentPhaseFailed - Container creation or execution failed.
+	// AgentPhaseFailed - Container creation failed, or the main process exited non-zero and will not be restarted.
 	AgentPhaseFailed AgentSandboxPhase = "failed"
 	// AgentPhaseTerminated - Container has been deleted and cleaned up.
 	AgentPhaseTerminated AgentSandboxPhase = "terminated"
@@ -69,14 +110,52 @@ type SandboxSpec struct {
 	Envs       []corev1.EnvVar `json:"envs,omitempty"`
 	WorkingDir string          `json:"workingDir,omitempty"`
 
+	// TTY allocates a pseudo-terminal for the main process, needed for interactive attach.
+	TTY bool `json:"tty,omitempty"`
+
+	// Stdin keeps the main process stdin open so that clients can attach to it.
+	Stdin bool `json:"stdin,omitempty"`
+
+	// Resources specifies the CPU and memory requests and limits of the sandbox.
+	// Limits are enforced by the Agent as cgroup limits; requests default to limits.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// RestartPolicy decides whether the Agent restarts the main process after it exits,
+	// with exponential backoff (10s doubling up to 5m). Defaults to "Never".
+	// +kubebuilder:default="Never"
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+
 	// ExpireTime specifies when this sandbox should expire and be garbage collected.
 	// If not set, the sandbox will not expire automatically.
 	ExpireTime *metav1.Time `json:"expireTime,omitempty"`
 
+	// TTLSecondsAfterFinished deletes the Sandbox this many seconds after it reached a final
+	// phase (Succeeded, Failed, Expired or Preempted). 0 deletes it right away; if not set,
+	// the sandbox is kept until deleted or collected by the controller retention policy.
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// IdleTimeoutSeconds applies IdlePolicy once the sandbox has been idle for this long.
+	// The Agent counts exec and attach sessions, log readers, traffic on ExposedPorts and
+	// CPU usage as activity. 0 disables the idle timeout.
+	// +kubebuilder:validation:Minimum=0
+	IdleTimeoutSeconds int32 `json:"idleTimeoutSeconds,omitempty"`
+
+	// IdlePolicy is the action taken on an idle sandbox. Defaults to "Pause".
+	// +kubebuilder:default="Pause"
+	IdlePolicy IdlePolicy `json:"idlePolicy,omitempty"`
+
 	// ExposedPorts specifies the ports that the sandbox application will listen on.
 	// The controller ensures no port conflicts on the same Agent Pod during scheduling.
 	ExposedPorts []int32 `json:"exposedPorts,omitempty"`
 
+	// ReadinessProbe is run by the Agent against the running sandbox, the Ready condition
+	// turns True once it passes. Only exec, httpGet and tcpSocket with numeric ports are
+	// supported; probes without a host connect to the Agent Pod IP that the sandbox shares.
+	// periodSeconds and timeoutSeconds default to 1. Without a probe the sandbox is ready
+	// while it runs.
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+
 	// FailurePolicy defines the recovery strategy when the agent is lost.
 	// Defaults to "Manual".
 	// +kubebuilder:default="Manual"
@@ -91,6 +170,21 @@ type SandboxSpec struct {
 	// When Spec.ResetRevision > Status.AcceptedResetRevision, the sandbox will be rescheduled.
 	ResetRevision *metav1.Time `json:"resetRevision,omitempty"`
 
+	// Paused freezes all processes of the sandbox with the cgroup freezer, so that an idle
+	// sandbox stops using CPU without losing its process state. A paused sandbox keeps its
+	// Agent slot and ports; setting it back to false resumes the sandbox.
+	Paused bool `json:"paused,omitempty"`
+
+	// RestoreFrom is the name of a Ready SandboxCheckpoint in the same namespace. The sandbox
+	// then resumes the checkpointed processes and filesystem instead of starting a new main
+	// process; later restarts start the main process from the image as usual. The checkpoint
+	// must come from a sandbox of the same pool.
+	RestoreFrom string `json:"restoreFrom,omitempty"`
+
+	// Priority of the sandbox within its pool, higher is more important. Defaults to 0.
+	// When the pool is full, a sandbox may preempt running sandboxes of lower priority.
+	Priority int32 `json:"priority,omitempty"`
+
 	// +kubebuilder:validation:Required
 	// PoolRef specifies which SandboxPool this sandbox should be scheduled to.
 	// This field is required.
@@ -106,6 +200,21 @@ type SandboxStatus struct {
 	Endpoints   []string           `json:"endpoints,omitempty"`
 	Conditions  []metav1.Condition `json:"conditions,omitempty"`
 
+	// ExitCode is the exit code of the last main process exit, nil while it has never exited.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// FinishedAt is when the main process last exited.
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+	// Reason is the termination reason of the last exit: Completed, Error or OOMKilled.
+	Reason string `json:"reason,omitempty"`
+	// RestartCount is how many times the Agent restarted the main process.
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// LastActivityTime is the last activity the Agent observed, with a resolution of about a minute.
+	LastActivityTime *metav1.Time `json:"lastActivityTime,omitempty"`
+
+	// CompletionTime is when the sandbox reached a final phase, cleared when it is reset.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
 	// AcceptedResetRevision reflects the latest reset revision that was processed by the controller.
 	AcceptedResetRevision *metav1.Time `json:"acceptedResetRevision,omitempty"`
 }
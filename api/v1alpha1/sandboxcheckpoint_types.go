@@ -0,0 +1,100 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CheckpointPhase defines the lifecycle phase of a SandboxCheckpoint.
+// +kubebuilder:validation:Enum=Checkpointing;Ready;Failed
+type CheckpointPhase string
+
+const (
+	// CheckpointPhaseCheckpointing - The agent is taking the checkpoint.
+	CheckpointPhaseCheckpointing CheckpointPhase = "Checkpointing"
+	// CheckpointPhaseReady - The checkpoint is stored and sandboxes can be restored from it.
+	CheckpointPhaseReady CheckpointPhase = "Ready"
+	// CheckpointPhaseFailed - The checkpoint could not be taken, the message explains why.
+	CheckpointPhaseFailed CheckpointPhase = "Failed"
+)
+
+// SandboxCheckpointSpec selects the sandbox to checkpoint and where the checkpoint is stored.
+// The spec is immutable, delete and recreate the checkpoint to take a new one.
+type SandboxCheckpointSpec struct {
+	// +kubebuilder:validation:Required
+	// SandboxName is the Running or Paused sandbox to checkpoint, in the namespace of the checkpoint.
+	SandboxName string `json:"sandboxName"`
+
+	// Image is an OCI image reference the checkpoint is pushed to, so that it can be
+	// restored by the agents on every node of the pool. When empty the checkpoint stays in
+	// the containerd content store of the sandbox node, only agents on that node can
+	// restore it, and it is removed with the SandboxCheckpoint.
+	Image string `json:"image,omitempty"`
+
+	// RegistrySecret is a kubernetes.io/dockerconfigjson Secret in the namespace of the
+	// checkpoint with the credentials for the registry of Image. Agents use it to push the
+	// checkpoint and to pull it for restores, without it the registry is used anonymously.
+	RegistrySecret string `json:"registrySecret,omitempty"`
+}
+
+// SandboxCheckpointStatus defines the observed state of SandboxCheckpoint.
+type SandboxCheckpointStatus struct {
+	Phase   CheckpointPhase `json:"phase,omitempty"`
+	Message string          `json:"message,omitempty"`
+
+	// Ref is the name of the checkpoint image in containerd, the OCI reference when pushed.
+	Ref string `json:"ref,omitempty"`
+	// NodeName is the node the checkpoint was taken on.
+	NodeName string `json:"nodeName,omitempty"`
+	// PoolRef is the pool of the checkpointed sandbox, restored sandboxes must use the
+	// same pool so that they run with the same runtime.
+	PoolRef string `json:"poolRef,omitempty"`
+	// CheckpointedAt is when the checkpoint was taken.
+	CheckpointedAt *metav1.Time `json:"checkpointedAt,omitempty"`
+
+	// Sandbox is the spec of the checkpointed sandbox, FastPath restores start from a copy of it.
+	Sandbox *SandboxSpec `json:"sandbox,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// SandboxCheckpoint is the Schema for the sandboxcheckpoints API.
+type SandboxCheckpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SandboxCheckpointSpec   `json:"spec,omitempty"`
+	Status SandboxCheckpointStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SandboxCheckpointList contains a list of SandboxCheckpoint.
+type SandboxCheckpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SandboxCheckpoint `json:"items"`
+}
+
+func (in *SandboxCheckpoint) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxCheckpoint)
+	*out = *in
+	return out
+}
+
+func (in *SandboxCheckpointList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxCheckpointList)
+	*out = *in
+	return out
+}
+
+func init() {
+	SchemeBuilder.Register(&SandboxCheckpoint{}, &SandboxCheckpointList{})
+}
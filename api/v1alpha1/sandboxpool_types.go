@@ -15,6 +15,22 @@ const (
 	RuntimeGVisor    RuntimeType = "gvisor"
 )
 
+// SchedulingStrategy selects how sandboxes are placed on the agents of a pool.
+// +kubebuilder:validation:Enum=ImageLocality;LeastAllocated;MostAllocated;NodeSpread
+type SchedulingStrategy string
+
+const (
+	// SchedulingImageLocality prefers agents that already cache the image, then the least loaded one.
+	// This is the default and favours startup latency.
+	SchedulingImageLocality SchedulingStrategy = "ImageLocality"
+	// SchedulingLeastAllocated spreads sandboxes to the agent with the most free slots and resources.
+	SchedulingLeastAllocated SchedulingStrategy = "LeastAllocated"
+	// SchedulingMostAllocated bin-packs sandboxes onto the fullest agent that still fits, favouring density.
+	SchedulingMostAllocated SchedulingStrategy = "MostAllocated"
+	// SchedulingNodeSpread spreads sandboxes across nodes, so one node failure affects fewer sandboxes.
+	SchedulingNodeSpread SchedulingStrategy = "NodeSpread"
+)
+
 // SandboxPoolSpec defines the desired state of SandboxPool.
 
 type SandboxPoolSpec struct {
@@ -24,6 +40,11 @@ type SandboxPoolSpec struct {
 
 	RuntimeType RuntimeType `json:"runtimeType,omitempty"`
 
+	// SchedulingStrategy selects how sandboxes are placed on the agents of this pool.
+	// Defaults to "ImageLocality".
+	// +kubebuilder:default="ImageLocality"
+	SchedulingStrategy SchedulingStrategy `json:"schedulingStrategy,omitempty"`
+
 	AgentTemplate corev1.PodTemplateSpec `json:"agentTemplate"`
 }
 
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SandboxQuotaSpec limits what the sandboxes of the quota's namespace may consume.
+// Every limit left unset is unlimited; a namespace with several quotas must satisfy all of them.
+type SandboxQuotaSpec struct {
+	// MaxSandboxes caps the number of sandboxes placed on agents.
+	MaxSandboxes *int32 `json:"maxSandboxes,omitempty"`
+
+	// MaxCPU caps the summed CPU requests of the sandboxes.
+	MaxCPU *resource.Quantity `json:"maxCPU,omitempty"`
+
+	// MaxMemory caps the summed memory requests of the sandboxes.
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+
+	// MaxPerPool caps the number of sandboxes in a pool, keyed by SandboxPool name.
+	MaxPerPool map[string]int32 `json:"maxPerPool,omitempty"`
+}
+
+// SandboxQuotaStatus defines the observed state of SandboxQuota.
+type SandboxQuotaStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Used is the current consumption of the namespace.
+	Used QuotaUsage `json:"used,omitempty"`
+}
+
+// QuotaUsage is what the sandboxes of a namespace consume. Sandboxes count from the
+// moment they are placed on an agent until their runtime is cleaned up.
+type QuotaUsage struct {
+	Sandboxes int32             `json:"sandboxes"`
+	CPU       resource.Quantity `json:"cpu"`
+	Memory    resource.Quantity `json:"memory"`
+	// PerPool is the number of sandboxes per pool.
+	PerPool map[string]int32 `json:"perPool,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// SandboxQuota is the Schema for the sandboxquotas API.
+type SandboxQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SandboxQuotaSpec   `json:"spec,omitempty"`
+	Status SandboxQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SandboxQuotaList contains a list of SandboxQuota.
+type SandboxQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SandboxQuota `json:"items"`
+}
+
+func (in *SandboxQuota) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxQuota)
+	*out = *in
+	return out
+}
+
+func (in *SandboxQuotaList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxQuotaList)
+	*out = *in
+	return out
+}
+
+func init() {
+	SchemeBuilder.Register(&SandboxQuota{}, &SandboxQuotaList{})
+}
@@ -20,8 +20,12 @@ func main() {
 	nodeName := getEnv("NODE_NAME", "")
 	namespace := getEnv("NAMESPACE", "")
 	agentPort := getEnv("AGENT_PORT", ":5758")
+	grpcPort := getEnv("AGENT_GRPC_PORT", ":5759")
+	// HTTP API 保留一个版本，供尚未升级的 Controller 访问
+	httpAPI := getEnv("AGENT_HTTP_API", "true") == "true"
 	runtimeTypeStr := getEnv("RUNTIME_TYPE", "container")
 	runtimeSocket := getEnv("RUNTIME_SOCKET", "")
+	tlsDir := getEnv("AGENT_TLS_DIR", "")
 
 	klog.InfoS("Agent Info", "PodName", podName, "PodIP", podIP, "NodeName", nodeName, "Namespace", namespace)
 	klog.InfoS("Runtime", "Type", runtimeTypeStr, "Socket", runtimeSocket)
@@ -45,10 +49,29 @@ func main() {
 	sandboxManager := runtime.NewSandboxManager(rt)
 	defer sandboxManager.Close()
 
+	// 接管上一个 Agent 进程创建的 sandbox，恢复失败不影响新建
+	if err := sandboxManager.Recover(ctx); err != nil {
+		klog.ErrorS(err, "Failed to recover sandboxes from runtime")
+	}
+	go sandboxManager.WatchActivity(ctx)
+
 	agentServer := server.NewAgentServer(agentPort, sandboxManager)
-	klog.InfoS("Starting Agent HTTP Server", "port", agentPort)
+	if tlsDir != "" {
+		tlsConfig, err := server.LoadTLSConfig(ctx, tlsDir)
+		if err != nil {
+			klog.ErrorS(err, "Failed to load agent TLS configuration", "dir", tlsDir)
+			os.Exit(1)
+		}
+		agentServer.SetTLS(tlsConfig)
+	}
+	errCh := make(chan error, 2)
+	go func() { errCh <- agentServer.ServeGRPC(grpcPort) }()
+	if httpAPI {
+		klog.InfoS("Starting Agent HTTP Server", "port", agentPort)
+		go func() { errCh <- agentServer.Start() }()
+	}
 
-	if err := agentServer.Start(); err != nil {
+	if err := <-errCh; err != nil {
 		klog.ErrorS(err, "Agent server failed")
 		os.Exit(1)
 	}
@@ -2,20 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
+	"fmt"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"strings"
 	"time"
 
 	"fast-sandbox/internal/api"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
@@ -24,9 +30,13 @@ import (
 	"fast-sandbox/internal/controller"
 	"fast-sandbox/internal/controller/agentcontrol"
 	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/agenttls"
+	"fast-sandbox/internal/controller/auth"
 	"fast-sandbox/internal/controller/fastpath"
+	"fast-sandbox/internal/controller/quota"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
@@ -44,11 +54,35 @@ func main() {
 	var agentPort int
 	var fastpathConsistencyMode string
 	var fastpathOrphanTimeout time.Duration
+	var fastpathIdempotencyWindow time.Duration
+	var fastpathAuthMode string
+	var fastpathTokenFile string
+	var fastpathTokenAudiences string
+	var fastpathTLSCertFile string
+	var fastpathTLSKeyFile string
+	var agentMTLS bool
+	var agentProtocol string
+	var agentGRPCPort int
+	var agentCASecret string
+	var finishedSandboxMaxAge time.Duration
+	var finishedSandboxMaxCount int
 	flag.IntVar(&agentPort, "agent-port", 5758, "The port the agent server binds to.")
+	flag.StringVar(&agentProtocol, "agent-protocol", "http", "Protocol used to talk to agents: http, or grpc once all agents run this release")
+	flag.IntVar(&agentGRPCPort, "agent-grpc-port", 5759, "The port the agent gRPC server binds to.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":9091", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":5758", "The address the probe endpoint binds to.")
 	flag.StringVar(&fastpathConsistencyMode, "fastpath-consistency-mode", "fast", "Fast-Path consistency mode: fast (default) or strong")
 	flag.DurationVar(&fastpathOrphanTimeout, "fastpath-orphan-timeout", 10*time.Second, "Fast-Path orphan cleanup timeout (for Fast mode)")
+	flag.DurationVar(&fastpathIdempotencyWindow, "fastpath-idempotency-window", 10*time.Minute, "How long Fast-Path CreateSandbox deduplicates requests with the same idempotency key")
+	flag.StringVar(&fastpathAuthMode, "fastpath-auth", "none", "Fast-Path authentication: none, tokenreview (Kubernetes ServiceAccount tokens) or static (--fastpath-token-file). Authenticated requests are authorized with SubjectAccessReview")
+	flag.StringVar(&fastpathTokenFile, "fastpath-token-file", "", "CSV file of static bearer tokens (token,user,uid[,\"group1,group2\"]) for --fastpath-auth=static")
+	flag.StringVar(&fastpathTokenAudiences, "fastpath-token-audiences", "", "Comma-separated audiences Fast-Path tokens must be issued for with --fastpath-auth=tokenreview, empty accepts the API server audience")
+	flag.StringVar(&fastpathTLSCertFile, "fastpath-tls-cert-file", "", "TLS certificate for the Fast-Path gRPC server, reloaded when it changes. Empty serves plaintext")
+	flag.StringVar(&fastpathTLSKeyFile, "fastpath-tls-key-file", "", "TLS private key for the Fast-Path gRPC server")
+	flag.BoolVar(&agentMTLS, "agent-mtls", false, "Issue a certificate to every agent pod and talk to agents over mutual TLS, verifying each agent is the pod holding its IP")
+	flag.StringVar(&agentCASecret, "agent-ca-secret", "default/fast-sandbox-agent-ca", "namespace/name of the Secret holding the agent CA, created on first start with --agent-mtls")
+	flag.DurationVar(&finishedSandboxMaxAge, "finished-sandbox-max-age", 0, "Delete Succeeded, Failed, Expired and Preempted sandboxes this long after they finished, 0 keeps them")
+	flag.IntVar(&finishedSandboxMaxCount, "finished-sandbox-max-count", 0, "Keep at most this many finished sandboxes per namespace, deleting the oldest first, 0 keeps all")
 
 	flag.Parse()
 
@@ -65,28 +99,88 @@ func main() {
 	}
 
 	reg := agentpool.NewInMemoryRegistry()
-	agentHTTPClient := api.NewAgentClient(agentPort)
+	agentClient := api.NewAgentClient(agentPort)
+	switch agentProtocol {
+	case "grpc":
+		agentClient.UseGRPC(agentGRPCPort)
+	case "http":
+	default:
+		klog.ErrorS(nil, "unknown --agent-protocol, must be grpc or http", "protocol", agentProtocol)
+		os.Exit(1)
+	}
+	// Agent watch 流上的状态变化经由该 channel 触发 Sandbox reconcile
+	statusEvents := make(chan event.GenericEvent, 1024)
+	// 容量不足时排队的 FastPath 请求经由该 channel 触发池扩容，新 Agent 注册后唤醒队首
+	capacityQueue := agentpool.NewCapacityQueue()
+	scaleUpEvents := make(chan event.GenericEvent, 128)
+	capacityQueue.ScaleUp = scaleUpEvents
+	reg.SetCapacityListener(capacityQueue.Notify)
+	var agentCerts *agenttls.Issuer
+	if agentMTLS {
+		agentCerts, err = agentTLSIssuer(mgr, agentCASecret)
+		if err != nil {
+			klog.ErrorS(err, "unable to configure agent mTLS")
+			os.Exit(1)
+		}
+		agentClient.SetTLS(agentCerts.CA.ClientTLSConfig(), agenttls.ServerNameResolver(mgr.GetClient()))
+	}
+	// FastPath 与 SandboxReconciler 共用同一个 Tracker，两条路径的预留互相可见
+	quotaTracker := quota.NewTracker(mgr.GetClient())
+
 	if err = (&controller.SandboxReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Registry:     reg,
+		AgentClient:  agentClient,
+		StatusEvents: statusEvents,
+		Recorder:     mgr.GetEventRecorderFor("sandbox-controller"),
+		Quotas:       quotaTracker,
+		Reader:       mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		klog.ErrorS(err, "unable to create controller", "controller", "Sandbox")
+		os.Exit(1)
+	}
+
+	if err = (&controller.SandboxCheckpointReconciler{
 		Client:      mgr.GetClient(),
 		Scheme:      mgr.GetScheme(),
 		Registry:    reg,
-		AgentClient: agentHTTPClient,
+		AgentClient: agentClient,
+		Recorder:    mgr.GetEventRecorderFor("sandboxcheckpoint-controller"),
+		Reader:      mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
-		klog.ErrorS(err, "unable to create controller", "controller", "Sandbox")
+		klog.ErrorS(err, "unable to create controller", "controller", "SandboxCheckpoint")
+		os.Exit(1)
+	}
+
+	if err = (&controller.SandboxQuotaReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		klog.ErrorS(err, "unable to create controller", "controller", "SandboxQuota")
 		os.Exit(1)
 	}
 
 	if err = (&controller.SandboxPoolReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Registry: reg,
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Registry:      reg,
+		Queue:         capacityQueue,
+		ScaleUpEvents: scaleUpEvents,
+		AgentCerts:    agentCerts,
 	}).SetupWithManager(mgr); err != nil {
 		klog.ErrorS(err, "unable to create controller", "controller", "SandboxPool")
 		os.Exit(1)
 	}
 
+	if err := mgr.Add(controller.NewSandboxGC(mgr.GetClient(), finishedSandboxMaxAge, finishedSandboxMaxCount)); err != nil {
+		klog.ErrorS(err, "unable to set up sandbox GC")
+		os.Exit(1)
+	}
+
 	ctx := ctrl.SetupSignalHandler()
-	loop := agentcontrol.NewLoop(mgr.GetClient(), reg, agentHTTPClient)
+	loop := agentcontrol.NewLoop(mgr.GetClient(), reg, agentClient)
+	loop.SandboxEvents = statusEvents
 	go loop.Start(ctx)
 
 	lis, err := net.Listen("tcp", ":9090")
@@ -94,20 +188,45 @@ func main() {
 		klog.ErrorS(err, "failed to listen on port 9090 for fast-path")
 		os.Exit(1)
 	}
-	grpcServer := grpc.NewServer()
+	grpcOpts, err := fastpathServerOptions(mgr, fastpathAuthMode, fastpathTokenFile, fastpathTokenAudiences, fastpathTLSCertFile, fastpathTLSKeyFile)
+	if err != nil {
+		klog.ErrorS(err, "unable to configure fast-path security")
+		os.Exit(1)
+	}
+	grpcServer := grpc.NewServer(grpcOpts...)
 
 	consistencyMode := api.ConsistencyModeFast
 	if fastpathConsistencyMode == "strong" {
 		consistencyMode = api.ConsistencyModeStrong
 	}
 
+	// WatchSandboxes 直接从 informer 缓存推送变化，不额外访问 API Server
+	watchHub := fastpath.NewWatchHub()
+	sandboxInformer, err := mgr.GetCache().GetInformer(ctx, &apiv1alpha1.Sandbox{})
+	if err != nil {
+		klog.ErrorS(err, "unable to get sandbox informer")
+		os.Exit(1)
+	}
+	watchReg, err := sandboxInformer.AddEventHandler(watchHub)
+	if err != nil {
+		klog.ErrorS(err, "unable to register sandbox watch handler")
+		os.Exit(1)
+	}
+	watchHub.SetSynced(watchReg.HasSynced)
+
 	fastpathv1.RegisterFastPathServiceServer(grpcServer, &fastpath.Server{
 		K8sClient:              mgr.GetClient(),
 		Registry:               reg,
-		AgentClient:            agentHTTPClient,
+		AgentClient:            agentClient,
 		DefaultConsistencyMode: consistencyMode,
+		Watches:                watchHub,
+		IdempotencyWindow:      fastpathIdempotencyWindow,
+		Queue:                  capacityQueue,
+		Recorder:               mgr.GetEventRecorderFor("fastpath"),
+		Quotas:                 quotaTracker,
+		Reader:                 mgr.GetAPIReader(),
 	})
-	klog.InfoS("Starting Fast-Path gRPC server V2", "port", 9090, "consistency-mode", consistencyMode, "orphan-timeout", fastpathOrphanTimeout)
+	klog.InfoS("Starting Fast-Path gRPC server V2", "port", 9090, "consistency-mode", consistencyMode, "orphan-timeout", fastpathOrphanTimeout, "auth", fastpathAuthMode, "tls", fastpathTLSCertFile != "")
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
 			klog.ErrorS(err, "failed to serve gRPC")
@@ -149,3 +268,80 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// agentTLSIssuer loads the agent CA and indexes agent pods by IP so that the agent
+// client can tell which pod it is dialing.
+func agentTLSIssuer(mgr ctrl.Manager, caSecret string) (*agenttls.Issuer, error) {
+	namespace, name, ok := strings.Cut(caSecret, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("--agent-ca-secret must be namespace/name, got %q", caSecret)
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	// 缓存尚未启动，直接读 API Server
+	ca, err := agenttls.LoadOrCreateCA(context.Background(), mgr.GetClient(), mgr.GetAPIReader(), key)
+	if err != nil {
+		return nil, err
+	}
+	if err := agenttls.IndexPodIP(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return nil, err
+	}
+	return &agenttls.Issuer{CA: ca, CASecret: key, Client: mgr.GetClient(), Reader: mgr.GetAPIReader()}, nil
+}
+
+// fastpathServerOptions builds the TLS credentials and the authentication interceptors
+// of the Fast-Path gRPC server.
+func fastpathServerOptions(mgr ctrl.Manager, authMode, tokenFile, audiences, certFile, keyFile string) ([]grpc.ServerOption, error) {
+	var opts []grpc.ServerOption
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("--fastpath-tls-cert-file and --fastpath-tls-key-file must be set together")
+		}
+		watcher, err := certwatcher.New(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fast-path TLS certificate: %w", err)
+		}
+		if err := mgr.Add(watcher); err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			GetCertificate: watcher.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		})))
+	}
+
+	var authenticator auth.Authenticator
+	switch authMode {
+	case "none":
+		klog.InfoS("Fast-Path authentication is disabled, any client reaching port 9090 bypasses RBAC")
+		return opts, nil
+	case "tokenreview":
+		var auds []string
+		if audiences != "" {
+			auds = strings.Split(audiences, ",")
+		}
+		authenticator = auth.NewTokenReviewAuthenticator(mgr.GetClient(), auds)
+	case "static":
+		if tokenFile == "" {
+			return nil, fmt.Errorf("--fastpath-auth=static requires --fastpath-token-file")
+		}
+		tokens, err := auth.LoadStaticTokens(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticator = tokens
+	default:
+		return nil, fmt.Errorf("unknown --fastpath-auth %q, must be none, tokenreview or static", authMode)
+	}
+	if certFile == "" {
+		klog.InfoS("Fast-Path authentication is enabled without TLS, bearer tokens are sent in plaintext")
+	}
+
+	a := &fastpath.Auth{
+		Authenticator: authenticator,
+		Authorizer:    auth.NewSubjectAccessReviewAuthorizer(mgr.GetClient()),
+	}
+	return append(opts,
+		grpc.ChainUnaryInterceptor(a.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(a.StreamInterceptor()),
+	), nil
+}
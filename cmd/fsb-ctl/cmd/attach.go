@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+var (
+	attachStdin bool
+	attachTTY   bool
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <sandbox-name> [-i] [-t]",
+	Short: "Attach to the main process of a running sandbox",
+	Long: `Attach to the main process of a running sandbox.
+
+Sending input (-i) requires the sandbox to be created with --stdin, and an
+interactive terminal (-t) requires it to be created with --tty:
+
+  fsb-ctl run my-shell --image=alpine --tty --stdin -- /bin/sh
+  fsb-ctl attach my-shell -it
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		namespace := viper.GetString("namespace")
+		klog.V(4).InfoS("CLI attach command started", "name", name, "namespace", namespace, "stdin", attachStdin, "tty", attachTTY)
+
+		client, conn := getClient()
+		if conn != nil {
+			defer conn.Close()
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream, err := client.AttachSandbox(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Failed to open attach stream", "name", name)
+			log.Fatalf("Failed to attach: %s", formatRPCError(err))
+		}
+
+		if err := stream.Send(&fastpathv1.AttachRequest{Payload: &fastpathv1.AttachRequest_Start{Start: &fastpathv1.AttachStart{
+			SandboxName: name,
+			Namespace:   namespace,
+			Stdin:       attachStdin,
+		}}}); err != nil {
+			klog.ErrorS(err, "Failed to send attach start", "name", name)
+			log.Fatalf("Failed to attach: %s", formatRPCError(err))
+		}
+
+		session := &streamSession{
+			sendStdin: func(data []byte) error {
+				return stream.Send(&fastpathv1.AttachRequest{Payload: &fastpathv1.AttachRequest_Stdin{Stdin: data}})
+			},
+			sendResize: func(size *fastpathv1.TerminalSize) error {
+				return stream.Send(&fastpathv1.AttachRequest{Payload: &fastpathv1.AttachRequest_Resize{Resize: size}})
+			},
+			closeSend: stream.CloseSend,
+			recv:      stream.Recv,
+		}
+		exit, err := session.run(attachStdin, attachTTY)
+		if err != nil {
+			klog.ErrorS(err, "Attach stream failed", "name", name)
+			log.Fatalf("Error: %s", formatRPCError(err))
+		}
+		klog.V(4).InfoS("Attached process exited", "name", name, "exitCode", exit.ExitCode, "message", exit.Message)
+		if exit.Message != "" {
+			log.Fatalf("Attach failed: %s", exit.Message)
+		}
+		os.Exit(int(exit.ExitCode))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+	attachCmd.Flags().BoolVarP(&attachStdin, "stdin", "i", false, "Pass stdin to the main process")
+	attachCmd.Flags().BoolVarP(&attachTTY, "tty", "t", false, "Stdin is a terminal: use raw mode and forward window size")
+}
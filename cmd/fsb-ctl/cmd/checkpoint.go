@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+var (
+	checkpointName   string
+	checkpointImage  string
+	checkpointSecret string
+	restoreName      string
+	restoreMode      string
+)
+
+// checkpointCmd represents the checkpoint command
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint <sandbox-name>",
+	Short: "Checkpoint the processes and filesystem of a sandbox",
+	Long: `Take a CRIU checkpoint of a Running or Paused sandbox (runsc checkpoint on gVisor pools).
+The sandbox keeps running, restore starts new sandboxes from the checkpoint.
+
+Without --image the checkpoint stays on the node of the sandbox and can only be
+restored there. With --image it is pushed to that OCI reference and can be restored
+by every agent of the pool. --registry-secret names a kubernetes.io/dockerconfigjson
+Secret in the namespace with the credentials for that registry, agents use it to push
+the checkpoint and to pull it for restores.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sandboxName := args[0]
+		namespace := viper.GetString("namespace")
+		klog.V(4).InfoS("CLI checkpoint command started", "sandboxName", sandboxName, "namespace", namespace, "name", checkpointName, "image", checkpointImage)
+
+		client, conn := getClient()
+		if conn != nil {
+			defer conn.Close()
+		}
+
+		start := time.Now()
+		info, err := client.CheckpointSandbox(context.Background(), &fastpathv1.CheckpointRequest{
+			SandboxName:    sandboxName,
+			Namespace:      namespace,
+			Name:           checkpointName,
+			Image:          checkpointImage,
+			RegistrySecret: checkpointSecret,
+		})
+		if err != nil {
+			klog.ErrorS(err, "CheckpointSandbox request failed", "sandboxName", sandboxName)
+			log.Fatalf("Error: %s", formatRPCError(err))
+		}
+
+		klog.V(4).InfoS("Sandbox checkpointed", "sandboxName", sandboxName, "checkpoint", info.Name, "ref", info.Ref, "duration", time.Since(start))
+		fmt.Printf("✓ Sandbox %s checkpointed in %v\n", sandboxName, time.Since(start))
+		fmt.Printf("Checkpoint: %s\n", info.Name)
+		fmt.Printf("Image:      %s\n", info.Ref)
+		if checkpointImage == "" {
+			fmt.Printf("Node:       %s (restore is limited to this node)\n", info.NodeName)
+		}
+	},
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <checkpoint-name>",
+	Short: "Start a new sandbox from a checkpoint",
+	Long: `Start a new sandbox that resumes the processes and filesystem of a checkpoint.
+The sandbox gets the image, command, environment, resources and ports the checkpointed
+sandbox had and runs in the same pool.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ckptName := args[0]
+		namespace := viper.GetString("namespace")
+		klog.V(4).InfoS("CLI restore command started", "checkpoint", ckptName, "namespace", namespace, "name", restoreName)
+
+		client, conn := getClient()
+		if conn != nil {
+			defer conn.Close()
+		}
+
+		consistency := fastpathv1.ConsistencyMode_FAST
+		if restoreMode == "strong" {
+			consistency = fastpathv1.ConsistencyMode_STRONG
+		}
+
+		start := time.Now()
+		resp, err := client.RestoreSandbox(context.Background(), &fastpathv1.RestoreRequest{
+			CheckpointName:  ckptName,
+			Namespace:       namespace,
+			Name:            restoreName,
+			ConsistencyMode: consistency,
+		})
+		if err != nil {
+			klog.ErrorS(err, "RestoreSandbox request failed", "checkpoint", ckptName)
+			log.Fatalf("Error: %s", formatRPCError(err))
+		}
+
+		klog.V(4).InfoS("Sandbox restored", "checkpoint", ckptName, "sandboxName", resp.SandboxName, "agent", resp.AgentPod, "duration", time.Since(start))
+		fmt.Printf("🎉 Sandbox restored from %s in %v\n", ckptName, time.Since(start))
+		fmt.Printf("Name:      %s\n", resp.SandboxName)
+		fmt.Printf("ID:        %s\n", resp.SandboxId)
+		fmt.Printf("Agent:     %s\n", resp.AgentPod)
+		fmt.Printf("Endpoints: %v\n", resp.Endpoints)
+	},
+}
+
+func init() {
+	checkpointCmd.Flags().StringVar(&checkpointName, "name", "", "Name of the SandboxCheckpoint (default <sandbox-name>-<unix time>)")
+	checkpointCmd.Flags().StringVar(&checkpointImage, "image", "", "OCI reference to push the checkpoint to, so that any node of the pool can restore it")
+	checkpointCmd.Flags().StringVar(&checkpointSecret, "registry-secret", "", "kubernetes.io/dockerconfigjson Secret with the credentials for the registry of --image")
+	restoreCmd.Flags().StringVar(&restoreName, "name", "", "Name of the new sandbox")
+	restoreCmd.Flags().StringVar(&restoreMode, "mode", "fast", "Consistency mode (fast/strong)")
+	rootCmd.AddCommand(checkpointCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <src> <dst>",
+	Short: "Copy files and directories to and from a sandbox",
+	Long: `Copy files and directories to and from a running sandbox.
+
+One of src or dst must be <sandbox-name>:<absolute-path>. The destination
+names the copied file or directory, a trailing "/" on a sandbox destination
+copies into that directory instead. The sandbox image must provide tar.
+
+Examples:
+  fsb-ctl cp ./app my-sandbox:/workspace/app
+  fsb-ctl cp main.py my-sandbox:/workspace/
+  fsb-ctl cp my-sandbox:/workspace/out ./out
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		srcSandbox, srcPath, srcRemote := splitCopyArg(args[0])
+		dstSandbox, dstPath, dstRemote := splitCopyArg(args[1])
+		namespace := viper.GetString("namespace")
+		klog.V(4).InfoS("CLI cp command started", "src", args[0], "dst", args[1], "namespace", namespace)
+
+		if srcRemote == dstRemote {
+			log.Fatal("Error: exactly one of src and dst must be <sandbox-name>:<path>")
+		}
+
+		client, conn := getClient()
+		if conn != nil {
+			defer conn.Close()
+		}
+
+		var err error
+		if dstRemote {
+			err = copyToSandbox(client, namespace, srcPath, dstSandbox, dstPath)
+		} else {
+			err = copyFromSandbox(client, namespace, srcSandbox, srcPath, dstPath)
+		}
+		if err != nil {
+			klog.ErrorS(err, "Copy failed", "src", args[0], "dst", args[1])
+			log.Fatalf("Error: %s", formatRPCError(err))
+		}
+		klog.V(4).InfoS("Copy completed", "src", args[0], "dst", args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+}
+
+// splitCopyArg splits "<sandbox>:<path>"; anything else is a local path.
+func splitCopyArg(arg string) (sandbox, p string, remote bool) {
+	i := strings.Index(arg, ":")
+	if i <= 0 || strings.ContainsAny(arg[:i], `/\`) {
+		return "", arg, false
+	}
+	return arg[:i], arg[i+1:], true
+}
+
+func copyToSandbox(client fastpathv1.FastPathServiceClient, namespace, localPath, sandbox, remotePath string) error {
+	if !path.IsAbs(remotePath) {
+		return fmt.Errorf("sandbox path %q must be absolute", remotePath)
+	}
+	if _, err := os.Stat(localPath); err != nil {
+		return err
+	}
+
+	dir, name := path.Dir(remotePath), path.Base(remotePath)
+	if strings.HasSuffix(remotePath, "/") {
+		dir, name = path.Clean(remotePath), filepath.Base(localPath)
+	}
+
+	stream, err := client.CopyToSandbox(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&fastpathv1.CopyToRequest{Payload: &fastpathv1.CopyToRequest_Target{Target: &fastpathv1.CopyTarget{
+		SandboxName: sandbox,
+		Namespace:   namespace,
+		Path:        dir,
+	}}}); err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTar(pw, localPath, name))
+	}()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := pr.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			if sendErr := stream.Send(&fastpathv1.CopyToRequest{Payload: &fastpathv1.CopyToRequest_Data{Data: data}}); sendErr != nil {
+				// The real failure is reported by CloseAndRecv
+				break
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	pr.Close()
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+func copyFromSandbox(client fastpathv1.FastPathServiceClient, namespace, sandbox, remotePath, localPath string) error {
+	if !path.IsAbs(remotePath) {
+		return fmt.Errorf("sandbox path %q must be absolute", remotePath)
+	}
+
+	stream, err := client.CopyFromSandbox(context.Background(), &fastpathv1.CopyFromRequest{Target: &fastpathv1.CopyTarget{
+		SandboxName: sandbox,
+		Namespace:   namespace,
+		Path:        remotePath,
+	}})
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(resp.Data); err != nil {
+				return
+			}
+		}
+	}()
+	defer pr.Close()
+
+	return extractTar(pr, localPath)
+}
+
+// writeTar archives src with every entry renamed so that src itself becomes name.
+func writeTar(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar unpacks an archive whose entries share one top-level name, placing that
+// top-level entry at dst. Entries escaping dst are rejected.
+func extractTar(r io.Reader, dst string) error {
+	dst = filepath.Clean(dst)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Strip the top-level component, it is replaced by dst
+		rel := path.Clean(hdr.Name)
+		if i := strings.Index(rel, "/"); i >= 0 {
+			rel = rel[i+1:]
+		} else {
+			rel = "."
+		}
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("refusing to extract %q outside of %s", hdr.Name, dst)
+		}
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// Only relative links that stay inside dst, so later entries cannot be written through them
+			linkTarget := filepath.Join(filepath.Dir(target), hdr.Linkname)
+			if filepath.IsAbs(hdr.Linkname) || !isWithin(dst, linkTarget) {
+				klog.InfoS("Skipping symlink pointing outside of destination", "name", hdr.Name, "link", hdr.Linkname)
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			klog.V(4).InfoS("Skipping unsupported tar entry", "name", hdr.Name, "type", hdr.Typeflag)
+		}
+	}
+}
+
+func isWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
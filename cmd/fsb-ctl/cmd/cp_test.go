@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCopyArg(t *testing.T) {
+	tests := []struct {
+		arg     string
+		sandbox string
+		path    string
+		remote  bool
+	}{
+		{arg: "my-sb:/workspace", sandbox: "my-sb", path: "/workspace", remote: true},
+		{arg: "./local/file", path: "./local/file"},
+		{arg: "/abs/path", path: "/abs/path"},
+		{arg: "./dir:with-colon", path: "./dir:with-colon"},
+		{arg: ":/no-name", path: ":/no-name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			sandbox, p, remote := splitCopyArg(tt.arg)
+			assert.Equal(t, tt.sandbox, sandbox)
+			assert.Equal(t, tt.path, p)
+			assert.Equal(t, tt.remote, remote)
+		})
+	}
+}
+
+func TestTarRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("world"), 0600))
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(src, "link")))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTar(&buf, src, "app"))
+
+	dst := filepath.Join(t.TempDir(), "restored")
+	require.NoError(t, extractTar(&buf, dst))
+
+	data, err := os.ReadFile(filepath.Join(dst, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	data, err = os.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", link)
+}
+
+func TestTarRoundTrip_SingleFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "main.py")
+	require.NoError(t, os.WriteFile(src, []byte("print(1)"), 0644))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTar(&buf, src, "main.py"))
+
+	dst := filepath.Join(t.TempDir(), "copy.py")
+	require.NoError(t, extractTar(&buf, dst))
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "print(1)", string(data))
+}
+
+func TestExtractTar_RejectsEscapes(t *testing.T) {
+	dst := t.TempDir()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "top/../../..", Typeflag: tar.TypeDir, Mode: 0755}))
+	require.NoError(t, tw.Close())
+	assert.Error(t, extractTar(&buf, dst))
+
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "top/evil", Typeflag: tar.TypeSymlink, Linkname: "/etc"}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "top/up", Typeflag: tar.TypeSymlink, Linkname: "../../outside"}))
+	require.NoError(t, tw.Close())
+	require.NoError(t, extractTar(&buf, dst))
+
+	_, err := os.Lstat(filepath.Join(dst, "evil"))
+	assert.True(t, os.IsNotExist(err), "Absolute symlink should be skipped")
+	_, err = os.Lstat(filepath.Join(dst, "up"))
+	assert.True(t, os.IsNotExist(err), "Escaping symlink should be skipped")
+}
@@ -34,7 +34,7 @@ var deleteCmd = &cobra.Command{
 		})
 		if err != nil {
 			klog.ErrorS(err, "DeleteSandbox request failed", "sandboxName", sandboxName, "namespace", namespace)
-			log.Fatalf("Error: %v", err)
+			log.Fatalf("Error: %s", formatRPCError(err))
 		}
 
 		klog.V(4).InfoS("DeleteSandbox request succeeded", "sandboxName", sandboxName)
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// formatRPCError renders a gRPC error with its code, the details sent by the controller
+// and a hint on what to do next. Other errors are returned as they are.
+func formatRPCError(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)", st.Message(), st.Code())
+	var ports []string
+	var quotaExceeded bool
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.RetryInfo:
+			fmt.Fprintf(&b, "\n  Retry after: %s", d.GetRetryDelay().AsDuration())
+		case *errdetails.QuotaFailure:
+			quotaExceeded = true
+			for _, v := range d.GetViolations() {
+				fmt.Fprintf(&b, "\n  Quota %s: %s", v.GetSubject(), v.GetDescription())
+			}
+		case *errdetails.ResourceInfo:
+			if d.GetDescription() != "" {
+				fmt.Fprintf(&b, "\n  %s %s: %s", d.GetResourceType(), d.GetResourceName(), d.GetDescription())
+			}
+		case *errdetails.PreconditionFailure:
+			for _, v := range d.GetViolations() {
+				if v.GetType() == "PORT" {
+					ports = append(ports, v.GetSubject())
+				}
+			}
+		}
+	}
+
+	switch st.Code() {
+	case codes.ResourceExhausted:
+		if quotaExceeded {
+			b.WriteString("\n  Hint: the namespace quota is used up, delete sandboxes or raise the SandboxQuota")
+		} else {
+			b.WriteString("\n  Hint: the pool has no free capacity, retry later or scale up the pool")
+		}
+	case codes.FailedPrecondition:
+		if len(ports) > 0 {
+			fmt.Fprintf(&b, "\n  Hint: ports %s are taken on every agent, choose other --ports", strings.Join(ports, ","))
+		}
+	case codes.NotFound:
+		b.WriteString("\n  Hint: check the sandbox name and --namespace")
+	case codes.Unavailable:
+		b.WriteString("\n  Hint: the agent or controller is unreachable, retry later")
+	case codes.Unauthenticated:
+		b.WriteString("\n  Hint: pass a valid token with --token or --token-file")
+	case codes.PermissionDenied:
+		b.WriteString("\n  Hint: the user needs RBAC access to sandboxes in this namespace")
+	}
+	return b.String()
+}
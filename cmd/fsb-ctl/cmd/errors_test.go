@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestFormatRPCError(t *testing.T) {
+	st, _ := status.New(codes.ResourceExhausted, "insufficient capacity in pool p").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(2 * time.Second)})
+	out := formatRPCError(st.Err())
+	assert.Contains(t, out, "insufficient capacity in pool p (ResourceExhausted)")
+	assert.Contains(t, out, "Retry after: 2s")
+	assert.Contains(t, out, "scale up the pool")
+
+	st, _ = status.New(codes.FailedPrecondition, "port conflict in pool p").
+		WithDetails(&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: "PORT", Subject: "8080"}}})
+	assert.Contains(t, formatRPCError(st.Err()), "ports 8080 are taken")
+
+	st, _ = status.New(codes.ResourceExhausted, "sandbox quota exceeded").
+		WithDetails(&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "team/q", Description: "sandboxes used 3 of 2"}}})
+	out = formatRPCError(st.Err())
+	assert.Contains(t, out, "Quota team/q: sandboxes used 3 of 2")
+	assert.Contains(t, out, "raise the SandboxQuota")
+
+	st, _ = status.New(codes.DeadlineExceeded, "sandbox default/sb-1 is not ready after 30s").
+		WithDetails(&errdetails.ResourceInfo{ResourceType: "Sandbox", ResourceName: "default/sb-1", Description: "sandbox 42 was created on agent a-1 and is kept"})
+	assert.Contains(t, formatRPCError(st.Err()), "Sandbox default/sb-1: sandbox 42 was created on agent a-1 and is kept")
+
+	out = formatRPCError(status.Error(codes.PermissionDenied, "user alice cannot create sandboxes in namespace team"))
+	assert.Contains(t, out, "needs RBAC access")
+
+	assert.Equal(t, "plain", formatRPCError(errors.New("plain")))
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+var (
+	execStdin   bool
+	execTTY     bool
+	execWorkdir string
+	execEnvs    []string
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <sandbox-name> [-i] [-t] -- <command> [args...]",
+	Short: "Execute a command in a running sandbox",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		command := args[1:]
+		namespace := viper.GetString("namespace")
+		klog.V(4).InfoS("CLI exec command started", "name", name, "namespace", namespace, "command", command, "stdin", execStdin, "tty", execTTY)
+
+		envs, err := parseEnvs(execEnvs)
+		if err != nil {
+			log.Fatalf("Invalid --env: %v", err)
+		}
+
+		client, conn := getClient()
+		if conn != nil {
+			defer conn.Close()
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream, err := client.ExecSandbox(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Failed to open exec stream", "name", name)
+			log.Fatalf("Failed to exec: %s", formatRPCError(err))
+		}
+
+		if err := stream.Send(&fastpathv1.ExecRequest{Payload: &fastpathv1.ExecRequest_Start{Start: &fastpathv1.ExecStart{
+			SandboxName: name,
+			Namespace:   namespace,
+			Command:     command,
+			Envs:        envs,
+			WorkingDir:  execWorkdir,
+			Stdin:       execStdin,
+			Tty:         execTTY,
+		}}}); err != nil {
+			klog.ErrorS(err, "Failed to send exec start", "name", name)
+			log.Fatalf("Failed to exec: %s", formatRPCError(err))
+		}
+
+		session := &streamSession{
+			sendStdin: func(data []byte) error {
+				return stream.Send(&fastpathv1.ExecRequest{Payload: &fastpathv1.ExecRequest_Stdin{Stdin: data}})
+			},
+			sendResize: func(size *fastpathv1.TerminalSize) error {
+				return stream.Send(&fastpathv1.ExecRequest{Payload: &fastpathv1.ExecRequest_Resize{Resize: size}})
+			},
+			closeSend: stream.CloseSend,
+			recv:      stream.Recv,
+		}
+		exit, err := session.run(execStdin, execTTY)
+		if err != nil {
+			klog.ErrorS(err, "Exec stream failed", "name", name)
+			log.Fatalf("Error: %s", formatRPCError(err))
+		}
+		klog.V(4).InfoS("Exec finished", "name", name, "exitCode", exit.ExitCode, "message", exit.Message)
+		if exit.Message != "" {
+			log.Fatalf("Exec failed: %s", exit.Message)
+		}
+		os.Exit(int(exit.ExitCode))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().BoolVarP(&execStdin, "stdin", "i", false, "Pass stdin to the command")
+	execCmd.Flags().BoolVarP(&execTTY, "tty", "t", false, "Allocate a pseudo-terminal")
+	execCmd.Flags().StringVarP(&execWorkdir, "workdir", "w", "", "Working directory inside the sandbox")
+	execCmd.Flags().StringArrayVarP(&execEnvs, "env", "e", nil, "Environment variables (KEY=VALUE)")
+}
+
+// parseEnvs converts KEY=VALUE pairs into a map.
+func parseEnvs(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	envs := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return nil, errors.New("expected KEY=VALUE, got " + pair)
+		}
+		envs[k] = v
+	}
+	return envs, nil
+}
@@ -3,27 +3,65 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"text/tabwriter"
 	"time"
 
 	fastpathv1 "fast-sandbox/api/proto/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gopkg.in/yaml.v3"
 	"k8s.io/klog/v2"
 )
 
-var outputFormat string
+var (
+	outputFormat         string
+	watchFlag            bool
+	watchPool            string
+	watchSelector        string
+	watchResourceVersion string
+)
 
 var getCmd = &cobra.Command{
 	Use:   "get <sandbox-name>",
 	Short: "Get detailed sandbox information",
-	Args:  cobra.ExactArgs(1),
+	Long: `Get detailed sandbox information.
+
+With -w, watch sandbox changes instead: the current sandboxes are printed first,
+then every change as it happens. The sandbox name is optional in watch mode.`,
+	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
-		sandboxName := args[0]
 		namespace := viper.GetString("namespace")
+		if watchFlag {
+			req := &fastpathv1.WatchRequest{
+				Namespace:       namespace,
+				PoolRef:         watchPool,
+				LabelSelector:   watchSelector,
+				ResourceVersion: watchResourceVersion,
+			}
+			if len(args) == 1 {
+				req.SandboxName = args[0]
+			}
+			client, conn := getClient()
+			if conn != nil {
+				defer conn.Close()
+			}
+			if err := watchSandboxes(context.Background(), client, req, os.Stdout); err != nil {
+				log.Fatalf("Error: %s", formatRPCError(err))
+			}
+			return
+		}
+		if len(args) != 1 {
+			log.Fatalf("Error: sandbox name is required unless --watch is set")
+		}
+
+		sandboxName := args[0]
 		klog.V(4).InfoS("CLI get command started", "sandboxName", sandboxName, "namespace", namespace)
 
 		client, conn := getClient()
@@ -38,7 +76,7 @@ var getCmd = &cobra.Command{
 		})
 		if err != nil {
 			klog.ErrorS(err, "GetSandbox request failed", "sandboxName", sandboxName, "namespace", namespace)
-			log.Fatalf("Error: %v", err)
+			log.Fatalf("Error: %s", formatRPCError(err))
 		}
 
 		klog.V(4).InfoS("GetSandbox request succeeded", "sandboxId", resp.SandboxId, "sandboxName", resp.SandboxName, "phase", resp.Phase, "outputFormat", outputFormat)
@@ -55,7 +93,61 @@ var getCmd = &cobra.Command{
 	},
 }
 
+// watchSandboxes prints sandbox watch events until the stream ends. When the controller
+// drops a slow watch, the watch is resumed from the last resource version seen.
+func watchSandboxes(ctx context.Context, client fastpathv1.FastPathServiceClient, req *fastpathv1.WatchRequest, out io.Writer) error {
+	// 逐行输出无法整体对齐，用最小列宽保持可读
+	w := tabwriter.NewWriter(out, 10, 8, 2, ' ', 0)
+	if outputFormat != "json" {
+		fmt.Fprintln(w, "EVENT\tNAME\tPHASE\tRESTARTS\tAGENT\tAGE")
+		w.Flush()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	for {
+		klog.V(4).InfoS("Sending WatchSandboxes request", "namespace", req.Namespace, "pool", req.PoolRef, "selector", req.LabelSelector, "resourceVersion", req.ResourceVersion)
+		stream, err := client.WatchSandboxes(ctx, req)
+		if err != nil {
+			return err
+		}
+		for {
+			ev, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if status.Code(err) == codes.Aborted && req.ResourceVersion != "" {
+				klog.V(2).InfoS("Watch dropped by controller, resuming", "resourceVersion", req.ResourceVersion)
+				break
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+
+			req.ResourceVersion = ev.ResourceVersion
+			if ev.Type == fastpathv1.WatchEventType_BOOKMARK {
+				continue
+			}
+			if outputFormat == "json" {
+				enc.Encode(ev)
+				continue
+			}
+			sb := ev.Sandbox
+			age := time.Since(time.Unix(sb.CreatedAt, 0)).Truncate(time.Second)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", ev.Type, sb.SandboxName, sb.Phase, sb.RestartCount, sb.AgentPod, age)
+			w.Flush()
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.Flags().StringVarP(&outputFormat, "output", "o", "yaml", "Output format (yaml|json)")
+	getCmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "Watch sandbox changes; the sandbox name becomes optional")
+	getCmd.Flags().StringVar(&watchPool, "pool", "", "Only watch sandboxes of this pool (with --watch)")
+	getCmd.Flags().StringVarP(&watchSelector, "selector", "l", "", "Label selector to filter watched sandboxes, e.g. app=web (with --watch)")
+	getCmd.Flags().StringVar(&watchResourceVersion, "resource-version", "", "Resume the watch after this resource version (with --watch)")
 }
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeWatchClient serves one scripted stream per WatchSandboxes call.
+type fakeWatchClient struct {
+	fastpathv1.FastPathServiceClient
+	streams  [][]watchResult
+	requests []*fastpathv1.WatchRequest
+}
+
+type watchResult struct {
+	ev  *fastpathv1.WatchEvent
+	err error
+}
+
+func (c *fakeWatchClient) WatchSandboxes(ctx context.Context, in *fastpathv1.WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[fastpathv1.WatchEvent], error) {
+	c.requests = append(c.requests, &fastpathv1.WatchRequest{ResourceVersion: in.ResourceVersion, PoolRef: in.PoolRef})
+	results := c.streams[0]
+	c.streams = c.streams[1:]
+	return &fakeWatchStream{results: results}, nil
+}
+
+type fakeWatchStream struct {
+	grpc.ClientStream
+	results []watchResult
+}
+
+func (s *fakeWatchStream) Recv() (*fastpathv1.WatchEvent, error) {
+	if len(s.results) == 0 {
+		return nil, io.EOF
+	}
+	r := s.results[0]
+	s.results = s.results[1:]
+	return r.ev, r.err
+}
+
+func TestWatchSandboxes_ResumeAfterAbort(t *testing.T) {
+	outputFormat = "yaml"
+	sb := &fastpathv1.SandboxInfo{SandboxName: "sb-1", Phase: "Pending", AgentPod: "agent-1"}
+	running := &fastpathv1.SandboxInfo{SandboxName: "sb-1", Phase: "Running", AgentPod: "agent-1"}
+	client := &fakeWatchClient{streams: [][]watchResult{
+		{
+			{ev: &fastpathv1.WatchEvent{Type: fastpathv1.WatchEventType_ADDED, Sandbox: sb, ResourceVersion: "3"}},
+			{ev: &fastpathv1.WatchEvent{Type: fastpathv1.WatchEventType_BOOKMARK, ResourceVersion: "5"}},
+			{err: status.Error(codes.Aborted, "watcher fell behind")},
+		},
+		{
+			{ev: &fastpathv1.WatchEvent{Type: fastpathv1.WatchEventType_MODIFIED, Sandbox: running, ResourceVersion: "6"}},
+		},
+	}}
+
+	var out bytes.Buffer
+	err := watchSandboxes(context.Background(), client, &fastpathv1.WatchRequest{PoolRef: "pool-a"}, &out)
+	require.NoError(t, err)
+
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, "", client.requests[0].ResourceVersion)
+	assert.Equal(t, "5", client.requests[1].ResourceVersion, "Resumes from the last bookmark")
+	assert.Equal(t, "pool-a", client.requests[1].PoolRef)
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[0]), "EVENT")
+	assert.Contains(t, string(lines[1]), "ADDED")
+	assert.Contains(t, string(lines[1]), "Pending")
+	assert.Contains(t, string(lines[2]), "MODIFIED")
+	assert.Contains(t, string(lines[2]), "Running")
+}
+
+func TestWatchSandboxes_Error(t *testing.T) {
+	outputFormat = "yaml"
+	client := &fakeWatchClient{streams: [][]watchResult{
+		{{err: status.Error(codes.OutOfRange, "too old")}},
+	}}
+	err := watchSandboxes(context.Background(), client, &fastpathv1.WatchRequest{ResourceVersion: "1"}, io.Discard)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+}
@@ -34,15 +34,15 @@ var listCmd = &cobra.Command{
 		})
 		if err != nil {
 			klog.ErrorS(err, "ListSandboxes request failed", "namespace", namespace)
-			log.Fatalf("Error: %v", err)
+			log.Fatalf("Error: %s", formatRPCError(err))
 		}
 
 		klog.V(4).InfoS("ListSandboxes request succeeded", "namespace", namespace, "count", len(resp.Items))
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-		fmt.Fprintln(w, "NAME\tID\tPHASE\tIMAGE\tAGENT\tAGE")
+		fmt.Fprintln(w, "NAME\tID\tPHASE\tRESTARTS\tIMAGE\tAGENT\tAGE")
 		for _, item := range resp.Items {
 			age := time.Since(time.Unix(item.CreatedAt, 0)).Truncate(time.Second)
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", item.SandboxName, item.SandboxId, item.Phase, item.Image, item.AgentPod, age)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", item.SandboxName, item.SandboxId, item.Phase, item.RestartCount, item.Image, item.AgentPod, age)
 		}
 		w.Flush()
 	},
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+// pauseCmd represents the pause command
+var pauseCmd = &cobra.Command{
+	Use:   "pause <sandbox-name>",
+	Short: "Pause a sandbox",
+	Long: `Freeze all processes of a sandbox with the cgroup freezer.
+
+A paused sandbox stops using CPU but keeps its memory, process state, agent slot
+and ports. Exec, attach and cp are refused until it is resumed, logs stay readable.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setPaused(args[0], true)
+	},
+}
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:     "resume <sandbox-name>",
+	Aliases: []string{"unpause"},
+	Short:   "Resume a paused sandbox",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setPaused(args[0], false)
+	},
+}
+
+// setPaused updates the paused field of a sandbox, the controller then freezes or thaws it.
+func setPaused(sandboxName string, paused bool) {
+	namespace := viper.GetString("namespace")
+	klog.V(4).InfoS("CLI pause command started", "sandboxName", sandboxName, "namespace", namespace, "paused", paused)
+
+	client, conn := getClient()
+	if conn != nil {
+		defer conn.Close()
+	}
+
+	req := &fastpathv1.UpdateRequest{
+		SandboxName: sandboxName,
+		Namespace:   namespace,
+		Update: &fastpathv1.UpdateRequest_Paused{
+			Paused: paused,
+		},
+	}
+
+	resp, err := client.UpdateSandbox(context.Background(), req)
+	if err != nil {
+		klog.ErrorS(err, "UpdateSandbox request failed for pause", "sandboxName", sandboxName)
+		log.Fatalf("Error: %s", formatRPCError(err))
+	}
+
+	if !resp.Success {
+		klog.ErrorS(nil, "UpdateSandbox request returned failure for pause", "sandboxName", sandboxName, "message", resp.Message)
+		log.Fatalf("Error: %s", resp.Message)
+	}
+
+	klog.V(4).InfoS("Sandbox pause updated successfully", "sandboxName", sandboxName, "paused", paused)
+	if paused {
+		fmt.Printf("✓ Sandbox %s pause requested\n", sandboxName)
+		fmt.Printf("  The phase becomes Paused once the agent has frozen it\n")
+	} else {
+		fmt.Printf("✓ Sandbox %s resume requested\n", sandboxName)
+		fmt.Printf("  The phase becomes Running once the agent has thawed it\n")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
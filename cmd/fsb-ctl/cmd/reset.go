@@ -48,7 +48,7 @@ preserving the sandbox configuration.`,
 		resp, err := client.UpdateSandbox(context.Background(), req)
 		if err != nil {
 			klog.ErrorS(err, "UpdateSandbox request failed for reset", "sandboxName", sandboxName)
-			log.Fatalf("Error: %v", err)
+			log.Fatalf("Error: %s", formatRPCError(err))
 		}
 
 		if !resp.Success {
@@ -10,7 +10,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"k8s.io/klog/v2"
 )
 
@@ -42,9 +41,15 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./.fsb/config.json)")
 	rootCmd.PersistentFlags().StringVar(&endpoint, "endpoint", "localhost:9090", "Controller gRPC endpoint")
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
-
-	viper.BindPFlag("endpoint", rootCmd.PersistentFlags().Lookup("endpoint"))
-	viper.BindPFlag("namespace", rootCmd.PersistentFlags().Lookup("namespace"))
+	rootCmd.PersistentFlags().Bool("tls", false, "Connect to the controller over TLS")
+	rootCmd.PersistentFlags().String("ca-file", "", "CA certificate to verify the controller with, implies --tls")
+	rootCmd.PersistentFlags().Bool("insecure-skip-tls-verify", false, "Do not verify the controller certificate, implies --tls")
+	rootCmd.PersistentFlags().String("token", "", "Bearer token, such as a ServiceAccount token, to authenticate with")
+	rootCmd.PersistentFlags().String("token-file", "", "File holding the bearer token")
+
+	for _, name := range []string{"endpoint", "namespace", "tls", "ca-file", "insecure-skip-tls-verify", "token", "token-file"} {
+		viper.BindPFlag(name, rootCmd.PersistentFlags().Lookup(name))
+	}
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -74,7 +79,11 @@ func defaultClientFactory() (fastpathv1.FastPathServiceClient, *grpc.ClientConn,
 	ep := viper.GetString("endpoint")
 	klog.V(4).InfoS("Creating gRPC client connection", "endpoint", ep)
 
-	conn, err := grpc.Dial(ep, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	opts, err := dialOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := grpc.Dial(ep, opts...)
 	if err != nil {
 		klog.ErrorS(err, "Failed to connect to gRPC endpoint", "endpoint", ep)
 		return nil, nil, fmt.Errorf("failed to connect to %s: %v", ep, err)
@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"time"
 
 	fastpathv1 "fast-sandbox/api/proto/v1"
@@ -18,14 +20,78 @@ import (
 
 // SandboxConfig for yaml
 type SandboxConfig struct {
-	Image           string            `yaml:"image"`
-	PoolRef         string            `yaml:"pool_ref"`
-	ConsistencyMode string            `yaml:"consistency_mode"` // "fast" or "strong"
-	Command         []string          `yaml:"command,omitempty"`
-	Args            []string          `yaml:"args,omitempty"`
-	ExposedPorts    []int32           `yaml:"exposed_ports,omitempty"`
-	Envs            map[string]string `yaml:"envs,omitempty"`
-	WorkingDir      string            `yaml:"working_dir,omitempty"`
+	Image            string            `yaml:"image"`
+	PoolRef          string            `yaml:"pool_ref"`
+	ConsistencyMode  string            `yaml:"consistency_mode"` // "fast" or "strong"
+	Command          []string          `yaml:"command,omitempty"`
+	Args             []string          `yaml:"args,omitempty"`
+	ExposedPorts     []int32           `yaml:"exposed_ports,omitempty"`
+	Envs             map[string]string `yaml:"envs,omitempty"`
+	WorkingDir       string            `yaml:"working_dir,omitempty"`
+	TTY              bool              `yaml:"tty,omitempty"`
+	Stdin            bool              `yaml:"stdin,omitempty"`
+	Resources        ResourceConfig    `yaml:"resources,omitempty"`
+	RestartPolicy    string            `yaml:"restart_policy,omitempty"` // "Never", "OnFailure" or "Always"
+	IdleTimeout      time.Duration     `yaml:"idle_timeout,omitempty"`
+	IdlePolicy       string            `yaml:"idle_policy,omitempty"`        // "Pause", "Expire" or "Delete"
+	TTLAfterFinished *time.Duration    `yaml:"ttl_after_finished,omitempty"` // nil keeps the finished sandbox
+	ReadinessProbe   *ProbeConfig      `yaml:"readiness_probe,omitempty"`
+}
+
+// ProbeConfig is a readiness probe, exactly one of Exec, HTTPGet and TCPPort is set
+type ProbeConfig struct {
+	Exec             []string       `yaml:"exec,omitempty"`
+	HTTPGet          *HTTPGetConfig `yaml:"http_get,omitempty"`
+	TCPPort          int32          `yaml:"tcp_port,omitempty"`
+	InitialDelay     time.Duration  `yaml:"initial_delay,omitempty"`
+	Period           time.Duration  `yaml:"period,omitempty"`
+	Timeout          time.Duration  `yaml:"timeout,omitempty"`
+	SuccessThreshold int32          `yaml:"success_threshold,omitempty"`
+	FailureThreshold int32          `yaml:"failure_threshold,omitempty"`
+}
+
+// HTTPGetConfig is the GET request of an HTTP readiness probe
+type HTTPGetConfig struct {
+	Port    int32             `yaml:"port"`
+	Path    string            `yaml:"path,omitempty"`
+	Scheme  string            `yaml:"scheme,omitempty"` // "HTTP" or "HTTPS"
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// toProto converts the probe for the CreateRequest, the controller validates it.
+func (p *ProbeConfig) toProto() *fastpathv1.ReadinessProbe {
+	if p == nil {
+		return nil
+	}
+	out := &fastpathv1.ReadinessProbe{
+		Exec:                p.Exec,
+		TcpPort:             p.TCPPort,
+		InitialDelaySeconds: int32(p.InitialDelay.Seconds()),
+		PeriodSeconds:       int32(p.Period.Seconds()),
+		TimeoutSeconds:      int32(p.Timeout.Seconds()),
+		SuccessThreshold:    p.SuccessThreshold,
+		FailureThreshold:    p.FailureThreshold,
+	}
+	if p.HTTPGet != nil {
+		out.HttpGet = &fastpathv1.HTTPGetProbe{Port: p.HTTPGet.Port, Path: p.HTTPGet.Path, Scheme: p.HTTPGet.Scheme, Headers: p.HTTPGet.Headers}
+	}
+	return out
+}
+
+// parseHTTPProbe parses the --ready-http value PORT[/PATH].
+func parseHTTPProbe(value string) (*HTTPGetConfig, error) {
+	port, path, _ := strings.Cut(value, "/")
+	n, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --ready-http %q, expected PORT[/PATH]", value)
+	}
+	return &HTTPGetConfig{Port: int32(n), Path: "/" + path}, nil
+}
+
+// ResourceConfig holds CPU/memory quantities keyed by "cpu" and "memory"
+type ResourceConfig struct {
+	Requests map[string]string `yaml:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty"`
 }
 
 var (
@@ -34,6 +100,21 @@ var (
 	mode       string
 	ports      []int32
 	image      string
+	runTTY     bool
+	runStdin   bool
+	requests   map[string]string
+	limits     map[string]string
+	restart    string
+	idemKey    string
+	waitFor    time.Duration
+	priority   int32
+	idleFor    time.Duration
+	idlePolicy string
+	ttlAfter   time.Duration
+	readyHTTP  string
+	readyTCP   int32
+	readyExec  string
+	waitReady  time.Duration
 )
 
 // runCmd represents the run command
@@ -98,6 +179,43 @@ Priority: Flags > Config File > Interactive Input
 		if len(args) > 1 {
 			config.Command = args[1:]
 		}
+		if runTTY {
+			config.TTY = true
+		}
+		if runStdin {
+			config.Stdin = true
+		}
+		if len(requests) > 0 {
+			config.Resources.Requests = requests
+		}
+		if len(limits) > 0 {
+			config.Resources.Limits = limits
+		}
+		if restart != "" {
+			config.RestartPolicy = restart
+		}
+		if idleFor > 0 {
+			config.IdleTimeout = idleFor
+		}
+		if idlePolicy != "" {
+			config.IdlePolicy = idlePolicy
+		}
+		if cmd.Flags().Changed("ttl-after-finished") {
+			config.TTLAfterFinished = &ttlAfter
+		}
+		// 命令行的探测替换配置文件中的探测
+		switch {
+		case readyHTTP != "":
+			httpGet, err := parseHTTPProbe(readyHTTP)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			config.ReadinessProbe = &ProbeConfig{HTTPGet: httpGet}
+		case readyTCP != 0:
+			config.ReadinessProbe = &ProbeConfig{TCPPort: readyTCP}
+		case readyExec != "":
+			config.ReadinessProbe = &ProbeConfig{Exec: strings.Fields(readyExec)}
+		}
 		if config.Image == "" {
 			klog.ErrorS(nil, "Image is required but not provided", "name", name)
 			log.Fatal("Error: image is required (via flag, file, or interactive mode)")
@@ -125,13 +243,32 @@ Priority: Flags > Config File > Interactive Input
 			Args:            config.Args,
 			Envs:            config.Envs,
 			WorkingDir:      config.WorkingDir,
+			Tty:             config.TTY,
+			Stdin:           config.Stdin,
+			Resources: &fastpathv1.ResourceRequirements{
+				Requests: config.Resources.Requests,
+				Limits:   config.Resources.Limits,
+			},
+			RestartPolicy:      config.RestartPolicy,
+			IdempotencyKey:     idemKey,
+			WaitTimeoutSeconds: int32(waitFor.Seconds()),
+			Priority:           priority,
+			IdleTimeoutSeconds: int32(config.IdleTimeout.Seconds()),
+			IdlePolicy:         config.IdlePolicy,
+			ReadinessProbe:     config.ReadinessProbe.toProto(),
+			// 向上取整到秒，避免 1s 以下的等待被忽略
+			ReadyTimeoutSeconds: int32((waitReady + time.Second - 1) / time.Second),
+		}
+		if config.TTLAfterFinished != nil {
+			ttl := int32(config.TTLAfterFinished.Seconds())
+			req.TtlSecondsAfterFinished = &ttl
 		}
 		klog.V(4).InfoS("Sending CreateSandbox request", "name", name, "image", config.Image, "pool", config.PoolRef, "namespace", req.Namespace)
 
 		resp, err := client.CreateSandbox(context.Background(), req)
 		if err != nil {
 			klog.ErrorS(err, "CreateSandbox request failed", "name", name)
-			log.Fatalf("Error: %v", err)
+			log.Fatalf("Error: %s", formatRPCError(err))
 		}
 
 		klog.V(4).InfoS("Sandbox created successfully", "name", name, "sandboxId", resp.SandboxId, "sandboxName", resp.SandboxName, "agent", resp.AgentPod, "duration", time.Since(start))
@@ -151,6 +288,21 @@ func init() {
 	runCmd.Flags().StringVar(&pool, "pool", "default-pool", "Target SandboxPool")
 	runCmd.Flags().StringVar(&mode, "mode", "fast", "Consistency mode (fast/strong)")
 	runCmd.Flags().Int32SliceVar(&ports, "ports", []int32{}, "Exposed ports")
+	runCmd.Flags().BoolVarP(&runTTY, "tty", "t", false, "Allocate a pseudo-terminal for the main process")
+	runCmd.Flags().BoolVarP(&runStdin, "stdin", "i", false, "Keep stdin of the main process open for attach")
+	runCmd.Flags().StringToStringVar(&requests, "requests", nil, "Resource requests, e.g. cpu=500m,memory=256Mi")
+	runCmd.Flags().StringToStringVar(&limits, "limits", nil, "Resource limits enforced as cgroup limits, e.g. cpu=1,memory=512Mi")
+	runCmd.Flags().StringVar(&restart, "restart", "", "Restart policy of the main process (Never/OnFailure/Always)")
+	runCmd.Flags().StringVar(&idemKey, "idempotency-key", "", "Key that makes retries of this create return the original sandbox")
+	runCmd.Flags().DurationVar(&waitFor, "wait", 0, "Wait up to this long for pool capacity instead of failing when the pool is full, e.g. 30s")
+	runCmd.Flags().Int32Var(&priority, "priority", 0, "Priority in the pool, a full pool evicts sandboxes of lower priority for this one")
+	runCmd.Flags().DurationVar(&idleFor, "idle-timeout", 0, "Apply the idle policy after no exec, attach, logs, port traffic or CPU use for this long, e.g. 30m")
+	runCmd.Flags().StringVar(&idlePolicy, "idle-policy", "", "What to do with an idle sandbox (Pause/Expire/Delete), defaults to Pause")
+	runCmd.Flags().DurationVar(&ttlAfter, "ttl-after-finished", 0, "Delete the sandbox this long after it succeeded, failed, expired or was preempted, 0 deletes it right away")
+	runCmd.Flags().StringVar(&readyHTTP, "ready-http", "", "Readiness probe sending GET requests to PORT[/PATH], e.g. 8080/healthz")
+	runCmd.Flags().Int32Var(&readyTCP, "ready-tcp", 0, "Readiness probe connecting to this TCP port")
+	runCmd.Flags().StringVar(&readyExec, "ready-exec", "", "Readiness probe running this command in the sandbox, e.g. \"cat /tmp/ready\"")
+	runCmd.Flags().DurationVar(&waitReady, "wait-ready", 0, "Wait up to this long for the sandbox to be ready before printing its endpoints, e.g. 30s")
 }
 
 func runInteractive(name string, config *SandboxConfig) error {
@@ -243,6 +395,37 @@ args: []
 # Optional: Working directory
 # working_dir: /app
 
+# Optional: Interactive main process (use with 'fsb-ctl attach')
+# tty: true
+# stdin: true
+
+# Optional: CPU/memory, limits are enforced, requests default to limits
+# resources:
+#   requests:
+#     cpu: 500m
+#     memory: 256Mi
+#   limits:
+#     cpu: "1"
+#     memory: 512Mi
+
+# Optional: Restart the main process after it exits (Never/OnFailure/Always)
+# restart_policy: OnFailure
+
+# Optional: Pause, expire or delete the sandbox after it has been idle this long
+# idle_timeout: 30m
+# idle_policy: Pause
+
+# Optional: Delete the sandbox this long after it succeeded, failed, expired or was preempted
+# ttl_after_finished: 1h
+
+# Optional: Only report the sandbox ready once this succeeds (use with --wait-ready)
+# readiness_probe:
+#   http_get:
+#     port: 8080
+#     path: /healthz
+#   period: 1s
+#   failure_threshold: 3
+
 # Optional: Expose ports
 # exposed_ports:
 #   - 8080
@@ -23,6 +23,10 @@ func (m *MockClient) CreateSandbox(ctx context.Context, in *fastpathv1.CreateReq
 	return &fastpathv1.CreateResponse{}, nil
 }
 
+func (m *MockClient) CreateSandboxes(ctx context.Context, in *fastpathv1.CreateSandboxesRequest, opts ...grpc.CallOption) (*fastpathv1.CreateSandboxesResponse, error) {
+	return &fastpathv1.CreateSandboxesResponse{}, nil
+}
+
 func (m *MockClient) DeleteSandbox(ctx context.Context, in *fastpathv1.DeleteRequest, opts ...grpc.CallOption) (*fastpathv1.DeleteResponse, error) {
 	return &fastpathv1.DeleteResponse{Success: true}, nil
 }
@@ -36,6 +40,38 @@ func (m *MockClient) UpdateSandbox(ctx context.Context, in *fastpathv1.UpdateReq
 	return &fastpathv1.UpdateResponse{}, nil
 }
 
+func (m *MockClient) ExecSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[fastpathv1.ExecRequest, fastpathv1.ExecResponse], error) {
+	return nil, nil
+}
+
+func (m *MockClient) AttachSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[fastpathv1.AttachRequest, fastpathv1.ExecResponse], error) {
+	return nil, nil
+}
+
+func (m *MockClient) CopyToSandbox(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[fastpathv1.CopyToRequest, fastpathv1.CopyToResponse], error) {
+	return nil, nil
+}
+
+func (m *MockClient) CopyFromSandbox(ctx context.Context, in *fastpathv1.CopyFromRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[fastpathv1.CopyFromResponse], error) {
+	return nil, nil
+}
+
+func (m *MockClient) StreamLogs(ctx context.Context, in *fastpathv1.LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[fastpathv1.LogsResponse], error) {
+	return nil, nil
+}
+
+func (m *MockClient) WatchSandboxes(ctx context.Context, in *fastpathv1.WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[fastpathv1.WatchEvent], error) {
+	return nil, nil
+}
+
+func (m *MockClient) CheckpointSandbox(ctx context.Context, in *fastpathv1.CheckpointRequest, opts ...grpc.CallOption) (*fastpathv1.CheckpointInfo, error) {
+	return nil, nil
+}
+
+func (m *MockClient) RestoreSandbox(ctx context.Context, in *fastpathv1.RestoreRequest, opts ...grpc.CallOption) (*fastpathv1.CreateResponse, error) {
+	return nil, nil
+}
+
 func TestRunCommand(t *testing.T) {
 	mockClient := &MockClient{}
 	clientFactory = func() (fastpathv1.FastPathServiceClient, *grpc.ClientConn, error) {
@@ -113,3 +149,30 @@ consistency_mode: fast
 		t.Errorf("expected pool 'override-pool' (from flag), got '%s'", capturedReq.PoolRef)
 	}
 }
+
+func TestRunCommandReadiness(t *testing.T) {
+	mockClient := &MockClient{}
+	clientFactory = func() (fastpathv1.FastPathServiceClient, *grpc.ClientConn, error) {
+		return mockClient, nil, nil
+	}
+	var capturedReq *fastpathv1.CreateRequest
+	mockClient.CreateFunc = func(ctx context.Context, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
+		capturedReq = req
+		return &fastpathv1.CreateResponse{}, nil
+	}
+	defer func() { readyHTTP, waitReady = "", 0 }()
+
+	configFile = ""
+	rootCmd.SetArgs([]string{"run", "web", "--image=nginx", "--ready-http=8080/healthz", "--wait-ready=1500ms"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	probe := capturedReq.ReadinessProbe
+	if probe == nil || probe.HttpGet == nil || probe.HttpGet.Port != 8080 || probe.HttpGet.Path != "/healthz" {
+		t.Errorf("expected HTTP probe of 8080/healthz, got %v", probe)
+	}
+	if capturedReq.ReadyTimeoutSeconds != 2 {
+		t.Errorf("expected ready timeout rounded up to 2s, got %d", capturedReq.ReadyTimeoutSeconds)
+	}
+}
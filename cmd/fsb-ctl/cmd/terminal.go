@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+// streamSession adapts the exec and attach streams, which share ExecResponse but
+// use different request messages.
+type streamSession struct {
+	// mu serialises sends, a gRPC stream does not allow concurrent Send calls.
+	mu         sync.Mutex
+	sendStdin  func(data []byte) error
+	sendResize func(size *fastpathv1.TerminalSize) error
+	closeSend  func() error
+	recv       func() (*fastpathv1.ExecResponse, error)
+}
+
+// run pumps local stdin and terminal resizes to the stream and copies output to
+// stdout/stderr until the exit message arrives.
+func (s *streamSession) run(stdin, tty bool) (*fastpathv1.ExecExit, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if tty {
+		restore := makeRawTerminal()
+		defer restore()
+		go watchTerminalSize(ctx, func(size *fastpathv1.TerminalSize) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.sendResize(size)
+		})
+	}
+
+	if stdin {
+		go func() {
+			buf := make([]byte, 32*1024)
+			for {
+				n, err := os.Stdin.Read(buf)
+				if n > 0 {
+					data := make([]byte, n)
+					copy(data, buf[:n])
+					s.mu.Lock()
+					sendErr := s.sendStdin(data)
+					s.mu.Unlock()
+					if sendErr != nil {
+						return
+					}
+				}
+				if err != nil {
+					s.mu.Lock()
+					s.closeSend()
+					s.mu.Unlock()
+					return
+				}
+			}
+		}()
+	} else if !tty {
+		s.closeSend()
+	}
+
+	for {
+		resp, err := s.recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("stream closed without exit status")
+			}
+			return nil, err
+		}
+		switch p := resp.Payload.(type) {
+		case *fastpathv1.ExecResponse_Stdout:
+			os.Stdout.Write(p.Stdout)
+		case *fastpathv1.ExecResponse_Stderr:
+			os.Stderr.Write(p.Stderr)
+		case *fastpathv1.ExecResponse_Exit:
+			return p.Exit, nil
+		}
+	}
+}
+
+// makeRawTerminal puts the local terminal into raw mode when stdin is a terminal and
+// returns a function that restores it.
+func makeRawTerminal() func() {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return func() {}
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		klog.V(4).InfoS("Failed to set terminal raw mode", "err", err)
+		return func() {}
+	}
+	return func() { term.Restore(fd, state) }
+}
+
+// watchTerminalSize sends the current window size and every SIGWINCH change until ctx is done.
+func watchTerminalSize(ctx context.Context, send func(*fastpathv1.TerminalSize) error) {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	for {
+		if width, height, err := term.GetSize(fd); err == nil {
+			if err := send(&fastpathv1.TerminalSize{Width: uint32(width), Height: uint32(height)}); err != nil {
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+		}
+	}
+}
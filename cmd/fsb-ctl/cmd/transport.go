@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// bearerToken sends the token in the authorization metadata of every call. It refuses
+// plaintext connections so that the token is never sent in the clear.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// dialOptions builds the transport credentials and the bearer token from the --tls,
+// --ca-file, --insecure-skip-tls-verify, --token and --token-file settings.
+func dialOptions() ([]grpc.DialOption, error) {
+	caFile := viper.GetString("ca-file")
+	skipVerify := viper.GetBool("insecure-skip-tls-verify")
+	useTLS := viper.GetBool("tls") || caFile != "" || skipVerify
+
+	token := viper.GetString("token")
+	if tokenFile := viper.GetString("token-file"); token == "" && tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" && !useTLS {
+		return nil, fmt.Errorf("a bearer token requires TLS, set --tls or --ca-file")
+	}
+
+	if !useTLS {
+		return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: skipVerify}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+		}
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(cfg))}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	return opts, nil
+}
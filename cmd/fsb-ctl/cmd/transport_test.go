@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialOptions(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	opts, err := dialOptions()
+	require.NoError(t, err)
+	assert.Len(t, opts, 1, "Plaintext without a token by default")
+
+	viper.Set("token", "secret")
+	_, err = dialOptions()
+	assert.ErrorContains(t, err, "requires TLS", "Tokens are never sent in plaintext")
+
+	viper.Set("tls", true)
+	opts, err = dialOptions()
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	viper.Set("ca-file", filepath.Join(t.TempDir(), "missing.crt"))
+	_, err = dialOptions()
+	assert.ErrorContains(t, err, "failed to read CA file")
+}
+
+func TestBearerToken_FromFile(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("secret\n"), 0o600))
+	viper.Set("token-file", path)
+	viper.Set("insecure-skip-tls-verify", true)
+
+	opts, err := dialOptions()
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	md, err := bearerToken("secret").GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", md["authorization"])
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	fastpathv1 "fast-sandbox/api/proto/v1"
 
@@ -18,6 +19,7 @@ var (
 	updateExpireTime      string
 	updateFailurePolicy   string
 	updateRecoveryTimeout int32
+	updateIdleTimeout     time.Duration
 	updateLabels          []string
 )
 
@@ -41,7 +43,10 @@ Examples:
   fsb-ctl update my-sandbox --labels env=prod,tier=backend
 
   # Update recovery timeout
-  fsb-ctl update my-sandbox --recovery-timeout 120`,
+  fsb-ctl update my-sandbox --recovery-timeout 120
+
+  # Pause the sandbox after one hour without activity, 0 turns the idle timeout off
+  fsb-ctl update my-sandbox --idle-timeout 1h`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		sandboxName := args[0]
@@ -90,6 +95,13 @@ Examples:
 			}
 		}
 
+		if cmd.Flags().Changed("idle-timeout") {
+			klog.V(4).InfoS("Updating idle-timeout", "sandboxName", sandboxName, "idleTimeout", updateIdleTimeout)
+			req.Update = &fastpathv1.UpdateRequest_IdleTimeoutSeconds{
+				IdleTimeoutSeconds: int32(updateIdleTimeout.Seconds()),
+			}
+		}
+
 		if len(updateLabels) > 0 {
 			klog.V(4).InfoS("Updating labels", "sandboxName", sandboxName, "labels", updateLabels)
 			for _, label := range updateLabels {
@@ -104,14 +116,14 @@ Examples:
 
 		if req.Update == nil && len(req.Labels) == 0 {
 			klog.ErrorS(nil, "No update field specified")
-			log.Fatal("Error: at least one update field must be specified (--expire-time, --failure-policy, --recovery-timeout, or --labels)")
+			log.Fatal("Error: at least one update field must be specified (--expire-time, --failure-policy, --recovery-timeout, --idle-timeout, or --labels)")
 		}
 
 		klog.V(4).InfoS("Sending UpdateSandbox request", "sandboxName", sandboxName)
 		resp, err := client.UpdateSandbox(context.Background(), req)
 		if err != nil {
 			klog.ErrorS(err, "UpdateSandbox request failed", "sandboxName", sandboxName)
-			log.Fatalf("Error: %v", err)
+			log.Fatalf("Error: %s", formatRPCError(err))
 		}
 
 		if !resp.Success {
@@ -134,6 +146,7 @@ func init() {
 	updateCmd.Flags().StringVar(&updateExpireTime, "expire-time", "", "Expiration time (Unix timestamp or '0' to remove)")
 	updateCmd.Flags().StringVar(&updateFailurePolicy, "failure-policy", "", "Failure policy (Manual|AutoRecreate)")
 	updateCmd.Flags().Int32Var(&updateRecoveryTimeout, "recovery-timeout", 0, "Recovery timeout in seconds")
+	updateCmd.Flags().DurationVar(&updateIdleTimeout, "idle-timeout", 0, "Idle timeout, e.g. 30m (0 to turn it off)")
 	updateCmd.Flags().StringSliceVar(&updateLabels, "labels", []string{}, "Labels to set (key=value format)")
 }
 
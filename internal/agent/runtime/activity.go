@@ -0,0 +1,141 @@
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// activitySampleInterval 采样 CPU 与暴露端口流量的间隔
+	activitySampleInterval = 10 * time.Second
+	// activityReportInterval 上报的最近活动时间至少前进该时长才推送，避免活跃 sandbox 刷屏
+	activityReportInterval = 30 * time.Second
+	// idleCPUFraction 采样间隔内 CPU 使用低于单核的该比例视为空闲，容忍后台心跳等少量占用
+	idleCPUFraction = 0.05
+)
+
+// WatchActivity samples the CPU usage and the traffic on the exposed ports of running
+// sandboxes until ctx is done, and reports their last activity for the idle timeout.
+func (m *SandboxManager) WatchActivity(ctx context.Context) {
+	ticker := time.NewTicker(activitySampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sampleActivity(ctx)
+		}
+	}
+}
+
+// activitySample is what one sampling round observed for a sandbox.
+type activitySample struct {
+	cpu     time.Duration
+	cpuErr  error
+	traffic portTraffic
+}
+
+// sampleActivity takes one sample of every running sandbox and records activity when its
+// CPU usage or exposed port traffic changed since the previous sample.
+func (m *SandboxManager) sampleActivity(ctx context.Context) {
+	// 运行时与内核查询放到锁外
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.sandboxes))
+	needTraffic := false
+	for id, meta := range m.sandboxes {
+		if meta.Phase == "running" {
+			ids = append(ids, id)
+			needTraffic = needTraffic || len(meta.ExposedPorts) > 0
+		}
+	}
+	m.mu.RUnlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	var traffic map[uint16]portTraffic
+	if needTraffic {
+		var err error
+		if traffic, err = m.readTraffic(); err != nil {
+			klog.V(1).InfoS("Failed to read exposed port traffic, using CPU and sessions only", "err", err)
+		}
+	}
+	samples := make(map[string]activitySample, len(ids))
+	for _, id := range ids {
+		cpu, err := m.runtime.CPUUsage(ctx, id)
+		samples[id] = activitySample{cpu: cpu, cpuErr: err}
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, sample := range samples {
+		meta, ok := m.sandboxes[id]
+		if !ok || meta.Phase != "running" {
+			continue
+		}
+		for _, port := range meta.ExposedPorts {
+			t := traffic[uint16(port)]
+			sample.traffic.Sockets += t.Sockets
+			sample.traffic.Bytes += t.Bytes
+		}
+		if meta.observeLocked(now, sample) {
+			m.touchLocked(id, meta, now)
+		}
+	}
+}
+
+// observeLocked compares a sample with the previous one and stores it, reporting whether
+// the sandbox was active in between. The first sample only sets the baseline.
+func (meta *SandboxMetadata) observeLocked(now time.Time, sample activitySample) bool {
+	active := meta.sessions > 0
+	if !meta.sampledAt.IsZero() {
+		elapsed := now.Sub(meta.sampledAt)
+		if sample.cpuErr == nil && sample.cpu-meta.cpuUsage > time.Duration(float64(elapsed)*idleCPUFraction) {
+			active = true
+		}
+		if sample.traffic != meta.traffic {
+			active = true
+		}
+	}
+	if sample.cpuErr == nil {
+		meta.cpuUsage = sample.cpu
+	}
+	meta.traffic = sample.traffic
+	meta.sampledAt = now
+	return active
+}
+
+// touchLocked records activity of a sandbox at now and reports it once it moved on by
+// activityReportInterval. Must be called with m.mu held.
+func (m *SandboxManager) touchLocked(sandboxID string, meta *SandboxMetadata, now time.Time) {
+	meta.lastActivity = now
+	if meta.reportedActivity.IsZero() || now.Sub(meta.reportedActivity) >= activityReportInterval {
+		meta.reportedActivity = now
+		m.publishLocked(sandboxID, meta)
+	}
+}
+
+// beginSession counts an exec, attach or log session as activity until the returned
+// function is called.
+func (m *SandboxManager) beginSession(sandboxID string) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	meta, ok := m.sandboxes[sandboxID]
+	if !ok {
+		return func() {}
+	}
+	meta.sessions++
+	m.touchLocked(sandboxID, meta, time.Now())
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		meta.sessions--
+		if m.sandboxes[sandboxID] == meta {
+			m.touchLocked(sandboxID, meta, time.Now())
+		}
+	}
+}
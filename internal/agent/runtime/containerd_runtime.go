@@ -8,16 +8,27 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"fast-sandbox/internal/agent/infra"
 	"fast-sandbox/internal/api"
 
+	cgroup1stats "github.com/containerd/cgroups/v3/cgroup1/stats"
+	cgroup2stats "github.com/containerd/cgroups/v3/cgroup2/stats"
+	apievents "github.com/containerd/containerd/api/events"
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	dockerconfig "github.com/containerd/containerd/v2/core/remotes/docker/config"
+	cruntime "github.com/containerd/containerd/v2/core/runtime"
 	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/typeurl/v2"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"k8s.io/klog/v2"
 )
@@ -33,11 +44,23 @@ type ContainerdRuntime struct {
 	infraMgr           *infra.Manager
 	allowedPluginPaths []string
 	runtimeHandler     string
+
+	// ioMu 保护 sandboxIOs：sandboxID -> 主进程 IO（日志 + attach）
+	ioMu       sync.Mutex
+	sandboxIOs map[string]*sandboxIO
+
+	// oomMu 保护 oomKilled：sandboxID -> 主进程被 OOM kill，由 /tasks/oom 事件写入
+	oomMu      sync.Mutex
+	oomKilled  map[string]bool
+	stopEvents context.CancelFunc
 }
 
 const (
 	defaultOperationTimeout = 30 * time.Second
 	waitStopTimeout         = 10 * time.Second
+	eventsRetryInterval     = time.Second
+	// checkpointTimeout 转储或恢复全部内存，并可能推送、拉取镜像，远慢于普通操作
+	checkpointTimeout = 5 * time.Minute
 )
 
 func newContainerdRuntime(runtimeHandler string) Runtime {
@@ -92,6 +115,10 @@ func (r *ContainerdRuntime) Initialize(ctx context.Context, socketPath string) e
 		klog.ErrorS(err, "Failed to discover network namespace")
 	}
 
+	eventsCtx, stopEvents := context.WithCancel(context.Background())
+	r.stopEvents = stopEvents
+	go r.watchOOMEvents(eventsCtx)
+
 	return nil
 }
 
@@ -161,6 +188,12 @@ func (r *ContainerdRuntime) CreateSandbox(ctx context.Context, config *api.Sandb
 	defer cancel()
 	ctx = namespaces.WithNamespace(ctx, "k8s.io")
 
+	resourceOpts, err := resourceSpecOpts(config)
+	if err != nil {
+		klog.ErrorS(err, "Invalid sandbox resources", "sandbox", config.SandboxID)
+		return nil, err
+	}
+
 	// 1. Image preparation
 	pullStart := time.Now()
 	image, err := r.prepareImage(ctx, config.Image)
@@ -171,7 +204,7 @@ func (r *ContainerdRuntime) CreateSandbox(ctx context.Context, config *api.Sandb
 	pullDuration := time.Since(pullStart)
 
 	containerID := config.SandboxID
-	specOpts := r.prepareSpecOpts(config, image)
+	specOpts := append(r.prepareSpecOpts(config, image), resourceOpts...)
 	labels := r.prepareLabels(config)
 
 	// 2. Create container
@@ -185,6 +218,7 @@ func (r *ContainerdRuntime) CreateSandbox(ctx context.Context, config *api.Sandb
 		containerd.WithRuntime(r.runtimeHandler, nil), // 使用配置的 Runtime
 		containerd.WithNewSpec(specOpts...),
 		containerd.WithContainerLabels(labels),
+		containerd.WithContainerExtension(sandboxSpecExtension, config),
 	)
 	if err != nil {
 		klog.ErrorS(err, "Failed to create container object", "sandbox", containerID)
@@ -192,24 +226,54 @@ func (r *ContainerdRuntime) CreateSandbox(ctx context.Context, config *api.Sandb
 	}
 	createDuration := time.Since(createStart)
 
-	logDir := "/var/log/fast-sandbox"
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create log dir: %w", err)
+	// 3. Start container
+	startStart := time.Now()
+	task, err := r.startSandboxTask(ctx, container, config)
+	if err != nil {
+		return nil, err
+	}
+	startDuration := time.Since(startStart)
+
+	totalDuration := time.Since(totalStart)
+
+	klog.InfoS("Runtime CreateSandbox timing",
+		"sandboxID", config.SandboxID,
+		"total_ms", totalDuration.Milliseconds(),
+		"pull_ms", pullDuration.Milliseconds(),
+		"create_ms", createDuration.Milliseconds(),
+		"start_ms", startDuration.Milliseconds())
+
+	metadata := &SandboxMetadata{
+		SandboxSpec: *config,
+		ContainerID: containerID,
+		Phase:       "running",
+		CreatedAt:   time.Now().Unix(),
+		PID:         int(task.Pid()),
 	}
-	logPath := filepath.Join(logDir, fmt.Sprintf("%s.log", containerID))
+	klog.InfoS("Sandbox created successfully", "sandbox", containerID, "pid", task.Pid())
+	return metadata, nil
+}
 
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+// startSandboxTask creates and starts the main process task of a new container with the
+// sandbox IO, deleting the container when the task cannot be started.
+func (r *ContainerdRuntime) startSandboxTask(ctx context.Context, container containerd.Container, config *api.SandboxSpec, taskOpts ...containerd.NewTaskOpts) (containerd.Task, error) {
+	containerID := config.SandboxID
+	logPath := sandboxLogPath(containerID)
+	logFile, err := openSandboxLog(containerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, err
 	}
 
-	// 3. Start container
-	startStart := time.Now()
-	klog.InfoS("Creating containerd task", "sandbox", containerID)
-	task, err := container.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, logFile, logFile)))
+	klog.InfoS("Creating containerd task", "sandbox", containerID, "tty", config.TTY, "stdin", config.Stdin)
+	sbIO := newSandboxIO(logFile, config.Stdin)
+	ioOpts := []cio.Opt{cio.WithStreams(sbIO.Stdin(), sbIO.Stdout(), sbIO.Stderr())}
+	if config.TTY {
+		ioOpts = append(ioOpts, cio.WithTerminal)
+	}
+	task, err := container.NewTask(ctx, cio.NewCreator(ioOpts...), taskOpts...)
 	if err != nil {
 		klog.ErrorS(err, "Failed to create containerd task", "sandbox", containerID, "logPath", logPath)
-		logFile.Close()
+		sbIO.Close()
 		_ = container.Delete(ctx, containerd.WithSnapshotCleanup)
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
@@ -219,28 +283,97 @@ func (r *ContainerdRuntime) CreateSandbox(ctx context.Context, config *api.Sandb
 		klog.ErrorS(err, "Failed to start containerd task", "sandbox", containerID)
 		_, _ = task.Delete(ctx, containerd.WithProcessKill)
 		_ = container.Delete(ctx, containerd.WithSnapshotCleanup)
+		sbIO.Close()
 		return nil, fmt.Errorf("failed to start task: %w", err)
 	}
-	startDuration := time.Since(startStart)
+	r.setSandboxIO(containerID, sbIO)
+	return task, nil
+}
 
-	totalDuration := time.Since(totalStart)
+// RestoreSandbox creates the container from the base image like CreateSandbox, applies the
+// filesystem changes saved in the checkpoint and resumes the checkpointed processes.
+func (r *ContainerdRuntime) RestoreSandbox(ctx context.Context, config *api.SandboxSpec) (*SandboxMetadata, error) {
+	totalStart := time.Now()
 
-	klog.InfoS("Runtime CreateSandbox timing",
-		"sandboxID", config.SandboxID,
-		"total_ms", totalDuration.Milliseconds(),
-		"pull_ms", pullDuration.Milliseconds(),
-		"create_ms", createDuration.Milliseconds(),
-		"start_ms", startDuration.Milliseconds())
+	klog.InfoS("Restoring sandbox", "sandbox", config.SandboxID, "checkpoint", config.Checkpoint, "runtime", r.runtimeHandler)
+	ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
 
-	metadata := &SandboxMetadata{
+	resourceOpts, err := resourceSpecOpts(config)
+	if err != nil {
+		klog.ErrorS(err, "Invalid sandbox resources", "sandbox", config.SandboxID)
+		return nil, err
+	}
+
+	// lease 保护拉取的 checkpoint 内容，直到 task 恢复完成
+	ctx, done, err := r.client.WithLease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done(ctx)
+
+	image, err := r.prepareImage(ctx, config.Image)
+	if err != nil {
+		klog.ErrorS(err, "Failed to prepare image", "sandbox", config.SandboxID)
+		return nil, err
+	}
+	checkpoint, err := r.client.GetImage(ctx, config.Checkpoint)
+	if err != nil {
+		// 本地没有时从镜像仓库拉取，镜像记录只用于本次恢复
+		fetched, fetchErr := r.client.Fetch(ctx, config.Checkpoint, registryOpts(ctx, config.CheckpointCredentials)...)
+		if fetchErr != nil {
+			klog.ErrorS(fetchErr, "Failed to fetch checkpoint", "sandbox", config.SandboxID, "checkpoint", config.Checkpoint)
+			return nil, fmt.Errorf("failed to fetch checkpoint %s: %w", config.Checkpoint, fetchErr)
+		}
+		defer r.DeleteCheckpoint(ctx, config.Checkpoint)
+		checkpoint = containerd.NewImage(r.client, fetched)
+	}
+
+	containerID := config.SandboxID
+	specOpts := append(r.prepareSpecOpts(config, image), resourceOpts...)
+	labels := r.prepareLabels(config)
+	newContainerOpts := []containerd.NewContainerOpts{
+		containerd.WithImage(image),
+		containerd.WithNewSnapshot(snapShotName(containerID), image),
+		containerd.WithRuntime(r.runtimeHandler, nil),
+		containerd.WithNewSpec(specOpts...),
+		containerd.WithContainerLabels(labels),
+		containerd.WithContainerExtension(sandboxSpecExtension, config),
+	}
+	// 容器与 CreateSandbox 创建的一致，再叠加 checkpoint 中的可写层
+	withSandboxContainer := func(context.Context, string, *containerd.Client, containerd.Image, *imagespec.Index) containerd.NewContainerOpts {
+		return func(ctx context.Context, client *containerd.Client, c *containers.Container) error {
+			for _, o := range newContainerOpts {
+				if err := o(ctx, client, c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	container, err := r.client.Restore(ctx, containerID, checkpoint, withSandboxContainer, containerd.WithRestoreRW)
+	if err != nil {
+		klog.ErrorS(err, "Failed to restore container object", "sandbox", containerID)
+		// 可写层应用失败时快照已创建而容器未创建
+		_ = r.forceCleanupSnapshot(ctx, snapShotName(containerID))
+		return nil, fmt.Errorf("failed to restore container: %w", err)
+	}
+
+	task, err := r.startSandboxTask(ctx, container, config, containerd.WithTaskCheckpoint(checkpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	klog.InfoS("Sandbox restored successfully", "sandbox", containerID, "pid", task.Pid(),
+		"total_ms", time.Since(totalStart).Milliseconds())
+	return &SandboxMetadata{
 		SandboxSpec: *config,
 		ContainerID: containerID,
 		Phase:       "running",
 		CreatedAt:   time.Now().Unix(),
 		PID:         int(task.Pid()),
-	}
-	klog.InfoS("Sandbox created successfully", "sandbox", containerID, "pid", task.Pid())
-	return metadata, nil
+	}, nil
 }
 
 func (r *ContainerdRuntime) prepareImage(ctx context.Context, imageName string) (containerd.Image, error) {
@@ -302,10 +435,19 @@ func (r *ContainerdRuntime) prepareSpecOpts(config *api.SandboxSpec, image conta
 		specOpts = append(specOpts, oci.WithProcessCwd(config.WorkingDir))
 	}
 
+	if config.TTY {
+		specOpts = append(specOpts, oci.WithTTY)
+	}
+
 	if len(mounts) > 0 {
 		specOpts = append(specOpts, oci.WithMounts(mounts))
 	}
 
+	// 以 Agent 所在 Pod 的 cgroup 作为父级，资源 limits 在该 cgroup 内生效
+	if cgroupsPath := sandboxCgroupsPath(r.cgroupPath, config.SandboxID); cgroupsPath != "" {
+		specOpts = append(specOpts, oci.WithCgroup(cgroupsPath))
+	}
+
 	if r.netnsPath != "" {
 		specOpts = append(specOpts, oci.WithLinuxNamespace(specs.LinuxNamespace{
 			Type: specs.NetworkNamespace,
@@ -349,6 +491,7 @@ func (r *ContainerdRuntime) SetNamespace(ns string) {
 
 func (r *ContainerdRuntime) DeleteSandbox(ctx context.Context, sandboxID string) error {
 	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	defer r.releaseSandboxIO(sandboxID)
 	snapshotName := snapShotName(sandboxID)
 
 	container, err := r.client.LoadContainer(ctx, sandboxID)
@@ -370,6 +513,13 @@ func (r *ContainerdRuntime) DeleteSandbox(ctx context.Context, sandboxID string)
 		return JoinErrors(err, delErr, snapErr)
 	}
 
+	// 冻结的进程收不到信号，先解冻再停止
+	if st, err := task.Status(ctx); err == nil && st.Status == containerd.Paused {
+		if err := task.Resume(ctx); err != nil {
+			klog.InfoS("Failed to resume paused task before delete", "sandbox", sandboxID, "err", err)
+		}
+	}
+
 	// Task exists, try graceful shutdown first
 	if taskKillErr := task.Kill(ctx, syscall.SIGTERM); taskKillErr != nil {
 		exitS, taskDelErr := task.Delete(ctx, containerd.WithProcessKill)
@@ -432,6 +582,484 @@ func (r *ContainerdRuntime) GetSandboxStatus(ctx context.Context, sandboxID stri
 	return string(status.Status), nil
 }
 
+func (r *ContainerdRuntime) WaitSandbox(ctx context.Context, sandboxID string) (*ExitStatus, error) {
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	container, err := r.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+
+	waitCh, err := task.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for task: %w", err)
+	}
+	select {
+	case st := <-waitCh:
+		code, exitedAt, err := st.Result()
+		if err != nil {
+			return nil, err
+		}
+		return &ExitStatus{
+			ExitCode:  int32(code),
+			ExitedAt:  exitedAt,
+			OOMKilled: r.takeOOMKilled(sandboxID),
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *ContainerdRuntime) RestartSandbox(ctx context.Context, sandboxID string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+
+	container, err := r.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+	sbIO := r.getSandboxIO(sandboxID)
+	if sbIO == nil {
+		return fmt.Errorf("%w: no IO for sandbox %s", ErrSandboxNotFound, sandboxID)
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+
+	// 旧 task 已退出，删除后才能在同一容器上创建新 task
+	if old, err := container.Task(ctx, nil); err == nil {
+		if _, err := old.Delete(ctx, containerd.WithProcessKill); err != nil {
+			return fmt.Errorf("failed to delete exited task: %w", err)
+		}
+	}
+	r.takeOOMKilled(sandboxID)
+
+	ioOpts := []cio.Opt{cio.WithStreams(sbIO.Stdin(), sbIO.Stdout(), sbIO.Stderr())}
+	if spec.Process != nil && spec.Process.Terminal {
+		ioOpts = append(ioOpts, cio.WithTerminal)
+	}
+	task, err := container.NewTask(ctx, cio.NewCreator(ioOpts...))
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	if err := task.Start(ctx); err != nil {
+		_, _ = task.Delete(ctx, containerd.WithProcessKill)
+		return fmt.Errorf("failed to start task: %w", err)
+	}
+	klog.InfoS("Sandbox main process restarted", "sandbox", sandboxID, "pid", task.Pid())
+	return nil
+}
+
+func (r *ContainerdRuntime) Pause(ctx context.Context, sandboxID string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+
+	task, err := r.loadTask(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	if err := task.Pause(ctx); err != nil {
+		return fmt.Errorf("failed to pause task: %w", err)
+	}
+	klog.InfoS("Sandbox paused", "sandbox", sandboxID)
+	return nil
+}
+
+func (r *ContainerdRuntime) Resume(ctx context.Context, sandboxID string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+
+	task, err := r.loadTask(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	if err := task.Resume(ctx); err != nil {
+		return fmt.Errorf("failed to resume task: %w", err)
+	}
+	klog.InfoS("Sandbox resumed", "sandbox", sandboxID)
+	return nil
+}
+
+// CPUUsage reads the CPU time of the sandbox cgroup from the task metrics, which come in
+// the cgroup v1 or v2 format depending on the host (runsc reports the v1 format).
+func (r *ContainerdRuntime) CPUUsage(ctx context.Context, sandboxID string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+
+	task, err := r.loadTask(ctx, sandboxID)
+	if err != nil {
+		return 0, err
+	}
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get task metrics: %w", err)
+	}
+	data, err := typeurl.UnmarshalAny(metric.Data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode task metrics: %w", err)
+	}
+	switch m := data.(type) {
+	case *cgroup1stats.Metrics:
+		return time.Duration(m.GetCPU().GetUsage().GetTotal()), nil
+	case *cgroup2stats.Metrics:
+		return time.Duration(m.GetCPU().GetUsageUsec()) * time.Microsecond, nil
+	default:
+		return 0, fmt.Errorf("unsupported task metrics type %T", data)
+	}
+}
+
+// Checkpoint dumps the processes of the sandbox with the runtime (CRIU for runc, runsc
+// checkpoint for gVisor) together with its writable layer into the image ref.
+func (r *ContainerdRuntime) Checkpoint(ctx context.Context, sandboxID, ref string, push bool, creds *api.RegistryCredentials) error {
+	ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+
+	container, err := r.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+	// 同名镜像已存在时 containerd 拒绝创建，先删除旧的 checkpoint
+	if err := r.DeleteCheckpoint(ctx, ref); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	checkpoint, err := container.Checkpoint(ctx, ref,
+		containerd.WithCheckpointImage,
+		containerd.WithCheckpointRW,
+		containerd.WithCheckpointTask,
+		containerd.WithCheckpointRuntime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint task: %w", err)
+	}
+	if push {
+		err := r.client.Push(ctx, ref, checkpoint.Target(), registryOpts(ctx, creds)...)
+		// 推送后本地只是副本，删除镜像记录，内容由 containerd GC 回收
+		if delErr := r.DeleteCheckpoint(ctx, ref); delErr != nil {
+			klog.InfoS("Failed to delete local checkpoint after push", "sandbox", sandboxID, "ref", ref, "err", delErr)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to push checkpoint: %w", err)
+		}
+	}
+	klog.InfoS("Sandbox checkpointed", "sandbox", sandboxID, "ref", ref, "push", push, "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// registryOpts authenticates pushes and pulls of checkpoint images with creds, without
+// them the default resolver accesses the registry anonymously.
+func registryOpts(ctx context.Context, creds *api.RegistryCredentials) []containerd.RemoteOpt {
+	if creds == nil {
+		return nil
+	}
+	hosts := dockerconfig.ConfigureHosts(ctx, dockerconfig.HostOptions{
+		Credentials: func(host string) (string, string, error) {
+			return creds.Username, creds.Password, nil
+		},
+	})
+	return []containerd.RemoteOpt{containerd.WithResolver(docker.NewResolver(docker.ResolverOptions{Hosts: hosts}))}
+}
+
+func (r *ContainerdRuntime) DeleteCheckpoint(ctx context.Context, ref string) error {
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	if err := r.client.ImageService().Delete(ctx, ref); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete checkpoint %s: %w", ref, err)
+	}
+	return nil
+}
+
+// loadTask loads the task of the sandbox main process.
+func (r *ContainerdRuntime) loadTask(ctx context.Context, sandboxID string) (containerd.Task, error) {
+	container, err := r.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+	return task, nil
+}
+
+// watchOOMEvents records OOM kills reported by containerd until ctx is done, so that
+// WaitSandbox can tell an OOM kill from a regular SIGKILL.
+func (r *ContainerdRuntime) watchOOMEvents(ctx context.Context) {
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	for {
+		envelopes, errs := r.client.Subscribe(ctx, fmt.Sprintf(`topic==%q`, cruntime.TaskOOMEventTopic))
+	recv:
+		for {
+			select {
+			case env := <-envelopes:
+				if env.Namespace != "k8s.io" {
+					continue
+				}
+				ev, err := typeurl.UnmarshalAny(env.Event)
+				if err != nil {
+					klog.ErrorS(err, "Failed to decode OOM event")
+					continue
+				}
+				if oom, ok := ev.(*apievents.TaskOOM); ok {
+					klog.InfoS("Sandbox OOM killed", "sandbox", oom.ContainerID)
+					r.oomMu.Lock()
+					if r.oomKilled == nil {
+						r.oomKilled = make(map[string]bool)
+					}
+					r.oomKilled[oom.ContainerID] = true
+					r.oomMu.Unlock()
+				}
+			case err := <-errs:
+				if ctx.Err() != nil {
+					return
+				}
+				klog.ErrorS(err, "OOM event subscription broken, resubscribing")
+				break recv
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventsRetryInterval):
+		}
+	}
+}
+
+// takeOOMKilled reports and clears whether the sandbox main process was OOM killed.
+func (r *ContainerdRuntime) takeOOMKilled(sandboxID string) bool {
+	r.oomMu.Lock()
+	defer r.oomMu.Unlock()
+	killed := r.oomKilled[sandboxID]
+	delete(r.oomKilled, sandboxID)
+	return killed
+}
+
+func (r *ContainerdRuntime) Exec(ctx context.Context, sandboxID string, opts *ExecOptions) (int, error) {
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	container, err := r.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return -1, fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return -1, fmt.Errorf("failed to load task: %w", err)
+	}
+
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("failed to load container spec: %w", err)
+	}
+	pspec := buildExecProcessSpec(spec.Process, opts)
+
+	// containerd does not propagate EOF on the stdin fifo by itself, CloseIO has to be called explicitly
+	var stdin io.Reader
+	var stdinEOF chan struct{}
+	if opts.Stdin != nil {
+		stdinEOF = make(chan struct{})
+		stdin = &eofNotifyReader{r: opts.Stdin, eof: stdinEOF}
+	}
+
+	execID := fmt.Sprintf("exec-%d", time.Now().UnixNano())
+	klog.InfoS("Starting exec process", "sandbox", sandboxID, "execID", execID, "command", opts.Command)
+	ioOpts := []cio.Opt{cio.WithStreams(stdin, opts.Stdout, opts.Stderr)}
+	if opts.TTY {
+		ioOpts = append(ioOpts, cio.WithTerminal)
+	}
+	process, err := task.Exec(ctx, execID, pspec, cio.NewCreator(ioOpts...))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create exec process: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the process is reaped even if the caller went away
+		delCtx, cancel := context.WithTimeout(namespaces.WithNamespace(context.Background(), "k8s.io"), defaultOperationTimeout)
+		defer cancel()
+		if _, err := process.Delete(delCtx, containerd.WithProcessKill); err != nil {
+			klog.InfoS("Failed to delete exec process", "sandbox", sandboxID, "execID", execID, "err", err)
+		}
+	}()
+
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("failed to wait for exec process: %w", err)
+	}
+
+	if err := process.Start(ctx); err != nil {
+		return -1, fmt.Errorf("failed to start exec process: %w", err)
+	}
+
+	if opts.TTY && opts.Resize != nil {
+		go forwardResize(ctx, process, opts.Resize, sandboxID)
+	}
+
+	if stdinEOF != nil {
+		go func() {
+			select {
+			case <-stdinEOF:
+				if err := process.CloseIO(ctx, containerd.WithStdinCloser); err != nil {
+					klog.InfoS("Failed to close exec stdin", "sandbox", sandboxID, "execID", execID, "err", err)
+				}
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			return -1, err
+		}
+		klog.InfoS("Exec process exited", "sandbox", sandboxID, "execID", execID, "exitCode", code)
+		return int(code), nil
+	case <-ctx.Done():
+		klog.InfoS("Exec cancelled, killing process", "sandbox", sandboxID, "execID", execID)
+		return -1, ctx.Err()
+	}
+}
+
+// buildExecProcessSpec derives the exec process from the sandbox main process so that
+// user, capabilities and base environment are inherited.
+func buildExecProcessSpec(base *specs.Process, opts *ExecOptions) *specs.Process {
+	var pspec specs.Process
+	if base != nil {
+		pspec = *base
+	}
+	pspec.Args = opts.Command
+	pspec.Terminal = opts.TTY
+	pspec.Env = append(append([]string{}, pspec.Env...), envMapToSlice(opts.Env)...)
+	if opts.WorkingDir != "" {
+		pspec.Cwd = opts.WorkingDir
+	}
+	if pspec.Cwd == "" {
+		pspec.Cwd = "/"
+	}
+	return &pspec
+}
+
+// Attach streams the main process IO of a sandbox created by this agent.
+func (r *ContainerdRuntime) Attach(ctx context.Context, sandboxID string, opts *AttachOptions) (int, error) {
+	sbIO := r.getSandboxIO(sandboxID)
+	if sbIO == nil {
+		return -1, fmt.Errorf("%w: no attachable streams for %s", ErrSandboxNotFound, sandboxID)
+	}
+	if opts.Stdin != nil && !sbIO.HasStdin() {
+		return -1, ErrStdinNotEnabled
+	}
+
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	container, err := r.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return -1, fmt.Errorf("%w: %v", ErrSandboxNotFound, err)
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return -1, fmt.Errorf("failed to load task: %w", err)
+	}
+	statusC, err := task.Wait(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("failed to wait for task: %w", err)
+	}
+
+	klog.InfoS("Attaching to sandbox", "sandbox", sandboxID, "stdin", opts.Stdin != nil)
+	dropped, detach := sbIO.Attach(opts.Stdout, opts.Stderr)
+	defer detach()
+
+	if opts.Stdin != nil {
+		go func() {
+			if err := sbIO.CopyStdin(opts.Stdin); err != nil {
+				klog.V(4).InfoS("Attach stdin closed", "sandbox", sandboxID, "err", err)
+			}
+		}()
+	}
+	if opts.Resize != nil {
+		go forwardResize(ctx, task, opts.Resize, sandboxID)
+	}
+
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			return -1, err
+		}
+		klog.InfoS("Attached sandbox process exited", "sandbox", sandboxID, "exitCode", code)
+		return int(code), nil
+	case err := <-dropped:
+		klog.InfoS("Dropped attach client", "sandbox", sandboxID, "reason", err)
+		return -1, err
+	case <-ctx.Done():
+		klog.InfoS("Detached from sandbox", "sandbox", sandboxID)
+		return -1, ctx.Err()
+	}
+}
+
+// forwardResize applies terminal size changes to a process until ctx is done.
+func forwardResize(ctx context.Context, process containerd.Process, sizes <-chan api.TerminalSize, sandboxID string) {
+	for {
+		select {
+		case size, ok := <-sizes:
+			if !ok {
+				return
+			}
+			if err := process.Resize(ctx, size.Width, size.Height); err != nil {
+				klog.V(4).InfoS("Failed to resize terminal", "sandbox", sandboxID, "err", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *ContainerdRuntime) setSandboxIO(sandboxID string, sbIO *sandboxIO) {
+	r.ioMu.Lock()
+	defer r.ioMu.Unlock()
+	if r.sandboxIOs == nil {
+		r.sandboxIOs = make(map[string]*sandboxIO)
+	}
+	r.sandboxIOs[sandboxID] = sbIO
+}
+
+func (r *ContainerdRuntime) getSandboxIO(sandboxID string) *sandboxIO {
+	r.ioMu.Lock()
+	defer r.ioMu.Unlock()
+	return r.sandboxIOs[sandboxID]
+}
+
+// releaseSandboxIO closes the log file and stdin of a deleted sandbox.
+func (r *ContainerdRuntime) releaseSandboxIO(sandboxID string) {
+	r.ioMu.Lock()
+	sbIO, ok := r.sandboxIOs[sandboxID]
+	delete(r.sandboxIOs, sandboxID)
+	r.ioMu.Unlock()
+	if ok {
+		sbIO.Close()
+	}
+}
+
+// eofNotifyReader closes eof once the wrapped reader is drained.
+type eofNotifyReader struct {
+	r    io.Reader
+	eof  chan struct{}
+	once sync.Once
+}
+
+func (e *eofNotifyReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil {
+		e.once.Do(func() { close(e.eof) })
+	}
+	return n, err
+}
+
 func (r *ContainerdRuntime) ListImages(ctx context.Context) ([]string, error) {
 	ctx = namespaces.WithNamespace(ctx, "k8s.io")
 	images, err := r.client.ListImages(ctx)
@@ -456,6 +1084,9 @@ func (r *ContainerdRuntime) PullImage(ctx context.Context, image string) error {
 }
 
 func (r *ContainerdRuntime) Close() error {
+	if r.stopEvents != nil {
+		r.stopEvents()
+	}
 	if r.client != nil {
 		return r.client.Close()
 	}
@@ -463,8 +1094,7 @@ func (r *ContainerdRuntime) Close() error {
 }
 
 func (r *ContainerdRuntime) GetSandboxLogs(ctx context.Context, sandboxID string, follow bool, stdout io.Writer) error {
-	logPath := filepath.Join("/var/log/fast-sandbox", fmt.Sprintf("%s.log", sandboxID))
-	file, err := os.Open(logPath)
+	file, err := os.Open(sandboxLogPath(sandboxID))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("log file not found")
@@ -10,6 +10,9 @@ import (
 
 	"fast-sandbox/internal/api"
 
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/typeurl/v2"
+	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -786,3 +789,87 @@ func TestContainerdRuntime_Close_NotInitialized(t *testing.T) {
 	err := cr.Close()
 	assert.NoError(t, err, "Close should not error on uninitialized runtime")
 }
+
+// ============================================================================
+// 12. Test buildExecProcessSpec
+// ============================================================================
+
+func TestBuildExecProcessSpec(t *testing.T) {
+	// EP-01: Exec inherits the sandbox process but overrides args, env and cwd
+	base := &specs.Process{
+		Args:     []string{"/bin/sleep", "3600"},
+		Env:      []string{"PATH=/usr/bin"},
+		Cwd:      "/app",
+		Terminal: true,
+	}
+
+	pspec := buildExecProcessSpec(base, &ExecOptions{
+		Command:    []string{"ls", "-l"},
+		Env:        map[string]string{"FOO": "bar"},
+		WorkingDir: "/tmp",
+	})
+
+	assert.Equal(t, []string{"ls", "-l"}, pspec.Args)
+	assert.Equal(t, []string{"PATH=/usr/bin", "FOO=bar"}, pspec.Env)
+	assert.Equal(t, "/tmp", pspec.Cwd)
+	assert.False(t, pspec.Terminal)
+	assert.Equal(t, []string{"PATH=/usr/bin"}, base.Env, "Base spec must not be mutated")
+	assert.Equal(t, []string{"/bin/sleep", "3600"}, base.Args)
+}
+
+func TestBuildExecProcessSpec_TTY(t *testing.T) {
+	// EP-03: TTY exec requests a terminal even if the main process has none
+	pspec := buildExecProcessSpec(&specs.Process{}, &ExecOptions{Command: []string{"sh"}, TTY: true})
+	assert.True(t, pspec.Terminal)
+}
+
+func TestBuildExecProcessSpec_DefaultCwd(t *testing.T) {
+	// EP-02: Empty working directory falls back to the sandbox cwd, then "/"
+	pspec := buildExecProcessSpec(&specs.Process{Cwd: "/app"}, &ExecOptions{Command: []string{"pwd"}})
+	assert.Equal(t, "/app", pspec.Cwd)
+
+	pspec = buildExecProcessSpec(nil, &ExecOptions{Command: []string{"pwd"}})
+	assert.Equal(t, "/", pspec.Cwd)
+}
+
+// ============================================================================
+// 13. Test recoverSandboxSpec
+// ============================================================================
+
+func TestRecoverSandboxSpec_FromExtension(t *testing.T) {
+	// RS-01: The spec stored at creation is restored in full
+	spec := &api.SandboxSpec{
+		SandboxID:     "sb-1",
+		ClaimUID:      "uid-1",
+		ClaimName:     "claim-1",
+		Image:         "alpine:latest",
+		Env:           map[string]string{"A": "1"},
+		Stdin:         true,
+		RestartPolicy: api.RestartPolicyOnFailure,
+	}
+	ext, err := typeurl.MarshalAny(spec)
+	require.NoError(t, err)
+
+	got := recoverSandboxSpec(containers.Container{
+		ID:         "sb-1",
+		Extensions: map[string]typeurl.Any{sandboxSpecExtension: ext},
+	})
+	assert.Equal(t, spec, got)
+}
+
+func TestRecoverSandboxSpec_FromLabels(t *testing.T) {
+	// RS-02: Containers without the extension fall back to labels and the image
+	got := recoverSandboxSpec(containers.Container{
+		ID:    "sb-2",
+		Image: "nginx:latest",
+		Labels: map[string]string{
+			"fast-sandbox.io/id":           "sb-2",
+			"fast-sandbox.io/claim-uid":    "uid-2",
+			"fast-sandbox.io/sandbox-name": "claim-2",
+		},
+	})
+	assert.Equal(t, &api.SandboxSpec{SandboxID: "sb-2", ClaimUID: "uid-2", ClaimName: "claim-2", Image: "nginx:latest"}, got)
+
+	got = recoverSandboxSpec(containers.Container{ID: "sb-3"})
+	assert.Equal(t, "sb-3", got.SandboxID, "Container ID is used when the id label is missing")
+}
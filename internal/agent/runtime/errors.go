@@ -20,6 +20,18 @@ var (
 
 	// ErrInvalidConfig 无效的配置
 	ErrInvalidConfig = errors.New("invalid sandbox config")
+
+	// ErrStdinNotEnabled sandbox 创建时未开启 stdin，无法 attach 输入
+	ErrStdinNotEnabled = errors.New("sandbox stdin is not enabled")
+
+	// ErrAttachTooSlow attach 客户端读取输出过慢，缓冲写满后被断开
+	ErrAttachTooSlow = errors.New("attach client could not keep up with the sandbox output")
+
+	// ErrInvalidPhase sandbox 当前阶段不允许该操作，例如暂停已退出的 sandbox
+	ErrInvalidPhase = errors.New("operation not allowed in the sandbox phase")
+
+	// ErrCheckpointUnsupported sandbox 的配置无法被 CRIU 转储，例如主进程使用伪终端
+	ErrCheckpointUnsupported = errors.New("sandbox cannot be checkpointed")
 )
 
 type Errors []error
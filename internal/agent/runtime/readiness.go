@@ -0,0 +1,219 @@
+package runtime
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fast-sandbox/internal/api"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// 探测默认值，间隔与超时比 Kubernetes 短，sandbox 通常在秒级内就绪
+	defaultProbePeriod      = time.Second
+	defaultProbeTimeout     = time.Second
+	defaultSuccessThreshold = 1
+	defaultFailureThreshold = 3
+)
+
+// validateProbe checks that the agent can run a readiness probe.
+func validateProbe(probe *api.Probe) error {
+	if probe == nil {
+		return nil
+	}
+	handlers := 0
+	if len(probe.Exec) > 0 {
+		handlers++
+	}
+	if probe.HTTPGet != nil {
+		handlers++
+		if probe.HTTPGet.Port < 1 || probe.HTTPGet.Port > 65535 {
+			return fmt.Errorf("%w: readiness probe port %d out of range", ErrInvalidConfig, probe.HTTPGet.Port)
+		}
+		if s := strings.ToUpper(probe.HTTPGet.Scheme); s != "" && s != "HTTP" && s != "HTTPS" {
+			return fmt.Errorf("%w: readiness probe scheme %q", ErrInvalidConfig, probe.HTTPGet.Scheme)
+		}
+	}
+	if probe.TCPPort != 0 {
+		handlers++
+		if probe.TCPPort < 0 || probe.TCPPort > 65535 {
+			return fmt.Errorf("%w: readiness probe port %d out of range", ErrInvalidConfig, probe.TCPPort)
+		}
+	}
+	if handlers != 1 {
+		return fmt.Errorf("%w: readiness probe must have exactly one of exec, httpGet or tcpPort", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// orDefault returns seconds as a duration, or def when it is not set.
+func orDefault(seconds int32, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// probeReadiness runs the readiness probe of a sandbox every period while it is running,
+// until ctx is done, the sandbox is removed or its main process exited for good. The
+// sandbox becomes ready after SuccessThreshold consecutive successes and not ready after
+// FailureThreshold consecutive failures; every start of the main process begins not ready.
+func (m *SandboxManager) probeReadiness(ctx context.Context, sandboxID string, probe *api.Probe) {
+	period := orDefault(probe.PeriodSeconds, defaultProbePeriod)
+	timeout := orDefault(probe.TimeoutSeconds, defaultProbeTimeout)
+	initialDelay := time.Duration(max(probe.InitialDelaySeconds, 0)) * time.Second
+	successThreshold := max(probe.SuccessThreshold, defaultSuccessThreshold)
+	failureThreshold := probe.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultFailureThreshold
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	var successes, failures int32
+	restarts := int32(-1)
+	var startedAt time.Time
+	for {
+		m.mu.RLock()
+		meta, ok := m.sandboxes[sandboxID]
+		var phase string
+		var restartCount int32
+		if ok {
+			phase, restartCount = meta.Phase, meta.RestartCount
+		}
+		m.mu.RUnlock()
+		if !ok || phase == "succeeded" || phase == "failed" || phase == "terminating" {
+			return
+		}
+
+		// 主进程重启后重新计算初始延迟与连续次数
+		if restartCount != restarts {
+			restarts, startedAt = restartCount, time.Now()
+			successes, failures = 0, 0
+		}
+		if phase == "running" && time.Since(startedAt) >= initialDelay {
+			err := m.runProbe(ctx, sandboxID, probe, timeout)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				successes, failures = successes+1, 0
+			} else {
+				successes, failures = 0, failures+1
+				klog.V(2).InfoS("Readiness probe failed", "sandbox", sandboxID, "failures", failures, "err", err)
+			}
+			switch {
+			case successes >= successThreshold:
+				m.setReady(sandboxID, restartCount, true)
+			case failures >= failureThreshold:
+				m.setReady(sandboxID, restartCount, false)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setReady records the readiness of a running sandbox and reports changes, unless the main
+// process was restarted since the probe ran.
+func (m *SandboxManager) setReady(sandboxID string, restartCount int32, ready bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	meta, ok := m.sandboxes[sandboxID]
+	if !ok || meta.Phase != "running" || meta.RestartCount != restartCount || meta.ready == ready {
+		return
+	}
+	meta.ready = ready
+	m.publishLocked(sandboxID, meta)
+	klog.InfoS("Sandbox readiness changed", "sandbox", sandboxID, "ready", ready)
+}
+
+// runProbe runs the probe once.
+func (m *SandboxManager) runProbe(ctx context.Context, sandboxID string, probe *api.Probe, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case len(probe.Exec) > 0:
+		// 直接调用运行时，探测不计为空闲检测中的会话
+		code, err := m.runtime.Exec(ctx, sandboxID, &ExecOptions{Command: probe.Exec, Stdout: io.Discard, Stderr: io.Discard})
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("exec probe exited with %d", code)
+		}
+		return nil
+	case probe.HTTPGet != nil:
+		return m.probeHTTP(ctx, probe.HTTPGet)
+	default:
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(m.probeHost, strconv.Itoa(int(probe.TCPPort))))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// probeHTTP sends the GET request of an HTTP probe, a status from 200 to 399 is a success.
+func (m *SandboxManager) probeHTTP(ctx context.Context, probe *api.HTTPGetProbe) error {
+	scheme := strings.ToLower(probe.Scheme)
+	if scheme == "" {
+		scheme = "http"
+	}
+	host := probe.Host
+	if host == "" {
+		host = m.probeHost
+	}
+	path := probe.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(probe.Port))), path), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "fast-sandbox-probe")
+	for name, value := range probe.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 10*1024))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP probe returned %s", resp.Status)
+	}
+	return nil
+}
+
+// probeClient 不跟随重定向（3xx 即成功）、不复用连接、不校验证书，与 kubelet 一致
+var probeClient = &http.Client{
+	Transport: &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
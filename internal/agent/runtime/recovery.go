@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fast-sandbox/internal/api"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/typeurl/v2"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// sandboxLogDir 保存 sandbox 主进程日志，Agent 重启后重新打开继续追加
+	sandboxLogDir = "/var/log/fast-sandbox"
+	// sandboxSpecExtension 是容器扩展字段，保存创建时的完整 SandboxSpec，用于重启后恢复
+	sandboxSpecExtension = "fast-sandbox.io/spec"
+)
+
+func init() {
+	typeurl.Register(&api.SandboxSpec{}, "fast-sandbox.io", "SandboxSpec")
+}
+
+func sandboxLogPath(sandboxID string) string {
+	return filepath.Join(sandboxLogDir, fmt.Sprintf("%s.log", sandboxID))
+}
+
+// openSandboxLog opens the log file of a sandbox for appending, creating it if needed.
+func openSandboxLog(sandboxID string) (*os.File, error) {
+	if err := os.MkdirAll(sandboxLogDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log dir: %w", err)
+	}
+	logFile, err := os.OpenFile(sandboxLogPath(sandboxID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return logFile, nil
+}
+
+// RecoverSandboxes rebuilds the metadata of the containers labeled with this agent pod UID.
+// Running tasks get their FIFOs reopened so that logs and attach keep working; stopped
+// tasks are reported as running so that the exit is picked up by WaitSandbox.
+func (r *ContainerdRuntime) RecoverSandboxes(ctx context.Context) ([]*SandboxMetadata, error) {
+	if r.agentPodUID == "" {
+		klog.InfoS("POD_UID not set, skipping sandbox recovery")
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+	defer cancel()
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+
+	filter := fmt.Sprintf("labels.\"fast-sandbox.io/agent-uid\"==\"%s\"", r.agentPodUID)
+	list, err := r.client.Containers(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var recovered []*SandboxMetadata
+	for _, c := range list {
+		meta, err := r.recoverSandbox(ctx, c)
+		if err != nil {
+			klog.ErrorS(err, "Failed to recover sandbox", "container", c.ID())
+			continue
+		}
+		recovered = append(recovered, meta)
+	}
+	return recovered, nil
+}
+
+func (r *ContainerdRuntime) recoverSandbox(ctx context.Context, c containerd.Container) (*SandboxMetadata, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load container info: %w", err)
+	}
+	spec := recoverSandboxSpec(info)
+	meta := &SandboxMetadata{
+		SandboxSpec: *spec,
+		ContainerID: c.ID(),
+		CreatedAt:   info.CreatedAt.Unix(),
+	}
+
+	logFile, err := openSandboxLog(c.ID())
+	if err != nil {
+		return nil, err
+	}
+	sbIO := newSandboxIO(logFile, spec.Stdin)
+
+	task, err := c.Task(ctx, nil)
+	if errdefs.IsNotFound(err) {
+		// 没有 task：Agent 在创建或重启主进程的中途退出，只能报告失败，等待删除
+		r.setSandboxIO(c.ID(), sbIO)
+		meta.Phase = "failed"
+		meta.Reason = api.ReasonError
+		return meta, nil
+	}
+	if err != nil {
+		sbIO.Close()
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+
+	status, err := task.Status(ctx)
+	if err != nil {
+		sbIO.Close()
+		return nil, fmt.Errorf("failed to get task status: %w", err)
+	}
+	if status.Status != containerd.Stopped {
+		// 重新打开 task 的 FIFO，接管主进程的输出与 stdin
+		task, err = c.Task(ctx, cio.NewAttach(cio.WithStreams(sbIO.Stdin(), sbIO.Stdout(), sbIO.Stderr())))
+		if err != nil {
+			sbIO.Close()
+			return nil, fmt.Errorf("failed to reattach task IO: %w", err)
+		}
+	}
+	r.setSandboxIO(c.ID(), sbIO)
+
+	meta.Phase = "running"
+	if status.Status == containerd.Paused {
+		meta.Phase = "paused"
+	}
+	meta.PID = int(task.Pid())
+	klog.InfoS("Recovered sandbox from containerd", "sandbox", spec.SandboxID, "taskStatus", status.Status, "pid", meta.PID)
+	return meta, nil
+}
+
+// recoverSandboxSpec reads the spec stored at creation, falling back to the labels for
+// containers created before the spec extension existed.
+func recoverSandboxSpec(info containers.Container) *api.SandboxSpec {
+	if ext, ok := info.Extensions[sandboxSpecExtension]; ok {
+		var spec api.SandboxSpec
+		err := typeurl.UnmarshalTo(ext, &spec)
+		if err == nil {
+			return &spec
+		}
+		klog.ErrorS(err, "Invalid sandbox spec extension, falling back to labels", "container", info.ID)
+	}
+
+	spec := &api.SandboxSpec{
+		SandboxID: info.Labels["fast-sandbox.io/id"],
+		ClaimUID:  info.Labels["fast-sandbox.io/claim-uid"],
+		ClaimName: info.Labels["fast-sandbox.io/sandbox-name"],
+		Image:     info.Image,
+	}
+	if spec.SandboxID == "" {
+		spec.SandboxID = info.ID
+	}
+	return spec
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"fast-sandbox/internal/api"
+
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// cpuPeriod 是 CFS 调度周期（微秒），与 kubelet 默认值一致
+	cpuPeriod uint64 = 100000
+	// minCPUQuota 是内核允许的最小 CFS quota（微秒）
+	minCPUQuota int64 = 1000
+	// minCPUShares 是 cgroup v1 cpu.shares 的最小值
+	minCPUShares uint64 = 2
+	// sandboxCgroupPrefix 是 sandbox cgroup 名称前缀
+	sandboxCgroupPrefix = "fast-sandbox"
+)
+
+// resourceSpecOpts maps the CPU/memory settings of a sandbox to OCI cgroup limits.
+// The CPU request sets the relative weight and defaults to the CPU limit.
+func resourceSpecOpts(config *api.SandboxSpec) ([]oci.SpecOpts, error) {
+	var opts []oci.SpecOpts
+
+	if config.CPU != "" {
+		milli, err := parsePositiveQuantity("cpu", config.CPU)
+		if err != nil {
+			return nil, err
+		}
+		quota := milli * int64(cpuPeriod) / 1000
+		if quota < minCPUQuota {
+			quota = minCPUQuota
+		}
+		opts = append(opts, oci.WithCPUCFS(quota, cpuPeriod))
+	}
+
+	cpuRequest := config.CPURequest
+	if cpuRequest == "" {
+		cpuRequest = config.CPU
+	}
+	if cpuRequest != "" {
+		milli, err := parsePositiveQuantity("cpu request", cpuRequest)
+		if err != nil {
+			return nil, err
+		}
+		shares := uint64(milli) * 1024 / 1000
+		if shares < minCPUShares {
+			shares = minCPUShares
+		}
+		opts = append(opts, oci.WithCPUShares(shares))
+	}
+
+	if config.Memory != "" {
+		q, err := resource.ParseQuantity(config.Memory)
+		if err != nil || q.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid memory limit %q", config.Memory)
+		}
+		opts = append(opts, oci.WithMemoryLimit(uint64(q.Value())))
+	}
+
+	return opts, nil
+}
+
+// parsePositiveQuantity parses a CPU quantity and returns it in millicores.
+func parsePositiveQuantity(name, value string) (int64, error) {
+	q, err := resource.ParseQuantity(value)
+	if err != nil || q.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return q.MilliValue(), nil
+}
+
+// sandboxCgroupsPath places the sandbox cgroup next to the agent container, under the
+// agent pod cgroup, so that sandboxes are accounted to and bounded by the agent pod.
+// An empty result leaves the placement to containerd.
+func sandboxCgroupsPath(agentCgroup, sandboxID string) string {
+	agentCgroup = strings.TrimSuffix(agentCgroup, "/")
+	parent := path.Dir(agentCgroup)
+	if agentCgroup == "" || parent == "/" || parent == "." {
+		return ""
+	}
+
+	// systemd cgroup driver: runc expects "slice:prefix:name"
+	if strings.HasSuffix(agentCgroup, ".scope") && strings.HasSuffix(parent, ".slice") {
+		return fmt.Sprintf("%s:%s:%s", path.Base(parent), sandboxCgroupPrefix, sandboxID)
+	}
+	return path.Join(parent, sandboxCgroupPrefix+"-"+sandboxID)
+}
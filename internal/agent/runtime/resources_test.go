@@ -0,0 +1,125 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"fast-sandbox/internal/api"
+
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func applySpecOpts(t *testing.T, opts []oci.SpecOpts) *oci.Spec {
+	t.Helper()
+	s := &oci.Spec{Linux: &specs.Linux{}}
+	for _, o := range opts {
+		require.NoError(t, o(context.Background(), nil, nil, s))
+	}
+	return s
+}
+
+// ============================================================================
+// 1. Test resourceSpecOpts
+// ============================================================================
+
+func TestResourceSpecOpts_Limits(t *testing.T) {
+	// RES-01: CPU and memory limits become CFS quota and memory limit, weight defaults to the limit
+	opts, err := resourceSpecOpts(&api.SandboxSpec{CPU: "500m", Memory: "256Mi"})
+	require.NoError(t, err)
+
+	s := applySpecOpts(t, opts)
+	require.NotNil(t, s.Linux.Resources)
+	assert.Equal(t, int64(50000), *s.Linux.Resources.CPU.Quota)
+	assert.Equal(t, uint64(100000), *s.Linux.Resources.CPU.Period)
+	assert.Equal(t, uint64(512), *s.Linux.Resources.CPU.Shares)
+	assert.Equal(t, int64(256*1024*1024), *s.Linux.Resources.Memory.Limit)
+}
+
+func TestResourceSpecOpts_RequestOnly(t *testing.T) {
+	// RES-02: A CPU request alone sets the weight without a quota
+	opts, err := resourceSpecOpts(&api.SandboxSpec{CPURequest: "2"})
+	require.NoError(t, err)
+
+	s := applySpecOpts(t, opts)
+	assert.Nil(t, s.Linux.Resources.CPU.Quota)
+	assert.Equal(t, uint64(2048), *s.Linux.Resources.CPU.Shares)
+	assert.Nil(t, s.Linux.Resources.Memory)
+}
+
+func TestResourceSpecOpts_Minimums(t *testing.T) {
+	// RES-03: Tiny CPU values are raised to the kernel minimums
+	opts, err := resourceSpecOpts(&api.SandboxSpec{CPU: "1m"})
+	require.NoError(t, err)
+
+	s := applySpecOpts(t, opts)
+	assert.Equal(t, minCPUQuota, *s.Linux.Resources.CPU.Quota)
+	assert.Equal(t, minCPUShares, *s.Linux.Resources.CPU.Shares)
+}
+
+func TestResourceSpecOpts_None(t *testing.T) {
+	// RES-04: No resources means no cgroup limits
+	opts, err := resourceSpecOpts(&api.SandboxSpec{})
+	require.NoError(t, err)
+	assert.Empty(t, opts)
+}
+
+func TestResourceSpecOpts_Invalid(t *testing.T) {
+	// RES-05: Invalid or non-positive quantities are rejected
+	for _, spec := range []api.SandboxSpec{
+		{CPU: "lots"},
+		{CPU: "0"},
+		{CPURequest: "-1"},
+		{Memory: "1Gx"},
+	} {
+		_, err := resourceSpecOpts(&spec)
+		assert.Error(t, err, "spec %+v", spec)
+	}
+}
+
+// ============================================================================
+// 2. Test sandboxCgroupsPath
+// ============================================================================
+
+func TestSandboxCgroupsPath(t *testing.T) {
+	// RES-06: Sandbox cgroups are siblings of the agent container under the pod cgroup
+	tests := []struct {
+		name     string
+		agent    string
+		expected string
+	}{
+		{
+			name:     "systemd driver",
+			agent:    "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-podabc.slice/cri-containerd-123.scope",
+			expected: "kubepods-burstable-podabc.slice:fast-sandbox:sb-1",
+		},
+		{
+			name:     "cgroupfs driver",
+			agent:    "/kubepods/burstable/podabc/123",
+			expected: "/kubepods/burstable/podabc/fast-sandbox-sb-1",
+		},
+		{
+			name:     "unknown path",
+			agent:    "",
+			expected: "",
+		},
+		{
+			name:     "private cgroup namespace",
+			agent:    "/",
+			expected: "",
+		},
+		{
+			name:     "top level cgroup",
+			agent:    "/agent",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sandboxCgroupsPath(tt.agent, "sb-1"))
+		})
+	}
+}
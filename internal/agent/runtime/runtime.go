@@ -3,6 +3,7 @@ package runtime
 import (
 	"context"
 	"io"
+	"time"
 
 	"fast-sandbox/internal/api"
 )
@@ -13,6 +14,61 @@ type SandboxMetadata struct {
 	PID         int
 	Phase       string
 	CreatedAt   int64
+
+	// 主进程最近一次退出的信息，ExitedAt 为 0 表示尚未退出
+	ExitCode     int32
+	ExitedAt     int64
+	Reason       string
+	RestartCount int32
+
+	// stopWatch 停止退出监听与重启，由 SandboxManager 设置
+	stopWatch context.CancelFunc
+	// transitioning 表示暂停或恢复正在进行，运行时调用不持有 SandboxManager 的锁
+	transitioning bool
+
+	// 空闲检测状态，由 SandboxManager 维护：lastActivity 为最近活动，reportedActivity 为上报值，
+	// sessions 为进行中的 exec/attach/日志会话，其余为上次采样的 CPU 时间与暴露端口流量
+	lastActivity     time.Time
+	reportedActivity time.Time
+	sessions         int
+	sampledAt        time.Time
+	cpuUsage         time.Duration
+	traffic          portTraffic
+
+	// ready 为就绪探测的结果，只在有 ReadinessProbe 时使用，主进程每次退出后重置
+	ready bool
+}
+
+// ExitStatus describes how the main process of a sandbox exited.
+type ExitStatus struct {
+	ExitCode  int32
+	ExitedAt  time.Time
+	OOMKilled bool
+}
+
+// ExecOptions describes a process started inside an existing sandbox.
+type ExecOptions struct {
+	Command    []string
+	Env        map[string]string
+	WorkingDir string
+	// Stdin is optional; when nil the process gets no stdin.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY allocates a pseudo-terminal; stderr is merged into stdout.
+	TTY bool
+	// Resize delivers terminal size changes, only used with TTY.
+	Resize <-chan api.TerminalSize
+}
+
+// AttachOptions describes a client attached to the sandbox main process.
+type AttachOptions struct {
+	// Stdin is optional and requires the sandbox to be created with Stdin.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Resize delivers terminal size changes, only used when the sandbox has a TTY.
+	Resize <-chan api.TerminalSize
 }
 
 type Runtime interface {
@@ -22,6 +78,10 @@ type Runtime interface {
 
 	CreateSandbox(ctx context.Context, config *api.SandboxSpec) (*SandboxMetadata, error)
 
+	// RestoreSandbox creates the sandbox from the checkpoint image config.Checkpoint, resuming
+	// the checkpointed processes and filesystem instead of starting a new main process.
+	RestoreSandbox(ctx context.Context, config *api.SandboxSpec) (*SandboxMetadata, error)
+
 	DeleteSandbox(ctx context.Context, sandboxID string) error
 
 	GetSandboxLogs(ctx context.Context, sandboxID string, follow bool, stdout io.Writer) error
@@ -32,6 +92,38 @@ type Runtime interface {
 
 	GetSandboxStatus(ctx context.Context, sandboxID string) (string, error)
 
+	// Exec runs a process inside the sandbox and blocks until it exits, returning its exit code.
+	Exec(ctx context.Context, sandboxID string, opts *ExecOptions) (int, error)
+
+	// Attach streams the main process IO until it exits or ctx is done, returning its exit code.
+	Attach(ctx context.Context, sandboxID string, opts *AttachOptions) (int, error)
+
+	// WaitSandbox blocks until the main process exits or ctx is done.
+	WaitSandbox(ctx context.Context, sandboxID string) (*ExitStatus, error)
+
+	// RestartSandbox starts a new main process in an exited sandbox, reusing its container and IO.
+	RestartSandbox(ctx context.Context, sandboxID string) error
+
+	// Pause freezes all processes of the sandbox with the cgroup freezer.
+	Pause(ctx context.Context, sandboxID string) error
+
+	// Resume thaws the processes of a paused sandbox.
+	Resume(ctx context.Context, sandboxID string) error
+
+	// CPUUsage returns the cumulative CPU time used by all processes of the sandbox.
+	CPUUsage(ctx context.Context, sandboxID string) (time.Duration, error)
+
+	// Checkpoint saves the processes and filesystem of the sandbox as the checkpoint image ref,
+	// pushing it to the registry of ref with creds when push is set. The sandbox keeps running.
+	Checkpoint(ctx context.Context, sandboxID, ref string, push bool, creds *api.RegistryCredentials) error
+
+	// DeleteCheckpoint removes a local checkpoint image, a missing image is not an error.
+	DeleteCheckpoint(ctx context.Context, ref string) error
+
+	// RecoverSandboxes returns the sandboxes created by this agent pod that still exist in the
+	// runtime, reattaching to the IO of their main processes. Used after an agent restart.
+	RecoverSandboxes(ctx context.Context) ([]*SandboxMetadata, error)
+
 	Close() error
 }
 
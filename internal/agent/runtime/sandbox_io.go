@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// attachBufferChunks 是每个 attach 客户端最多缓冲的输出块数，写满后断开该客户端
+const attachBufferChunks = 256
+
+// sandboxIO owns the streams of a sandbox main process. Output always goes to the
+// log sink and is additionally fanned out to attached clients; stdin, when enabled,
+// is fed by whichever clients are attached.
+type sandboxIO struct {
+	mu      sync.Mutex
+	log     io.WriteCloser
+	clients map[int]*attachedClient
+	nextID  int
+
+	stdinMu sync.Mutex
+	stdinR  *io.PipeReader
+	stdinW  *io.PipeWriter
+}
+
+// attachedClient buffers the output of one client and writes it from its own goroutine,
+// so that a slow client never blocks the container or the other clients.
+type attachedClient struct {
+	stdout io.Writer
+	stderr io.Writer
+	chunks chan outputChunk
+	// dropped 在客户端写失败或缓冲写满被断开时收到原因
+	dropped chan error
+	// finished 在写协程退出时关闭
+	finished chan struct{}
+}
+
+type outputChunk struct {
+	data   []byte
+	stderr bool
+}
+
+// run writes buffered output until the chunks channel is closed or a write fails.
+func (c *attachedClient) run(s *sandboxIO, id int) {
+	defer close(c.finished)
+	for chunk := range c.chunks {
+		out := c.stdout
+		if chunk.stderr && c.stderr != nil {
+			out = c.stderr
+		}
+		if out == nil {
+			continue
+		}
+		if _, err := out.Write(chunk.data); err != nil {
+			s.mu.Lock()
+			s.dropLocked(id, err)
+			s.mu.Unlock()
+			// 客户端已从 clients 中移除，剩余的缓冲输出直接丢弃
+			return
+		}
+	}
+}
+
+func newSandboxIO(log io.WriteCloser, stdin bool) *sandboxIO {
+	s := &sandboxIO{
+		log:     log,
+		clients: make(map[int]*attachedClient),
+	}
+	if stdin {
+		s.stdinR, s.stdinW = io.Pipe()
+	}
+	return s
+}
+
+// Stdin returns the reader handed to containerd, nil when stdin is disabled.
+func (s *sandboxIO) Stdin() io.Reader {
+	if s.stdinR == nil {
+		return nil
+	}
+	return s.stdinR
+}
+
+// Stdout returns the writer handed to containerd for stdout.
+func (s *sandboxIO) Stdout() io.Writer {
+	return &fanoutWriter{s: s}
+}
+
+// Stderr returns the writer handed to containerd for stderr.
+func (s *sandboxIO) Stderr() io.Writer {
+	return &fanoutWriter{s: s, stderr: true}
+}
+
+// HasStdin reports whether the main process stdin can be written to.
+func (s *sandboxIO) HasStdin() bool {
+	return s.stdinW != nil
+}
+
+// Attach registers output writers and returns a channel that receives the reason when
+// the client is dropped, because a write failed or it fell too far behind, and a
+// function that removes the client again after flushing its buffered output.
+func (s *sandboxIO) Attach(stdout, stderr io.Writer) (<-chan error, func()) {
+	c := &attachedClient{
+		stdout:   stdout,
+		stderr:   stderr,
+		chunks:   make(chan outputChunk, attachBufferChunks),
+		dropped:  make(chan error, 1),
+		finished: make(chan struct{}),
+	}
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.clients[id] = c
+	s.mu.Unlock()
+	go c.run(s, id)
+
+	return c.dropped, func() {
+		s.mu.Lock()
+		if s.clients[id] == c {
+			delete(s.clients, id)
+			close(c.chunks)
+		}
+		s.mu.Unlock()
+		<-c.finished
+	}
+}
+
+// dropLocked disconnects a client for reason, its writer goroutine exits once the
+// buffered output is consumed. Callers hold s.mu.
+func (s *sandboxIO) dropLocked(id int, reason error) {
+	c, ok := s.clients[id]
+	if !ok {
+		return
+	}
+	delete(s.clients, id)
+	close(c.chunks)
+	c.dropped <- reason
+}
+
+// CopyStdin forwards r to the main process stdin until r is drained. EOF on r only
+// detaches the client, the process stdin stays open for later attaches.
+func (s *sandboxIO) CopyStdin(r io.Reader) error {
+	if s.stdinW == nil {
+		return ErrStdinNotEnabled
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			s.stdinMu.Lock()
+			_, werr := s.stdinW.Write(buf[:n])
+			s.stdinMu.Unlock()
+			if werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Close releases the log sink and unblocks the stdin copier.
+func (s *sandboxIO) Close() error {
+	if s.stdinW != nil {
+		s.stdinW.Close()
+	}
+	return s.log.Close()
+}
+
+type fanoutWriter struct {
+	s      *sandboxIO
+	stderr bool
+}
+
+// Write never fails and never waits for a client: output is queued to every attached
+// client, a client whose buffer is full is dropped so it cannot stall the container.
+func (w *fanoutWriter) Write(p []byte) (int, error) {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+	_, _ = w.s.log.Write(p)
+	if len(w.s.clients) == 0 {
+		return len(p), nil
+	}
+	// containerd 会复用 p，客户端异步写出，需要复制一份
+	chunk := outputChunk{data: bytes.Clone(p), stderr: w.stderr}
+	for id, c := range w.s.clients {
+		select {
+		case c.chunks <- chunk:
+		default:
+			w.s.dropLocked(id, ErrAttachTooSlow)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *nopWriteCloser) Close() error {
+	w.closed = true
+	return nil
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("client gone")
+}
+
+// blockingWriter blocks every write until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+// signalWriter records writes and signals each one on wrote.
+type signalWriter struct {
+	bytes.Buffer
+	wrote chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	w.wrote <- struct{}{}
+	return n, err
+}
+
+// ============================================================================
+// 1. Output fan-out
+// ============================================================================
+
+func TestSandboxIO_FanoutToLogAndClients(t *testing.T) {
+	// SIO-01: Output always reaches the log and every attached client
+	log := &nopWriteCloser{}
+	sbIO := newSandboxIO(log, false)
+
+	var out, errOut bytes.Buffer
+	_, detach := sbIO.Attach(&out, &errOut)
+
+	sbIO.Stdout().Write([]byte("hello "))
+	sbIO.Stderr().Write([]byte("oops"))
+	// detach 等待缓冲的输出写完
+	detach()
+
+	assert.Equal(t, "hello oops", log.String())
+	assert.Equal(t, "hello ", out.String())
+	assert.Equal(t, "oops", errOut.String())
+
+	sbIO.Stdout().Write([]byte("after"))
+	assert.Equal(t, "hello ", out.String(), "Detached client should not receive output")
+	assert.Equal(t, "hello oopsafter", log.String())
+}
+
+func TestSandboxIO_BrokenClientDropped(t *testing.T) {
+	// SIO-02: A failing client is dropped and never fails the container write
+	log := &nopWriteCloser{}
+	sbIO := newSandboxIO(log, false)
+	dropped, detach := sbIO.Attach(failingWriter{}, nil)
+
+	n, err := sbIO.Stdout().Write([]byte("data"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.EqualError(t, <-dropped, "client gone")
+	detach()
+	assert.Empty(t, sbIO.clients)
+}
+
+func TestSandboxIO_SlowClientDropped(t *testing.T) {
+	// SIO-05: A client that stops reading neither blocks the container nor other clients
+	log := &nopWriteCloser{}
+	sbIO := newSandboxIO(log, false)
+	stuck := &blockingWriter{release: make(chan struct{})}
+	slowDropped, slowDetach := sbIO.Attach(stuck, nil)
+	out := &signalWriter{wrote: make(chan struct{}, 1)}
+	_, detach := sbIO.Attach(out, nil)
+
+	// 每次写入后等待正常客户端写出，只有卡住的客户端会写满缓冲
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < attachBufferChunks+2; i++ {
+			sbIO.Stdout().Write([]byte("x"))
+			<-out.wrote
+		}
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Container output is blocked by a slow client")
+	}
+
+	assert.ErrorIs(t, <-slowDropped, ErrAttachTooSlow)
+	close(stuck.release)
+	slowDetach()
+	detach()
+	assert.Equal(t, attachBufferChunks+2, out.Len(), "Other clients receive all output")
+	assert.Equal(t, attachBufferChunks+2, log.Len())
+	assert.Empty(t, sbIO.clients)
+}
+
+// ============================================================================
+// 2. Stdin
+// ============================================================================
+
+func TestSandboxIO_StdinDisabled(t *testing.T) {
+	// SIO-03: Without stdin the containerd reader is nil and copying is rejected
+	sbIO := newSandboxIO(&nopWriteCloser{}, false)
+
+	assert.Nil(t, sbIO.Stdin())
+	assert.False(t, sbIO.HasStdin())
+	assert.ErrorIs(t, sbIO.CopyStdin(strings.NewReader("x")), ErrStdinNotEnabled)
+}
+
+func TestSandboxIO_StdinSurvivesDetach(t *testing.T) {
+	// SIO-04: EOF from one client does not close the process stdin
+	log := &nopWriteCloser{}
+	sbIO := newSandboxIO(log, true)
+	require.True(t, sbIO.HasStdin())
+
+	received := make(chan string, 1)
+	go func() {
+		data, _ := io.ReadAll(sbIO.Stdin())
+		received <- string(data)
+	}()
+
+	require.NoError(t, sbIO.CopyStdin(strings.NewReader("first ")))
+	require.NoError(t, sbIO.CopyStdin(strings.NewReader("second")))
+
+	require.NoError(t, sbIO.Close())
+	assert.Equal(t, "first second", <-received)
+	assert.True(t, log.closed)
+}
@@ -18,10 +18,34 @@ type SandboxManager struct {
 	mu       sync.RWMutex
 	runtime  Runtime
 	capacity int
+	// allocatableCPU（毫核）与 allocatableMemory（字节）来自 Agent 容器的 limits，0 表示不限制
+	allocatableCPU    int64
+	allocatableMemory int64
 	// sandboxes  sandboxID -> metadata
 	sandboxes map[string]*SandboxMetadata
+	// 重启退避：初始 restartBackoff，每次翻倍至 maxRestartBackoff，与 kubelet 一致
+	restartBackoff    time.Duration
+	maxRestartBackoff time.Duration
+	// readTraffic 读取各本地端口的 TCP 流量，用于空闲检测，测试中可替换
+	readTraffic func() (map[uint16]portTraffic, error)
+	// probeHost 为 HTTP/TCP 就绪探测的目标地址，sandbox 与 Agent 共享网络命名空间
+	probeHost string
+
+	// subMu 保护 subscribers：状态变化事件的订阅者（watch 流），锁顺序 mu -> subMu
+	subMu       sync.Mutex
+	subscribers map[int]chan api.AgentEvent
+	nextSubID   int
 }
 
+const (
+	defaultRestartBackoff    = 10 * time.Second
+	defaultMaxRestartBackoff = 5 * time.Minute
+	// restartBackoffReset 主进程稳定运行超过该时长后，退避重新从初始值开始
+	restartBackoffReset = 10 * time.Minute
+	// subscriberBuffer 订阅者事件缓冲，写满说明订阅者过慢，关闭其通道让其重新 watch
+	subscriberBuffer = 256
+)
+
 func NewSandboxManager(runtime Runtime) *SandboxManager {
 	capVal := 5
 	if capStr := os.Getenv("AGENT_CAPACITY"); capStr != "" {
@@ -30,13 +54,41 @@ func NewSandboxManager(runtime Runtime) *SandboxManager {
 		}
 	}
 	return &SandboxManager{
-		runtime:   runtime,
-		capacity:  capVal,
-		sandboxes: make(map[string]*SandboxMetadata),
+		runtime:           runtime,
+		capacity:          capVal,
+		allocatableCPU:    envInt64("CPU_LIMIT"),
+		allocatableMemory: envInt64("MEMORY_LIMIT"),
+		sandboxes:         make(map[string]*SandboxMetadata),
+		restartBackoff:    defaultRestartBackoff,
+		maxRestartBackoff: defaultMaxRestartBackoff,
+		readTraffic:       readTCPTraffic,
+		probeHost:         probeHost(),
+		subscribers:       make(map[int]chan api.AgentEvent),
+	}
+}
+
+// probeHost returns the agent pod IP, which also reaches gVisor sandboxes with their own
+// network stack, or the loopback address outside a pod.
+func probeHost() string {
+	if ip := os.Getenv("POD_IP"); ip != "" {
+		return ip
 	}
+	return "127.0.0.1"
+}
+
+// envInt64 reads a non-negative integer from the environment, 0 when unset or invalid.
+func envInt64(key string) int64 {
+	v, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
 }
 
 func (m *SandboxManager) CreateSandbox(ctx context.Context, spec *api.SandboxSpec) (*api.CreateSandboxResponse, error) {
+	if err := validateProbe(spec.ReadinessProbe); err != nil {
+		return &api.CreateSandboxResponse{Success: false, Message: err.Error()}, err
+	}
 	m.mu.Lock()
 	if _, exists := m.sandboxes[spec.SandboxID]; exists {
 		m.mu.Unlock()
@@ -53,7 +105,11 @@ func (m *SandboxManager) CreateSandbox(ctx context.Context, spec *api.SandboxSpe
 	m.mu.Unlock()
 
 	createdAt := time.Now().Unix()
-	metadata, err := m.runtime.CreateSandbox(ctx, spec)
+	create := m.runtime.CreateSandbox
+	if spec.Checkpoint != "" {
+		create = m.runtime.RestoreSandbox
+	}
+	metadata, err := create(ctx, spec)
 	if err != nil {
 		// Clean up the "creating" placeholder on failure
 		m.mu.Lock()
@@ -65,11 +121,18 @@ func (m *SandboxManager) CreateSandbox(ctx context.Context, spec *api.SandboxSpe
 			Message: fmt.Sprintf("create failed: %v", err),
 		}, err
 	}
+	watchCtx, stopWatch := context.WithCancel(context.Background())
 	metadata.Phase = "running"
+	metadata.stopWatch = stopWatch
 	m.mu.Lock()
 	m.sandboxes[spec.SandboxID] = metadata
+	m.touchLocked(spec.SandboxID, metadata, time.Now())
 	m.mu.Unlock()
-	klog.InfoS("Created sandbox", "sandbox", spec.SandboxID, "image", spec.Image)
+	go m.watchSandbox(watchCtx, spec.SandboxID)
+	if spec.ReadinessProbe != nil {
+		go m.probeReadiness(watchCtx, spec.SandboxID, spec.ReadinessProbe)
+	}
+	klog.InfoS("Created sandbox", "sandbox", spec.SandboxID, "image", spec.Image, "checkpoint", spec.Checkpoint)
 	return &api.CreateSandboxResponse{
 		Success:   true,
 		SandboxID: spec.SandboxID,
@@ -77,11 +140,49 @@ func (m *SandboxManager) CreateSandbox(ctx context.Context, spec *api.SandboxSpe
 	}, nil
 }
 
+// Recover rebuilds the sandbox table from the runtime after an agent restart, so that the
+// sandboxes created by the previous agent process are reported, deletable and watched again.
+func (m *SandboxManager) Recover(ctx context.Context) error {
+	recovered, err := m.runtime.RecoverSandboxes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, meta := range recovered {
+		var watchCtx context.Context
+		m.mu.Lock()
+		if _, exists := m.sandboxes[meta.SandboxID]; exists {
+			m.mu.Unlock()
+			continue
+		}
+		if meta.Phase == "running" || meta.Phase == "paused" {
+			watchCtx, meta.stopWatch = context.WithCancel(context.Background())
+		}
+		m.sandboxes[meta.SandboxID] = meta
+		// 上一个 Agent 进程观察到的活动已丢失，空闲时间从接管时重新计算
+		m.touchLocked(meta.SandboxID, meta, time.Now())
+		m.mu.Unlock()
+		if watchCtx != nil {
+			go m.watchSandbox(watchCtx, meta.SandboxID)
+			if meta.ReadinessProbe != nil {
+				go m.probeReadiness(watchCtx, meta.SandboxID, meta.ReadinessProbe)
+			}
+		}
+		klog.InfoS("Recovered sandbox", "sandbox", meta.SandboxID, "claim", meta.ClaimName, "phase", meta.Phase)
+	}
+	klog.InfoS("Sandbox recovery completed", "count", len(recovered))
+	return nil
+}
+
 func (m *SandboxManager) DeleteSandbox(sandboxID string) (*api.DeleteSandboxResponse, error) {
 	klog.InfoS("[DEBUG-AGENT] DeleteSandbox ENTER", "sandboxID", sandboxID)
 	m.mu.Lock()
 	sandbox, ok := m.sandboxes[sandboxID]
-	if ok && sandbox.Phase == "terminating" {
+	if !ok {
+		// Agent 重启后 Controller 重试删除等情况下 sandbox 已不存在，视为删除成功
+		m.mu.Unlock()
+		return &api.DeleteSandboxResponse{Success: true}, nil
+	}
+	if sandbox.Phase == "terminating" {
 		m.mu.Unlock()
 		klog.InfoS("[DEBUG-AGENT] DeleteSandbox: already terminating, idempotent", "sandboxID", sandboxID)
 		return &api.DeleteSandboxResponse{
@@ -89,6 +190,10 @@ func (m *SandboxManager) DeleteSandbox(sandboxID string) (*api.DeleteSandboxResp
 		}, nil
 	}
 	sandbox.Phase = "terminating"
+	if sandbox.stopWatch != nil {
+		sandbox.stopWatch()
+	}
+	m.publishLocked(sandboxID, sandbox)
 	m.mu.Unlock()
 	klog.InfoS("[DEBUG-AGENT] DeleteSandbox: marked terminating, starting asyncDelete", "sandboxID", sandboxID)
 	go m.asyncDelete(sandboxID)
@@ -112,11 +217,239 @@ func (m *SandboxManager) asyncDelete(sandboxID string) {
 	defer m.mu.Unlock()
 	// Remove from sandboxes map after deletion completes
 	delete(m.sandboxes, sandboxID)
+	m.publishLocked(sandboxID, nil)
 	klog.InfoS("[DEBUG-AGENT] asyncDelete: DONE, sandbox removed from sandboxes",
 		"sandboxID", sandboxID)
 }
 
+// watchSandbox records every exit of the sandbox main process and restarts it according
+// to the RestartPolicy, until the sandbox is deleted or reaches a final phase.
+func (m *SandboxManager) watchSandbox(ctx context.Context, sandboxID string) {
+	backoff := m.restartBackoff
+	for {
+		startedAt := time.Now()
+		exit, err := m.runtime.WaitSandbox(ctx, sandboxID)
+		if err != nil {
+			if ctx.Err() == nil {
+				klog.ErrorS(err, "Failed to wait for sandbox exit", "sandbox", sandboxID)
+			}
+			return
+		}
+		if !m.recordExit(sandboxID, exit) {
+			return
+		}
+
+		if time.Since(startedAt) >= restartBackoffReset {
+			backoff = m.restartBackoff
+		}
+		klog.InfoS("Restarting sandbox after backoff", "sandbox", sandboxID, "backoff", backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, m.maxRestartBackoff)
+
+		err = m.runtime.RestartSandbox(ctx, sandboxID)
+		m.mu.Lock()
+		if meta, ok := m.sandboxes[sandboxID]; ok && meta.Phase == "restarting" {
+			if err != nil {
+				meta.Phase = "failed"
+			} else {
+				meta.Phase = "running"
+				meta.RestartCount++
+				meta.lastActivity = time.Now()
+				meta.reportedActivity = meta.lastActivity
+			}
+			m.publishLocked(sandboxID, meta)
+		}
+		m.mu.Unlock()
+		if err != nil {
+			klog.ErrorS(err, "Failed to restart sandbox", "sandbox", sandboxID)
+			return
+		}
+	}
+}
+
+// recordExit stores the exit status and reports whether the main process should be restarted.
+func (m *SandboxManager) recordExit(sandboxID string, exit *ExitStatus) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	meta, ok := m.sandboxes[sandboxID]
+	if !ok || meta.Phase == "terminating" {
+		return false
+	}
+
+	meta.ExitCode = exit.ExitCode
+	meta.ExitedAt = exit.ExitedAt.Unix()
+	meta.ready = false
+	switch {
+	case exit.OOMKilled:
+		meta.Reason = api.ReasonOOMKilled
+	case exit.ExitCode == 0:
+		meta.Reason = api.ReasonCompleted
+	default:
+		meta.Reason = api.ReasonError
+	}
+	klog.InfoS("Sandbox main process exited", "sandbox", sandboxID, "exitCode", exit.ExitCode,
+		"reason", meta.Reason, "restartPolicy", meta.RestartPolicy, "restartCount", meta.RestartCount)
+
+	restart := shouldRestart(meta.RestartPolicy, exit.ExitCode)
+	switch {
+	case restart:
+		meta.Phase = "restarting"
+	case exit.ExitCode == 0:
+		meta.Phase = "succeeded"
+	default:
+		meta.Phase = "failed"
+	}
+	m.publishLocked(sandboxID, meta)
+	return restart
+}
+
+// shouldRestart applies the restart policy to an exit code, an empty policy means Never.
+func shouldRestart(policy api.RestartPolicy, exitCode int32) bool {
+	switch policy {
+	case api.RestartPolicyAlways:
+		return true
+	case api.RestartPolicyOnFailure:
+		return exitCode != 0
+	default:
+		return false
+	}
+}
+
+// Exec runs a process inside a running sandbox and returns its exit code.
+func (m *SandboxManager) Exec(ctx context.Context, sandboxID string, opts *ExecOptions) (int, error) {
+	if !m.IsRunning(sandboxID) {
+		return -1, ErrSandboxNotFound
+	}
+	defer m.beginSession(sandboxID)()
+	return m.runtime.Exec(ctx, sandboxID, opts)
+}
+
+// Attach connects to the main process of a running sandbox and returns its exit code once it exits.
+func (m *SandboxManager) Attach(ctx context.Context, sandboxID string, opts *AttachOptions) (int, error) {
+	if !m.IsRunning(sandboxID) {
+		return -1, ErrSandboxNotFound
+	}
+	defer m.beginSession(sandboxID)()
+	return m.runtime.Attach(ctx, sandboxID, opts)
+}
+
+// Pause freezes all processes of a running sandbox, which keeps its resources on the
+// agent. Pausing a paused sandbox is a no-op.
+func (m *SandboxManager) Pause(ctx context.Context, sandboxID string) error {
+	return m.setPaused(ctx, sandboxID, "running", "paused", m.runtime.Pause)
+}
+
+// Resume thaws a paused sandbox. Resuming a running sandbox is a no-op.
+func (m *SandboxManager) Resume(ctx context.Context, sandboxID string) error {
+	return m.setPaused(ctx, sandboxID, "paused", "running", m.runtime.Resume)
+}
+
+// setPaused moves the sandbox from phase from to phase to by freezing or thawing it.
+// The sandbox is marked as transitioning while the runtime is called without the lock,
+// a freeze can hang on processes in uninterruptible sleep.
+func (m *SandboxManager) setPaused(ctx context.Context, sandboxID, from, to string, apply func(context.Context, string) error) error {
+	m.mu.Lock()
+	meta, ok := m.sandboxes[sandboxID]
+	if !ok {
+		m.mu.Unlock()
+		return ErrSandboxNotFound
+	}
+	if meta.transitioning {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: sandbox %s is being paused or resumed", ErrInvalidPhase, sandboxID)
+	}
+	if meta.Phase == to {
+		m.mu.Unlock()
+		return nil
+	}
+	if meta.Phase != from {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: sandbox %s is %s", ErrInvalidPhase, sandboxID, meta.Phase)
+	}
+	meta.transitioning = true
+	m.mu.Unlock()
+
+	err := apply(ctx, sandboxID)
+
+	m.mu.Lock()
+	meta.transitioning = false
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	if current, ok := m.sandboxes[sandboxID]; !ok || current != meta || meta.Phase != from {
+		// 期间主进程退出或 sandbox 开始删除，不覆盖新的阶段
+		phase := meta.Phase
+		m.mu.Unlock()
+		if to == "paused" {
+			// 冻结的进程收不到停止信号，重新解冻
+			if err := m.runtime.Resume(context.WithoutCancel(ctx), sandboxID); err != nil {
+				klog.V(4).InfoS("Failed to thaw sandbox that changed while pausing", "sandbox", sandboxID, "err", err)
+			}
+		}
+		return fmt.Errorf("%w: sandbox %s changed to %s while being paused or resumed", ErrInvalidPhase, sandboxID, phase)
+	}
+	meta.Phase = to
+	if to == "running" {
+		// 恢复视为活动，否则空闲策略会立即再次暂停
+		meta.lastActivity = time.Now()
+		meta.reportedActivity = meta.lastActivity
+	}
+	m.publishLocked(sandboxID, meta)
+	m.mu.Unlock()
+	klog.InfoS("Sandbox phase changed", "sandbox", sandboxID, "phase", to)
+	return nil
+}
+
+// Checkpoint saves a running or paused sandbox as the checkpoint image ref, the sandbox
+// keeps its phase.
+func (m *SandboxManager) Checkpoint(ctx context.Context, sandboxID, ref string, push bool, creds *api.RegistryCredentials) error {
+	m.mu.RLock()
+	meta, ok := m.sandboxes[sandboxID]
+	phase, tty := "", false
+	if ok {
+		phase, tty = meta.Phase, meta.TTY
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return ErrSandboxNotFound
+	}
+	if phase != "running" && phase != "paused" {
+		return fmt.Errorf("%w: sandbox %s is %s", ErrInvalidPhase, sandboxID, phase)
+	}
+	// 伪终端的另一端在 Agent 进程中，CRIU 无法转储
+	if tty {
+		return fmt.Errorf("%w: sandbox %s has a TTY", ErrCheckpointUnsupported, sandboxID)
+	}
+	// checkpoint 需要转储全部内存、可能推送镜像，耗时较长，不持有锁
+	if err := m.runtime.Checkpoint(ctx, sandboxID, ref, push, creds); err != nil {
+		return err
+	}
+	klog.InfoS("Checkpointed sandbox", "sandbox", sandboxID, "ref", ref, "push", push)
+	return nil
+}
+
+// DeleteCheckpoint removes a local checkpoint image.
+func (m *SandboxManager) DeleteCheckpoint(ctx context.Context, ref string) error {
+	return m.runtime.DeleteCheckpoint(ctx, ref)
+}
+
+// IsRunning reports whether the sandbox is known to this agent and in running phase.
+func (m *SandboxManager) IsRunning(sandboxID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sandbox, ok := m.sandboxes[sandboxID]
+	return ok && sandbox.Phase == "running"
+}
+
 func (m *SandboxManager) GetLogs(ctx context.Context, sandboxID string, follow bool, w io.Writer) error {
+	defer m.beginSession(sandboxID)()
 	return m.runtime.GetSandboxLogs(ctx, sandboxID, follow, w)
 }
 func (m *SandboxManager) ListImages(ctx context.Context) ([]string, error) {
@@ -127,27 +460,88 @@ func (m *SandboxManager) GetCapacity() int {
 	return m.capacity
 }
 
+// GetAllocatable returns the CPU (millicores) and memory (bytes) available to sandboxes, 0 means unlimited.
+func (m *SandboxManager) GetAllocatable() (cpu, memory int64) {
+	return m.allocatableCPU, m.allocatableMemory
+}
+
 func (m *SandboxManager) GetSandboxStatuses(ctx context.Context) []api.SandboxStatus {
+	// 先在锁内拷贝元数据，运行时查询放到锁外，避免慢速 containerd 调用阻塞创建/删除
 	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	result := make([]api.SandboxStatus, 0)
-
-	// Add active sandboxes
+	result := make([]api.SandboxStatus, 0, len(m.sandboxes))
 	for sandboxID, meta := range m.sandboxes {
-		runtimeStatus, _ := m.runtime.GetSandboxStatus(ctx, sandboxID)
-		result = append(result, api.SandboxStatus{
-			SandboxID: sandboxID,
-			ClaimUID:  meta.ClaimUID,
-			Phase:     meta.Phase,
-			Message:   runtimeStatus,
-			CreatedAt: meta.CreatedAt,
-		})
+		result = append(result, sandboxStatusOf(sandboxID, meta))
 	}
+	m.mu.RUnlock()
 
+	for i := range result {
+		result[i].Message, _ = m.runtime.GetSandboxStatus(ctx, result[i].SandboxID)
+	}
 	return result
 }
 
+// sandboxStatusOf builds the reported status from the metadata, without the runtime message.
+func sandboxStatusOf(sandboxID string, meta *SandboxMetadata) api.SandboxStatus {
+	status := api.SandboxStatus{
+		SandboxID:    sandboxID,
+		ClaimUID:     meta.ClaimUID,
+		ClaimName:    meta.ClaimName,
+		Phase:        meta.Phase,
+		CreatedAt:    meta.CreatedAt,
+		ExitCode:     meta.ExitCode,
+		ExitedAt:     meta.ExitedAt,
+		Reason:       meta.Reason,
+		RestartCount: meta.RestartCount,
+		Ready:        meta.Phase == "running" && (meta.ReadinessProbe == nil || meta.ready),
+	}
+	if !meta.reportedActivity.IsZero() {
+		status.LastActivityAt = meta.reportedActivity.Unix()
+	}
+	return status
+}
+
+// Subscribe returns a channel that receives an event for every sandbox state change,
+// and a function to unsubscribe. The channel is closed when the subscriber falls
+// behind; the subscriber must then resync from GetSandboxStatuses.
+func (m *SandboxManager) Subscribe() (<-chan api.AgentEvent, func()) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	id := m.nextSubID
+	m.nextSubID++
+	ch := make(chan api.AgentEvent, subscriberBuffer)
+	m.subscribers[id] = ch
+	return ch, func() {
+		m.subMu.Lock()
+		defer m.subMu.Unlock()
+		if ch, ok := m.subscribers[id]; ok {
+			delete(m.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+// publishLocked notifies subscribers of a sandbox change, a nil meta means the sandbox
+// was removed. Must be called with m.mu held so that events follow state order.
+func (m *SandboxManager) publishLocked(sandboxID string, meta *SandboxMetadata) {
+	ev := api.AgentEvent{Type: api.AgentEventSandboxDeleted, Sandbox: &api.SandboxStatus{SandboxID: sandboxID}}
+	if meta != nil {
+		status := sandboxStatusOf(sandboxID, meta)
+		ev = api.AgentEvent{Type: api.AgentEventSandbox, Sandbox: &status}
+	}
+
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	for id, ch := range m.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			klog.InfoS("Status subscriber too slow, closing it", "subscriber", id)
+			delete(m.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
 func (m *SandboxManager) Close() error {
 	return m.runtime.Close()
 }
@@ -3,9 +3,17 @@ package runtime
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,6 +39,20 @@ type MockRuntime struct {
 	deleteCalled   bool
 	closeCalled    bool
 	getStatusCalls map[string]int
+	execCalls      [][]string
+	execExitCode   int
+	exits          map[string]chan *ExitStatus
+	restartCalls   map[string]int
+	restartError   error
+	recovered      []*SandboxMetadata
+	recoverError   error
+	paused         map[string]bool
+	pauseError     error
+	pauseGate      chan struct{}
+	pauseEntered   chan struct{}
+	restored       map[string]string
+	checkpoints    map[string]string
+	cpuUsage       map[string]time.Duration
 }
 
 // NewMockRuntime creates a new mock runtime for testing.
@@ -40,6 +62,12 @@ func NewMockRuntime() *MockRuntime {
 		containers:     make(map[string]string),
 		listImages:     []string{"alpine:latest", "nginx:latest"},
 		getStatusCalls: make(map[string]int),
+		exits:          make(map[string]chan *ExitStatus),
+		restartCalls:   make(map[string]int),
+		paused:         make(map[string]bool),
+		restored:       make(map[string]string),
+		checkpoints:    make(map[string]string),
+		cpuUsage:       make(map[string]time.Duration),
 	}
 }
 
@@ -65,12 +93,21 @@ func (m *MockRuntime) CreateSandbox(ctx context.Context, spec *api.SandboxSpec)
 		Phase:       "created",
 		CreatedAt:   time.Now().Unix(),
 	}
-	m.sandboxes[spec.SandboxID] = metadata
+	// 保存副本，返回的元数据归 SandboxManager 所有，由其在自己的锁下修改
+	stored := *metadata
+	m.sandboxes[spec.SandboxID] = &stored
 	m.containers[spec.SandboxID] = metadata.ContainerID
 
 	return metadata, nil
 }
 
+func (m *MockRuntime) RestoreSandbox(ctx context.Context, spec *api.SandboxSpec) (*SandboxMetadata, error) {
+	m.mu.Lock()
+	m.restored[spec.SandboxID] = spec.Checkpoint
+	m.mu.Unlock()
+	return m.CreateSandbox(ctx, spec)
+}
+
 func (m *MockRuntime) DeleteSandbox(ctx context.Context, sandboxID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -105,6 +142,114 @@ func (m *MockRuntime) GetSandboxLogs(ctx context.Context, sandboxID string, foll
 	return nil
 }
 
+func (m *MockRuntime) Exec(ctx context.Context, sandboxID string, opts *ExecOptions) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sandboxes[sandboxID]; !ok {
+		return -1, ErrSandboxNotFound
+	}
+	m.execCalls = append(m.execCalls, opts.Command)
+	if opts.Stdout != nil {
+		fmt.Fprintf(opts.Stdout, "%s", strings.Join(opts.Command, " "))
+	}
+	return m.execExitCode, nil
+}
+
+func (m *MockRuntime) Attach(ctx context.Context, sandboxID string, opts *AttachOptions) (int, error) {
+	m.mu.Lock()
+	_, ok := m.sandboxes[sandboxID]
+	m.mu.Unlock()
+	if !ok {
+		return -1, ErrSandboxNotFound
+	}
+	<-ctx.Done()
+	return -1, ctx.Err()
+}
+
+func (m *MockRuntime) WaitSandbox(ctx context.Context, sandboxID string) (*ExitStatus, error) {
+	select {
+	case exit := <-m.exitChan(sandboxID):
+		return exit, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *MockRuntime) RestartSandbox(ctx context.Context, sandboxID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restartCalls[sandboxID]++
+	return m.restartError
+}
+
+func (m *MockRuntime) Pause(ctx context.Context, sandboxID string) error {
+	m.mu.Lock()
+	gate, entered := m.pauseGate, m.pauseEntered
+	m.mu.Unlock()
+	if gate != nil {
+		// 模拟冻结卡在 D 状态的进程上
+		entered <- struct{}{}
+		<-gate
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pauseError != nil {
+		return m.pauseError
+	}
+	m.paused[sandboxID] = true
+	return nil
+}
+
+// BlockPause makes Pause wait until release is called, entered receives once Pause
+// has been called.
+func (m *MockRuntime) BlockPause() (entered <-chan struct{}, release func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pauseGate = make(chan struct{})
+	m.pauseEntered = make(chan struct{}, 1)
+	gate := m.pauseGate
+	return m.pauseEntered, func() { close(gate) }
+}
+
+func (m *MockRuntime) Resume(ctx context.Context, sandboxID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.paused, sandboxID)
+	return nil
+}
+
+func (m *MockRuntime) CPUUsage(ctx context.Context, sandboxID string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cpuUsage[sandboxID], nil
+}
+
+func (m *MockRuntime) Checkpoint(ctx context.Context, sandboxID, ref string, push bool, creds *api.RegistryCredentials) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[ref] = sandboxID
+	return nil
+}
+
+func (m *MockRuntime) DeleteCheckpoint(ctx context.Context, ref string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.checkpoints, ref)
+	return nil
+}
+
+func (m *MockRuntime) RecoverSandboxes(ctx context.Context) ([]*SandboxMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.recoverError != nil {
+		return nil, m.recoverError
+	}
+	for _, meta := range m.recovered {
+		m.sandboxes[meta.SandboxID] = meta
+	}
+	return m.recovered, nil
+}
+
 func (m *MockRuntime) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -114,6 +259,13 @@ func (m *MockRuntime) Close() error {
 
 // Helper methods for testing
 
+func (m *MockRuntime) SetRecovered(recovered []*SandboxMetadata, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recovered = recovered
+	m.recoverError = err
+}
+
 func (m *MockRuntime) SetCreateError(err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -163,6 +315,72 @@ func (m *MockRuntime) GetStatusCallCount(sandboxID string) int {
 	return m.getStatusCalls[sandboxID]
 }
 
+func (m *MockRuntime) exitChan(sandboxID string) chan *ExitStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch, ok := m.exits[sandboxID]
+	if !ok {
+		ch = make(chan *ExitStatus, 1)
+		m.exits[sandboxID] = ch
+	}
+	return ch
+}
+
+// Exit simulates the sandbox main process exiting with the given status.
+func (m *MockRuntime) Exit(sandboxID string, exit *ExitStatus) {
+	m.exitChan(sandboxID) <- exit
+}
+
+// SetExecExitCode sets the exit code of the following exec calls.
+func (m *MockRuntime) SetExecExitCode(code int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.execExitCode = code
+}
+
+func (m *MockRuntime) GetRestartCalls(sandboxID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restartCalls[sandboxID]
+}
+
+func (m *MockRuntime) SetRestartError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restartError = err
+}
+
+func (m *MockRuntime) IsPaused(sandboxID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused[sandboxID]
+}
+
+func (m *MockRuntime) SetPauseError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pauseError = err
+}
+
+func (m *MockRuntime) GetRestoredFrom(sandboxID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restored[sandboxID]
+}
+
+func (m *MockRuntime) GetCheckpointSandbox(ref string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoints[ref]
+}
+
+// AddCPUUsage simulates the sandbox using CPU time.
+func (m *MockRuntime) AddCPUUsage(sandboxID string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cpuUsage[sandboxID] += d
+}
+
 func (m *MockRuntime) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -289,6 +507,23 @@ func TestNewSandboxManager_InvalidCapacity(t *testing.T) {
 	}
 }
 
+func TestNewSandboxManager_Allocatable(t *testing.T) {
+	// NSM-04: Allocatable resources come from CPU_LIMIT (millicores) and MEMORY_LIMIT (bytes)
+	t.Setenv("CPU_LIMIT", "4000")
+	t.Setenv("MEMORY_LIMIT", "8589934592")
+
+	cpu, memory := NewSandboxManager(NewMockRuntime()).GetAllocatable()
+	assert.Equal(t, int64(4000), cpu)
+	assert.Equal(t, int64(8<<30), memory)
+
+	// Missing or invalid values mean unlimited
+	t.Setenv("CPU_LIMIT", "")
+	t.Setenv("MEMORY_LIMIT", "lots")
+	cpu, memory = NewSandboxManager(NewMockRuntime()).GetAllocatable()
+	assert.Zero(t, cpu)
+	assert.Zero(t, memory)
+}
+
 // ============================================================================
 // 2. TestSandboxManager_CreateSandbox
 // ============================================================================
@@ -516,19 +751,21 @@ func TestSandboxManager_DeleteSandbox_Idempotent(t *testing.T) {
 }
 
 func TestSandboxManager_DeleteSandbox_NonExistent(t *testing.T) {
-	// DS-03: Deleting non-existent sandbox - this is an edge case that triggers a nil pointer panic
-	// Note: The current implementation has a bug where it tries to set Phase on a nil sandbox
-	// In production, DeleteSandbox should only be called on sandboxes that were previously created
-	// This test documents the current buggy behavior
+	// DS-03: Deleting an unknown or already-removed sandbox succeeds without touching the runtime
 	mockRuntime := NewMockRuntime()
 	manager := NewSandboxManager(mockRuntime)
+	events, cancel := manager.Subscribe()
+	defer cancel()
 
-	// The following will panic due to nil pointer dereference in sandbox_manager.go:85
-	// The implementation does: sandbox.Phase = "terminating" even when ok is false
-	// This test verifies this buggy behavior exists
-	assert.Panics(t, func() {
-		manager.DeleteSandbox("non-existent-sandbox")
-	}, "DeleteSandbox should panic when called on non-existent sandbox due to nil pointer bug")
+	resp, err := manager.DeleteSandbox("non-existent-sandbox")
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Empty(t, manager.GetSandboxStatuses(context.Background()))
+	assert.Empty(t, events, "no event should be published for an unknown sandbox")
+	mockRuntime.mu.Lock()
+	defer mockRuntime.mu.Unlock()
+	assert.False(t, mockRuntime.deleteCalled)
 }
 
 func TestSandboxManager_DeleteSandbox_MultipleDeletes(t *testing.T) {
@@ -781,6 +1018,46 @@ func TestSandboxManager_GetLogs(t *testing.T) {
 	assert.NoError(t, err, "GetLogs should succeed")
 }
 
+func TestSandboxManager_Exec(t *testing.T) {
+	// EX-01: Exec delegates to runtime for a running sandbox
+	mockRuntime := NewMockRuntime()
+	manager := NewSandboxManager(mockRuntime)
+
+	ctx := context.Background()
+	_, err := manager.CreateSandbox(ctx, &api.SandboxSpec{SandboxID: "exec-sb", Image: "alpine:latest"})
+	require.NoError(t, err)
+
+	var out strings.Builder
+	code, err := manager.Exec(ctx, "exec-sb", &ExecOptions{Command: []string{"echo", "hi"}, Stdout: &out})
+
+	require.NoError(t, err, "Exec should succeed")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "echo hi", out.String())
+	assert.True(t, manager.IsRunning("exec-sb"))
+}
+
+func TestSandboxManager_Exec_NotFound(t *testing.T) {
+	// EX-02: Exec on an unknown sandbox never reaches the runtime
+	mockRuntime := NewMockRuntime()
+	manager := NewSandboxManager(mockRuntime)
+
+	code, err := manager.Exec(context.Background(), "missing", &ExecOptions{Command: []string{"true"}})
+
+	assert.ErrorIs(t, err, ErrSandboxNotFound)
+	assert.Equal(t, -1, code)
+	assert.Empty(t, mockRuntime.execCalls, "Runtime should not be called")
+}
+
+func TestSandboxManager_Attach_NotFound(t *testing.T) {
+	// EX-03: Attach to an unknown sandbox fails fast
+	manager := NewSandboxManager(NewMockRuntime())
+
+	code, err := manager.Attach(context.Background(), "missing", &AttachOptions{})
+
+	assert.ErrorIs(t, err, ErrSandboxNotFound)
+	assert.Equal(t, -1, code)
+}
+
 // ============================================================================
 // 8. TestSandboxManager_ListImages
 // ============================================================================
@@ -878,3 +1155,583 @@ func TestSandboxManager_AsyncDelete_RuntimeError(t *testing.T) {
 	statuses := manager.GetSandboxStatuses(ctx)
 	assert.Empty(t, statuses, "Sandbox should be completely removed even with runtime error")
 }
+
+// ============================================================================
+// 10. TestSandboxManager_ExitAndRestartPolicy
+// ============================================================================
+
+func newRestartTestManager(t *testing.T, policy api.RestartPolicy, backoff time.Duration) (*SandboxManager, *MockRuntime, string) {
+	t.Helper()
+	mockRuntime := NewMockRuntime()
+	manager := NewSandboxManager(mockRuntime)
+	manager.restartBackoff = backoff
+	manager.maxRestartBackoff = 4 * backoff
+
+	spec := &api.SandboxSpec{SandboxID: "sb-exit", ClaimUID: "claim-exit", Image: "alpine:latest", RestartPolicy: policy}
+	_, err := manager.CreateSandbox(context.Background(), spec)
+	require.NoError(t, err)
+	return manager, mockRuntime, spec.SandboxID
+}
+
+func sandboxStatus(manager *SandboxManager, sandboxID string) api.SandboxStatus {
+	for _, s := range manager.GetSandboxStatuses(context.Background()) {
+		if s.SandboxID == sandboxID {
+			return s
+		}
+	}
+	return api.SandboxStatus{}
+}
+
+func waitForPhase(t *testing.T, manager *SandboxManager, sandboxID, phase string) api.SandboxStatus {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return sandboxStatus(manager, sandboxID).Phase == phase
+	}, time.Second, 5*time.Millisecond, "sandbox should reach phase %s", phase)
+	return sandboxStatus(manager, sandboxID)
+}
+
+func TestSandboxManager_Exit_NeverSucceeded(t *testing.T) {
+	// RP-01: Exit code 0 with the default policy ends in succeeded/Completed without restart
+	manager, mockRuntime, id := newRestartTestManager(t, "", time.Millisecond)
+	exitedAt := time.Unix(1700000000, 0)
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 0, ExitedAt: exitedAt})
+
+	status := waitForPhase(t, manager, id, "succeeded")
+	assert.Equal(t, int32(0), status.ExitCode)
+	assert.Equal(t, exitedAt.Unix(), status.ExitedAt)
+	assert.Equal(t, api.ReasonCompleted, status.Reason)
+	assert.Equal(t, 0, mockRuntime.GetRestartCalls(id))
+	assert.False(t, manager.IsRunning(id))
+}
+
+func TestSandboxManager_Exit_OOMKilled(t *testing.T) {
+	// RP-02: An OOM kill is reported as failed with reason OOMKilled
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 137, ExitedAt: time.Now(), OOMKilled: true})
+
+	status := waitForPhase(t, manager, id, "failed")
+	assert.Equal(t, int32(137), status.ExitCode)
+	assert.Equal(t, api.ReasonOOMKilled, status.Reason)
+}
+
+func TestSandboxManager_Exit_OnFailure(t *testing.T) {
+	// RP-03: OnFailure restarts after a non-zero exit and stops after a clean exit
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyOnFailure, time.Millisecond)
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 1, ExitedAt: time.Now()})
+	require.Eventually(t, func() bool {
+		return sandboxStatus(manager, id).RestartCount == 1
+	}, time.Second, 5*time.Millisecond)
+	status := sandboxStatus(manager, id)
+	assert.Equal(t, "running", status.Phase)
+	assert.Equal(t, api.ReasonError, status.Reason, "Last termination is kept after restart")
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 0, ExitedAt: time.Now()})
+	status = waitForPhase(t, manager, id, "succeeded")
+	assert.Equal(t, int32(1), status.RestartCount)
+	assert.Equal(t, 1, mockRuntime.GetRestartCalls(id))
+}
+
+func TestSandboxManager_Exit_Always(t *testing.T) {
+	// RP-04: Always restarts even after a clean exit
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyAlways, time.Millisecond)
+
+	for i := 1; i <= 3; i++ {
+		mockRuntime.Exit(id, &ExitStatus{ExitCode: 0, ExitedAt: time.Now()})
+		require.Eventually(t, func() bool {
+			return sandboxStatus(manager, id).RestartCount == int32(i)
+		}, time.Second, 5*time.Millisecond)
+	}
+	assert.Equal(t, "running", sandboxStatus(manager, id).Phase)
+	assert.Equal(t, 3, mockRuntime.GetRestartCalls(id))
+}
+
+func TestSandboxManager_Exit_DeleteDuringBackoff(t *testing.T) {
+	// RP-05: Deleting a sandbox in backoff cancels the pending restart
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyAlways, time.Hour)
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 1, ExitedAt: time.Now()})
+	waitForPhase(t, manager, id, "restarting")
+
+	_, err := manager.DeleteSandbox(id)
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, mockRuntime.GetRestartCalls(id))
+}
+
+func TestSandboxManager_Exit_RestartFailure(t *testing.T) {
+	// RP-06: A failed restart leaves the sandbox failed
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyAlways, time.Millisecond)
+	mockRuntime.SetRestartError(errors.New("task create failed"))
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 2, ExitedAt: time.Now()})
+	status := waitForPhase(t, manager, id, "failed")
+	assert.Equal(t, int32(2), status.ExitCode)
+	assert.Equal(t, int32(0), status.RestartCount)
+}
+
+func TestShouldRestart(t *testing.T) {
+	// RP-07: Restart policy decisions per exit code
+	assert.False(t, shouldRestart("", 1))
+	assert.False(t, shouldRestart(api.RestartPolicyNever, 1))
+	assert.False(t, shouldRestart(api.RestartPolicyOnFailure, 0))
+	assert.True(t, shouldRestart(api.RestartPolicyOnFailure, 137))
+	assert.True(t, shouldRestart(api.RestartPolicyAlways, 0))
+}
+
+// ============================================================================
+// 11. TestSandboxManager_Subscribe
+// ============================================================================
+
+func nextEvent(t *testing.T, ch <-chan api.AgentEvent) api.AgentEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		require.True(t, ok, "subscription should be open")
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return api.AgentEvent{}
+	}
+}
+
+func TestSandboxManager_Subscribe_Lifecycle(t *testing.T) {
+	// SUB-01: Subscribers see create, terminate and delete in order
+	manager := NewSandboxManager(NewMockRuntime())
+	events, unsubscribe := manager.Subscribe()
+	defer unsubscribe()
+
+	spec := &api.SandboxSpec{SandboxID: "sb-sub", ClaimUID: "claim-sub", ClaimName: "claim-sub", Image: "alpine:latest"}
+	_, err := manager.CreateSandbox(context.Background(), spec)
+	require.NoError(t, err)
+
+	ev := nextEvent(t, events)
+	assert.Equal(t, api.AgentEventSandbox, ev.Type)
+	require.NotNil(t, ev.Sandbox)
+	assert.Equal(t, "sb-sub", ev.Sandbox.SandboxID)
+	assert.Equal(t, "claim-sub", ev.Sandbox.ClaimName)
+	assert.Equal(t, "running", ev.Sandbox.Phase)
+
+	_, err = manager.DeleteSandbox(spec.SandboxID)
+	require.NoError(t, err)
+
+	ev = nextEvent(t, events)
+	assert.Equal(t, api.AgentEventSandbox, ev.Type)
+	assert.Equal(t, "terminating", ev.Sandbox.Phase)
+
+	ev = nextEvent(t, events)
+	assert.Equal(t, api.AgentEventSandboxDeleted, ev.Type)
+	assert.Equal(t, "sb-sub", ev.Sandbox.SandboxID)
+}
+
+func TestSandboxManager_Subscribe_Exit(t *testing.T) {
+	// SUB-02: Exits are pushed with the termination details
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+	events, unsubscribe := manager.Subscribe()
+	defer unsubscribe()
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 3, ExitedAt: time.Now()})
+	ev := nextEvent(t, events)
+	assert.Equal(t, "failed", ev.Sandbox.Phase)
+	assert.Equal(t, int32(3), ev.Sandbox.ExitCode)
+	assert.Equal(t, api.ReasonError, ev.Sandbox.Reason)
+}
+
+func TestSandboxManager_Subscribe_SlowSubscriberDropped(t *testing.T) {
+	// SUB-03: A subscriber that stops reading is closed instead of blocking the agent
+	manager := NewSandboxManager(NewMockRuntime())
+	events, unsubscribe := manager.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{
+			SandboxID: fmt.Sprintf("sb-%d", i),
+			ClaimUID:  fmt.Sprintf("claim-%d", i),
+			Image:     "alpine:latest",
+		})
+		require.NoError(t, err)
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "channel should be closed after the buffer filled up")
+}
+
+// ============================================================================
+// 12. TestSandboxManager_Recover
+// ============================================================================
+
+func TestSandboxManager_Recover(t *testing.T) {
+	// RC-01: Recovered sandboxes are reported, watched and deletable
+	mockRuntime := NewMockRuntime()
+	mockRuntime.SetRecovered([]*SandboxMetadata{
+		{
+			SandboxSpec: api.SandboxSpec{SandboxID: "sb-live", ClaimUID: "uid-live", ClaimName: "live", RestartPolicy: api.RestartPolicyNever},
+			ContainerID: "sb-live",
+			Phase:       "running",
+			CreatedAt:   1700000000,
+		},
+		{
+			SandboxSpec: api.SandboxSpec{SandboxID: "sb-broken", ClaimName: "broken"},
+			ContainerID: "sb-broken",
+			Phase:       "failed",
+			Reason:      api.ReasonError,
+		},
+	}, nil)
+	manager := NewSandboxManager(mockRuntime)
+	events, unsubscribe := manager.Subscribe()
+	defer unsubscribe()
+
+	require.NoError(t, manager.Recover(context.Background()))
+	assert.Equal(t, api.AgentEventSandbox, nextEvent(t, events).Type)
+	assert.Equal(t, api.AgentEventSandbox, nextEvent(t, events).Type)
+
+	live := sandboxStatus(manager, "sb-live")
+	assert.Equal(t, "running", live.Phase)
+	assert.Equal(t, "uid-live", live.ClaimUID)
+	assert.Equal(t, int64(1700000000), live.CreatedAt)
+	assert.True(t, manager.IsRunning("sb-live"))
+	assert.Equal(t, "failed", sandboxStatus(manager, "sb-broken").Phase)
+
+	// 恢复后的主进程退出同样被记录
+	mockRuntime.Exit("sb-live", &ExitStatus{ExitCode: 1, ExitedAt: time.Now()})
+	waitForPhase(t, manager, "sb-live", "failed")
+
+	_, err := manager.DeleteSandbox("sb-broken")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return sandboxStatus(manager, "sb-broken").SandboxID == ""
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, mockRuntime.HasSandbox("sb-broken"))
+}
+
+func TestSandboxManager_Recover_KeepsExisting(t *testing.T) {
+	// RC-02: Sandboxes already known to the manager are not overwritten
+	mockRuntime := NewMockRuntime()
+	manager := NewSandboxManager(mockRuntime)
+	_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-1", ClaimName: "new", Image: "alpine:latest"})
+	require.NoError(t, err)
+
+	mockRuntime.SetRecovered([]*SandboxMetadata{
+		{SandboxSpec: api.SandboxSpec{SandboxID: "sb-1", ClaimName: "old"}, Phase: "running"},
+	}, nil)
+	require.NoError(t, manager.Recover(context.Background()))
+	assert.Equal(t, "new", sandboxStatus(manager, "sb-1").ClaimName)
+}
+
+func TestSandboxManager_Recover_Error(t *testing.T) {
+	// RC-03: Runtime errors are returned and leave the table empty
+	mockRuntime := NewMockRuntime()
+	mockRuntime.SetRecovered(nil, errors.New("containerd unavailable"))
+	manager := NewSandboxManager(mockRuntime)
+
+	err := manager.Recover(context.Background())
+	require.Error(t, err)
+	assert.Empty(t, manager.GetSandboxStatuses(context.Background()))
+}
+
+// ============================================================================
+// 13. TestSandboxManager_Pause
+// ============================================================================
+
+func TestSandboxManager_Pause(t *testing.T) {
+	// PA-01: Pause freezes a running sandbox and Resume thaws it, both idempotent
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+	events, unsubscribe := manager.Subscribe()
+	defer unsubscribe()
+
+	require.NoError(t, manager.Pause(context.Background(), id))
+	assert.True(t, mockRuntime.IsPaused(id))
+	assert.Equal(t, "paused", nextEvent(t, events).Sandbox.Phase)
+	assert.False(t, manager.IsRunning(id), "Exec and attach are refused while paused")
+	require.NoError(t, manager.Pause(context.Background(), id))
+
+	require.NoError(t, manager.Resume(context.Background(), id))
+	assert.False(t, mockRuntime.IsPaused(id))
+	assert.Equal(t, "running", nextEvent(t, events).Sandbox.Phase)
+	assert.True(t, manager.IsRunning(id))
+	require.NoError(t, manager.Resume(context.Background(), id))
+}
+
+func TestSandboxManager_Pause_Errors(t *testing.T) {
+	// PA-02: Unknown or exited sandboxes cannot be paused, runtime failures keep the phase
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+
+	assert.ErrorIs(t, manager.Pause(context.Background(), "missing"), ErrSandboxNotFound)
+
+	mockRuntime.SetPauseError(errors.New("freezer unavailable"))
+	assert.Error(t, manager.Pause(context.Background(), id))
+	assert.Equal(t, "running", sandboxStatus(manager, id).Phase)
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 0, ExitedAt: time.Now()})
+	waitForPhase(t, manager, id, "succeeded")
+	assert.ErrorIs(t, manager.Pause(context.Background(), id), ErrInvalidPhase)
+	assert.ErrorIs(t, manager.Resume(context.Background(), id), ErrInvalidPhase)
+}
+
+func TestSandboxManager_Pause_DoesNotBlock(t *testing.T) {
+	// PA-03: A hanging freeze neither blocks other calls nor allows a second transition
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+	entered, release := mockRuntime.BlockPause()
+
+	paused := make(chan error, 1)
+	go func() { paused <- manager.Pause(context.Background(), id) }()
+	<-entered
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.True(t, manager.IsRunning(id))
+		assert.Len(t, manager.GetSandboxStatuses(context.Background()), 1)
+		assert.ErrorIs(t, manager.Pause(context.Background(), id), ErrInvalidPhase)
+		assert.ErrorIs(t, manager.Resume(context.Background(), id), ErrInvalidPhase)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sandbox manager is blocked while a sandbox is being paused")
+	}
+
+	release()
+	require.NoError(t, <-paused)
+	assert.Equal(t, "paused", sandboxStatus(manager, id).Phase)
+	assert.True(t, mockRuntime.IsPaused(id))
+}
+
+func TestSandboxManager_Pause_DeletedWhilePausing(t *testing.T) {
+	// PA-04: A sandbox deleted while it is being frozen is thawed again so that it can stop
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+	entered, release := mockRuntime.BlockPause()
+
+	paused := make(chan error, 1)
+	go func() { paused <- manager.Pause(context.Background(), id) }()
+	<-entered
+
+	resp, err := manager.DeleteSandbox(id)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	release()
+	assert.ErrorIs(t, <-paused, ErrInvalidPhase)
+	assert.False(t, mockRuntime.IsPaused(id))
+}
+
+// ============================================================================
+// 14. TestSandboxManager_Checkpoint
+// ============================================================================
+
+func TestSandboxManager_Checkpoint(t *testing.T) {
+	// CP-01: Running and paused sandboxes can be checkpointed, exited and TTY ones cannot
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+
+	require.NoError(t, manager.Checkpoint(context.Background(), id, "checkpoint/1", false, nil))
+	assert.Equal(t, id, mockRuntime.GetCheckpointSandbox("checkpoint/1"))
+
+	require.NoError(t, manager.Pause(context.Background(), id))
+	require.NoError(t, manager.Checkpoint(context.Background(), id, "checkpoint/2", true, nil))
+	assert.Equal(t, "paused", sandboxStatus(manager, id).Phase, "Checkpoint keeps the phase")
+
+	require.NoError(t, manager.DeleteCheckpoint(context.Background(), "checkpoint/1"))
+	assert.Empty(t, mockRuntime.GetCheckpointSandbox("checkpoint/1"))
+
+	assert.ErrorIs(t, manager.Checkpoint(context.Background(), "missing", "checkpoint/3", false, nil), ErrSandboxNotFound)
+	require.NoError(t, manager.Resume(context.Background(), id))
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 0, ExitedAt: time.Now()})
+	waitForPhase(t, manager, id, "succeeded")
+	assert.ErrorIs(t, manager.Checkpoint(context.Background(), id, "checkpoint/3", false, nil), ErrInvalidPhase)
+
+	_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "tty", Image: "alpine:latest", TTY: true})
+	require.NoError(t, err)
+	assert.ErrorIs(t, manager.Checkpoint(context.Background(), "tty", "checkpoint/4", false, nil), ErrCheckpointUnsupported)
+}
+
+func TestSandboxManager_CreateSandbox_Restore(t *testing.T) {
+	// CP-02: A spec with a checkpoint is restored instead of created
+	mockRuntime := NewMockRuntime()
+	manager := NewSandboxManager(mockRuntime)
+
+	_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-1", Image: "alpine:latest", Checkpoint: "checkpoint/1"})
+	require.NoError(t, err)
+	assert.Equal(t, "checkpoint/1", mockRuntime.GetRestoredFrom("sb-1"))
+	assert.True(t, manager.IsRunning("sb-1"))
+
+	_, err = manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-2", Image: "alpine:latest"})
+	require.NoError(t, err)
+	assert.Empty(t, mockRuntime.GetRestoredFrom("sb-2"))
+}
+
+// ============================================================================
+// 15. TestSandboxManager_Activity
+// ============================================================================
+
+// ageActivity moves the activity and the last sample of a sandbox back by d.
+func ageActivity(manager *SandboxManager, sandboxID string, d time.Duration) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	meta := manager.sandboxes[sandboxID]
+	meta.lastActivity = meta.lastActivity.Add(-d)
+	meta.reportedActivity = meta.reportedActivity.Add(-d)
+	meta.sampledAt = meta.sampledAt.Add(-d)
+}
+
+func TestSandboxManager_Activity_Sampling(t *testing.T) {
+	// IA-01: CPU above the idle threshold and traffic on exposed ports count as activity
+	mockRuntime := NewMockRuntime()
+	manager := NewSandboxManager(mockRuntime)
+	traffic := map[uint16]portTraffic{8080: {Sockets: 1, Bytes: 100}, 9090: {Sockets: 3, Bytes: 5000}}
+	manager.readTraffic = func() (map[uint16]portTraffic, error) { return maps.Clone(traffic), nil }
+	_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-1", Image: "alpine:latest", ExposedPorts: []int32{8080}})
+	require.NoError(t, err)
+	created := sandboxStatus(manager, "sb-1").LastActivityAt
+	assert.NotZero(t, created, "Creation counts as activity")
+
+	manager.sampleActivity(context.Background())
+	ageActivity(manager, "sb-1", time.Hour)
+	idleSince := sandboxStatus(manager, "sb-1").LastActivityAt
+	events, unsubscribe := manager.Subscribe()
+	defer unsubscribe()
+
+	// 10 秒内用 100ms CPU（1%），低于阈值；其他端口的流量不计
+	ageActivity(manager, "sb-1", 10*time.Second)
+	mockRuntime.AddCPUUsage("sb-1", 100*time.Millisecond)
+	traffic[9090] = portTraffic{Sockets: 4, Bytes: 9000}
+	manager.sampleActivity(context.Background())
+	assert.Equal(t, idleSince-10, sandboxStatus(manager, "sb-1").LastActivityAt)
+
+	ageActivity(manager, "sb-1", 10*time.Second)
+	mockRuntime.AddCPUUsage("sb-1", time.Second)
+	manager.sampleActivity(context.Background())
+	assert.GreaterOrEqual(t, sandboxStatus(manager, "sb-1").LastActivityAt, created)
+	assert.NotZero(t, nextEvent(t, events).Sandbox.LastActivityAt, "Activity is pushed to subscribers")
+
+	ageActivity(manager, "sb-1", time.Hour)
+	traffic[8080] = portTraffic{Sockets: 1, Bytes: 200}
+	manager.sampleActivity(context.Background())
+	assert.GreaterOrEqual(t, sandboxStatus(manager, "sb-1").LastActivityAt, created)
+
+	// 暂停的 sandbox 不采样，恢复视为活动
+	require.NoError(t, manager.Pause(context.Background(), "sb-1"))
+	ageActivity(manager, "sb-1", time.Hour)
+	mockRuntime.AddCPUUsage("sb-1", time.Minute)
+	manager.sampleActivity(context.Background())
+	assert.Less(t, sandboxStatus(manager, "sb-1").LastActivityAt, created)
+	require.NoError(t, manager.Resume(context.Background(), "sb-1"))
+	assert.GreaterOrEqual(t, sandboxStatus(manager, "sb-1").LastActivityAt, created)
+}
+
+func TestSandboxManager_Activity_Sessions(t *testing.T) {
+	// IA-02: Attach, exec and log sessions keep the sandbox active while they last
+	manager, _, id := newRestartTestManager(t, api.RestartPolicyNever, time.Millisecond)
+	manager.readTraffic = func() (map[uint16]portTraffic, error) { return nil, nil }
+	start := sandboxStatus(manager, id).LastActivityAt
+	ageActivity(manager, id, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		manager.Attach(ctx, id, &AttachOptions{Stdout: io.Discard})
+	}()
+	require.Eventually(t, func() bool {
+		return sandboxStatus(manager, id).LastActivityAt >= start
+	}, time.Second, 5*time.Millisecond, "Attaching counts as activity")
+
+	ageActivity(manager, id, time.Hour)
+	manager.sampleActivity(context.Background())
+	assert.GreaterOrEqual(t, sandboxStatus(manager, id).LastActivityAt, start, "An open session is activity")
+	cancel()
+	<-done
+
+	ageActivity(manager, id, time.Hour)
+	manager.sampleActivity(context.Background())
+	assert.Less(t, sandboxStatus(manager, id).LastActivityAt, start)
+
+	_, err := manager.Exec(context.Background(), id, &ExecOptions{Command: []string{"true"}, Stdout: io.Discard})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, sandboxStatus(manager, id).LastActivityAt, start)
+
+	ageActivity(manager, id, time.Hour)
+	require.NoError(t, manager.GetLogs(context.Background(), id, false, io.Discard))
+	assert.GreaterOrEqual(t, sandboxStatus(manager, id).LastActivityAt, start)
+}
+
+func TestSandboxManager_Readiness_HTTP(t *testing.T) {
+	// RD-01: An HTTP probe makes the sandbox ready once it answers 2xx/3xx and not ready after failures
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || r.Host != "app" || !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	manager := NewSandboxManager(NewMockRuntime())
+	manager.probeHost = "127.0.0.1"
+	_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-1", Image: "nginx", ReadinessProbe: &api.Probe{
+		HTTPGet:          &api.HTTPGetProbe{Port: int32(port), Path: "healthz", Headers: map[string]string{"Host": "app"}},
+		FailureThreshold: 1,
+	}})
+	require.NoError(t, err)
+	status := sandboxStatus(manager, "sb-1")
+	assert.Equal(t, "running", status.Phase)
+	assert.False(t, status.Ready, "Running is not ready before the probe passes")
+
+	healthy.Store(true)
+	require.Eventually(t, func() bool { return sandboxStatus(manager, "sb-1").Ready }, 3*time.Second, 10*time.Millisecond)
+	healthy.Store(false)
+	require.Eventually(t, func() bool { return !sandboxStatus(manager, "sb-1").Ready }, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestSandboxManager_Readiness_Exec(t *testing.T) {
+	// RD-02: An exec probe runs in the sandbox without counting as a session, exit 0 is ready
+	mockRuntime := NewMockRuntime()
+	mockRuntime.SetExecExitCode(1)
+	manager := NewSandboxManager(mockRuntime)
+	_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-1", Image: "python", ReadinessProbe: &api.Probe{
+		Exec: []string{"test", "-f", "/tmp/ready"},
+	}})
+	require.NoError(t, err)
+	ageActivity(manager, "sb-1", time.Hour)
+	idleSince := sandboxStatus(manager, "sb-1").LastActivityAt
+
+	require.Eventually(t, func() bool {
+		mockRuntime.mu.Lock()
+		defer mockRuntime.mu.Unlock()
+		return len(mockRuntime.execCalls) > 0
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, sandboxStatus(manager, "sb-1").Ready)
+	mockRuntime.SetExecExitCode(0)
+	require.Eventually(t, func() bool { return sandboxStatus(manager, "sb-1").Ready }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, idleSince, sandboxStatus(manager, "sb-1").LastActivityAt, "Probes are not activity")
+}
+
+func TestSandboxManager_Readiness_Restart(t *testing.T) {
+	// RD-03: Without a probe a running sandbox is ready; an exit resets it until the restart
+	manager, mockRuntime, id := newRestartTestManager(t, api.RestartPolicyAlways, 50*time.Millisecond)
+	assert.True(t, sandboxStatus(manager, id).Ready)
+
+	mockRuntime.Exit(id, &ExitStatus{ExitCode: 1, ExitedAt: time.Now()})
+	assert.False(t, waitForPhase(t, manager, id, "restarting").Ready)
+	assert.True(t, waitForPhase(t, manager, id, "running").Ready)
+}
+
+func TestSandboxManager_Readiness_InvalidProbe(t *testing.T) {
+	// RD-04: Probes without exactly one handler or with an invalid port are rejected
+	manager := NewSandboxManager(NewMockRuntime())
+	for _, probe := range []*api.Probe{
+		{},
+		{TCPPort: 70000},
+		{Exec: []string{"true"}, TCPPort: 80},
+		{HTTPGet: &api.HTTPGetProbe{Port: 80, Scheme: "FTP"}},
+	} {
+		_, err := manager.CreateSandbox(context.Background(), &api.SandboxSpec{SandboxID: "sb-1", Image: "alpine", ReadinessProbe: probe})
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	}
+	assert.Empty(t, manager.GetSandboxStatuses(context.Background()))
+}
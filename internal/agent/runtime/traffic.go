@@ -0,0 +1,125 @@
+package runtime
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// portTraffic sums the TCP sockets bound to a local port.
+type portTraffic struct {
+	Sockets int
+	// Bytes is bytes received plus bytes acked by the peer, over the open sockets.
+	Bytes uint64
+}
+
+// inet_diag 协议常量与结构体布局，x/sys/unix 未提供，见 linux/inet_diag.h
+const (
+	inetDiagInfo        = 2 // INET_DIAG_INFO，响应中 tcp_info 属性的类型
+	sizeofInetDiagReq   = 56
+	sizeofInetDiagMsg   = 72
+	inetDiagAllStates   = 0xffffffff
+	inetDiagStateOffset = 1 // inet_diag_msg.idiag_state 在消息中的偏移
+	inetDiagSportOffset = 4 // inet_diag_msg.id.idiag_sport 在消息中的偏移
+	tcpStateTimeWait    = 6 // TCP_TIME_WAIT，见 include/net/tcp_states.h
+)
+
+// readTCPTraffic dumps the TCP sockets of the agent network namespace, which runc sandboxes
+// share, with INET_DIAG and sums them per local port. gVisor sandboxes run their own network
+// stack, their sockets are not visible here.
+func readTCPTraffic() (map[uint16]portTraffic, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_INET_DIAG)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inet_diag socket: %w", err)
+	}
+	defer unix.Close(fd)
+
+	result := make(map[uint16]portTraffic)
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		if err := dumpTCPSockets(fd, family, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// dumpTCPSockets sends one inet_diag dump request and adds the answered sockets to result.
+func dumpTCPSockets(fd int, family uint8, result map[uint16]portTraffic) error {
+	req := make([]byte, unix.SizeofNlMsghdr+sizeofInetDiagReq)
+	hdr := (*unix.NlMsghdr)(unsafe.Pointer(&req[0]))
+	hdr.Len = uint32(len(req))
+	hdr.Type = unix.SOCK_DIAG_BY_FAMILY
+	hdr.Flags = unix.NLM_F_REQUEST | unix.NLM_F_DUMP
+	body := req[unix.SizeofNlMsghdr:]
+	body[0] = family
+	body[1] = unix.IPPROTO_TCP
+	body[2] = 1 << (inetDiagInfo - 1) // 请求 tcp_info 扩展
+	binary.NativeEndian.PutUint32(body[4:], inetDiagAllStates)
+	if err := unix.Sendto(fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return fmt.Errorf("failed to send inet_diag request: %w", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return fmt.Errorf("failed to read inet_diag response: %w", err)
+		}
+		data := buf[:n]
+		for len(data) >= unix.SizeofNlMsghdr {
+			msg := (*unix.NlMsghdr)(unsafe.Pointer(&data[0]))
+			msgLen := int(msg.Len)
+			if msgLen < unix.SizeofNlMsghdr || msgLen > len(data) {
+				return fmt.Errorf("malformed inet_diag response")
+			}
+			switch msg.Type {
+			case unix.NLMSG_DONE:
+				return nil
+			case unix.NLMSG_ERROR:
+				return fmt.Errorf("inet_diag request failed")
+			}
+			addSocket(data[unix.SizeofNlMsghdr:msgLen], result)
+			data = data[nlmAlign(msgLen):]
+		}
+	}
+}
+
+// addSocket adds one inet_diag_msg and its tcp_info attribute to result.
+func addSocket(msg []byte, result map[uint16]portTraffic) {
+	if len(msg) < sizeofInetDiagMsg {
+		return
+	}
+	// 已关闭的短连接（如就绪探测）会在 TIME_WAIT 停留一分钟，不计入连接数，否则被误判为活动
+	if msg[inetDiagStateOffset] == tcpStateTimeWait {
+		return
+	}
+	// 端口按网络字节序存放
+	port := binary.BigEndian.Uint16(msg[inetDiagSportOffset:])
+	traffic := result[port]
+	traffic.Sockets++
+
+	attrs := msg[sizeofInetDiagMsg:]
+	for len(attrs) >= unix.SizeofRtAttr {
+		attr := (*unix.RtAttr)(unsafe.Pointer(&attrs[0]))
+		attrLen := int(attr.Len)
+		if attrLen < unix.SizeofRtAttr || attrLen > len(attrs) {
+			break
+		}
+		value := attrs[unix.SizeofRtAttr:attrLen]
+		// 旧内核的 tcp_info 较短，没有字节计数时只统计连接数
+		if attr.Type == inetDiagInfo && len(value) >= int(unsafe.Offsetof(unix.TCPInfo{}.Segs_out)) {
+			var info unix.TCPInfo
+			copy(unsafe.Slice((*byte)(unsafe.Pointer(&info)), unix.SizeofTCPInfo), value)
+			traffic.Bytes += info.Bytes_acked + info.Bytes_received
+		}
+		attrs = attrs[nlmAlign(attrLen):]
+	}
+	result[port] = traffic
+}
+
+// nlmAlign rounds a netlink message or attribute length up to 4 bytes.
+func nlmAlign(n int) int {
+	return (n + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"fast-sandbox/internal/agent/runtime"
+	"fast-sandbox/internal/api"
+
+	"k8s.io/klog/v2"
+)
+
+// handleExec runs a command inside a sandbox over an upgraded, framed connection.
+func (s *AgentServer) handleExec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req api.ExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.SandboxID == "" || len(req.Command) == 0 {
+		http.Error(w, "sandboxId and command are required", http.StatusBadRequest)
+		return
+	}
+	if !s.sandboxManager.IsRunning(req.SandboxID) {
+		http.Error(w, fmt.Sprintf("sandbox %s not found or not running", req.SandboxID), http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgradeStream(w, r)
+	if err != nil {
+		klog.ErrorS(err, "Failed to upgrade exec connection", "sandbox", req.SandboxID)
+		return
+	}
+	defer conn.Close()
+
+	s.execStream(conn, &req)
+}
+
+// execStream runs the command of req with its stdio carried by conn and sends the exit
+// status as the last frame.
+func (s *AgentServer) execStream(conn *api.StreamConn, req *api.ExecRequest) {
+	// The request context is detached from a hijacked connection, so cancellation
+	// is driven by the read loop below noticing the peer went away.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	input := readClientFrames(conn, req.Stdin, cancel, req.SandboxID)
+	defer input.close()
+
+	opts := &runtime.ExecOptions{
+		Command:    req.Command,
+		Env:        req.Env,
+		WorkingDir: req.WorkingDir,
+		Stdout:     conn.Writer(api.StreamStdout),
+		Stderr:     conn.Writer(api.StreamStderr),
+		TTY:        req.TTY,
+		Resize:     input.resize,
+	}
+	if input.stdin != nil {
+		opts.Stdin = input.stdin
+	}
+
+	code, err := s.sandboxManager.Exec(ctx, req.SandboxID, opts)
+	if err != nil {
+		klog.ErrorS(err, "Exec failed", "sandbox", req.SandboxID)
+	}
+	sendExit(conn, req.SandboxID, code, err)
+}
+
+// handleAttach connects to the main process of a sandbox over an upgraded, framed connection.
+func (s *AgentServer) handleAttach(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req api.AttachRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.SandboxID == "" {
+		http.Error(w, "sandboxId is required", http.StatusBadRequest)
+		return
+	}
+	if !s.sandboxManager.IsRunning(req.SandboxID) {
+		http.Error(w, fmt.Sprintf("sandbox %s not found or not running", req.SandboxID), http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgradeStream(w, r)
+	if err != nil {
+		klog.ErrorS(err, "Failed to upgrade attach connection", "sandbox", req.SandboxID)
+		return
+	}
+	defer conn.Close()
+
+	s.attachStream(conn, &req)
+}
+
+// attachStream connects conn to the main process of the sandbox and sends the exit
+// status as the last frame.
+func (s *AgentServer) attachStream(conn *api.StreamConn, req *api.AttachRequest) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	input := readClientFrames(conn, req.Stdin, cancel, req.SandboxID)
+	defer input.close()
+
+	opts := &runtime.AttachOptions{
+		Stdout: conn.Writer(api.StreamStdout),
+		Stderr: conn.Writer(api.StreamStderr),
+		Resize: input.resize,
+	}
+	if input.stdin != nil {
+		opts.Stdin = input.stdin
+	}
+
+	code, err := s.sandboxManager.Attach(ctx, req.SandboxID, opts)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		klog.ErrorS(err, "Attach failed", "sandbox", req.SandboxID)
+	}
+	sendExit(conn, req.SandboxID, code, err)
+}
+
+// sendExit writes the final exit frame of an exec or attach stream.
+func sendExit(conn *api.StreamConn, sandboxID string, code int, err error) {
+	exit := api.ExecExitStatus{ExitCode: code}
+	if err != nil {
+		exit.Message = err.Error()
+		if errors.Is(err, runtime.ErrSandboxNotFound) {
+			exit.Message = fmt.Sprintf("sandbox %s not found", sandboxID)
+		}
+	}
+	data, _ := json.Marshal(exit)
+	if err := conn.Send(api.StreamExit, data); err != nil {
+		klog.V(4).InfoS("Failed to send exit status", "sandbox", sandboxID, "err", err)
+	}
+}
+
+// clientInput carries what the controller sends on an exec or attach stream.
+type clientInput struct {
+	// stdin is nil unless the client asked for it.
+	stdin  *io.PipeReader
+	stdinW *io.PipeWriter
+	resize chan api.TerminalSize
+}
+
+func (in *clientInput) close() {
+	if in.stdinW != nil {
+		in.stdinW.Close()
+	}
+}
+
+// readClientFrames demultiplexes incoming frames until the connection breaks, then calls cancel.
+func readClientFrames(conn *api.StreamConn, stdin bool, cancel context.CancelFunc, sandboxID string) *clientInput {
+	in := &clientInput{resize: make(chan api.TerminalSize, 1)}
+	if stdin {
+		in.stdin, in.stdinW = io.Pipe()
+	}
+
+	go func() {
+		for {
+			t, p, err := conn.Recv()
+			if err != nil {
+				if in.stdinW != nil {
+					in.stdinW.CloseWithError(err)
+				}
+				cancel()
+				return
+			}
+			switch t {
+			case api.StreamStdin:
+				if in.stdinW == nil {
+					continue
+				}
+				if _, err := in.stdinW.Write(p); err != nil {
+					klog.V(4).InfoS("Stdin closed", "sandbox", sandboxID, "err", err)
+				}
+			case api.StreamStdinClose:
+				if in.stdinW != nil {
+					in.stdinW.Close()
+				}
+			case api.StreamResize:
+				var size api.TerminalSize
+				if err := json.Unmarshal(p, &size); err != nil {
+					klog.V(4).InfoS("Invalid resize frame", "sandbox", sandboxID, "err", err)
+					continue
+				}
+				// Only the latest size matters, drop a pending one instead of blocking
+				select {
+				case <-in.resize:
+				default:
+				}
+				in.resize <- size
+			}
+		}
+	}()
+	return in
+}
+
+// upgradeStream switches the HTTP connection to the framed stream protocol.
+func upgradeStream(w http.ResponseWriter, r *http.Request) (*api.StreamConn, error) {
+	if r.Header.Get("Upgrade") != api.StreamUpgradeProtocol {
+		http.Error(w, "upgrade to "+api.StreamUpgradeProtocol+" required", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("missing upgrade header")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + api.StreamUpgradeProtocol + "\r\n\r\n"
+	if _, err := brw.WriteString(resp); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if err := brw.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return api.NewStreamConn(&hijackedConn{Conn: netConn, r: brw.Reader}), nil
+}
+
+// hijackedConn reads through the server's buffered reader so bytes the client
+// sent right after the request headers are not lost.
+type hijackedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *hijackedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
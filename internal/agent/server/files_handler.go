@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"fast-sandbox/internal/agent/runtime"
+	"fast-sandbox/internal/api"
+
+	"k8s.io/klog/v2"
+)
+
+// handleFiles copies tar streams in (POST) and out (GET) of a sandbox by running tar inside it.
+func (s *AgentServer) handleFiles(w http.ResponseWriter, r *http.Request) {
+	sandboxID := r.URL.Query().Get("sandboxId")
+	p := r.URL.Query().Get("path")
+	if sandboxID == "" || p == "" {
+		http.Error(w, "sandboxId and path are required", http.StatusBadRequest)
+		return
+	}
+	p, err := cleanSandboxPath(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.sandboxManager.IsRunning(sandboxID) {
+		http.Error(w, fmt.Sprintf("sandbox %s not found or not running", sandboxID), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.copyToSandbox(w, r, sandboxID, p)
+	case http.MethodGet:
+		s.copyFromSandbox(w, r, sandboxID, p)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// cleanSandboxPath validates a path inside a sandbox given by the controller.
+func cleanSandboxPath(p string) (string, error) {
+	if !path.IsAbs(p) {
+		return "", errors.New("path must be absolute")
+	}
+	return path.Clean(p), nil
+}
+
+// copyToSandbox extracts the request body into dir, creating it if needed.
+func (s *AgentServer) copyToSandbox(w http.ResponseWriter, r *http.Request, sandboxID, dir string) {
+	if err := s.copyIn(r.Context(), sandboxID, dir, r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// copyIn extracts a tar stream into dir, creating it if needed.
+func (s *AgentServer) copyIn(ctx context.Context, sandboxID, dir string, tarStream io.Reader) error {
+	klog.InfoS("Copying files into sandbox", "sandbox", sandboxID, "dir", dir)
+
+	if err := s.runInSandbox(ctx, sandboxID, &runtime.ExecOptions{Command: []string{"mkdir", "-p", dir}}); err != nil {
+		return err
+	}
+	opts := &runtime.ExecOptions{
+		Command: []string{"tar", "-xmf", "-", "-C", dir},
+		Stdin:   tarStream,
+	}
+	if err := s.runInSandbox(ctx, sandboxID, opts); err != nil {
+		klog.ErrorS(err, "Copy into sandbox failed", "sandbox", sandboxID, "dir", dir)
+		return err
+	}
+	return nil
+}
+
+// copyFromSandbox streams a tar archive of p whose entries are rooted at the base name of p.
+func (s *AgentServer) copyFromSandbox(w http.ResponseWriter, r *http.Request, sandboxID, p string) {
+	// The archive is streamed before tar exits, so failures are reported in a trailer
+	w.Header().Set("Trailer", api.CopyErrorTrailer)
+	w.Header().Set("Content-Type", "application/x-tar")
+
+	fw := &flushWriter{w: w}
+	if f, ok := w.(http.Flusher); ok {
+		fw.f = f
+	}
+	if err := s.copyOut(r.Context(), sandboxID, p, fw); err != nil {
+		w.Header().Set(api.CopyErrorTrailer, err.Error())
+	}
+}
+
+// copyOut writes a tar archive of p whose entries are rooted at the base name of p.
+func (s *AgentServer) copyOut(ctx context.Context, sandboxID, p string, w io.Writer) error {
+	klog.InfoS("Copying files from sandbox", "sandbox", sandboxID, "path", p)
+
+	opts := &runtime.ExecOptions{
+		Command: []string{"tar", "-cf", "-", "-C", path.Dir(p), path.Base(p)},
+		Stdout:  w,
+	}
+	if err := s.runInSandbox(ctx, sandboxID, opts); err != nil {
+		klog.ErrorS(err, "Copy from sandbox failed", "sandbox", sandboxID, "path", p)
+		return err
+	}
+	return nil
+}
+
+// runInSandbox executes a helper command in the sandbox and turns a non-zero exit into an error carrying stderr.
+func (s *AgentServer) runInSandbox(ctx context.Context, sandboxID string, opts *runtime.ExecOptions) error {
+	var stderr bytes.Buffer
+	opts.Stderr = &stderr
+	code, err := s.sandboxManager.Exec(ctx, sandboxID, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.Command[0], err)
+	}
+	if code != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", opts.Command[0], code, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
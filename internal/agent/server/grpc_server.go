@@ -0,0 +1,348 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	agentv1 "fast-sandbox/api/proto/agent/v1"
+	"fast-sandbox/internal/agent/runtime"
+	"fast-sandbox/internal/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// maxChunkSize 是日志与文件流每条消息携带的最大数据量
+const maxChunkSize = 1 << 20
+
+// grpcService implements the gRPC agent API on top of the same handlers as the HTTP API.
+type grpcService struct {
+	agentv1.UnimplementedAgentServiceServer
+	s *AgentServer
+}
+
+// NewGRPCServer creates the gRPC server of the agent API, requiring mutual TLS when a
+// TLS configuration was set.
+func (s *AgentServer) NewGRPCServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	srv := grpc.NewServer(opts...)
+	agentv1.RegisterAgentServiceServer(srv, &grpcService{s: s})
+	return srv
+}
+
+// ServeGRPC serves the gRPC agent API on addr.
+func (s *AgentServer) ServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	klog.InfoS("Starting agent gRPC server", "addr", addr, "tls", s.tlsConfig != nil)
+	return s.NewGRPCServer().Serve(lis)
+}
+
+func (g *grpcService) CreateSandbox(ctx context.Context, req *agentv1.CreateSandboxRequest) (*agentv1.CreateSandboxResponse, error) {
+	if req.GetSandbox().GetSandboxId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	spec := api.SandboxSpecFromProto(req.GetSandbox())
+	resp, err := g.s.sandboxManager.CreateSandbox(ctx, spec)
+	if err != nil {
+		klog.ErrorS(err, "Create sandbox failed", "sandbox", spec.SandboxID)
+		return nil, status.Errorf(codes.Internal, "create failed: %v", err)
+	}
+	return &agentv1.CreateSandboxResponse{SandboxId: resp.SandboxID, CreatedAt: resp.CreatedAt}, nil
+}
+
+func (g *grpcService) DeleteSandbox(_ context.Context, req *agentv1.DeleteSandboxRequest) (*agentv1.DeleteSandboxResponse, error) {
+	if req.GetSandboxId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if _, err := g.s.sandboxManager.DeleteSandbox(req.GetSandboxId()); err != nil {
+		klog.ErrorS(err, "Delete sandbox failed", "sandbox", req.GetSandboxId())
+		return nil, sandboxError(err, req.GetSandboxId())
+	}
+	return &agentv1.DeleteSandboxResponse{}, nil
+}
+
+func (g *grpcService) PauseSandbox(ctx context.Context, req *agentv1.PauseSandboxRequest) (*agentv1.PauseSandboxResponse, error) {
+	if req.GetSandboxId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if err := g.s.sandboxManager.Pause(ctx, req.GetSandboxId()); err != nil {
+		klog.ErrorS(err, "Pause sandbox failed", "sandbox", req.GetSandboxId())
+		return nil, sandboxError(err, req.GetSandboxId())
+	}
+	return &agentv1.PauseSandboxResponse{}, nil
+}
+
+func (g *grpcService) ResumeSandbox(ctx context.Context, req *agentv1.ResumeSandboxRequest) (*agentv1.ResumeSandboxResponse, error) {
+	if req.GetSandboxId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if err := g.s.sandboxManager.Resume(ctx, req.GetSandboxId()); err != nil {
+		klog.ErrorS(err, "Resume sandbox failed", "sandbox", req.GetSandboxId())
+		return nil, sandboxError(err, req.GetSandboxId())
+	}
+	return &agentv1.ResumeSandboxResponse{}, nil
+}
+
+func (g *grpcService) CheckpointSandbox(ctx context.Context, req *agentv1.CheckpointSandboxRequest) (*agentv1.CheckpointSandboxResponse, error) {
+	if req.GetSandboxId() == "" || req.GetRef() == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id and ref are required")
+	}
+	if err := g.s.sandboxManager.Checkpoint(ctx, req.GetSandboxId(), req.GetRef(), req.GetPush(), api.RegistryCredentialsFromProto(req.GetCredentials())); err != nil {
+		klog.ErrorS(err, "Checkpoint sandbox failed", "sandbox", req.GetSandboxId(), "ref", req.GetRef())
+		return nil, sandboxError(err, req.GetSandboxId())
+	}
+	return &agentv1.CheckpointSandboxResponse{}, nil
+}
+
+func (g *grpcService) DeleteCheckpoint(ctx context.Context, req *agentv1.DeleteCheckpointRequest) (*agentv1.DeleteCheckpointResponse, error) {
+	if req.GetRef() == "" {
+		return nil, status.Error(codes.InvalidArgument, "ref is required")
+	}
+	if err := g.s.sandboxManager.DeleteCheckpoint(ctx, req.GetRef()); err != nil {
+		klog.ErrorS(err, "Delete checkpoint failed", "ref", req.GetRef())
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &agentv1.DeleteCheckpointResponse{}, nil
+}
+
+func (g *grpcService) GetStatus(ctx context.Context, _ *agentv1.GetStatusRequest) (*agentv1.AgentStatus, error) {
+	return api.AgentStatusToProto(g.s.agentStatus(ctx)), nil
+}
+
+func (g *grpcService) WatchEvents(_ *agentv1.WatchEventsRequest, stream grpc.ServerStreamingServer[agentv1.AgentEvent]) error {
+	remote := ""
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		remote = p.Addr.String()
+	}
+	g.s.streamEvents(stream.Context(), remote, func(ev *api.AgentEvent) error {
+		msg, err := api.AgentEventToProto(ev)
+		if err != nil {
+			return err
+		}
+		return stream.Send(msg)
+	})
+	return nil
+}
+
+func (g *grpcService) StreamLogs(req *agentv1.LogsRequest, stream grpc.ServerStreamingServer[agentv1.DataChunk]) error {
+	if req.GetSandboxId() == "" {
+		return status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	if err := g.s.sandboxManager.GetLogs(stream.Context(), req.GetSandboxId(), req.GetFollow(), &chunkWriter{send: stream.Send}); err != nil {
+		if stream.Context().Err() != nil {
+			return nil
+		}
+		klog.ErrorS(err, "GetLogs failed", "sandbox", req.GetSandboxId())
+		return sandboxError(err, req.GetSandboxId())
+	}
+	return nil
+}
+
+func (g *grpcService) Exec(stream grpc.BidiStreamingServer[agentv1.ExecRequest, agentv1.StreamFrame]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start.GetSandboxId() == "" || len(start.GetCommand()) == 0 {
+		return status.Error(codes.InvalidArgument, "the first message must start the exec with sandbox_id and command")
+	}
+	if err := g.acceptStream(stream, start.GetSandboxId()); err != nil {
+		return err
+	}
+	conn := api.NewFrameStreamConn(&grpcServerFrames{
+		send: stream.Send,
+		recv: func() (*agentv1.ClientFrame, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if req.GetFrame() == nil {
+				return nil, status.Error(codes.InvalidArgument, "exec already started")
+			}
+			return req.GetFrame(), nil
+		},
+	})
+	g.s.execStream(conn, &api.ExecRequest{
+		SandboxID:  start.GetSandboxId(),
+		Command:    start.GetCommand(),
+		Env:        start.GetEnv(),
+		WorkingDir: start.GetWorkingDir(),
+		Stdin:      start.GetStdin(),
+		TTY:        start.GetTty(),
+	})
+	return nil
+}
+
+func (g *grpcService) Attach(stream grpc.BidiStreamingServer[agentv1.AttachRequest, agentv1.StreamFrame]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start.GetSandboxId() == "" {
+		return status.Error(codes.InvalidArgument, "the first message must start the attach with sandbox_id")
+	}
+	if err := g.acceptStream(stream, start.GetSandboxId()); err != nil {
+		return err
+	}
+	conn := api.NewFrameStreamConn(&grpcServerFrames{
+		send: stream.Send,
+		recv: func() (*agentv1.ClientFrame, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if req.GetFrame() == nil {
+				return nil, status.Error(codes.InvalidArgument, "attach already started")
+			}
+			return req.GetFrame(), nil
+		},
+	})
+	g.s.attachStream(conn, &api.AttachRequest{SandboxID: start.GetSandboxId(), Stdin: start.GetStdin()})
+	return nil
+}
+
+func (g *grpcService) CopyTo(stream grpc.ClientStreamingServer[agentv1.CopyToRequest, agentv1.CopyToResponse]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start.GetSandboxId() == "" || start.GetDir() == "" {
+		return status.Error(codes.InvalidArgument, "the first message must start the copy with sandbox_id and dir")
+	}
+	dir, err := cleanSandboxPath(start.GetDir())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !g.s.sandboxManager.IsRunning(start.GetSandboxId()) {
+		return notRunning(start.GetSandboxId())
+	}
+	if err := g.s.copyIn(stream.Context(), start.GetSandboxId(), dir, &chunkReader{recv: stream.Recv}); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendAndClose(&agentv1.CopyToResponse{})
+}
+
+func (g *grpcService) CopyFrom(req *agentv1.CopyFromRequest, stream grpc.ServerStreamingServer[agentv1.DataChunk]) error {
+	if req.GetSandboxId() == "" || req.GetPath() == "" {
+		return status.Error(codes.InvalidArgument, "sandbox_id and path are required")
+	}
+	p, err := cleanSandboxPath(req.GetPath())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := g.acceptStream(stream, req.GetSandboxId()); err != nil {
+		return err
+	}
+	if err := g.s.copyOut(stream.Context(), req.GetSandboxId(), p, &chunkWriter{send: stream.Send}); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// acceptStream checks that the sandbox is running and sends the response header, which
+// tells the controller that the stream was accepted.
+func (g *grpcService) acceptStream(stream grpc.ServerStream, sandboxID string) error {
+	if !g.s.sandboxManager.IsRunning(sandboxID) {
+		return notRunning(sandboxID)
+	}
+	return stream.SendHeader(metadata.MD{})
+}
+
+func notRunning(sandboxID string) error {
+	return status.Errorf(codes.NotFound, "sandbox %s not found or not running", sandboxID)
+}
+
+// sandboxError maps a sandbox manager error to a gRPC status.
+func sandboxError(err error, sandboxID string) error {
+	if errors.Is(err, runtime.ErrSandboxNotFound) {
+		return status.Errorf(codes.NotFound, "sandbox %s not found", sandboxID)
+	}
+	if errors.Is(err, runtime.ErrInvalidPhase) || errors.Is(err, runtime.ErrCheckpointUnsupported) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// grpcServerFrames carries the frames of an exec or attach stream over gRPC.
+type grpcServerFrames struct {
+	send func(*agentv1.StreamFrame) error
+	recv func() (*agentv1.ClientFrame, error)
+}
+
+func (f *grpcServerFrames) SendFrame(t api.StreamType, p []byte) error {
+	frame, err := api.StreamFrameToProto(t, p)
+	if err != nil {
+		return err
+	}
+	return f.send(frame)
+}
+
+func (f *grpcServerFrames) RecvFrame() (api.StreamType, []byte, error) {
+	frame, err := f.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	return api.ClientFrameFromProto(frame)
+}
+
+// Close is a no-op, the stream ends when the handler returns.
+func (f *grpcServerFrames) Close() error {
+	return nil
+}
+
+// chunkWriter sends every write as data chunks.
+type chunkWriter struct {
+	send func(*agentv1.DataChunk) error
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	for written := 0; written < len(p); {
+		end := min(written+maxChunkSize, len(p))
+		// gRPC 可能在 Send 返回后才序列化消息，拷贝一份避免调用方复用缓冲区
+		if err := w.send(&agentv1.DataChunk{Data: append([]byte(nil), p[written:end]...)}); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return len(p), nil
+}
+
+// chunkReader reads the data of the CopyTo messages after the start message.
+type chunkReader struct {
+	recv func() (*agentv1.CopyToRequest, error)
+	buf  []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		if req.GetStart() != nil {
+			return 0, errors.New("copy already started")
+		}
+		r.buf = req.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
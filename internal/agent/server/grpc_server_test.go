@@ -0,0 +1,228 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fast-sandbox/internal/agent/runtime"
+	"fast-sandbox/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRuntime runs every sandbox in memory: exec echoes stdin, tar extracts into and
+// archives from an in-memory buffer, logs are fixed.
+type stubRuntime struct {
+	runtime.Runtime
+
+	mu        sync.Mutex
+	created   map[string]bool
+	files     bytes.Buffer
+	pushCreds *api.RegistryCredentials
+}
+
+func (r *stubRuntime) CreateSandbox(_ context.Context, spec *api.SandboxSpec) (*runtime.SandboxMetadata, error) {
+	if spec.Image == "broken" {
+		return nil, errors.New("image not found")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created[spec.SandboxID] = true
+	return &runtime.SandboxMetadata{SandboxSpec: *spec, CreatedAt: 1700000000}, nil
+}
+
+func (r *stubRuntime) DeleteSandbox(context.Context, string) error { return nil }
+
+func (r *stubRuntime) RestoreSandbox(ctx context.Context, spec *api.SandboxSpec) (*runtime.SandboxMetadata, error) {
+	return r.CreateSandbox(ctx, spec)
+}
+
+func (r *stubRuntime) Pause(context.Context, string) error { return nil }
+
+func (r *stubRuntime) Resume(context.Context, string) error { return nil }
+
+func (r *stubRuntime) Checkpoint(_ context.Context, _, _ string, _ bool, creds *api.RegistryCredentials) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pushCreds = creds
+	return nil
+}
+
+func (r *stubRuntime) DeleteCheckpoint(context.Context, string) error { return nil }
+
+func (r *stubRuntime) WaitSandbox(ctx context.Context, _ string) (*runtime.ExitStatus, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r *stubRuntime) GetSandboxStatus(context.Context, string) (string, error) { return "", nil }
+
+func (r *stubRuntime) ListImages(context.Context) ([]string, error) { return []string{"alpine"}, nil }
+
+func (r *stubRuntime) GetSandboxLogs(_ context.Context, _ string, _ bool, w io.Writer) error {
+	_, err := io.WriteString(w, "hello\n")
+	return err
+}
+
+func (r *stubRuntime) Exec(_ context.Context, _ string, opts *runtime.ExecOptions) (int, error) {
+	switch strings.Join(opts.Command, " ") {
+	case "mkdir -p /data":
+		return 0, nil
+	case "tar -xmf - -C /data":
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		_, err := io.Copy(&r.files, opts.Stdin)
+		return 0, err
+	case "tar -cf - -C / data":
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		_, err := opts.Stdout.Write(r.files.Bytes())
+		return 0, err
+	case "cat":
+		_, err := io.Copy(opts.Stdout, opts.Stdin)
+		return 3, err
+	}
+	io.WriteString(opts.Stderr, "unknown command")
+	return 127, nil
+}
+
+// startGRPCAgent serves an agent with the stub runtime and returns a gRPC client for it
+// together with the runtime.
+func startGRPCAgent(t *testing.T) (*api.AgentClient, *stubRuntime) {
+	rt := &stubRuntime{created: map[string]bool{}}
+	manager := runtime.NewSandboxManager(rt)
+	s := NewAgentServer("", manager)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := s.NewGRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	c := api.NewAgentClient(0)
+	c.UseGRPC(lis.Addr().(*net.TCPAddr).Port)
+	t.Cleanup(func() { c.CloseAgent("127.0.0.1") })
+	return c, rt
+}
+
+func TestGRPC_Lifecycle(t *testing.T) {
+	// GS-01: Create, status, watch, pause and checkpoint over gRPC, agent failures keep their meaning
+	c, rt := startGRPCAgent(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan *api.AgentEvent, 16)
+	go c.WatchAgent(ctx, "127.0.0.1", func(ev *api.AgentEvent) error {
+		events <- ev
+		return nil
+	})
+	ev := <-events
+	require.Equal(t, api.AgentEventSnapshot, ev.Type)
+	assert.Equal(t, []string{"alpine"}, ev.Status.Images)
+
+	resp, err := c.CreateSandbox("127.0.0.1", &api.CreateSandboxRequest{Sandbox: api.SandboxSpec{
+		SandboxID: "sb-1", Image: "alpine", Env: map[string]string{"A": "1"}, RestartPolicy: api.RestartPolicyAlways,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "sb-1", resp.SandboxID)
+
+	for ev = <-events; ev.Type == api.AgentEventHeartbeat; ev = <-events {
+	}
+	assert.Equal(t, api.AgentEventSandbox, ev.Type)
+	assert.Equal(t, "sb-1", ev.Sandbox.SandboxID)
+	assert.Equal(t, "running", ev.Sandbox.Phase)
+
+	status, err := c.GetAgentStatus(ctx, "127.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, status.SandboxStatuses, 1)
+	assert.Equal(t, int64(1700000000), status.SandboxStatuses[0].CreatedAt)
+
+	_, err = c.CreateSandbox("127.0.0.1", &api.CreateSandboxRequest{Sandbox: api.SandboxSpec{SandboxID: "sb-2", Image: "broken"}})
+	var statusErr *api.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 500, statusErr.StatusCode)
+	assert.Contains(t, statusErr.Message, "image not found")
+
+	require.NoError(t, c.PauseSandbox(ctx, "127.0.0.1", "sb-1"))
+	for ev = <-events; ev.Type == api.AgentEventHeartbeat; ev = <-events {
+	}
+	assert.Equal(t, "paused", ev.Sandbox.Phase)
+	err = c.PauseSandbox(ctx, "127.0.0.1", "missing")
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 404, statusErr.StatusCode)
+	require.NoError(t, c.ResumeSandbox(ctx, "127.0.0.1", "sb-1"))
+
+	creds := &api.RegistryCredentials{Username: "robot", Password: "s3cret"}
+	require.NoError(t, c.CheckpointSandbox(ctx, "127.0.0.1", &api.CheckpointRequest{SandboxID: "sb-1", Ref: "checkpoint/sb-1", Push: true, Credentials: creds}))
+	rt.mu.Lock()
+	assert.Equal(t, creds, rt.pushCreds, "registry credentials reach the runtime")
+	rt.mu.Unlock()
+	err = c.CheckpointSandbox(ctx, "127.0.0.1", &api.CheckpointRequest{SandboxID: "missing", Ref: "checkpoint/missing"})
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 404, statusErr.StatusCode)
+	require.NoError(t, c.DeleteCheckpoint(ctx, "127.0.0.1", "checkpoint/sb-1"))
+
+	_, err = c.DeleteSandbox("127.0.0.1", &api.DeleteSandboxRequest{SandboxID: "sb-1"})
+	assert.NoError(t, err)
+}
+
+func TestGRPC_Streams(t *testing.T) {
+	// GS-02: Exec, logs and file copies stream over gRPC, unknown sandboxes fail at open
+	c, _ := startGRPCAgent(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.CreateSandbox("127.0.0.1", &api.CreateSandboxRequest{Sandbox: api.SandboxSpec{SandboxID: "sb-1", Image: "alpine"}})
+	require.NoError(t, err)
+
+	conn, err := c.Exec(ctx, "127.0.0.1", &api.ExecRequest{SandboxID: "sb-1", Command: []string{"cat"}, Stdin: true})
+	require.NoError(t, err)
+	require.NoError(t, conn.Send(api.StreamStdin, []byte("ping")))
+	require.NoError(t, conn.Send(api.StreamStdinClose, nil))
+	var stdout []byte
+	for {
+		typ, p, err := conn.Recv()
+		require.NoError(t, err)
+		if typ == api.StreamExit {
+			assert.JSONEq(t, `{"exitCode":3}`, string(p))
+			break
+		}
+		stdout = append(stdout, p...)
+	}
+	assert.Equal(t, "ping", string(stdout))
+	conn.Close()
+
+	_, err = c.Exec(ctx, "127.0.0.1", &api.ExecRequest{SandboxID: "missing", Command: []string{"cat"}})
+	var statusErr *api.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 404, statusErr.StatusCode)
+
+	logs, err := c.StreamLogs(ctx, "127.0.0.1", "sb-1", false)
+	require.NoError(t, err)
+	data, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+	logs.Close()
+
+	archive := bytes.Repeat([]byte("tar"), 50000)
+	require.NoError(t, c.CopyTo(ctx, "127.0.0.1", "sb-1", "/data", bytes.NewReader(archive)))
+	out, err := c.CopyFrom(ctx, "127.0.0.1", "sb-1", "/data")
+	require.NoError(t, err)
+	data, err = io.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, archive, data)
+	out.Close()
+
+	_, err = c.CopyFrom(ctx, "127.0.0.1", "missing", "/data")
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 404, statusErr.StatusCode)
+	err = c.CopyTo(ctx, "127.0.0.1", "sb-1", "relative", bytes.NewReader(archive))
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 400, statusErr.StatusCode)
+}
@@ -1,10 +1,13 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"fast-sandbox/internal/agent/runtime"
 	"fast-sandbox/internal/api"
@@ -16,6 +19,8 @@ import (
 type AgentServer struct {
 	addr           string
 	sandboxManager *runtime.SandboxManager
+	// tlsConfig 非空时以 mTLS 提供服务
+	tlsConfig *tls.Config
 }
 
 // NewAgentServer creates a new agent HTTP server.
@@ -26,14 +31,28 @@ func NewAgentServer(addr string, sandboxManager *runtime.SandboxManager) *AgentS
 	}
 }
 
+// SetTLS makes the server require mutual TLS with the given configuration.
+func (s *AgentServer) SetTLS(cfg *tls.Config) {
+	s.tlsConfig = cfg
+}
+
 // Start starts the HTTP server.
 func (s *AgentServer) Start() error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v1/agent/create", s.handleCreate)
 	mux.HandleFunc("/api/v1/agent/delete", s.handleDelete)
 	mux.HandleFunc("/api/v1/agent/status", s.handleStatus)
+	mux.HandleFunc("/api/v1/agent/watch", s.handleWatch)
 	mux.HandleFunc("/api/v1/agent/logs", s.handleLogs)
+	mux.HandleFunc("/api/v1/agent/exec", s.handleExec)
+	mux.HandleFunc("/api/v1/agent/attach", s.handleAttach)
+	mux.HandleFunc("/api/v1/agent/files", s.handleFiles)
 
+	if s.tlsConfig != nil {
+		klog.InfoS("Starting agent HTTPS server with client authentication", "addr", s.addr)
+		srv := &http.Server{Addr: s.addr, Handler: mux, TLSConfig: s.tlsConfig}
+		return srv.ListenAndServeTLS("", "")
+	}
 	klog.InfoS("Starting agent HTTP server", "addr", s.addr)
 	return http.ListenAndServe(s.addr, mux)
 }
@@ -142,22 +161,88 @@ func (s *AgentServer) handleStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	images, err := s.sandboxManager.ListImages(r.Context())
+	status := s.agentStatus(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleWatch pushes sandbox state changes as Server-Sent Events: a snapshot first,
+// then one event per change, with heartbeats in between.
+func (s *AgentServer) handleWatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	s.streamEvents(r.Context(), r.RemoteAddr, func(ev *api.AgentEvent) error {
+		if err := api.WriteAgentEvent(w, ev); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// streamEvents sends a snapshot, then every sandbox change with heartbeats in between,
+// until ctx is done or send fails.
+func (s *AgentServer) streamEvents(ctx context.Context, remote string, send func(*api.AgentEvent) error) {
+	// 先订阅再取快照，保证快照之后的变化不会丢失（重复事件是幂等的）
+	events, unsubscribe := s.sandboxManager.Subscribe()
+	defer unsubscribe()
+
+	if send(&api.AgentEvent{Type: api.AgentEventSnapshot, Status: s.agentStatus(ctx)}) != nil {
+		return
+	}
+	klog.InfoS("Controller watch connected", "remote", remote)
+
+	heartbeat := time.NewTicker(api.AgentHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			klog.InfoS("Controller watch disconnected", "remote", remote)
+			return
+		case ev, ok := <-events:
+			if !ok {
+				// 订阅者过慢被关闭，结束流让 controller 重新 watch 拿到新快照
+				return
+			}
+			if send(&ev) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if send(&api.AgentEvent{Type: api.AgentEventHeartbeat}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// agentStatus collects the full status reported by /status and watch snapshots.
+func (s *AgentServer) agentStatus(ctx context.Context) *api.AgentStatus {
+	images, err := s.sandboxManager.ListImages(ctx)
 	if err != nil {
 		klog.ErrorS(err, "Warning: failed to list images")
 		images = []string{}
 	}
-	sbStatuses := s.sandboxManager.GetSandboxStatuses(r.Context())
+	sbStatuses := s.sandboxManager.GetSandboxStatuses(ctx)
 	nodeName := os.Getenv("NODE_NAME")
-	status := api.AgentStatus{
-		AgentID:         os.Getenv("POD_NAME"), // Use Pod Name as Agent ID
-		NodeName:        nodeName,
-		Capacity:        s.sandboxManager.GetCapacity(),
-		Allocated:       len(sbStatuses),
-		Images:          images,
-		SandboxStatuses: sbStatuses,
+	allocatableCPU, allocatableMemory := s.sandboxManager.GetAllocatable()
+	return &api.AgentStatus{
+		AgentID:           os.Getenv("POD_NAME"), // Use Pod Name as Agent ID
+		NodeName:          nodeName,
+		Capacity:          s.sandboxManager.GetCapacity(),
+		Allocated:         len(sbStatuses),
+		Images:            images,
+		SandboxStatuses:   sbStatuses,
+		AllocatableCPU:    allocatableCPU,
+		AllocatableMemory: allocatableMemory,
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(status)
 }
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+// controllerCommonName 与 Controller 客户端证书的 CommonName 一致（agenttls.ControllerName）
+const controllerCommonName = "fast-sandbox-controller"
+
+// LoadTLSConfig builds the server TLS configuration from the certificate Secret mounted
+// at dir. Only clients holding the controller certificate signed by the same CA are
+// accepted. The certificate and the CA are reloaded when the kubelet updates the Secret,
+// until ctx is done.
+func LoadTLSConfig(ctx context.Context, dir string) (*tls.Config, error) {
+	watcher, err := certwatcher.New(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent certificate: %w", err)
+	}
+	var clientCAs atomic.Pointer[x509.CertPool]
+	pool, err := loadCAPool(dir)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs.Store(pool)
+	// Controller 续签证书时同时更新 CA，证书变化后重新读取 CA
+	watcher.RegisterCallback(func(tls.Certificate) {
+		pool, err := loadCAPool(dir)
+		if err != nil {
+			klog.ErrorS(err, "Failed to reload agent CA, keeping the previous one", "dir", dir)
+			return
+		}
+		clientCAs.Store(pool)
+		klog.InfoS("Reloaded agent certificate and CA", "dir", dir)
+	})
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			klog.ErrorS(err, "Agent certificate watcher stopped", "dir", dir)
+		}
+	}()
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: watcher.GetCertificate,
+		// 客户端证书在 VerifyConnection 中按当前 CA 校验，CA 可随 Secret 更新
+		ClientAuth: tls.RequireAnyClientCert,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("client certificate is required")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			if _, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         clientCAs.Load(),
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			}); err != nil {
+				return fmt.Errorf("invalid client certificate: %w", err)
+			}
+			// 同一 CA 也为其他 Agent 签发了证书，只接受 Controller 的客户端证书
+			if cs.PeerCertificates[0].Subject.CommonName != controllerCommonName {
+				return errors.New("client is not the fast-sandbox controller")
+			}
+			return nil
+		},
+	}, nil
+}
+
+// loadCAPool reads the CA trust bundle of the mounted Secret.
+func loadCAPool(dir string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("agent CA file contains no certificate")
+	}
+	return pool, nil
+}
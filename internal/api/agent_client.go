@@ -3,12 +3,19 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
+	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
 )
 
@@ -18,18 +25,37 @@ type AgentAPIClient interface {
 	CreateSandbox(agentIP string, req *CreateSandboxRequest) (*CreateSandboxResponse, error)
 	DeleteSandbox(agentIP string, req *DeleteSandboxRequest) (*DeleteSandboxResponse, error)
 	GetAgentStatus(ctx context.Context, agentIP string) (*AgentStatus, error)
+	PauseSandbox(ctx context.Context, agentIP, sandboxID string) error
+	ResumeSandbox(ctx context.Context, agentIP, sandboxID string) error
+	CheckpointSandbox(ctx context.Context, agentIP string, req *CheckpointRequest) error
+	DeleteCheckpoint(ctx context.Context, agentIP, ref string) error
 }
 
 const (
 	// defaultAgentTimeout is the default timeout for agent API calls
 	defaultAgentTimeout = 5 * time.Second
+	// checkpointTimeout bounds checkpoints and restores, which dump or load the whole
+	// sandbox memory and may push or pull the checkpoint image.
+	checkpointTimeout = 5 * time.Minute
 )
 
-// AgentClient handles HTTP communication with agents.
+// AgentClient handles communication with agents, over the gRPC agent API after UseGRPC
+// and over the HTTP API otherwise.
 type AgentClient struct {
 	httpClient *http.Client
-	timeout    time.Duration
-	agentPort  int
+	// streamClient has no overall timeout so long-lived streams are not cut off.
+	streamClient *http.Client
+	timeout      time.Duration
+	agentPort    int
+	// useTLS 为 true 时通过 mTLS 访问 Agent
+	useTLS     bool
+	tlsConfig  *tls.Config
+	serverName func(ctx context.Context, agentIP string) (string, error)
+
+	// grpcPort 非 0 时使用 gRPC 协议，conns 为每个 Agent 复用的连接
+	grpcPort int
+	connMu   sync.Mutex
+	conns    map[string]*grpc.ClientConn
 }
 
 // NewAgentClient creates a new agent client.
@@ -38,9 +64,49 @@ func NewAgentClient(agentPort int) *AgentClient {
 		httpClient: &http.Client{
 			Timeout: defaultAgentTimeout,
 		},
-		timeout:   defaultAgentTimeout,
-		agentPort: agentPort,
+		streamClient: &http.Client{},
+		timeout:      defaultAgentTimeout,
+		agentPort:    agentPort,
+	}
+}
+
+// SetTLS makes the client talk to agents over mutual TLS. cfg carries the client
+// certificate and the CA; serverName returns the name the certificate of the agent at
+// an IP must carry, so a connection fails unless the agent is the expected pod.
+func (c *AgentClient) SetTLS(cfg *tls.Config, serverName func(ctx context.Context, agentIP string) (string, error)) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			name, err := serverName(ctx, host)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve agent identity for %s: %w", host, err)
+			}
+			conf := cfg.Clone()
+			conf.ServerName = name
+			return (&tls.Dialer{NetDialer: dialer, Config: conf}).DialContext(ctx, network, addr)
+		},
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
 	}
+	c.httpClient.Transport = transport
+	c.streamClient.Transport = transport
+	c.useTLS = true
+	c.tlsConfig = cfg
+	c.serverName = serverName
+}
+
+// endpoint returns the URL of an agent API path.
+func (c *AgentClient) endpoint(agentIP, path string) string {
+	scheme := "http"
+	if c.useTLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d%s", scheme, agentIP, c.agentPort, path)
 }
 
 // SetTimeout sets the timeout for agent API calls.
@@ -63,8 +129,14 @@ func (c *AgentClient) CreateSandbox(agentIP string, req *CreateSandboxRequest) (
 	if req.Sandbox.SandboxID == "" {
 		return nil, errors.New("sandboxID is required")
 	}
+	if c.grpcPort != 0 {
+		return c.grpcCreateSandbox(agentIP, req)
+	}
+	if req.Sandbox.Checkpoint != "" {
+		return nil, errRequiresGRPC
+	}
 
-	url := fmt.Sprintf("http://%s:%d/api/v1/agent/create", agentIP, c.agentPort)
+	url := c.endpoint(agentIP, "/api/v1/agent/create")
 
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -89,7 +161,7 @@ func (c *AgentClient) CreateSandbox(agentIP string, req *CreateSandboxRequest) (
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return &createResp, fmt.Errorf("create failed with status: %d, message: %s", resp.StatusCode, createResp.Message)
+		return &createResp, &StatusError{StatusCode: resp.StatusCode, Message: createResp.Message}
 	}
 
 	return &createResp, nil
@@ -105,8 +177,11 @@ func (c *AgentClient) DeleteSandbox(agentIP string, req *DeleteSandboxRequest) (
 			"sandboxID", req.SandboxID,
 			"duration_ms", duration.Milliseconds())
 	}()
+	if c.grpcPort != 0 {
+		return c.grpcDeleteSandbox(agentIP, req)
+	}
 
-	url := fmt.Sprintf("http://%s:%d/api/v1/agent/delete", agentIP, c.agentPort)
+	url := c.endpoint(agentIP, "/api/v1/agent/delete")
 
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -131,14 +206,66 @@ func (c *AgentClient) DeleteSandbox(agentIP string, req *DeleteSandboxRequest) (
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return &deleteResp, fmt.Errorf("delete failed with status: %d, message: %s", resp.StatusCode, deleteResp.Message)
+		return &deleteResp, &StatusError{StatusCode: resp.StatusCode, Message: deleteResp.Message}
 	}
 
 	return &deleteResp, nil
 }
 
+// PauseSandbox freezes all processes of a running sandbox. Pause and resume are only
+// served by the gRPC agent API.
+func (c *AgentClient) PauseSandbox(ctx context.Context, agentIP, sandboxID string) error {
+	if sandboxID == "" {
+		return errors.New("sandboxID is required")
+	}
+	if c.grpcPort == 0 {
+		return errRequiresGRPC
+	}
+	return c.grpcPauseSandbox(ctx, agentIP, sandboxID, true)
+}
+
+// ResumeSandbox thaws a paused sandbox.
+func (c *AgentClient) ResumeSandbox(ctx context.Context, agentIP, sandboxID string) error {
+	if sandboxID == "" {
+		return errors.New("sandboxID is required")
+	}
+	if c.grpcPort == 0 {
+		return errRequiresGRPC
+	}
+	return c.grpcPauseSandbox(ctx, agentIP, sandboxID, false)
+}
+
+// CheckpointSandbox saves a running or paused sandbox as a checkpoint image, only served
+// by the gRPC agent API like pause and restore.
+func (c *AgentClient) CheckpointSandbox(ctx context.Context, agentIP string, req *CheckpointRequest) error {
+	if req.SandboxID == "" || req.Ref == "" {
+		return errors.New("sandboxID and ref are required")
+	}
+	if c.grpcPort == 0 {
+		return errRequiresGRPC
+	}
+	return c.grpcCheckpointSandbox(ctx, agentIP, req)
+}
+
+// DeleteCheckpoint removes a checkpoint image kept on the node of the agent.
+func (c *AgentClient) DeleteCheckpoint(ctx context.Context, agentIP, ref string) error {
+	if ref == "" {
+		return errors.New("ref is required")
+	}
+	if c.grpcPort == 0 {
+		return errRequiresGRPC
+	}
+	return c.grpcDeleteCheckpoint(ctx, agentIP, ref)
+}
+
+// errRequiresGRPC 已弃用的 HTTP Agent API 不再增加新接口（暂停、checkpoint 与恢复）
+var errRequiresGRPC = &StatusError{StatusCode: http.StatusNotImplemented, Message: "the operation requires the gRPC agent API"}
+
 // GetAgentStatus fetches the current status of an agent with context support.
 func (c *AgentClient) GetAgentStatus(ctx context.Context, agentIP string) (*AgentStatus, error) {
+	if c.grpcPort != 0 {
+		return c.grpcGetAgentStatus(ctx, agentIP)
+	}
 	// Apply timeout if not already set in context
 	if _, hasDeadline := ctx.Deadline(); !hasDeadline && c.timeout > 0 {
 		var cancel context.CancelFunc
@@ -146,7 +273,7 @@ func (c *AgentClient) GetAgentStatus(ctx context.Context, agentIP string) (*Agen
 		defer cancel()
 	}
 
-	url := fmt.Sprintf("http://%s:%d/api/v1/agent/status", agentIP, c.agentPort)
+	url := c.endpoint(agentIP, "/api/v1/agent/status")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -170,3 +297,230 @@ func (c *AgentClient) GetAgentStatus(ctx context.Context, agentIP string) (*Agen
 
 	return &status, nil
 }
+
+// WatchAgent opens the event stream of an agent and calls fn for every event until
+// the stream ends, ctx is cancelled or fn returns an error. The first event is a snapshot.
+func (c *AgentClient) WatchAgent(ctx context.Context, agentIP string, fn func(*AgentEvent) error) error {
+	if c.grpcPort != 0 {
+		return c.grpcWatchAgent(ctx, agentIP, fn)
+	}
+	url := c.endpoint(agentIP, "/api/v1/agent/watch")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(msg))}
+	}
+
+	reader := NewAgentEventReader(resp.Body)
+	for {
+		ev, err := reader.Next()
+		if err != nil {
+			return err
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+}
+
+// Exec starts a process inside a sandbox and returns the upgraded stream.
+// The caller owns the returned StreamConn and must close it.
+func (c *AgentClient) Exec(ctx context.Context, agentIP string, req *ExecRequest) (*StreamConn, error) {
+	if req.SandboxID == "" {
+		return nil, errors.New("sandboxID is required")
+	}
+	if len(req.Command) == 0 {
+		return nil, errors.New("command is required")
+	}
+	if c.grpcPort != 0 {
+		return c.grpcExec(ctx, agentIP, req)
+	}
+
+	url := c.endpoint(agentIP, "/api/v1/agent/exec")
+	return c.openStream(ctx, url, req)
+}
+
+// Attach connects to the main process of a sandbox and returns the upgraded stream.
+// The caller owns the returned StreamConn and must close it.
+func (c *AgentClient) Attach(ctx context.Context, agentIP string, req *AttachRequest) (*StreamConn, error) {
+	if req.SandboxID == "" {
+		return nil, errors.New("sandboxID is required")
+	}
+	if c.grpcPort != 0 {
+		return c.grpcAttach(ctx, agentIP, req)
+	}
+
+	url := c.endpoint(agentIP, "/api/v1/agent/attach")
+	return c.openStream(ctx, url, req)
+}
+
+// CopyTo extracts a tar stream into dir inside the sandbox.
+func (c *AgentClient) CopyTo(ctx context.Context, agentIP, sandboxID, dir string, tarStream io.Reader) error {
+	if sandboxID == "" || dir == "" {
+		return errors.New("sandboxID and dir are required")
+	}
+	if c.grpcPort != 0 {
+		return c.grpcCopyTo(ctx, agentIP, sandboxID, dir, tarStream)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.filesURL(agentIP, sandboxID, dir), tarStream)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-tar")
+
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// CopyFrom returns a tar stream of path inside the sandbox, with entries rooted at the
+// base name of path. Failures after the stream started are returned by Read.
+func (c *AgentClient) CopyFrom(ctx context.Context, agentIP, sandboxID, path string) (io.ReadCloser, error) {
+	if sandboxID == "" || path == "" {
+		return nil, errors.New("sandboxID and path are required")
+	}
+	if c.grpcPort != 0 {
+		return c.grpcCopyFrom(ctx, agentIP, sandboxID, path)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.filesURL(agentIP, sandboxID, path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(msg))}
+	}
+	return &trailerErrorReader{resp: resp}, nil
+}
+
+// StreamLogs returns the log stream of a sandbox. With follow the stream stays open
+// until ctx is cancelled.
+func (c *AgentClient) StreamLogs(ctx context.Context, agentIP, sandboxID string, follow bool) (io.ReadCloser, error) {
+	if sandboxID == "" {
+		return nil, errors.New("sandboxID is required")
+	}
+	if c.grpcPort != 0 {
+		return c.grpcStreamLogs(ctx, agentIP, sandboxID, follow)
+	}
+
+	q := url.Values{}
+	q.Set("sandboxId", sandboxID)
+	q.Set("follow", strconv.FormatBool(follow))
+	logsURL := c.endpoint(agentIP, "/api/v1/agent/logs?"+q.Encode())
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, logsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(msg))}
+	}
+	return resp.Body, nil
+}
+
+func (c *AgentClient) filesURL(agentIP, sandboxID, path string) string {
+	q := url.Values{}
+	q.Set("sandboxId", sandboxID)
+	q.Set("path", path)
+	return c.endpoint(agentIP, "/api/v1/agent/files?"+q.Encode())
+}
+
+// trailerErrorReader surfaces the agent's copy error trailer once the body is drained.
+type trailerErrorReader struct {
+	resp *http.Response
+}
+
+func (r *trailerErrorReader) Read(p []byte) (int, error) {
+	n, err := r.resp.Body.Read(p)
+	if err == io.EOF {
+		if msg := r.resp.Trailer.Get(CopyErrorTrailer); msg != "" {
+			return n, errors.New(msg)
+		}
+	}
+	return n, err
+}
+
+func (r *trailerErrorReader) Close() error {
+	return r.resp.Body.Close()
+}
+
+// openStream posts a JSON body and upgrades the connection to a framed stream.
+func (c *AgentClient) openStream(ctx context.Context, url string, payload interface{}) (*StreamConn, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Connection", "Upgrade")
+	httpReq.Header.Set("Upgrade", StreamUpgradeProtocol)
+
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(msg))}
+	}
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("agent response body is not writable")
+	}
+	return NewStreamConn(rwc), nil
+}
+
+// StatusError is returned when the agent rejects a request, for streams before upgrading.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("agent returned status %d: %s", e.StatusCode, e.Message)
+}
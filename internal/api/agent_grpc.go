@@ -0,0 +1,464 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	agentv1 "fast-sandbox/api/proto/agent/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// copyChunkSize 是 CopyTo 每条消息携带的 tar 数据量
+const copyChunkSize = 32 * 1024
+
+// UseGRPC switches the client to the gRPC agent API served on grpcPort. One connection
+// is kept per agent and shared by all calls and streams to it.
+func (c *AgentClient) UseGRPC(grpcPort int) {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	c.grpcPort = grpcPort
+	c.conns = make(map[string]*grpc.ClientConn)
+}
+
+// CloseAgent closes the connection kept for an agent that went away.
+func (c *AgentClient) CloseAgent(agentIP string) {
+	c.connMu.Lock()
+	conn, ok := c.conns[agentIP]
+	delete(c.conns, agentIP)
+	c.connMu.Unlock()
+	if ok {
+		conn.Close()
+	}
+}
+
+// agentService returns the gRPC client of an agent, connecting on first use.
+func (c *AgentClient) agentService(agentIP string) (agentv1.AgentServiceClient, error) {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	if conn, ok := c.conns[agentIP]; ok {
+		return agentv1.NewAgentServiceClient(conn), nil
+	}
+
+	creds := insecure.NewCredentials()
+	if c.tlsConfig != nil {
+		creds = &agentCredentials{cfg: c.tlsConfig, serverName: c.serverName}
+	}
+	// passthrough 跳过 DNS 解析，authority 保持为 IP:port，供 agentCredentials 查找 Pod
+	target := "passthrough:///" + net.JoinHostPort(agentIP, strconv.Itoa(c.grpcPort))
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	c.conns[agentIP] = conn
+	return agentv1.NewAgentServiceClient(conn), nil
+}
+
+// withTimeout applies the client timeout unless ctx already has a deadline.
+func (c *AgentClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+func (c *AgentClient) grpcCreateSandbox(agentIP string, req *CreateSandboxRequest) (*CreateSandboxResponse, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if req.Sandbox.Checkpoint != "" {
+		// 恢复需要加载全部内存并可能拉取 checkpoint 镜像，不使用默认超时
+		var cancelRestore context.CancelFunc
+		ctx, cancelRestore = context.WithTimeout(ctx, checkpointTimeout)
+		defer cancelRestore()
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := svc.CreateSandbox(ctx, &agentv1.CreateSandboxRequest{Sandbox: SandboxSpecToProto(&req.Sandbox)})
+	if err != nil {
+		return nil, agentRPCError(err)
+	}
+	return &CreateSandboxResponse{Success: true, SandboxID: resp.GetSandboxId(), CreatedAt: resp.GetCreatedAt()}, nil
+}
+
+func (c *AgentClient) grpcDeleteSandbox(agentIP string, req *DeleteSandboxRequest) (*DeleteSandboxResponse, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.withTimeout(context.Background())
+	defer cancel()
+	if _, err := svc.DeleteSandbox(ctx, &agentv1.DeleteSandboxRequest{SandboxId: req.SandboxID}); err != nil {
+		return nil, agentRPCError(err)
+	}
+	return &DeleteSandboxResponse{Success: true}, nil
+}
+
+func (c *AgentClient) grpcPauseSandbox(ctx context.Context, agentIP, sandboxID string, pause bool) error {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	if pause {
+		_, err = svc.PauseSandbox(ctx, &agentv1.PauseSandboxRequest{SandboxId: sandboxID})
+	} else {
+		_, err = svc.ResumeSandbox(ctx, &agentv1.ResumeSandboxRequest{SandboxId: sandboxID})
+	}
+	return agentRPCError(err)
+}
+
+func (c *AgentClient) grpcCheckpointSandbox(ctx context.Context, agentIP string, req *CheckpointRequest) error {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, checkpointTimeout)
+		defer cancel()
+	}
+	_, err = svc.CheckpointSandbox(ctx, &agentv1.CheckpointSandboxRequest{
+		SandboxId:   req.SandboxID,
+		Ref:         req.Ref,
+		Push:        req.Push,
+		Credentials: RegistryCredentialsToProto(req.Credentials),
+	})
+	return agentRPCError(err)
+}
+
+func (c *AgentClient) grpcDeleteCheckpoint(ctx context.Context, agentIP, ref string) error {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	_, err = svc.DeleteCheckpoint(ctx, &agentv1.DeleteCheckpointRequest{Ref: ref})
+	return agentRPCError(err)
+}
+
+func (c *AgentClient) grpcGetAgentStatus(ctx context.Context, agentIP string) (*AgentStatus, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := svc.GetStatus(ctx, &agentv1.GetStatusRequest{})
+	if err != nil {
+		return nil, agentRPCError(err)
+	}
+	return AgentStatusFromProto(resp), nil
+}
+
+func (c *AgentClient) grpcWatchAgent(ctx context.Context, agentIP string, fn func(*AgentEvent) error) error {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := svc.WatchEvents(ctx, &agentv1.WatchEventsRequest{})
+	if err != nil {
+		return agentRPCError(err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return agentRPCError(err)
+		}
+		ev, err := AgentEventFromProto(msg)
+		if err != nil {
+			return err
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *AgentClient) grpcExec(ctx context.Context, agentIP string, req *ExecRequest) (*StreamConn, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := svc.Exec(ctx)
+	if err == nil {
+		err = stream.Send(&agentv1.ExecRequest{Payload: &agentv1.ExecRequest_Start{Start: &agentv1.ExecStart{
+			SandboxId:  req.SandboxID,
+			Command:    req.Command,
+			Env:        req.Env,
+			WorkingDir: req.WorkingDir,
+			Stdin:      req.Stdin,
+			Tty:        req.TTY,
+		}}})
+	}
+	if err == nil {
+		err = awaitAccepted(stream, &agentv1.StreamFrame{})
+	}
+	if err != nil {
+		cancel()
+		return nil, agentRPCError(err)
+	}
+	return NewFrameStreamConn(&grpcClientFrames{
+		send: func(f *agentv1.ClientFrame) error {
+			return stream.Send(&agentv1.ExecRequest{Payload: &agentv1.ExecRequest_Frame{Frame: f}})
+		},
+		recv:   stream.Recv,
+		cancel: cancel,
+	}), nil
+}
+
+func (c *AgentClient) grpcAttach(ctx context.Context, agentIP string, req *AttachRequest) (*StreamConn, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := svc.Attach(ctx)
+	if err == nil {
+		err = stream.Send(&agentv1.AttachRequest{Payload: &agentv1.AttachRequest_Start{Start: &agentv1.AttachStart{
+			SandboxId: req.SandboxID,
+			Stdin:     req.Stdin,
+		}}})
+	}
+	if err == nil {
+		err = awaitAccepted(stream, &agentv1.StreamFrame{})
+	}
+	if err != nil {
+		cancel()
+		return nil, agentRPCError(err)
+	}
+	return NewFrameStreamConn(&grpcClientFrames{
+		send: func(f *agentv1.ClientFrame) error {
+			return stream.Send(&agentv1.AttachRequest{Payload: &agentv1.AttachRequest_Frame{Frame: f}})
+		},
+		recv:   stream.Recv,
+		cancel: cancel,
+	}), nil
+}
+
+func (c *AgentClient) grpcCopyTo(ctx context.Context, agentIP, sandboxID, dir string, tarStream io.Reader) error {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := svc.CopyTo(ctx)
+	if err != nil {
+		return agentRPCError(err)
+	}
+	err = stream.Send(&agentv1.CopyToRequest{Payload: &agentv1.CopyToRequest_Start{Start: &agentv1.CopyStart{SandboxId: sandboxID, Dir: dir}}})
+	buf := make([]byte, copyChunkSize)
+	for err == nil {
+		n, readErr := tarStream.Read(buf)
+		if n > 0 {
+			// gRPC 可能在发送返回后才序列化消息，每条消息使用独立的缓冲区
+			err = stream.Send(&agentv1.CopyToRequest{Payload: &agentv1.CopyToRequest_Data{Data: append([]byte(nil), buf[:n]...)}})
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	// Send 失败时真正的原因由 CloseAndRecv 返回
+	if _, recvErr := stream.CloseAndRecv(); recvErr != nil {
+		return agentRPCError(recvErr)
+	}
+	return agentRPCError(err)
+}
+
+func (c *AgentClient) grpcCopyFrom(ctx context.Context, agentIP, sandboxID, path string) (io.ReadCloser, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := svc.CopyFrom(ctx, &agentv1.CopyFromRequest{SandboxId: sandboxID, Path: path})
+	if err != nil {
+		cancel()
+		return nil, agentRPCError(err)
+	}
+	r, err := newChunkReader(stream, cancel)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *AgentClient) grpcStreamLogs(ctx context.Context, agentIP, sandboxID string, follow bool) (io.ReadCloser, error) {
+	svc, err := c.agentService(agentIP)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := svc.StreamLogs(ctx, &agentv1.LogsRequest{SandboxId: sandboxID, Follow: follow})
+	if err != nil {
+		cancel()
+		return nil, agentRPCError(err)
+	}
+	r, err := newChunkReader(stream, cancel)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// chunkReader reads the data of a server stream of chunks.
+type chunkReader struct {
+	stream grpc.ServerStreamingClient[agentv1.DataChunk]
+	cancel context.CancelFunc
+	buf    []byte
+}
+
+// newChunkReader waits until the agent accepts the stream, so that a rejected request,
+// such as an unknown sandbox, fails before the caller starts reading.
+func newChunkReader(stream grpc.ServerStreamingClient[agentv1.DataChunk], cancel context.CancelFunc) (*chunkReader, error) {
+	if err := awaitAccepted(stream, &agentv1.DataChunk{}); err != nil {
+		cancel()
+		return nil, err
+	}
+	return &chunkReader{stream: stream, cancel: cancel}, nil
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+			return 0, agentRPCError(err)
+		}
+		r.buf = chunk.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *chunkReader) Close() error {
+	r.cancel()
+	return nil
+}
+
+// awaitAccepted waits until the agent accepts a stream. Agents send the response header
+// once the request is validated, rejected requests end with a status and no header.
+func awaitAccepted(stream grpc.ClientStream, m any) error {
+	md, err := stream.Header()
+	if err == nil && md == nil {
+		if err = stream.RecvMsg(m); err == nil || err == io.EOF {
+			err = errors.New("agent ended the stream without accepting it")
+		}
+	}
+	return agentRPCError(err)
+}
+
+// grpcClientFrames carries the frames of an exec or attach stream over gRPC.
+type grpcClientFrames struct {
+	send   func(*agentv1.ClientFrame) error
+	recv   func() (*agentv1.StreamFrame, error)
+	cancel context.CancelFunc
+}
+
+func (f *grpcClientFrames) SendFrame(t StreamType, p []byte) error {
+	frame, err := ClientFrameToProto(t, p)
+	if err != nil {
+		return err
+	}
+	return f.send(frame)
+}
+
+func (f *grpcClientFrames) RecvFrame() (StreamType, []byte, error) {
+	frame, err := f.recv()
+	if err != nil {
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, agentRPCError(err)
+	}
+	return StreamFrameFromProto(frame)
+}
+
+func (f *grpcClientFrames) Close() error {
+	f.cancel()
+	return nil
+}
+
+// agentRPCError turns the statuses an agent returns for rejected requests into a
+// StatusError, the same error the HTTP API produces. Other errors mean the agent could
+// not be reached and are returned as they are.
+func agentRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.InvalidArgument:
+		return &StatusError{StatusCode: http.StatusBadRequest, Message: s.Message()}
+	case codes.NotFound:
+		return &StatusError{StatusCode: http.StatusNotFound, Message: s.Message()}
+	case codes.FailedPrecondition:
+		return &StatusError{StatusCode: http.StatusConflict, Message: s.Message()}
+	case codes.Internal:
+		return &StatusError{StatusCode: http.StatusInternalServerError, Message: s.Message()}
+	}
+	return err
+}
+
+// agentCredentials verifies every connection to an agent against the identity of the
+// pod currently holding its IP, resolved again on each reconnect.
+type agentCredentials struct {
+	cfg        *tls.Config
+	serverName func(ctx context.Context, agentIP string) (string, error)
+}
+
+func (c *agentCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	host, _, err := net.SplitHostPort(authority)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, err := c.serverName(ctx, host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve agent identity for %s: %w", host, err)
+	}
+	// gRPC 的 TLS 凭据以 authority 作为 ServerName，这里传入 Agent 身份代替 IP
+	return credentials.NewTLS(c.cfg).ClientHandshake(ctx, name, rawConn)
+}
+
+func (c *agentCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("agent credentials are client only")
+}
+
+func (c *agentCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls"}
+}
+
+func (c *agentCredentials) Clone() credentials.TransportCredentials {
+	return &agentCredentials{cfg: c.cfg.Clone(), serverName: c.serverName}
+}
+
+func (c *agentCredentials) OverrideServerName(string) error {
+	return nil
+}
@@ -0,0 +1,293 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	agentv1 "fast-sandbox/api/proto/agent/v1"
+)
+
+// 以下函数在 Agent API 的 Go 类型与 gRPC 消息之间转换，HTTP 与 gRPC 两种协议共用同一套 Go 类型
+
+// SandboxSpecToProto converts a sandbox spec to its gRPC message.
+func SandboxSpecToProto(s *SandboxSpec) *agentv1.SandboxSpec {
+	return &agentv1.SandboxSpec{
+		SandboxId:      s.SandboxID,
+		ClaimUid:       s.ClaimUID,
+		ClaimName:      s.ClaimName,
+		Image:          s.Image,
+		Cpu:            s.CPU,
+		Memory:         s.Memory,
+		Command:        s.Command,
+		Args:           s.Args,
+		Env:            s.Env,
+		WorkingDir:     s.WorkingDir,
+		CpuRequest:     s.CPURequest,
+		Tty:            s.TTY,
+		Stdin:          s.Stdin,
+		RestartPolicy:  string(s.RestartPolicy),
+		Checkpoint:     s.Checkpoint,
+		ExposedPorts:   s.ExposedPorts,
+		ReadinessProbe: probeToProto(s.ReadinessProbe),
+
+		CheckpointCredentials: RegistryCredentialsToProto(s.CheckpointCredentials),
+	}
+}
+
+// SandboxSpecFromProto converts a gRPC sandbox spec.
+func SandboxSpecFromProto(s *agentv1.SandboxSpec) *SandboxSpec {
+	return &SandboxSpec{
+		SandboxID:      s.GetSandboxId(),
+		ClaimUID:       s.GetClaimUid(),
+		ClaimName:      s.GetClaimName(),
+		Image:          s.GetImage(),
+		CPU:            s.GetCpu(),
+		Memory:         s.GetMemory(),
+		Command:        s.GetCommand(),
+		Args:           s.GetArgs(),
+		Env:            s.GetEnv(),
+		WorkingDir:     s.GetWorkingDir(),
+		CPURequest:     s.GetCpuRequest(),
+		TTY:            s.GetTty(),
+		Stdin:          s.GetStdin(),
+		RestartPolicy:  RestartPolicy(s.GetRestartPolicy()),
+		Checkpoint:     s.GetCheckpoint(),
+		ExposedPorts:   s.GetExposedPorts(),
+		ReadinessProbe: probeFromProto(s.GetReadinessProbe()),
+
+		CheckpointCredentials: RegistryCredentialsFromProto(s.GetCheckpointCredentials()),
+	}
+}
+
+// RegistryCredentialsToProto converts registry credentials, nil stays nil.
+func RegistryCredentialsToProto(c *RegistryCredentials) *agentv1.RegistryCredentials {
+	if c == nil {
+		return nil
+	}
+	return &agentv1.RegistryCredentials{Username: c.Username, Password: c.Password}
+}
+
+// RegistryCredentialsFromProto converts gRPC registry credentials, nil stays nil.
+func RegistryCredentialsFromProto(c *agentv1.RegistryCredentials) *RegistryCredentials {
+	if c == nil {
+		return nil
+	}
+	return &RegistryCredentials{Username: c.GetUsername(), Password: c.GetPassword()}
+}
+
+// probeToProto converts a readiness probe, nil stays nil.
+func probeToProto(p *Probe) *agentv1.Probe {
+	if p == nil {
+		return nil
+	}
+	out := &agentv1.Probe{
+		Exec:                p.Exec,
+		TcpPort:             p.TCPPort,
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       p.PeriodSeconds,
+		TimeoutSeconds:      p.TimeoutSeconds,
+		SuccessThreshold:    p.SuccessThreshold,
+		FailureThreshold:    p.FailureThreshold,
+	}
+	if p.HTTPGet != nil {
+		out.HttpGet = &agentv1.HTTPGetProbe{
+			Scheme:  p.HTTPGet.Scheme,
+			Host:    p.HTTPGet.Host,
+			Port:    p.HTTPGet.Port,
+			Path:    p.HTTPGet.Path,
+			Headers: p.HTTPGet.Headers,
+		}
+	}
+	return out
+}
+
+// probeFromProto converts a gRPC readiness probe, nil stays nil.
+func probeFromProto(p *agentv1.Probe) *Probe {
+	if p == nil {
+		return nil
+	}
+	out := &Probe{
+		Exec:                p.GetExec(),
+		TCPPort:             p.GetTcpPort(),
+		InitialDelaySeconds: p.GetInitialDelaySeconds(),
+		PeriodSeconds:       p.GetPeriodSeconds(),
+		TimeoutSeconds:      p.GetTimeoutSeconds(),
+		SuccessThreshold:    p.GetSuccessThreshold(),
+		FailureThreshold:    p.GetFailureThreshold(),
+	}
+	if h := p.GetHttpGet(); h != nil {
+		out.HTTPGet = &HTTPGetProbe{
+			Scheme:  h.GetScheme(),
+			Host:    h.GetHost(),
+			Port:    h.GetPort(),
+			Path:    h.GetPath(),
+			Headers: h.GetHeaders(),
+		}
+	}
+	return out
+}
+
+// SandboxStatusToProto converts a sandbox status to its gRPC message.
+func SandboxStatusToProto(s *SandboxStatus) *agentv1.SandboxStatus {
+	return &agentv1.SandboxStatus{
+		SandboxId:      s.SandboxID,
+		ClaimUid:       s.ClaimUID,
+		ClaimName:      s.ClaimName,
+		Phase:          s.Phase,
+		Message:        s.Message,
+		CreatedAt:      s.CreatedAt,
+		ExitCode:       s.ExitCode,
+		ExitedAt:       s.ExitedAt,
+		Reason:         s.Reason,
+		RestartCount:   s.RestartCount,
+		LastActivityAt: s.LastActivityAt,
+		Ready:          s.Ready,
+	}
+}
+
+// SandboxStatusFromProto converts a gRPC sandbox status.
+func SandboxStatusFromProto(s *agentv1.SandboxStatus) *SandboxStatus {
+	return &SandboxStatus{
+		SandboxID:      s.GetSandboxId(),
+		ClaimUID:       s.GetClaimUid(),
+		ClaimName:      s.GetClaimName(),
+		Phase:          s.GetPhase(),
+		Message:        s.GetMessage(),
+		CreatedAt:      s.GetCreatedAt(),
+		ExitCode:       s.GetExitCode(),
+		ExitedAt:       s.GetExitedAt(),
+		Reason:         s.GetReason(),
+		RestartCount:   s.GetRestartCount(),
+		LastActivityAt: s.GetLastActivityAt(),
+		Ready:          s.GetReady(),
+	}
+}
+
+// AgentStatusToProto converts an agent status to its gRPC message.
+func AgentStatusToProto(s *AgentStatus) *agentv1.AgentStatus {
+	out := &agentv1.AgentStatus{
+		AgentId:           s.AgentID,
+		NodeName:          s.NodeName,
+		Capacity:          int32(s.Capacity),
+		Allocated:         int32(s.Allocated),
+		Images:            s.Images,
+		AllocatableCpu:    s.AllocatableCPU,
+		AllocatableMemory: s.AllocatableMemory,
+	}
+	for i := range s.SandboxStatuses {
+		out.SandboxStatuses = append(out.SandboxStatuses, SandboxStatusToProto(&s.SandboxStatuses[i]))
+	}
+	return out
+}
+
+// AgentStatusFromProto converts a gRPC agent status.
+func AgentStatusFromProto(s *agentv1.AgentStatus) *AgentStatus {
+	out := &AgentStatus{
+		AgentID:           s.GetAgentId(),
+		NodeName:          s.GetNodeName(),
+		Capacity:          int(s.GetCapacity()),
+		Allocated:         int(s.GetAllocated()),
+		Images:            s.GetImages(),
+		SandboxStatuses:   make([]SandboxStatus, 0, len(s.GetSandboxStatuses())),
+		AllocatableCPU:    s.GetAllocatableCpu(),
+		AllocatableMemory: s.GetAllocatableMemory(),
+	}
+	for _, st := range s.GetSandboxStatuses() {
+		out.SandboxStatuses = append(out.SandboxStatuses, *SandboxStatusFromProto(st))
+	}
+	return out
+}
+
+// AgentEventToProto converts a watch event to its gRPC message.
+func AgentEventToProto(ev *AgentEvent) (*agentv1.AgentEvent, error) {
+	switch ev.Type {
+	case AgentEventSnapshot:
+		return &agentv1.AgentEvent{Event: &agentv1.AgentEvent_Snapshot{Snapshot: AgentStatusToProto(ev.Status)}}, nil
+	case AgentEventSandbox:
+		return &agentv1.AgentEvent{Event: &agentv1.AgentEvent_Sandbox{Sandbox: SandboxStatusToProto(ev.Sandbox)}}, nil
+	case AgentEventSandboxDeleted:
+		return &agentv1.AgentEvent{Event: &agentv1.AgentEvent_DeletedSandboxId{DeletedSandboxId: ev.Sandbox.SandboxID}}, nil
+	case AgentEventHeartbeat:
+		return &agentv1.AgentEvent{Event: &agentv1.AgentEvent_Heartbeat{Heartbeat: &agentv1.Heartbeat{}}}, nil
+	}
+	return nil, fmt.Errorf("unknown agent event type %q", ev.Type)
+}
+
+// AgentEventFromProto converts a gRPC watch event.
+func AgentEventFromProto(ev *agentv1.AgentEvent) (*AgentEvent, error) {
+	switch e := ev.GetEvent().(type) {
+	case *agentv1.AgentEvent_Snapshot:
+		return &AgentEvent{Type: AgentEventSnapshot, Status: AgentStatusFromProto(e.Snapshot)}, nil
+	case *agentv1.AgentEvent_Sandbox:
+		return &AgentEvent{Type: AgentEventSandbox, Sandbox: SandboxStatusFromProto(e.Sandbox)}, nil
+	case *agentv1.AgentEvent_DeletedSandboxId:
+		return &AgentEvent{Type: AgentEventSandboxDeleted, Sandbox: &SandboxStatus{SandboxID: e.DeletedSandboxId}}, nil
+	case *agentv1.AgentEvent_Heartbeat:
+		return &AgentEvent{Type: AgentEventHeartbeat}, nil
+	}
+	return nil, fmt.Errorf("unknown agent event %T", ev.GetEvent())
+}
+
+// ClientFrameToProto converts a frame the controller sends on an exec or attach stream.
+func ClientFrameToProto(t StreamType, p []byte) (*agentv1.ClientFrame, error) {
+	switch t {
+	case StreamStdin:
+		return &agentv1.ClientFrame{Payload: &agentv1.ClientFrame_Stdin{Stdin: p}}, nil
+	case StreamStdinClose:
+		return &agentv1.ClientFrame{Payload: &agentv1.ClientFrame_CloseStdin{CloseStdin: &agentv1.CloseStdin{}}}, nil
+	case StreamResize:
+		var size TerminalSize
+		if err := json.Unmarshal(p, &size); err != nil {
+			return nil, fmt.Errorf("invalid resize frame: %w", err)
+		}
+		return &agentv1.ClientFrame{Payload: &agentv1.ClientFrame_Resize{Resize: &agentv1.TerminalSize{Width: size.Width, Height: size.Height}}}, nil
+	}
+	return nil, fmt.Errorf("stream type %d cannot be sent to an agent", t)
+}
+
+// ClientFrameFromProto converts a gRPC client frame, resize frames carry a JSON encoded
+// TerminalSize like on the HTTP protocol.
+func ClientFrameFromProto(f *agentv1.ClientFrame) (StreamType, []byte, error) {
+	switch p := f.GetPayload().(type) {
+	case *agentv1.ClientFrame_Stdin:
+		return StreamStdin, p.Stdin, nil
+	case *agentv1.ClientFrame_CloseStdin:
+		return StreamStdinClose, nil, nil
+	case *agentv1.ClientFrame_Resize:
+		data, err := json.Marshal(TerminalSize{Width: p.Resize.GetWidth(), Height: p.Resize.GetHeight()})
+		return StreamResize, data, err
+	}
+	return 0, nil, fmt.Errorf("unknown client frame %T", f.GetPayload())
+}
+
+// StreamFrameToProto converts a frame the agent sends on an exec or attach stream.
+func StreamFrameToProto(t StreamType, p []byte) (*agentv1.StreamFrame, error) {
+	switch t {
+	case StreamStdout:
+		return &agentv1.StreamFrame{Payload: &agentv1.StreamFrame_Stdout{Stdout: p}}, nil
+	case StreamStderr:
+		return &agentv1.StreamFrame{Payload: &agentv1.StreamFrame_Stderr{Stderr: p}}, nil
+	case StreamExit:
+		var exit ExecExitStatus
+		if err := json.Unmarshal(p, &exit); err != nil {
+			return nil, fmt.Errorf("invalid exit frame: %w", err)
+		}
+		return &agentv1.StreamFrame{Payload: &agentv1.StreamFrame_Exit{Exit: &agentv1.ExitStatus{ExitCode: int32(exit.ExitCode), Message: exit.Message}}}, nil
+	}
+	return nil, fmt.Errorf("stream type %d cannot be sent to the controller", t)
+}
+
+// StreamFrameFromProto converts a gRPC stream frame, exit frames carry a JSON encoded
+// ExecExitStatus like on the HTTP protocol.
+func StreamFrameFromProto(f *agentv1.StreamFrame) (StreamType, []byte, error) {
+	switch p := f.GetPayload().(type) {
+	case *agentv1.StreamFrame_Stdout:
+		return StreamStdout, p.Stdout, nil
+	case *agentv1.StreamFrame_Stderr:
+		return StreamStderr, p.Stderr, nil
+	case *agentv1.StreamFrame_Exit:
+		data, err := json.Marshal(ExecExitStatus{ExitCode: int(p.Exit.GetExitCode()), Message: p.Exit.GetMessage()})
+		return StreamExit, data, err
+	}
+	return 0, nil, fmt.Errorf("unknown stream frame %T", f.GetPayload())
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentEventProto_RoundTrip(t *testing.T) {
+	// PB-01: Watch events keep type and payload across the gRPC messages
+	events := []*AgentEvent{
+		{Type: AgentEventSnapshot, Status: &AgentStatus{
+			AgentID: "agent-1", Capacity: 5, Images: []string{"alpine"},
+			SandboxStatuses: []SandboxStatus{{SandboxID: "sb-1", Phase: "running", RestartCount: 2}},
+		}},
+		{Type: AgentEventSandbox, Sandbox: &SandboxStatus{SandboxID: "sb-1", Phase: "failed", ExitCode: 137, Reason: "OOMKilled"}},
+		{Type: AgentEventSandboxDeleted, Sandbox: &SandboxStatus{SandboxID: "sb-1"}},
+		{Type: AgentEventHeartbeat},
+	}
+	for _, ev := range events {
+		msg, err := AgentEventToProto(ev)
+		require.NoError(t, err)
+		got, err := AgentEventFromProto(msg)
+		require.NoError(t, err)
+		assert.Equal(t, ev, got)
+	}
+
+	_, err := AgentEventToProto(&AgentEvent{Type: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestSandboxSpecProto_RoundTrip(t *testing.T) {
+	// PB-03: Readiness probes and checkpoint credentials survive the gRPC messages
+	specs := []*SandboxSpec{
+		{SandboxID: "sb-1", Image: "nginx", ExposedPorts: []int32{80}, ReadinessProbe: &Probe{
+			HTTPGet:       &HTTPGetProbe{Port: 80, Path: "/healthz", Headers: map[string]string{"Host": "app"}},
+			PeriodSeconds: 2, FailureThreshold: 5,
+		}},
+		{SandboxID: "sb-2", Image: "redis", ReadinessProbe: &Probe{TCPPort: 6379, InitialDelaySeconds: 1}},
+		{SandboxID: "sb-3", Image: "python", ReadinessProbe: &Probe{Exec: []string{"test", "-f", "/tmp/ready"}}},
+		{SandboxID: "sb-4", Image: "alpine"},
+		{SandboxID: "sb-5", Image: "alpine", Checkpoint: "registry.example.com/ckpt:1", CheckpointCredentials: &RegistryCredentials{Username: "robot", Password: "s3cret"}},
+	}
+	for _, spec := range specs {
+		assert.Equal(t, spec, SandboxSpecFromProto(SandboxSpecToProto(spec)))
+	}
+}
+
+func TestStreamFrameProto_RoundTrip(t *testing.T) {
+	// PB-02: Resize and exit frames keep their JSON payload on both protocols
+	msg, err := ClientFrameToProto(StreamResize, []byte(`{"width":80,"height":24}`))
+	require.NoError(t, err)
+	typ, p, err := ClientFrameFromProto(msg)
+	require.NoError(t, err)
+	assert.Equal(t, StreamResize, typ)
+	assert.JSONEq(t, `{"width":80,"height":24}`, string(p))
+
+	// 超出 uint16 的尺寸不会被截断
+	msg, err = ClientFrameToProto(StreamResize, []byte(`{"width":70000,"height":24}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(70000), msg.GetResize().GetWidth())
+	_, p, err = ClientFrameFromProto(msg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"width":70000,"height":24}`, string(p))
+
+	frame, err := StreamFrameToProto(StreamExit, []byte(`{"exitCode":1,"message":"killed"}`))
+	require.NoError(t, err)
+	typ, p, err = StreamFrameFromProto(frame)
+	require.NoError(t, err)
+	assert.Equal(t, StreamExit, typ)
+	assert.JSONEq(t, `{"exitCode":1,"message":"killed"}`, string(p))
+
+	_, err = ClientFrameToProto(StreamStdout, nil)
+	assert.Error(t, err, "Output frames are never sent to an agent")
+}
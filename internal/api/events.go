@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// AgentEventType identifies the payload of an AgentEvent.
+type AgentEventType string
+
+const (
+	// AgentEventSnapshot carries the full agent status, always the first event of a watch.
+	AgentEventSnapshot AgentEventType = "snapshot"
+	// AgentEventSandbox carries the new status of a single sandbox.
+	AgentEventSandbox AgentEventType = "sandbox"
+	// AgentEventSandboxDeleted reports that a sandbox is no longer known to the agent.
+	AgentEventSandboxDeleted AgentEventType = "deleted"
+	// AgentEventHeartbeat keeps the watch and the agent heartbeat alive when nothing changes.
+	AgentEventHeartbeat AgentEventType = "heartbeat"
+)
+
+// AgentHeartbeatInterval is how often the agent sends heartbeat events on a watch.
+const AgentHeartbeatInterval = 3 * time.Second
+
+// AgentEvent is pushed by the agent to the controller over the watch stream.
+type AgentEvent struct {
+	Type AgentEventType `json:"type"`
+	// Status is set for snapshot events.
+	Status *AgentStatus `json:"status,omitempty"`
+	// Sandbox is set for sandbox and deleted events, deleted events only carry the SandboxID.
+	Sandbox *SandboxStatus `json:"sandbox,omitempty"`
+}
+
+// WriteAgentEvent writes an event in Server-Sent Events format.
+func WriteAgentEvent(w io.Writer, ev *AgentEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
+
+// AgentEventReader decodes the Server-Sent Events written by WriteAgentEvent.
+type AgentEventReader struct {
+	r *bufio.Reader
+}
+
+// NewAgentEventReader wraps a watch response body.
+func NewAgentEventReader(r io.Reader) *AgentEventReader {
+	return &AgentEventReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event, skipping comments and fields other than data.
+func (r *AgentEventReader) Next() (*AgentEvent, error) {
+	var data []byte
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		// 空行表示一个事件结束
+		if len(line) == 0 {
+			if len(data) == 0 {
+				continue
+			}
+			var ev AgentEvent
+			if err := json.Unmarshal(data, &ev); err != nil {
+				return nil, fmt.Errorf("invalid agent event: %w", err)
+			}
+			return &ev, nil
+		}
+		if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(payload, []byte(" "))...)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// 1. AgentEvent SSE encoding
+// ============================================================================
+
+func TestAgentEvent_RoundTrip(t *testing.T) {
+	// EV-01: Events keep type and payload across the SSE encoding
+	var buf bytes.Buffer
+	require.NoError(t, WriteAgentEvent(&buf, &AgentEvent{
+		Type:   AgentEventSnapshot,
+		Status: &AgentStatus{AgentID: "agent-1", Capacity: 5},
+	}))
+	require.NoError(t, WriteAgentEvent(&buf, &AgentEvent{
+		Type:    AgentEventSandbox,
+		Sandbox: &SandboxStatus{SandboxID: "sb-1", ClaimName: "sb-1", Phase: "running"},
+	}))
+	assert.True(t, strings.HasPrefix(buf.String(), "event: snapshot\ndata: "))
+
+	r := NewAgentEventReader(&buf)
+	ev, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, AgentEventSnapshot, ev.Type)
+	require.NotNil(t, ev.Status)
+	assert.Equal(t, "agent-1", ev.Status.AgentID)
+	assert.Equal(t, 5, ev.Status.Capacity)
+
+	ev, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, AgentEventSandbox, ev.Type)
+	require.NotNil(t, ev.Sandbox)
+	assert.Equal(t, "sb-1", ev.Sandbox.ClaimName)
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestAgentEventReader_SkipsCommentsAndBlankLines(t *testing.T) {
+	// EV-02: Comments, unknown fields and repeated blank lines are ignored
+	input := ": keepalive\n\n\nid: 1\nevent: heartbeat\r\ndata: {\"type\":\"heartbeat\"}\r\n\r\n"
+	ev, err := NewAgentEventReader(strings.NewReader(input)).Next()
+	require.NoError(t, err)
+	assert.Equal(t, AgentEventHeartbeat, ev.Type)
+}
+
+func TestAgentEventReader_InvalidData(t *testing.T) {
+	// EV-03: Malformed JSON payloads are reported
+	_, err := NewAgentEventReader(strings.NewReader("data: {not json\n\n")).Next()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid agent event")
+}
+
+// ============================================================================
+// 2. AgentClient.WatchAgent
+// ============================================================================
+
+func TestAgentClient_WatchAgent(t *testing.T) {
+	// EV-04: Events are delivered in order until the stream ends
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/agent/watch", r.URL.Path)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_ = WriteAgentEvent(w, &AgentEvent{Type: AgentEventSnapshot, Status: &AgentStatus{AgentID: "agent-1"}})
+		_ = WriteAgentEvent(w, &AgentEvent{Type: AgentEventSandboxDeleted, Sandbox: &SandboxStatus{SandboxID: "sb-1"}})
+	}))
+	defer server.Close()
+
+	var got []AgentEventType
+	err := NewAgentClient(serverPort(t, server)).WatchAgent(context.Background(), "127.0.0.1", func(ev *AgentEvent) error {
+		got = append(got, ev.Type)
+		return nil
+	})
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []AgentEventType{AgentEventSnapshot, AgentEventSandboxDeleted}, got)
+}
+
+func TestAgentClient_WatchAgent_CallbackError(t *testing.T) {
+	// EV-05: An error from the callback stops the watch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = WriteAgentEvent(w, &AgentEvent{Type: AgentEventHeartbeat})
+		_ = WriteAgentEvent(w, &AgentEvent{Type: AgentEventHeartbeat})
+	}))
+	defer server.Close()
+
+	stop := errors.New("stop")
+	calls := 0
+	err := NewAgentClient(serverPort(t, server)).WatchAgent(context.Background(), "127.0.0.1", func(ev *AgentEvent) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestAgentClient_WatchAgent_StatusError(t *testing.T) {
+	// EV-06: Non-200 responses surface as StatusError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not supported", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := NewAgentClient(serverPort(t, server)).WatchAgent(context.Background(), "127.0.0.1", func(ev *AgentEvent) error {
+		return nil
+	})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.Equal(t, "not supported", statusErr.Message)
+}
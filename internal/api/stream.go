@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// StreamType identifies the payload carried by a frame on an upgraded agent connection.
+type StreamType byte
+
+const (
+	// StreamStdin carries data written to the process stdin (controller -> agent).
+	StreamStdin StreamType = 0
+	// StreamStdout carries process stdout (agent -> controller).
+	StreamStdout StreamType = 1
+	// StreamStderr carries process stderr (agent -> controller).
+	StreamStderr StreamType = 2
+	// StreamStdinClose signals that no more stdin will be sent (controller -> agent).
+	StreamStdinClose StreamType = 3
+	// StreamExit carries a JSON encoded ExecExitStatus and is always the last frame (agent -> controller).
+	StreamExit StreamType = 4
+	// StreamResize carries a JSON encoded TerminalSize (controller -> agent).
+	StreamResize StreamType = 5
+)
+
+// StreamUpgradeProtocol is the value of the Upgrade header used to switch an agent
+// HTTP request to a framed bidirectional stream.
+const StreamUpgradeProtocol = "fast-sandbox-stream"
+
+const (
+	// frameHeaderSize is 1 byte stream type, 3 bytes padding and 4 bytes big-endian payload length.
+	frameHeaderSize = 8
+	// maxFrameSize guards against corrupted headers allocating huge buffers.
+	maxFrameSize = 1 << 20
+)
+
+// FrameTransport carries the frames of a StreamConn: the framed protocol of an
+// upgraded HTTP connection or the messages of a gRPC stream.
+type FrameTransport interface {
+	SendFrame(t StreamType, p []byte) error
+	RecvFrame() (StreamType, []byte, error)
+	Close() error
+}
+
+// StreamConn multiplexes typed frames over a single bidirectional connection.
+// Send is safe for concurrent use, Recv must be called from a single goroutine.
+type StreamConn struct {
+	mu sync.Mutex
+	t  FrameTransport
+}
+
+// NewStreamConn wraps an upgraded connection.
+func NewStreamConn(rw io.ReadWriteCloser) *StreamConn {
+	return &StreamConn{t: &framedConn{rw: rw}}
+}
+
+// NewFrameStreamConn wraps another frame transport.
+func NewFrameStreamConn(t FrameTransport) *StreamConn {
+	return &StreamConn{t: t}
+}
+
+// Send writes a single frame.
+func (c *StreamConn) Send(t StreamType, p []byte) error {
+	if len(p) > maxFrameSize {
+		return fmt.Errorf("frame too large: %d bytes", len(p))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t.SendFrame(t, p)
+}
+
+// Recv reads the next frame.
+func (c *StreamConn) Recv() (StreamType, []byte, error) {
+	return c.t.RecvFrame()
+}
+
+// Writer returns an io.Writer that sends every write as a frame of the given type.
+func (c *StreamConn) Writer(t StreamType) io.Writer {
+	return &streamWriter{conn: c, t: t}
+}
+
+// Close closes the underlying connection.
+func (c *StreamConn) Close() error {
+	return c.t.Close()
+}
+
+// framedConn encodes frames as a header followed by the payload on a byte stream.
+type framedConn struct {
+	rw io.ReadWriteCloser
+}
+
+func (c *framedConn) SendFrame(t StreamType, p []byte) error {
+	var header [frameHeaderSize]byte
+	header[0] = byte(t)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(p)))
+	if _, err := c.rw.Write(header[:]); err != nil {
+		return err
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	_, err := c.rw.Write(p)
+	return err
+}
+
+func (c *framedConn) RecvFrame() (StreamType, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[4:])
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame too large: %d bytes", size)
+	}
+	p := make([]byte, size)
+	if _, err := io.ReadFull(c.rw, p); err != nil {
+		return 0, nil, err
+	}
+	return StreamType(header[0]), p, nil
+}
+
+func (c *framedConn) Close() error {
+	return c.rw.Close()
+}
+
+type streamWriter struct {
+	conn *StreamConn
+	t    StreamType
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	for written := 0; written < len(p); {
+		end := written + maxFrameSize
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := w.conn.Send(w.t, p[written:end]); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return len(p), nil
+}
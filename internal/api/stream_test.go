@@ -0,0 +1,268 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// 1. StreamConn framing
+// ============================================================================
+
+func TestStreamConn_RoundTrip(t *testing.T) {
+	// ST-01: Frames keep type and payload across the connection
+	a, b := net.Pipe()
+	client, server := NewStreamConn(a), NewStreamConn(b)
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		_ = client.Send(StreamStdin, []byte("hello"))
+		_ = client.Send(StreamStdinClose, nil)
+	}()
+
+	typ, data, err := server.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, StreamStdin, typ)
+	assert.Equal(t, []byte("hello"), data)
+
+	typ, data, err = server.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, StreamStdinClose, typ)
+	assert.Empty(t, data)
+}
+
+func TestStreamConn_WriterChunksLargePayload(t *testing.T) {
+	// ST-02: Writer splits payloads larger than the max frame size
+	a, b := net.Pipe()
+	client, server := NewStreamConn(a), NewStreamConn(b)
+	defer client.Close()
+	defer server.Close()
+
+	payload := bytes.Repeat([]byte("x"), maxFrameSize+10)
+	go func() {
+		n, err := client.Writer(StreamStdout).Write(payload)
+		assert.NoError(t, err)
+		assert.Equal(t, len(payload), n)
+	}()
+
+	_, first, err := server.Recv()
+	require.NoError(t, err)
+	typ, second, err := server.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, StreamStdout, typ)
+	assert.Len(t, first, maxFrameSize)
+	assert.Len(t, second, 10)
+}
+
+// ============================================================================
+// 2. AgentClient.Exec
+// ============================================================================
+
+func TestAgentClient_Exec_ValidationError(t *testing.T) {
+	// EX-01: Missing sandboxID or command is rejected before dialing
+	client := NewAgentClient(5758)
+
+	_, err := client.Exec(context.Background(), "127.0.0.1", &ExecRequest{Command: []string{"ls"}})
+	assert.Error(t, err)
+	_, err = client.Exec(context.Background(), "127.0.0.1", &ExecRequest{SandboxID: "sb-1"})
+	assert.Error(t, err)
+}
+
+func TestAgentClient_Exec_StatusError(t *testing.T) {
+	// EX-02: A non-upgrade response surfaces as *StatusError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "sandbox not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	_, err := client.Exec(context.Background(), "127.0.0.1", &ExecRequest{SandboxID: "sb-1", Command: []string{"ls"}})
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestAgentClient_Exec_Upgrade(t *testing.T) {
+	// EX-03: Successful upgrade yields a usable bidirectional stream
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ExecRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, StreamUpgradeProtocol, r.Header.Get("Upgrade"))
+
+		netConn, brw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + StreamUpgradeProtocol + "\r\n\r\n")
+		brw.Flush()
+
+		conn := NewStreamConn(struct {
+			io.Reader
+			io.WriteCloser
+		}{brw.Reader, netConn})
+		defer conn.Close()
+
+		_, data, err := conn.Recv()
+		require.NoError(t, err)
+		conn.Send(StreamStdout, data)
+		exit, _ := json.Marshal(ExecExitStatus{ExitCode: 3})
+		conn.Send(StreamExit, exit)
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	conn, err := client.Exec(context.Background(), "127.0.0.1", &ExecRequest{SandboxID: "sb-1", Command: []string{"cat"}, Stdin: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.Send(StreamStdin, []byte("ping")))
+
+	typ, data, err := conn.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, StreamStdout, typ)
+	assert.Equal(t, []byte("ping"), data)
+
+	typ, data, err = conn.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, StreamExit, typ)
+	var exit ExecExitStatus
+	require.NoError(t, json.Unmarshal(data, &exit))
+	assert.Equal(t, 3, exit.ExitCode)
+}
+
+func serverPort(t *testing.T, server *httptest.Server) int {
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return port
+}
+
+// ============================================================================
+// 3. AgentClient.CopyTo / CopyFrom
+// ============================================================================
+
+func TestAgentClient_CopyTo(t *testing.T) {
+	// CP-01: Upload posts the tar stream with sandboxId and path
+	var gotQuery url.Values
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	err := client.CopyTo(context.Background(), "127.0.0.1", "sb-1", "/workspace", bytes.NewReader([]byte("tar-data")))
+
+	require.NoError(t, err)
+	assert.Equal(t, "sb-1", gotQuery.Get("sandboxId"))
+	assert.Equal(t, "/workspace", gotQuery.Get("path"))
+	assert.Equal(t, []byte("tar-data"), gotBody)
+}
+
+func TestAgentClient_CopyTo_Error(t *testing.T) {
+	// CP-02: Agent failures surface as *StatusError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "tar exited with code 2", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	err := client.CopyTo(context.Background(), "127.0.0.1", "sb-1", "/workspace", bytes.NewReader(nil))
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	assert.Contains(t, statusErr.Message, "tar exited")
+}
+
+func TestAgentClient_CopyFrom_TrailerError(t *testing.T) {
+	// CP-03: An error trailer set after streaming is returned at the end of the body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", CopyErrorTrailer)
+		w.Write([]byte("partial"))
+		w.Header().Set(CopyErrorTrailer, "tar: /missing: No such file")
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	body, err := client.CopyFrom(context.Background(), "127.0.0.1", "sb-1", "/missing")
+	require.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	assert.Equal(t, "partial", string(data))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No such file")
+}
+
+func TestAgentClient_CopyFrom_Success(t *testing.T) {
+	// CP-04: A clean stream ends with io.EOF
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", CopyErrorTrailer)
+		w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	body, err := client.CopyFrom(context.Background(), "127.0.0.1", "sb-1", "/workspace")
+	require.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(data))
+}
+
+// ============================================================================
+// Logs 测试
+// ============================================================================
+
+func TestAgentClient_StreamLogs(t *testing.T) {
+	// LG-01: Logs are requested with sandboxId and follow and the body is returned as is
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/agent/logs", r.URL.Path)
+		assert.Equal(t, "sb-1", r.URL.Query().Get("sandboxId"))
+		assert.Equal(t, "true", r.URL.Query().Get("follow"))
+		w.Write([]byte("hello\n"))
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	body, err := client.StreamLogs(context.Background(), "127.0.0.1", "sb-1", true)
+	require.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+}
+
+func TestAgentClient_StreamLogs_StatusError(t *testing.T) {
+	// LG-02: A missing log file surfaces as *StatusError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Log file not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewAgentClient(serverPort(t, server))
+	_, err := client.StreamLogs(context.Background(), "127.0.0.1", "sb-1", false)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.Equal(t, "Log file not found", statusErr.Message)
+}
@@ -19,21 +19,107 @@ type SandboxSpec struct {
 	ClaimUID   string            `json:"claimUid"`
 	ClaimName  string            `json:"claimName"`
 	Image      string            `json:"image"`
-	CPU        string            `json:"cpu,omitempty"`
-	Memory     string            `json:"memory,omitempty"`
+	CPU        string            `json:"cpu,omitempty"`    // CPU limit, e.g. "500m"
+	Memory     string            `json:"memory,omitempty"` // Memory limit, e.g. "256Mi"
 	Command    []string          `json:"command,omitempty"`
 	Args       []string          `json:"args,omitempty"`
 	Env        map[string]string `json:"env,omitempty"`
 	WorkingDir string            `json:"workingDir,omitempty"`
+	// CPURequest sets the relative CPU weight of the sandbox, defaults to CPU.
+	CPURequest string `json:"cpuRequest,omitempty"`
+	// TTY allocates a pseudo-terminal for the sandbox main process.
+	TTY bool `json:"tty,omitempty"`
+	// Stdin keeps the main process stdin open so that it can be attached to.
+	Stdin bool `json:"stdin,omitempty"`
+	// RestartPolicy decides whether the agent restarts the main process after it exits.
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+	// Checkpoint is the checkpoint image the sandbox is restored from instead of starting
+	// a new main process, only served by the gRPC agent API.
+	Checkpoint string `json:"checkpoint,omitempty"`
+	// CheckpointCredentials authenticate the pull of a pushed checkpoint. Restores are only
+	// served by the gRPC protocol, the credentials are never encoded as JSON.
+	CheckpointCredentials *RegistryCredentials `json:"-"`
+	// ExposedPorts are the ports the sandbox listens on, traffic on them counts as activity.
+	ExposedPorts []int32 `json:"exposedPorts,omitempty"`
+	// ReadinessProbe decides when the running sandbox is ready, without it the sandbox is
+	// ready while it runs.
+	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
 }
 
+// Probe is a readiness check the agent runs periodically against a running sandbox.
+// Exactly one of Exec, HTTPGet and TCPPort is set.
+type Probe struct {
+	// Exec runs the command in the sandbox, exit code 0 is a success.
+	Exec []string `json:"exec,omitempty"`
+	// HTTPGet sends a GET request, a status from 200 to 399 is a success.
+	HTTPGet *HTTPGetProbe `json:"httpGet,omitempty"`
+	// TCPPort is a success when a TCP connection to the port can be opened.
+	TCPPort int32 `json:"tcpPort,omitempty"`
+
+	// InitialDelaySeconds 主进程启动后首次探测前的等待时间
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// PeriodSeconds 探测间隔，默认 1 秒
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// TimeoutSeconds 单次探测超时，默认 1 秒
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// SuccessThreshold 连续成功该次数后变为就绪，默认 1
+	SuccessThreshold int32 `json:"successThreshold,omitempty"`
+	// FailureThreshold 连续失败该次数后变为未就绪，默认 3
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// HTTPGetProbe is the HTTP request of a readiness probe.
+type HTTPGetProbe struct {
+	// Scheme is HTTP (default) or HTTPS, certificates are not verified.
+	Scheme string `json:"scheme,omitempty"`
+	// Host defaults to the agent pod IP, which the sandboxes share.
+	Host    string            `json:"host,omitempty"`
+	Port    int32             `json:"port"`
+	Path    string            `json:"path,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// RestartPolicy defines when the agent restarts an exited sandbox main process.
+type RestartPolicy string
+
+const (
+	// RestartPolicyNever leaves the sandbox stopped after the main process exits (default).
+	RestartPolicyNever RestartPolicy = "Never"
+	// RestartPolicyOnFailure restarts the main process only after a non-zero exit.
+	RestartPolicyOnFailure RestartPolicy = "OnFailure"
+	// RestartPolicyAlways restarts the main process after every exit.
+	RestartPolicyAlways RestartPolicy = "Always"
+)
+
+// Termination reasons reported in SandboxStatus.Reason, same as Kubernetes containers.
+const (
+	ReasonCompleted = "Completed"
+	ReasonError     = "Error"
+	ReasonOOMKilled = "OOMKilled"
+)
+
 // SandboxStatus represents the observed state of a sandbox on an agent.
 type SandboxStatus struct {
 	SandboxID string `json:"sandboxId"`
 	ClaimUID  string `json:"claimUid"`
+	// ClaimName is the Sandbox CR name, in the namespace of the agent.
+	ClaimName string `json:"claimName,omitempty"`
 	Phase     string `json:"phase"`
 	Message   string `json:"message,omitempty"`
 	CreatedAt int64  `json:"createdAt"` // Unix timestamp for orphan cleanup
+	// ExitCode and ExitedAt (Unix timestamp) describe the last exit of the main process,
+	// ExitedAt is 0 while it has never exited.
+	ExitCode int32 `json:"exitCode,omitempty"`
+	ExitedAt int64 `json:"exitedAt,omitempty"`
+	// Reason is the termination reason of the last exit: Completed, Error or OOMKilled.
+	Reason string `json:"reason,omitempty"`
+	// RestartCount is how many times the agent restarted the main process.
+	RestartCount int32 `json:"restartCount,omitempty"`
+	// LastActivityAt is the Unix timestamp of the last observed activity: exec and attach
+	// sessions, log readers, traffic on the exposed ports or CPU usage.
+	LastActivityAt int64 `json:"lastActivityAt,omitempty"`
+	// Ready is true while the sandbox runs and passes its readiness probe.
+	Ready bool `json:"ready,omitempty"`
 }
 
 // CreateSandboxRequest is sent to create a single sandbox on an agent.
@@ -60,6 +146,23 @@ type DeleteSandboxResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// CheckpointRequest is sent to checkpoint a running or paused sandbox on an agent.
+type CheckpointRequest struct {
+	SandboxID string `json:"sandboxId"`
+	// Ref is the name of the checkpoint image, an existing image with the name is replaced.
+	Ref string `json:"ref"`
+	// Push pushes the checkpoint to the registry of Ref and drops the local copy.
+	Push bool `json:"push,omitempty"`
+	// Credentials authenticate the push, the registry is used anonymously without them.
+	Credentials *RegistryCredentials `json:"-"`
+}
+
+// RegistryCredentials authenticate an agent with the registry of a checkpoint image.
+type RegistryCredentials struct {
+	Username string
+	Password string
+}
+
 // AgentStatus represents the current status of an agent (internal use).
 type AgentStatus struct {
 	AgentID         string          `json:"agentId"`
@@ -68,4 +171,41 @@ type AgentStatus struct {
 	Allocated       int             `json:"allocated"`
 	Images          []string        `json:"images"`
 	SandboxStatuses []SandboxStatus `json:"sandboxStatuses"`
+	// AllocatableCPU 为 Agent 可分配给 sandbox 的 CPU（毫核），0 表示不限制
+	AllocatableCPU int64 `json:"allocatableCpu,omitempty"`
+	// AllocatableMemory 为 Agent 可分配给 sandbox 的内存（字节），0 表示不限制
+	AllocatableMemory int64 `json:"allocatableMemory,omitempty"`
+}
+
+// ExecRequest is sent to start a new process inside a running sandbox.
+type ExecRequest struct {
+	SandboxID  string            `json:"sandboxId"`
+	Command    []string          `json:"command"`
+	Env        map[string]string `json:"env,omitempty"`
+	WorkingDir string            `json:"workingDir,omitempty"`
+	Stdin      bool              `json:"stdin,omitempty"`
+	TTY        bool              `json:"tty,omitempty"`
 }
+
+// AttachRequest is sent to attach to the main process of a running sandbox.
+type AttachRequest struct {
+	SandboxID string `json:"sandboxId"`
+	Stdin     bool   `json:"stdin,omitempty"`
+}
+
+// TerminalSize is carried by resize frames of an exec or attach stream. It has the
+// width of the proto and containerd fields, so sizes pass through unchanged.
+type TerminalSize struct {
+	Width  uint32 `json:"width"`
+	Height uint32 `json:"height"`
+}
+
+// ExecExitStatus is carried by the final frame of an exec stream.
+type ExecExitStatus struct {
+	ExitCode int    `json:"exitCode"`
+	Message  string `json:"message,omitempty"`
+}
+
+// CopyErrorTrailer is the HTTP trailer set by the agent when a file download fails
+// after the tar stream has started.
+const CopyErrorTrailer = "X-Copy-Error"
@@ -2,32 +2,61 @@ package agentcontrol
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"sync"
 	"time"
 
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
 	"fast-sandbox/internal/api"
 	"fast-sandbox/internal/controller/agentpool"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
-// Loop periodically syncs desired sandboxes with agents and updates claim status.
+// Loop keeps the registry in sync with the agents. Every agent pushes its state over a
+// long-lived watch stream; agents without a working stream are polled on every tick,
+// and streamed agents are fully resynced every ResyncInterval as a fallback.
 type Loop struct {
 	Client      client.Client
 	Registry    agentpool.AgentRegistry
 	AgentClient *api.AgentClient
-	Interval    time.Duration
+	// Interval 为 tick 周期：发现新 Agent，并轮询没有 watch 流的 Agent
+	Interval time.Duration
+	// ResyncInterval 为 watch 流正常的 Agent 全量轮询兜底的周期
+	ResyncInterval time.Duration
+	// SandboxEvents 可选，sandbox 状态变化时投递对应的 Sandbox，用于立即触发 reconcile
+	SandboxEvents chan<- event.GenericEvent
+
+	mu      sync.Mutex
+	watches map[agentpool.AgentID]*agentWatch
+}
+
+// agentWatch tracks the watch stream of one agent pod and the last full view built from it.
+type agentWatch struct {
+	podIP  string
+	cancel context.CancelFunc
+
+	// mu 串行化同一 Agent 的事件应用与全量轮询，保证 registry 中的视图按顺序更新
+	mu        sync.Mutex
+	info      *agentpool.AgentInfo
+	connected bool
+	lastSync  time.Time
 }
 
 // NewLoop creates a new AgentControlLoop with a default interval.
 func NewLoop(c client.Client, reg agentpool.AgentRegistry, agentClient *api.AgentClient) *Loop {
 	return &Loop{
-		Client:      c,
-		Registry:    reg,
-		AgentClient: agentClient,
-		Interval:    2 * time.Second,
+		Client:         c,
+		Registry:       reg,
+		AgentClient:    agentClient,
+		Interval:       2 * time.Second,
+		ResyncInterval: 30 * time.Second,
+		watches:        make(map[agentpool.AgentID]*agentWatch),
 	}
 }
 
@@ -36,6 +65,7 @@ func (l *Loop) Start(ctx context.Context) {
 	logger := klog.Background().WithName("agent-control-loop")
 	ticker := time.NewTicker(l.Interval)
 	defer ticker.Stop()
+	defer l.stopAllWatches()
 
 	syncInProgress := false
 	var syncMu sync.Mutex
@@ -93,7 +123,8 @@ func (l *Loop) syncOnce(ctx context.Context) error {
 
 	seenAgents := make(map[agentpool.AgentID]bool)
 
-	for _, pod := range podList.Items {
+	for i := range podList.Items {
+		pod := &podList.Items[i]
 		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
 			continue
 		}
@@ -101,33 +132,10 @@ func (l *Loop) syncOnce(ctx context.Context) error {
 		agentID := agentpool.AgentID(pod.Name)
 		seenAgents[agentID] = true
 
-		agentCtx, agentCancel := context.WithTimeout(syncCtx, perAgentTimeout)
-		status, err := l.AgentClient.GetAgentStatus(agentCtx, pod.Status.PodIP)
-		agentCancel()
-
-		if err != nil {
+		w := l.ensureWatch(ctx, pod)
+		if err := l.pollIfNeeded(syncCtx, pod, w); err != nil {
 			logger.Error(err, "Failed to probe agent", "pod", pod.Name, "ip", pod.Status.PodIP)
-			continue
-		}
-
-		sbStatuses := make(map[string]api.SandboxStatus)
-		for _, s := range status.SandboxStatuses {
-			sbStatuses[s.SandboxID] = s
-		}
-
-		info := agentpool.AgentInfo{
-			ID:              agentID,
-			Namespace:       pod.Namespace,
-			PodName:         pod.Name,
-			PodIP:           pod.Status.PodIP,
-			NodeName:        pod.Spec.NodeName,
-			PoolName:        pod.Labels["fast-sandbox.io/pool"],
-			Capacity:        status.Capacity,
-			Images:          status.Images,
-			SandboxStatuses: sbStatuses,
-			LastHeartbeat:   time.Now(),
 		}
-		l.Registry.RegisterOrUpdate(info)
 	}
 
 	allAgents := l.Registry.GetAllAgents()
@@ -137,6 +145,7 @@ func (l *Loop) syncOnce(ctx context.Context) error {
 			l.Registry.Remove(a.ID)
 		}
 	}
+	l.stopWatchesExcept(seenAgents)
 
 	cleaned := l.Registry.CleanupStaleAgents(staleAgentTimeout)
 	if cleaned > 0 {
@@ -144,3 +153,191 @@ func (l *Loop) syncOnce(ctx context.Context) error {
 	}
 	return nil
 }
+
+// pollIfNeeded fetches the full agent status unless the watch stream is connected and
+// the last full sync is recent enough.
+func (l *Loop) pollIfNeeded(ctx context.Context, pod *corev1.Pod, w *agentWatch) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.connected && time.Since(w.lastSync) < l.ResyncInterval {
+		return nil
+	}
+
+	agentCtx, agentCancel := context.WithTimeout(ctx, perAgentTimeout)
+	status, err := l.AgentClient.GetAgentStatus(agentCtx, pod.Status.PodIP)
+	agentCancel()
+	if err != nil {
+		return err
+	}
+	l.applyLocked(w, agentInfoFromStatus(pod, status))
+	w.lastSync = time.Now()
+	return nil
+}
+
+// ensureWatch starts a watch stream for the agent pod, restarting it when the pod IP changed.
+func (l *Loop) ensureWatch(ctx context.Context, pod *corev1.Pod) *agentWatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.watches == nil {
+		l.watches = make(map[agentpool.AgentID]*agentWatch)
+	}
+
+	id := agentpool.AgentID(pod.Name)
+	if w, ok := l.watches[id]; ok {
+		if w.podIP == pod.Status.PodIP {
+			return w
+		}
+		w.cancel()
+		l.AgentClient.CloseAgent(w.podIP)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	w := &agentWatch{podIP: pod.Status.PodIP, cancel: cancel}
+	l.watches[id] = w
+	go l.runWatch(watchCtx, pod.DeepCopy(), w)
+	return w
+}
+
+// runWatch keeps the watch stream of an agent open until ctx is cancelled. While the
+// stream is down the agent is polled on every tick.
+func (l *Loop) runWatch(ctx context.Context, pod *corev1.Pod, w *agentWatch) {
+	logger := klog.Background().WithName("agent-control-loop")
+	for {
+		err := l.AgentClient.WatchAgent(ctx, pod.Status.PodIP, func(ev *api.AgentEvent) error {
+			return l.handleEvent(pod, w, ev)
+		})
+
+		w.mu.Lock()
+		w.connected = false
+		w.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		logger.V(1).Info("Agent watch closed, falling back to polling", "pod", pod.Name, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.Interval):
+		}
+	}
+}
+
+// handleEvent applies one pushed event to the last full view of the agent.
+func (l *Loop) handleEvent(pod *corev1.Pod, w *agentWatch, ev *api.AgentEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if ev.Type == api.AgentEventSnapshot {
+		if ev.Status == nil {
+			return fmt.Errorf("snapshot event without status")
+		}
+		l.applyLocked(w, agentInfoFromStatus(pod, ev.Status))
+		w.connected = true
+		w.lastSync = time.Now()
+		return nil
+	}
+	if w.info == nil {
+		return fmt.Errorf("%s event received before snapshot", ev.Type)
+	}
+
+	info := *w.info
+	info.SandboxStatuses = maps.Clone(w.info.SandboxStatuses)
+	info.LastHeartbeat = time.Now()
+	switch ev.Type {
+	case api.AgentEventSandbox:
+		if ev.Sandbox == nil {
+			return fmt.Errorf("sandbox event without status")
+		}
+		info.SandboxStatuses[ev.Sandbox.SandboxID] = *ev.Sandbox
+	case api.AgentEventSandboxDeleted:
+		if ev.Sandbox == nil {
+			return fmt.Errorf("deleted event without sandbox")
+		}
+		delete(info.SandboxStatuses, ev.Sandbox.SandboxID)
+	case api.AgentEventHeartbeat:
+	default:
+		klog.V(4).InfoS("Ignoring unknown agent event", "type", ev.Type, "pod", pod.Name)
+	}
+	l.applyLocked(w, info)
+	return nil
+}
+
+// applyLocked registers info as the latest view of the agent and enqueues the sandboxes
+// whose status changed since the previous view. Must be called with w.mu held.
+func (l *Loop) applyLocked(w *agentWatch, info agentpool.AgentInfo) {
+	prev := w.info
+	w.info = &info
+
+	// registry 会原地修改 SandboxStatuses（Release），交给它一份拷贝
+	registered := info
+	registered.SandboxStatuses = maps.Clone(info.SandboxStatuses)
+	l.Registry.RegisterOrUpdate(registered)
+
+	if prev == nil {
+		return
+	}
+	for id, status := range info.SandboxStatuses {
+		if old, ok := prev.SandboxStatuses[id]; !ok || old != status {
+			l.notifySandbox(info.Namespace, status.ClaimName)
+		}
+	}
+	for id, old := range prev.SandboxStatuses {
+		if _, ok := info.SandboxStatuses[id]; !ok {
+			l.notifySandbox(info.Namespace, old.ClaimName)
+		}
+	}
+}
+
+// notifySandbox enqueues the Sandbox for reconcile without blocking, the periodic
+// requeue of the Sandbox controller covers dropped notifications.
+func (l *Loop) notifySandbox(namespace, name string) {
+	if l.SandboxEvents == nil || name == "" {
+		return
+	}
+	sb := &apiv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	select {
+	case l.SandboxEvents <- event.GenericEvent{Object: sb}:
+	default:
+	}
+}
+
+func (l *Loop) stopWatchesExcept(seen map[agentpool.AgentID]bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, w := range l.watches {
+		if !seen[id] {
+			w.cancel()
+			// 关闭为已消失的 Agent 保留的 gRPC 连接
+			l.AgentClient.CloseAgent(w.podIP)
+			delete(l.watches, id)
+		}
+	}
+}
+
+func (l *Loop) stopAllWatches() {
+	l.stopWatchesExcept(nil)
+}
+
+// agentInfoFromStatus builds the registry view of an agent pod from its full status.
+func agentInfoFromStatus(pod *corev1.Pod, status *api.AgentStatus) agentpool.AgentInfo {
+	sbStatuses := make(map[string]api.SandboxStatus)
+	for _, s := range status.SandboxStatuses {
+		sbStatuses[s.SandboxID] = s
+	}
+
+	return agentpool.AgentInfo{
+		ID:                agentpool.AgentID(pod.Name),
+		Namespace:         pod.Namespace,
+		PodName:           pod.Name,
+		PodIP:             pod.Status.PodIP,
+		NodeName:          pod.Spec.NodeName,
+		PoolName:          pod.Labels["fast-sandbox.io/pool"],
+		Capacity:          status.Capacity,
+		AllocatableCPU:    status.AllocatableCPU,
+		AllocatableMemory: status.AllocatableMemory,
+		Images:            status.Images,
+		SandboxStatuses:   sbStatuses,
+		LastHeartbeat:     time.Now(),
+	}
+}
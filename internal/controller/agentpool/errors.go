@@ -0,0 +1,65 @@
+package agentpool
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/common"
+)
+
+var (
+	// ErrInsufficientCapacity 池内没有剩余槽位或 CPU/内存可以容纳 sandbox，扩容或释放后可重试
+	ErrInsufficientCapacity = errors.New("insufficient capacity")
+	// ErrInvalidPort sandbox 声明了非法端口
+	ErrInvalidPort = errors.New("invalid port")
+	// ErrNodeUnavailable sandbox 必须运行在本地 checkpoint 所在节点，而该节点上没有池内的 Agent，抢占无济于事
+	ErrNodeUnavailable = errors.New("required node unavailable")
+)
+
+// PortConflictError is returned when every agent with room for the sandbox already
+// uses one of its exposed ports. Retrying only helps once those ports are released.
+type PortConflictError struct {
+	Pool  string
+	Ports []int32
+}
+
+func (e *PortConflictError) Error() string {
+	return fmt.Sprintf("port conflict in pool %s: ports %v are in use on every agent with free capacity", e.Pool, e.Ports)
+}
+
+// IsUnschedulable reports whether err means the pool has no room for the sandbox,
+// either capacity or ports, so that releasing sandboxes of the pool can help.
+func IsUnschedulable(err error) bool {
+	var conflict *PortConflictError
+	return errors.Is(err, ErrInsufficientCapacity) || errors.As(err, &conflict)
+}
+
+// unschedulableError explains why no agent of the pool passed the filters. It is a
+// port conflict when the only agents with room were rejected for their ports.
+func unschedulableError(sb *apiv1alpha1.Sandbox, profile *Profile, state *SchedulingState, infos []AgentInfo) error {
+	if len(infos) == 0 {
+		return fmt.Errorf("%w in pool %s: no agents available", ErrInsufficientCapacity, sb.Spec.PoolRef)
+	}
+	if node := sb.Annotations[common.AnnotationRestoreNode]; node != "" &&
+		!slices.ContainsFunc(infos, func(info AgentInfo) bool { return info.NodeName == node }) {
+		return fmt.Errorf("%w: pool %s has no agent on node %s", ErrNodeUnavailable, sb.Spec.PoolRef, node)
+	}
+	var conflicts []int32
+	for i := range infos {
+		if _, ok := profile.rejectedBy(state, sb, &infos[i]).(portFilter); !ok {
+			continue
+		}
+		for _, p := range sb.Spec.ExposedPorts {
+			if infos[i].UsedPorts[p] && !slices.Contains(conflicts, p) {
+				conflicts = append(conflicts, p)
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return fmt.Errorf("%w in pool %s", ErrInsufficientCapacity, sb.Spec.PoolRef)
+	}
+	slices.Sort(conflicts)
+	return &PortConflictError{Pool: sb.Spec.PoolRef, Ports: conflicts}
+}
@@ -0,0 +1,207 @@
+package agentpool
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionPreempted 记录在被抢占的 sandbox 上，message 中包含抢占者
+	ConditionPreempted = "Preempted"
+	// ReasonPreempted 是被抢占 sandbox 上 condition 与 event 的 reason
+	ReasonPreempted = "Preempted"
+)
+
+// SelectVictims picks running sandboxes of lower priority whose eviction lets sb fit on
+// one agent of its pool. running are the sandboxes of the pool as stored in the API
+// server. The agent whose highest-priority victim is lowest wins, then the one with
+// the fewest victims. It returns nil when no eviction makes room.
+func (r *InMemoryRegistry) SelectVictims(sb *apiv1alpha1.Sandbox, running []apiv1alpha1.Sandbox) []*apiv1alpha1.Sandbox {
+	r.mu.RLock()
+	strategy := r.strategies[poolKey(sb.Namespace, sb.Spec.PoolRef)]
+	r.mu.RUnlock()
+
+	var infos []AgentInfo
+	for _, info := range r.GetAllAgents() {
+		if info.PoolName == sb.Spec.PoolRef && info.Namespace == sb.Namespace {
+			infos = append(infos, info)
+		}
+	}
+	profile := ProfileFor(strategy)
+	state := newSchedulingState(sb, infos)
+
+	candidates := make(map[AgentID][]*apiv1alpha1.Sandbox)
+	for i := range running {
+		v := &running[i]
+		if v.Namespace != sb.Namespace || v.Spec.PoolRef != sb.Spec.PoolRef || v.Spec.Priority >= sb.Spec.Priority {
+			continue
+		}
+		if v.Status.AssignedPod == "" || !holdsAgent(v) {
+			continue
+		}
+		candidates[AgentID(v.Status.AssignedPod)] = append(candidates[AgentID(v.Status.AssignedPod)], v)
+	}
+
+	var best []*apiv1alpha1.Sandbox
+	for i := range infos {
+		victims := victimsOn(profile, state, sb, infos[i], candidates[infos[i].ID])
+		if victims != nil && (best == nil || fewerVictims(victims, best)) {
+			best = victims
+		}
+	}
+	return best
+}
+
+// holdsAgent reports whether a sandbox still occupies its agent and has not been
+// evicted or deleted already.
+func holdsAgent(sb *apiv1alpha1.Sandbox) bool {
+	if sb.DeletionTimestamp != nil {
+		return false
+	}
+	switch apiv1alpha1.SandboxPhase(sb.Status.Phase) {
+	case apiv1alpha1.PhaseTerminating, apiv1alpha1.PhasePreempted, apiv1alpha1.PhaseExpired, apiv1alpha1.PhaseLost:
+		return false
+	}
+	return true
+}
+
+// victimsOn returns the smallest set of candidates whose eviction lets sb pass the
+// filters on the agent, or nil when evicting all of them is not enough.
+func victimsOn(profile *Profile, state *SchedulingState, sb *apiv1alpha1.Sandbox, info AgentInfo, candidates []*apiv1alpha1.Sandbox) []*apiv1alpha1.Sandbox {
+	if len(candidates) == 0 {
+		return nil
+	}
+	// 先驱逐优先级最低的，同优先级先驱逐最新创建的，保留运行更久的 sandbox
+	slices.SortFunc(candidates, func(a, b *apiv1alpha1.Sandbox) int {
+		if c := cmp.Compare(a.Spec.Priority, b.Spec.Priority); c != 0 {
+			return c
+		}
+		return b.CreationTimestamp.Time.Compare(a.CreationTimestamp.Time)
+	})
+
+	info.UsedPorts = maps.Clone(info.UsedPorts)
+	var victims []*apiv1alpha1.Sandbox
+	for _, c := range candidates {
+		if profile.Feasible(state, sb, &info) {
+			break
+		}
+		unreserve(&info, c)
+		victims = append(victims, c)
+	}
+	if !profile.Feasible(state, sb, &info) {
+		return nil
+	}
+
+	// 从优先级最高的受害者开始尝试放回，放回后仍能容纳则不必驱逐
+	for i := len(victims) - 1; i >= 0; i-- {
+		cpu, memory := SandboxRequests(victims[i])
+		reserve(&info, victims[i], cpu, memory)
+		if profile.Feasible(state, sb, &info) {
+			victims = slices.Delete(victims, i, i+1)
+			continue
+		}
+		unreserve(&info, victims[i])
+	}
+	return victims
+}
+
+// fewerVictims reports whether victim set a is cheaper to evict than b. Both are sorted
+// by ascending priority.
+func fewerVictims(a, b []*apiv1alpha1.Sandbox) bool {
+	if pa, pb := a[len(a)-1].Spec.Priority, b[len(b)-1].Spec.Priority; pa != pb {
+		return pa < pb
+	}
+	return len(a) < len(b)
+}
+
+// unreserve reverses reserve on a snapshot of the agent.
+func unreserve(info *AgentInfo, sb *apiv1alpha1.Sandbox) {
+	cpu, memory := SandboxRequests(sb)
+	info.Allocated = max(info.Allocated-1, 0)
+	info.AllocatedCPU = max(info.AllocatedCPU-cpu, 0)
+	info.AllocatedMemory = max(info.AllocatedMemory-memory, 0)
+	for _, p := range sb.Spec.ExposedPorts {
+		delete(info.UsedPorts, p)
+	}
+}
+
+// Preempt evicts lower-priority sandboxes of the pool so that sb can be allocated.
+// Victims are moved to the Preempted phase with an event recorded on each, the sandbox
+// controller then deletes them from their agents and releases their slots.
+// It returns true when room is being made, either by the victims chosen now or by an
+// earlier preemption whose victims are still being cleaned up, so the caller should
+// wait for capacity instead of failing.
+func Preempt(ctx context.Context, c client.Client, registry AgentRegistry, recorder record.EventRecorder, sb *apiv1alpha1.Sandbox) (bool, error) {
+	var list apiv1alpha1.SandboxList
+	if err := c.List(ctx, &list, client.InNamespace(sb.Namespace)); err != nil {
+		return false, err
+	}
+	running := make([]apiv1alpha1.Sandbox, 0, len(list.Items))
+	for _, item := range list.Items {
+		if item.Spec.PoolRef != sb.Spec.PoolRef {
+			continue
+		}
+		// 上一次抢占的受害者尚未释放，等待其释放而不是继续驱逐
+		if apiv1alpha1.SandboxPhase(item.Status.Phase) == apiv1alpha1.PhasePreempted && item.Status.AssignedPod != "" {
+			return true, nil
+		}
+		running = append(running, item)
+	}
+
+	victims := registry.SelectVictims(sb, running)
+	if len(victims) == 0 {
+		return false, nil
+	}
+	for _, v := range victims {
+		if err := markPreempted(ctx, c, recorder, v, sb); err != nil {
+			return false, fmt.Errorf("failed to preempt sandbox %s/%s: %w", v.Namespace, v.Name, err)
+		}
+	}
+	klog.InfoS("Preempted sandboxes", "sandbox", sb.Name, "namespace", sb.Namespace, "pool", sb.Spec.PoolRef, "priority", sb.Spec.Priority, "victims", len(victims))
+	return true, nil
+}
+
+// markPreempted moves a victim to the Preempted phase unless it left its agent meanwhile.
+func markPreempted(ctx context.Context, c client.Client, recorder record.EventRecorder, victim, preemptor *apiv1alpha1.Sandbox) error {
+	message := fmt.Sprintf("Preempted by sandbox %s with priority %d", preemptor.Name, preemptor.Spec.Priority)
+	var latest apiv1alpha1.Sandbox
+	var marked bool
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		marked = false
+		if err := c.Get(ctx, client.ObjectKeyFromObject(victim), &latest); err != nil {
+			return err
+		}
+		if latest.Status.AssignedPod != victim.Status.AssignedPod || !holdsAgent(&latest) {
+			return nil
+		}
+		latest.Status.Phase = string(apiv1alpha1.PhasePreempted)
+		meta.SetStatusCondition(&latest.Status.Conditions, metav1.Condition{
+			Type:    ConditionPreempted,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonPreempted,
+			Message: message,
+		})
+		marked = true
+		return c.Status().Update(ctx, &latest)
+	})
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if marked && recorder != nil {
+		recorder.Event(&latest, corev1.EventTypeWarning, ReasonPreempted, message)
+	}
+	return nil
+}
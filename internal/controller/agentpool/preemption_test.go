@@ -0,0 +1,104 @@
+package agentpool
+
+import (
+	"testing"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// runningOn returns a sandbox placed on the agent, created age ago.
+func runningOn(name, agent string, priority int32, age time.Duration, opts ...func(*apiv1alpha1.Sandbox)) apiv1alpha1.Sandbox {
+	sb := newTestSandbox(name, opts...)
+	sb.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+	sb.Spec.Priority = priority
+	sb.Status.Phase = string(apiv1alpha1.PhaseRunning)
+	sb.Status.AssignedPod = agent
+	return *sb
+}
+
+// fillAgent registers an agent and allocates the running sandboxes on it.
+func fillAgent(t *testing.T, registry *InMemoryRegistry, id string, capacity int, running ...apiv1alpha1.Sandbox) {
+	registry.RegisterOrUpdate(newTestAgentInfo(AgentID(id), withPoolName("test-pool"), withCapacity(capacity)))
+	for i := range running {
+		agent, err := registry.Allocate(&running[i])
+		require.NoError(t, err)
+		require.Equal(t, AgentID(id), agent.ID)
+	}
+}
+
+func victimNames(victims []*apiv1alpha1.Sandbox) []string {
+	names := make([]string, len(victims))
+	for i, v := range victims {
+		names[i] = v.Name
+	}
+	return names
+}
+
+func TestSelectVictims_LowerPriorityOnly(t *testing.T) {
+	// P-01: Only sandboxes of strictly lower priority are evicted, the newest first
+	registry := NewInMemoryRegistry()
+	running := []apiv1alpha1.Sandbox{
+		runningOn("old", "agent-1", 0, time.Hour),
+		runningOn("new", "agent-1", 0, time.Minute),
+	}
+	fillAgent(t, registry, "agent-1", 2, running...)
+
+	sb := newTestSandbox("important")
+	sb.Spec.Priority = 10
+	assert.Equal(t, []string{"new"}, victimNames(registry.SelectVictims(sb, running)))
+
+	sb.Spec.Priority = 0
+	assert.Nil(t, registry.SelectVictims(sb, running), "Equal priority must not preempt")
+}
+
+func TestSelectVictims_PrefersLowestPriorityAgent(t *testing.T) {
+	// P-02: The agent whose victims have the lowest priority wins, then the fewest victims
+	registry := NewInMemoryRegistry()
+	onAgent1 := runningOn("mid", "agent-1", 5, time.Minute)
+	onAgent2 := []apiv1alpha1.Sandbox{
+		runningOn("low-1", "agent-2", 1, time.Minute),
+		runningOn("low-2", "agent-2", 1, time.Minute),
+	}
+	fillAgent(t, registry, "agent-1", 1, onAgent1)
+	fillAgent(t, registry, "agent-2", 2, onAgent2...)
+	running := append([]apiv1alpha1.Sandbox{onAgent1}, onAgent2...)
+
+	sb := newTestSandbox("important")
+	sb.Spec.Priority = 10
+	victims := registry.SelectVictims(sb, running)
+	require.Len(t, victims, 1)
+	assert.Equal(t, "agent-2", victims[0].Status.AssignedPod)
+}
+
+func TestSelectVictims_PortConflict(t *testing.T) {
+	// P-03: A port conflict is solved by evicting the holder of the port, the other
+	// lower-priority sandboxes are spared
+	registry := NewInMemoryRegistry()
+	running := []apiv1alpha1.Sandbox{
+		runningOn("lowest", "agent-1", 0, time.Minute),
+		runningOn("port-holder", "agent-1", 3, time.Minute, withSandboxPorts(8080)),
+	}
+	fillAgent(t, registry, "agent-1", 10, running...)
+
+	sb := newTestSandbox("important", withSandboxPorts(8080))
+	sb.Spec.Priority = 10
+	assert.Equal(t, []string{"port-holder"}, victimNames(registry.SelectVictims(sb, running)))
+}
+
+func TestSelectVictims_SkipsLeavingSandboxes(t *testing.T) {
+	// P-04: Sandboxes already preempted, terminating or of another pool are not victims
+	registry := NewInMemoryRegistry()
+	preempted := runningOn("preempted", "agent-1", 0, time.Minute)
+	other := runningOn("other", "agent-1", 0, time.Minute, withSandboxPoolRef("other-pool"))
+	fillAgent(t, registry, "agent-1", 1, preempted)
+	preempted.Status.Phase = string(apiv1alpha1.PhasePreempted)
+
+	sb := newTestSandbox("important")
+	sb.Spec.Priority = 10
+	assert.Nil(t, registry.SelectVictims(sb, []apiv1alpha1.Sandbox{preempted, other}))
+}
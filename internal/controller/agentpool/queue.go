@@ -0,0 +1,136 @@
+package agentpool
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// CapacityQueue parks allocations that found no capacity until their pool grows.
+// Waiters of a pool are served by descending priority and FIFO within a priority: only
+// the head retries its allocation when capacity may have changed, and a successful
+// head hands over to the next one.
+type CapacityQueue struct {
+	// ScaleUp 可选，有请求排队时投递对应的 SandboxPool，立即触发扩容
+	ScaleUp chan<- event.GenericEvent
+
+	mu    sync.Mutex
+	pools map[string][]*capacityWaiter
+}
+
+type capacityWaiter struct {
+	priority int32
+	// wake 缓冲为 1，多次唤醒合并为一次重试
+	wake chan struct{}
+}
+
+// NewCapacityQueue creates an empty queue.
+func NewCapacityQueue() *CapacityQueue {
+	return &CapacityQueue{pools: make(map[string][]*capacityWaiter)}
+}
+
+// Wait queues sb behind the waiters of its pool with the same or a higher priority and
+// calls allocate each time capacity may have changed, until it succeeds, fails for a
+// reason that released sandboxes cannot fix (see IsUnschedulable), or ctx is done.
+func (q *CapacityQueue) Wait(ctx context.Context, sb *apiv1alpha1.Sandbox, allocate func(*apiv1alpha1.Sandbox) (*AgentInfo, error)) (*AgentInfo, error) {
+	key := poolKey(sb.Namespace, sb.Spec.PoolRef)
+	w := &capacityWaiter{priority: sb.Spec.Priority, wake: make(chan struct{}, 1)}
+	position := q.push(key, w)
+	defer q.remove(key, w)
+	klog.InfoS("Sandbox queued for pool capacity", "sandbox", sb.Name, "namespace", sb.Namespace, "pool", sb.Spec.PoolRef, "position", position)
+	q.requestScaleUp(sb.Namespace, sb.Spec.PoolRef)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-w.wake:
+		}
+		agent, err := allocate(sb)
+		if err == nil {
+			klog.InfoS("Queued sandbox allocated", "sandbox", sb.Name, "namespace", sb.Namespace, "agent", agent.ID)
+			return agent, nil
+		}
+		if !IsUnschedulable(err) {
+			return nil, err
+		}
+	}
+}
+
+// Notify wakes the head waiter of the pool, called when its capacity may have grown.
+func (q *CapacityQueue) Notify(namespace, pool string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if waiters := q.pools[poolKey(namespace, pool)]; len(waiters) > 0 {
+		waiters[0].signal()
+	}
+}
+
+// Pending returns the number of sandboxes waiting for capacity in the pool.
+func (q *CapacityQueue) Pending(namespace, pool string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pools[poolKey(namespace, pool)])
+}
+
+// push inserts w after the waiters of the same or a higher priority and returns its
+// 1-based position. A new head retries at once, the capacity it missed may have been
+// freed before it was queued.
+func (q *CapacityQueue) push(key string, w *capacityWaiter) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	waiters := q.pools[key]
+	i := len(waiters)
+	for i > 0 && waiters[i-1].priority < w.priority {
+		i--
+	}
+	q.pools[key] = slices.Insert(waiters, i, w)
+	if i == 0 {
+		w.signal()
+	}
+	return i + 1
+}
+
+// remove drops w from the queue and wakes the next waiter if w was the head.
+func (q *CapacityQueue) remove(key string, w *capacityWaiter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	waiters := q.pools[key]
+	i := slices.Index(waiters, w)
+	if i < 0 {
+		return
+	}
+	waiters = slices.Delete(waiters, i, i+1)
+	if len(waiters) == 0 {
+		delete(q.pools, key)
+		return
+	}
+	q.pools[key] = waiters
+	if i == 0 {
+		waiters[0].signal()
+	}
+}
+
+func (q *CapacityQueue) requestScaleUp(namespace, pool string) {
+	if q.ScaleUp == nil {
+		return
+	}
+	sp := &apiv1alpha1.SandboxPool{ObjectMeta: metav1.ObjectMeta{Name: pool, Namespace: namespace}}
+	select {
+	case q.ScaleUp <- event.GenericEvent{Object: sp}:
+	default:
+	}
+}
+
+func (w *capacityWaiter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
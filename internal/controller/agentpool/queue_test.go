@@ -0,0 +1,135 @@
+package agentpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// newQueuedRegistry returns a registry wired to a queue, as in the controller.
+func newQueuedRegistry() (*InMemoryRegistry, *CapacityQueue) {
+	registry := NewInMemoryRegistry()
+	queue := NewCapacityQueue()
+	registry.SetCapacityListener(queue.Notify)
+	return registry, queue
+}
+
+type waitResult struct {
+	agent *AgentInfo
+	err   error
+}
+
+func waitAsync(queue *CapacityQueue, registry *InMemoryRegistry, ctx context.Context, name string, opts ...func(*apiv1alpha1.Sandbox)) <-chan waitResult {
+	ch := make(chan waitResult, 1)
+	go func() {
+		agent, err := queue.Wait(ctx, newTestSandbox(name, append(opts, withSandboxPoolRef("test-pool"))...), registry.Allocate)
+		ch <- waitResult{agent, err}
+	}()
+	return ch
+}
+
+func TestCapacityQueue_AllocatesWhenAgentRegisters(t *testing.T) {
+	// Q-01: A queued sandbox is allocated as soon as an agent of its pool registers
+	registry, queue := newQueuedRegistry()
+	scaleUp := make(chan event.GenericEvent, 1)
+	queue.ScaleUp = scaleUp
+
+	result := waitAsync(queue, registry, context.Background(), "sb-1")
+	select {
+	case ev := <-scaleUp:
+		assert.Equal(t, "test-pool", ev.Object.GetName())
+	case <-time.After(time.Second):
+		t.Fatal("Queueing should request a scale-up")
+	}
+	require.Eventually(t, func() bool { return queue.Pending("default", "test-pool") == 1 }, time.Second, time.Millisecond)
+
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withPoolName("test-pool"), withCapacity(1)))
+	select {
+	case r := <-result:
+		require.NoError(t, r.err)
+		assert.Equal(t, AgentID("agent-1"), r.agent.ID)
+	case <-time.After(time.Second):
+		t.Fatal("Queued sandbox was not allocated")
+	}
+	assert.Equal(t, 0, queue.Pending("default", "test-pool"))
+}
+
+func TestCapacityQueue_FIFO(t *testing.T) {
+	// Q-02: Waiters are served in arrival order as capacity is released
+	registry, queue := newQueuedRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withPoolName("test-pool"), withCapacity(1)))
+	holder := newTestSandbox("holder", withSandboxPoolRef("test-pool"))
+	_, err := registry.Allocate(holder)
+	require.NoError(t, err)
+
+	first := waitAsync(queue, registry, context.Background(), "sb-1")
+	require.Eventually(t, func() bool { return queue.Pending("default", "test-pool") == 1 }, time.Second, time.Millisecond)
+	second := waitAsync(queue, registry, context.Background(), "sb-2")
+	require.Eventually(t, func() bool { return queue.Pending("default", "test-pool") == 2 }, time.Second, time.Millisecond)
+
+	registry.Release("agent-1", holder)
+	r := <-first
+	require.NoError(t, r.err)
+	select {
+	case <-second:
+		t.Fatal("The second waiter must not get the slot of the first")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	registry.Release("agent-1", newTestSandbox("sb-1", withSandboxPoolRef("test-pool")))
+	r = <-second
+	require.NoError(t, r.err)
+}
+
+func TestCapacityQueue_Timeout(t *testing.T) {
+	// Q-03: A waiter that times out leaves the queue and hands over to the next one
+	registry, queue := newQueuedRegistry()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	r := <-waitAsync(queue, registry, ctx, "sb-1")
+	assert.ErrorIs(t, r.err, context.DeadlineExceeded)
+	assert.Equal(t, 0, queue.Pending("default", "test-pool"))
+}
+
+func TestCapacityQueue_OtherErrorsFailFast(t *testing.T) {
+	// Q-04: Errors other than insufficient capacity are returned without waiting
+	queue := NewCapacityQueue()
+	boom := errors.New("boom")
+	_, err := queue.Wait(context.Background(), newTestSandbox("sb-1"), func(*apiv1alpha1.Sandbox) (*AgentInfo, error) { return nil, boom })
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestCapacityQueue_Priority(t *testing.T) {
+	// Q-05: A higher-priority waiter is served before earlier waiters of lower priority
+	registry, queue := newQueuedRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withPoolName("test-pool"), withCapacity(1)))
+	holder := newTestSandbox("holder", withSandboxPoolRef("test-pool"))
+	_, err := registry.Allocate(holder)
+	require.NoError(t, err)
+
+	low := waitAsync(queue, registry, context.Background(), "low")
+	require.Eventually(t, func() bool { return queue.Pending("default", "test-pool") == 1 }, time.Second, time.Millisecond)
+	high := waitAsync(queue, registry, context.Background(), "high", func(sb *apiv1alpha1.Sandbox) { sb.Spec.Priority = 10 })
+	require.Eventually(t, func() bool { return queue.Pending("default", "test-pool") == 2 }, time.Second, time.Millisecond)
+
+	registry.Release("agent-1", holder)
+	r := <-high
+	require.NoError(t, r.err)
+	select {
+	case <-low:
+		t.Fatal("The lower-priority waiter must not get the released slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	registry.Release("agent-1", newTestSandbox("high", withSandboxPoolRef("test-pool")))
+	r = <-low
+	require.NoError(t, r.err)
+}
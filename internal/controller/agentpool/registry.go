@@ -3,6 +3,7 @@ package agentpool
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,6 +37,13 @@ type AgentInfo struct {
 	Images          []string
 	SandboxStatuses map[string]api.SandboxStatus
 	LastHeartbeat   time.Time
+
+	// AllocatableCPU（毫核）与 AllocatableMemory（字节）由 Agent 上报，0 表示不限制
+	AllocatableCPU    int64
+	AllocatableMemory int64
+	// AllocatedCPU 与 AllocatedMemory 为已分配 sandbox 的 requests 之和，由 Registry 维护
+	AllocatedCPU    int64
+	AllocatedMemory int64
 }
 
 // AgentRegistry defines operations to manage agents in controller memory.
@@ -44,10 +52,13 @@ type AgentRegistry interface {
 	GetAllAgents() []AgentInfo
 	GetAgentByID(id AgentID) (AgentInfo, bool)
 	Allocate(sb *apiv1alpha1.Sandbox) (*AgentInfo, error)
+	AllocateBatch(sbs []*apiv1alpha1.Sandbox) ([]*AgentInfo, []error)
 	Release(id AgentID, sb *apiv1alpha1.Sandbox)
+	SelectVictims(sb *apiv1alpha1.Sandbox, running []apiv1alpha1.Sandbox) []*apiv1alpha1.Sandbox
 	Restore(ctx context.Context, c client.Reader) error
 	Remove(id AgentID)
 	CleanupStaleAgents(timeout time.Duration) int
+	SetPoolStrategy(namespace, pool string, strategy apiv1alpha1.SchedulingStrategy)
 }
 
 type agentSlot struct {
@@ -58,15 +69,52 @@ type agentSlot struct {
 type InMemoryRegistry struct {
 	mu     sync.RWMutex
 	agents map[AgentID]*agentSlot
+	// strategies 记录每个池（namespace/name）的调度策略，未设置时使用默认策略
+	strategies map[string]apiv1alpha1.SchedulingStrategy
+	// capacityListener 在池的容量可能增加时被调用（Agent 加入、容量变化、释放 sandbox）
+	capacityListener func(namespace, pool string)
 }
 
 // NewInMemoryRegistry creates a new in-memory registry.
 func NewInMemoryRegistry() *InMemoryRegistry {
 	return &InMemoryRegistry{
-		agents: make(map[AgentID]*agentSlot),
+		agents:     make(map[AgentID]*agentSlot),
+		strategies: make(map[string]apiv1alpha1.SchedulingStrategy),
 	}
 }
 
+// SetCapacityListener registers fn to be called, without registry locks held, whenever
+// the capacity of a pool may have changed.
+func (r *InMemoryRegistry) SetCapacityListener(fn func(namespace, pool string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.capacityListener = fn
+}
+
+func (r *InMemoryRegistry) notifyCapacity(namespace, pool string) {
+	r.mu.RLock()
+	fn := r.capacityListener
+	r.mu.RUnlock()
+	if fn != nil && pool != "" {
+		fn(namespace, pool)
+	}
+}
+
+func poolKey(namespace, pool string) string {
+	return namespace + "/" + pool
+}
+
+// SetPoolStrategy sets the scheduling strategy used for sandboxes of a pool.
+func (r *InMemoryRegistry) SetPoolStrategy(namespace, pool string, strategy apiv1alpha1.SchedulingStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if strategy == "" {
+		delete(r.strategies, poolKey(namespace, pool))
+		return
+	}
+	r.strategies[poolKey(namespace, pool)] = strategy
+}
+
 func (r *InMemoryRegistry) RegisterOrUpdate(info AgentInfo) {
 	r.mu.RLock()
 	slot, exists := r.agents[info.ID]
@@ -87,15 +135,28 @@ func (r *InMemoryRegistry) RegisterOrUpdate(info AgentInfo) {
 		r.mu.Unlock()
 	}
 
+	var changed bool
+	defer func() {
+		if changed {
+			r.notifyCapacity(info.Namespace, info.PoolName)
+		}
+	}()
 	slot.mu.Lock()
 	defer slot.mu.Unlock()
 
+	prev := slot.info
+	changed = prev.PoolName != info.PoolName || prev.Namespace != info.Namespace || prev.Capacity != info.Capacity ||
+		prev.AllocatableCPU != info.AllocatableCPU || prev.AllocatableMemory != info.AllocatableMemory
 	allocated := slot.info.Allocated
+	allocatedCPU := slot.info.AllocatedCPU
+	allocatedMemory := slot.info.AllocatedMemory
 	usedPorts := slot.info.UsedPorts
 	sandboxStatuses := slot.info.SandboxStatuses
 
 	slot.info = info
 	slot.info.Allocated = allocated
+	slot.info.AllocatedCPU = allocatedCPU
+	slot.info.AllocatedMemory = allocatedMemory
 
 	if usedPorts != nil {
 		slot.info.UsedPorts = usedPorts
@@ -190,124 +251,144 @@ func (r *InMemoryRegistry) GetAgentByID(id AgentID) (AgentInfo, bool) {
 }
 
 func (r *InMemoryRegistry) Allocate(sb *apiv1alpha1.Sandbox) (*AgentInfo, error) {
-	totalStart := time.Now()
+	start := time.Now()
+	key := poolKey(sb.Namespace, sb.Spec.PoolRef)
+	candidates, strategies, unlock := r.lockPools(map[string]bool{key: true})
+	defer unlock()
+	lockDuration := time.Since(start)
+
+	agent, err := allocateLocked(sb, candidates[key], strategies[key])
+	if err != nil {
+		return nil, err
+	}
 
+	klog.InfoS("Registry Allocate timing",
+		"sandbox", sb.Name,
+		"total_ms", time.Since(start).Milliseconds(),
+		"lock_ms", lockDuration.Milliseconds(),
+		"selectedAgent", agent.ID,
+		"imageHit", imageLocality{}.Score(nil, sb, agent) > 0,
+		"strategy", strategies[key],
+		"agentCount", len(candidates[key]),
+		"allocated", agent.Allocated,
+		"allocatedCPU", agent.AllocatedCPU,
+		"allocatedMemory", agent.AllocatedMemory)
+	return agent, nil
+}
+
+// reserve accounts a sandbox placed on the agent. The caller must hold the slot lock.
+func reserve(info *AgentInfo, sb *apiv1alpha1.Sandbox, cpuRequest, memoryRequest int64) {
+	info.Allocated++
+	info.AllocatedCPU += cpuRequest
+	info.AllocatedMemory += memoryRequest
+	if info.UsedPorts == nil {
+		info.UsedPorts = make(map[int32]bool)
+	}
 	for _, p := range sb.Spec.ExposedPorts {
-		if p < 1 || p > 65535 {
-			return nil, fmt.Errorf("invalid port %d: must be between 1 and 65535", p)
-		}
+		info.UsedPorts[p] = true
 	}
+}
 
-	// 1. Find candidates
-	candidateStart := time.Now()
-	r.mu.RLock()
-	candidates := make([]*agentSlot, 0, len(r.agents))
-	for _, slot := range r.agents {
-		candidates = append(candidates, slot)
+// AllocateBatch allocates agents for several sandboxes in one pass. The slots of the
+// involved pools stay locked for the whole batch, and every placement is accounted
+// before the next sandbox is scored, so the batch sees a consistent view of the pool.
+// The results are index-aligned with sbs: a failed item has a nil agent and an error.
+func (r *InMemoryRegistry) AllocateBatch(sbs []*apiv1alpha1.Sandbox) ([]*AgentInfo, []error) {
+	start := time.Now()
+	agents := make([]*AgentInfo, len(sbs))
+	errs := make([]error, len(sbs))
+
+	pools := make(map[string]bool)
+	for _, sb := range sbs {
+		pools[poolKey(sb.Namespace, sb.Spec.PoolRef)] = true
 	}
-	r.mu.RUnlock()
-	candidateDuration := time.Since(candidateStart)
 
-	var bestSlot *agentSlot
-	var minScore = 1000000
-	var imageHit bool
+	candidates, strategies, unlock := r.lockPools(pools)
+	defer unlock()
 
-	// 2. Score agents and select best
-	scoreStart := time.Now()
-	for _, slot := range candidates {
-		slot.mu.RLock()
-		info := slot.info
+	for i, sb := range sbs {
+		agents[i], errs[i] = allocateLocked(sb, candidates[poolKey(sb.Namespace, sb.Spec.PoolRef)], strategies[poolKey(sb.Namespace, sb.Spec.PoolRef)])
+	}
 
-		if info.PoolName != sb.Spec.PoolRef {
-			slot.mu.RUnlock()
-			continue
-		}
-		if info.Namespace != sb.Namespace {
-			slot.mu.RUnlock()
-			continue
-		}
-		if info.Capacity > 0 && info.Allocated >= info.Capacity {
-			slot.mu.RUnlock()
-			continue
-		}
+	klog.InfoS("Registry AllocateBatch timing", "count", len(sbs), "pools", len(pools), "total_ms", time.Since(start).Milliseconds())
+	return agents, errs
+}
 
-		portConflict := false
-		for _, p := range sb.Spec.ExposedPorts {
-			if info.UsedPorts[p] {
-				portConflict = true
-				break
-			}
-		}
-		if portConflict {
-			slot.mu.RUnlock()
-			continue
-		}
+// lockPools write-locks the slots of the given pools and returns them with the pool
+// strategies, keyed by poolKey. The slots stay locked until unlock is called.
+func (r *InMemoryRegistry) lockPools(pools map[string]bool) (map[string][]*agentSlot, map[string]apiv1alpha1.SchedulingStrategy, func()) {
+	r.mu.RLock()
+	ids := make([]AgentID, 0, len(r.agents))
+	for id := range r.agents {
+		ids = append(ids, id)
+	}
+	slots := make(map[AgentID]*agentSlot, len(ids))
+	for _, id := range ids {
+		slots[id] = r.agents[id]
+	}
+	strategies := make(map[string]apiv1alpha1.SchedulingStrategy, len(pools))
+	for key := range pools {
+		strategies[key] = r.strategies[key]
+	}
+	r.mu.RUnlock()
 
-		hasImage := false
-		for _, img := range info.Images {
-			if img == sb.Spec.Image {
-				hasImage = true
-				break
-			}
+	// 按 ID 顺序加锁，避免并发的分配互相死锁
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	candidates := make(map[string][]*agentSlot, len(pools))
+	var locked []*agentSlot
+	for _, id := range ids {
+		slot := slots[id]
+		slot.mu.Lock()
+		key := poolKey(slot.info.Namespace, slot.info.PoolName)
+		if !pools[key] {
+			slot.mu.Unlock()
+			continue
 		}
-
-		klog.V(4).Info("Checking image affinity", "sandbox", sb.Name, "agent", info.ID, "hasImage", hasImage, "image", sb.Spec.Image)
-
-		score := info.Allocated
-		if !hasImage {
-			score += 1000
+		locked = append(locked, slot)
+		candidates[key] = append(candidates[key], slot)
+	}
+	return candidates, strategies, func() {
+		for _, slot := range locked {
+			slot.mu.Unlock()
 		}
+	}
+}
 
-		slot.mu.RUnlock()
-
-		if score < minScore {
-			minScore = score
-			bestSlot = slot
-			imageHit = hasImage
+// allocateLocked picks and reserves the best agent among slots already locked by the
+// caller. It is the only scheduling path of Allocate and AllocateBatch.
+func allocateLocked(sb *apiv1alpha1.Sandbox, slots []*agentSlot, strategy apiv1alpha1.SchedulingStrategy) (*AgentInfo, error) {
+	for _, p := range sb.Spec.ExposedPorts {
+		if p < 1 || p > 65535 {
+			return nil, fmt.Errorf("%w %d: must be between 1 and 65535", ErrInvalidPort, p)
 		}
 	}
-	scoreDuration := time.Since(scoreStart)
 
-	if bestSlot == nil {
-		return nil, fmt.Errorf("insufficient capacity or port conflict in pool %s", sb.Spec.PoolRef)
+	infos := make([]AgentInfo, len(slots))
+	for i, slot := range slots {
+		infos[i] = slot.info
 	}
+	profile := ProfileFor(strategy)
+	state := newSchedulingState(sb, infos)
 
-	// 3. Final allocation
-	selectStart := time.Now()
-	bestSlot.mu.Lock()
-	defer bestSlot.mu.Unlock()
-
-	info := bestSlot.info
-	if info.Capacity > 0 && info.Allocated >= info.Capacity {
-		return nil, fmt.Errorf("agent %s capacity full during allocation", info.ID)
-	}
-	for _, p := range sb.Spec.ExposedPorts {
-		if info.UsedPorts[p] {
-			return nil, fmt.Errorf("port %d conflicted during allocation", p)
+	var bestSlot *agentSlot
+	var maxScore float64
+	for i, slot := range slots {
+		info := &infos[i]
+		if !profile.Feasible(state, sb, info) {
+			continue
+		}
+		score := profile.Score(state, sb, info)
+		klog.V(4).Info("Scored agent", "sandbox", sb.Name, "agent", info.ID, "strategy", strategy, "score", score)
+		if bestSlot == nil || score > maxScore {
+			maxScore = score
+			bestSlot = slot
 		}
 	}
-
-	bestSlot.info.Allocated++
-	if bestSlot.info.UsedPorts == nil {
-		bestSlot.info.UsedPorts = make(map[int32]bool)
-	}
-	for _, p := range sb.Spec.ExposedPorts {
-		bestSlot.info.UsedPorts[p] = true
+	if bestSlot == nil {
+		return nil, unschedulableError(sb, profile, state, infos)
 	}
-	selectDuration := time.Since(selectStart)
-	totalDuration := time.Since(totalStart)
-
-	klog.InfoS("Registry Allocate timing",
-		"sandbox", sb.Name,
-		"total_ms", totalDuration.Milliseconds(),
-		"candidate_ms", candidateDuration.Milliseconds(),
-		"score_ms", scoreDuration.Milliseconds(),
-		"select_ms", selectDuration.Milliseconds(),
-		"selectedAgent", info.ID,
-		"imageHit", imageHit,
-		"agentCount", len(candidates),
-		"bestSlot.info.Allocated", bestSlot.info.Allocated)
 
+	reserve(&bestSlot.info, sb, state.CPURequest, state.MemoryRequest)
 	res := bestSlot.info
 	return &res, nil
 }
@@ -329,8 +410,11 @@ func (r *InMemoryRegistry) Release(id AgentID, sb *apiv1alpha1.Sandbox) {
 		return
 	}
 
+	var namespace, pool string
+	defer func() { r.notifyCapacity(namespace, pool) }()
 	slot.mu.Lock()
 	defer slot.mu.Unlock()
+	namespace, pool = slot.info.Namespace, slot.info.PoolName
 
 	klog.Info("[DEBUG-REGISTRY] Release: slot state BEFORE",
 		"allocated", slot.info.Allocated,
@@ -358,6 +442,10 @@ func (r *InMemoryRegistry) Release(id AgentID, sb *apiv1alpha1.Sandbox) {
 			"this", "indicates double-free or accounting bug")
 	}
 
+	cpuRequest, memoryRequest := SandboxRequests(sb)
+	slot.info.AllocatedCPU = max(slot.info.AllocatedCPU-cpuRequest, 0)
+	slot.info.AllocatedMemory = max(slot.info.AllocatedMemory-memoryRequest, 0)
+
 	for _, p := range sb.Spec.ExposedPorts {
 		delete(slot.info.UsedPorts, p)
 	}
@@ -430,6 +518,9 @@ func (r *InMemoryRegistry) Restore(ctx context.Context, c client.Reader) error {
 			item.slot.info.SandboxStatuses = make(map[string]api.SandboxStatus)
 		}
 		item.slot.info.Allocated++
+		cpuRequest, memoryRequest := SandboxRequests(item.sb)
+		item.slot.info.AllocatedCPU += cpuRequest
+		item.slot.info.AllocatedMemory += memoryRequest
 		for _, p := range item.sb.Spec.ExposedPorts {
 			item.slot.info.UsedPorts[p] = true
 		}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -80,6 +82,13 @@ func withLastHeartbeat(t time.Time) func(*AgentInfo) {
 	return func(a *AgentInfo) { a.LastHeartbeat = t }
 }
 
+func withAllocatable(cpuMilli, memoryBytes int64) func(*AgentInfo) {
+	return func(a *AgentInfo) {
+		a.AllocatableCPU = cpuMilli
+		a.AllocatableMemory = memoryBytes
+	}
+}
+
 func newTestSandbox(name string, opts ...func(*apiv1alpha1.Sandbox)) *apiv1alpha1.Sandbox {
 	sb := &apiv1alpha1.Sandbox{
 		ObjectMeta: metav1.ObjectMeta{
@@ -113,6 +122,15 @@ func withSandboxPorts(ports ...int32) func(*apiv1alpha1.Sandbox) {
 	return func(sb *apiv1alpha1.Sandbox) { sb.Spec.ExposedPorts = ports }
 }
 
+func withSandboxLimits(cpu, memory string) func(*apiv1alpha1.Sandbox) {
+	return func(sb *apiv1alpha1.Sandbox) {
+		sb.Spec.Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+	}
+}
+
 // ============================================================================
 // 1. RegisterOrUpdate Tests
 // ============================================================================
@@ -288,6 +306,7 @@ func TestInMemoryRegistry_Allocate_CapacityCheck(t *testing.T) {
 	_, err := registry.Allocate(sandbox)
 	assert.Error(t, err, "Should fail when no capacity in matching pool")
 	assert.Contains(t, err.Error(), "insufficient capacity")
+	assert.ErrorIs(t, err, ErrInsufficientCapacity)
 }
 
 func TestInMemoryRegistry_Allocate_PortConflict(t *testing.T) {
@@ -312,7 +331,10 @@ func TestInMemoryRegistry_Allocate_PortConflict(t *testing.T) {
 
 	_, err := registry.Allocate(sandbox)
 	assert.Error(t, err, "Should fail when port 8080 is already in use")
-	assert.Contains(t, err.Error(), "insufficient capacity or port conflict")
+	var conflict *PortConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []int32{8080}, conflict.Ports)
+	assert.NotErrorIs(t, err, ErrInsufficientCapacity)
 }
 
 func TestInMemoryRegistry_Allocate_SelectsAgentWithAvailablePorts(t *testing.T) {
@@ -426,21 +448,19 @@ func TestInMemoryRegistry_Allocate_LeastLoadedPreferred(t *testing.T) {
 	// A-08: When image affinity doesn't apply, prefer least loaded agent
 	registry := NewInMemoryRegistry()
 
-	// Register agent-1 with limited capacity to force some allocations to agent-2
+	// Fill agent-1 before agent-2 joins, then the 3rd sandbox goes to agent-2
 	registry.RegisterOrUpdate(newTestAgentInfo("agent-1",
 		withPoolName("test-pool"),
 		withCapacity(2), // Limited to 2 allocations
 	))
+	for i := 0; i < 2; i++ {
+		_, _ = registry.Allocate(newTestSandbox("load-" + string(rune('0'+i))))
+	}
 	registry.RegisterOrUpdate(newTestAgentInfo("agent-2",
 		withPoolName("test-pool"),
 		withCapacity(10),
 	))
-
-	// Allocate 3 sandboxes - first 2 go to agent-1 (fills it), 3rd goes to agent-2
-	for i := 0; i < 3; i++ {
-		dummySB := newTestSandbox("load-"+string(rune('0'+i)))
-		_, _ = registry.Allocate(dummySB)
-	}
+	_, _ = registry.Allocate(newTestSandbox("load-2"))
 
 	// Verify state
 	agent1, _ := registry.GetAgentByID("agent-1")
@@ -526,6 +546,139 @@ func TestInMemoryRegistry_Allocate_ImageAffinityOverLoad(t *testing.T) {
 	assert.Equal(t, 2, agent.Allocated)
 }
 
+func TestInMemoryRegistry_Allocate_ResourceFit(t *testing.T) {
+	// A-11: Agents without enough allocatable CPU or memory are skipped
+	registry := NewInMemoryRegistry()
+
+	registry.RegisterOrUpdate(newTestAgentInfo("small-agent",
+		withAllocatable(1000, 1<<30),
+	))
+	registry.RegisterOrUpdate(newTestAgentInfo("big-agent",
+		withAllocatable(8000, 16<<30),
+		withAllocated(5),
+	))
+
+	sandbox := newTestSandbox("build-sb", withSandboxLimits("4", "2Gi"))
+
+	agent, err := registry.Allocate(sandbox)
+	require.NoError(t, err)
+	assert.Equal(t, AgentID("big-agent"), agent.ID, "Only big-agent can hold 4 CPUs")
+	assert.Equal(t, int64(4000), agent.AllocatedCPU)
+	assert.Equal(t, int64(2<<30), agent.AllocatedMemory)
+}
+
+func TestInMemoryRegistry_Allocate_ResourceExhausted(t *testing.T) {
+	// A-12: Allocation fails once requests exceed allocatable, even with free slots
+	registry := NewInMemoryRegistry()
+
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1",
+		withCapacity(10),
+		withAllocatable(2000, 4<<30),
+	))
+
+	_, err := registry.Allocate(newTestSandbox("sb-1", withSandboxLimits("1500m", "1Gi")))
+	require.NoError(t, err)
+
+	_, err = registry.Allocate(newTestSandbox("sb-2", withSandboxLimits("1", "1Gi")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient capacity")
+
+	// Sandboxes without resources still fit
+	_, err = registry.Allocate(newTestSandbox("sb-3"))
+	require.NoError(t, err)
+}
+
+func TestInMemoryRegistry_Allocate_PrefersMoreFreeResources(t *testing.T) {
+	// A-13: With equal slot usage, the agent with more free resources is preferred
+	registry := NewInMemoryRegistry()
+
+	registry.RegisterOrUpdate(newTestAgentInfo("busy-agent",
+		withAllocatable(4000, 8<<30),
+	))
+	registry.RegisterOrUpdate(newTestAgentInfo("idle-agent",
+		withAllocatable(4000, 8<<30),
+	))
+
+	// Occupy most of busy-agent's CPU with a single sandbox
+	_, err := registry.Allocate(newTestSandbox("heavy", withSandboxLimits("3", "1Gi")))
+	require.NoError(t, err)
+	_, err = registry.Allocate(newTestSandbox("light-1", withSandboxLimits("100m", "64Mi")))
+	require.NoError(t, err)
+
+	heavyAgent := "busy-agent"
+	if a, _ := registry.GetAgentByID("idle-agent"); a.AllocatedCPU == 3000 {
+		heavyAgent = "idle-agent"
+	}
+
+	agent, err := registry.Allocate(newTestSandbox("light-2", withSandboxLimits("500m", "128Mi")))
+	require.NoError(t, err)
+	assert.NotEqual(t, AgentID(heavyAgent), agent.ID, "Should avoid the agent running the heavy sandbox")
+}
+
+func TestInMemoryRegistry_Allocate_RequestsOverLimits(t *testing.T) {
+	// A-14: Requests are used for accounting when set, limits otherwise
+	registry := NewInMemoryRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withAllocatable(4000, 8<<30)))
+
+	sandbox := newTestSandbox("sb", withSandboxLimits("2", "1Gi"))
+	sandbox.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}
+
+	agent, err := registry.Allocate(sandbox)
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), agent.AllocatedCPU)
+	assert.Equal(t, int64(1<<30), agent.AllocatedMemory)
+}
+
+func TestInMemoryRegistry_AllocateBatch_PartialFailure(t *testing.T) {
+	// A-20: A batch fills the pool in one pass, items beyond capacity fail individually
+	registry := NewInMemoryRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withCapacity(2)))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-2", withCapacity(1)))
+	registry.RegisterOrUpdate(newTestAgentInfo("other-pool", withPoolName("other-pool")))
+
+	sbs := []*apiv1alpha1.Sandbox{
+		newTestSandbox("sb-0"), newTestSandbox("sb-1"), newTestSandbox("sb-2"), newTestSandbox("sb-3"),
+	}
+	agents, errs := registry.AllocateBatch(sbs)
+	require.Len(t, agents, 4)
+	require.Len(t, errs, 4)
+
+	placed := map[AgentID]int{}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, errs[i])
+		placed[agents[i].ID]++
+	}
+	assert.Equal(t, map[AgentID]int{"agent-1": 2, "agent-2": 1}, placed, "Every placement counts before the next item")
+	assert.Nil(t, agents[3])
+	assert.Contains(t, errs[3].Error(), "insufficient capacity")
+
+	agent, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 2, agent.Allocated)
+	agent, _ = registry.GetAgentByID("other-pool")
+	assert.Equal(t, 0, agent.Allocated, "Agents of other pools are untouched")
+}
+
+func TestInMemoryRegistry_AllocateBatch_PortsAndPools(t *testing.T) {
+	// A-21: Ports reserved earlier in the batch are seen by later items, pools are independent
+	registry := NewInMemoryRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-a", withPoolName("pool-a")))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-b", withPoolName("pool-b")))
+
+	agents, errs := registry.AllocateBatch([]*apiv1alpha1.Sandbox{
+		newTestSandbox("sb-0", withSandboxPoolRef("pool-a"), withSandboxPorts(8080)),
+		newTestSandbox("sb-1", withSandboxPoolRef("pool-a"), withSandboxPorts(8080)),
+		newTestSandbox("sb-2", withSandboxPoolRef("pool-b"), withSandboxPorts(8080)),
+		newTestSandbox("sb-3", withSandboxPoolRef("pool-a"), withSandboxPorts(0)),
+	})
+
+	require.NoError(t, errs[0])
+	assert.Equal(t, AgentID("agent-a"), agents[0].ID)
+	assert.Contains(t, errs[1].Error(), "port conflict")
+	require.NoError(t, errs[2])
+	assert.Equal(t, AgentID("agent-b"), agents[2].ID)
+	assert.Contains(t, errs[3].Error(), "invalid port")
+}
+
 // ============================================================================
 // 3. Release Tests
 // ============================================================================
@@ -642,6 +795,32 @@ func TestInMemoryRegistry_Release_WithPartialPortMatch(t *testing.T) {
 	assert.True(t, agent.UsedPorts[7070], "Port 7070 should remain in use")
 }
 
+func TestInMemoryRegistry_Release_Resources(t *testing.T) {
+	// L-05: Release returns the sandbox requests and survives heartbeats in between
+	registry := NewInMemoryRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withAllocatable(4000, 8<<30)))
+
+	sandbox := newTestSandbox("test-sb", withSandboxLimits("1", "512Mi"))
+	_, err := registry.Allocate(sandbox)
+	require.NoError(t, err)
+
+	// A heartbeat must not reset the tracked usage
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withAllocatable(4000, 8<<30)))
+	agent, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, int64(1000), agent.AllocatedCPU)
+	assert.Equal(t, int64(512<<20), agent.AllocatedMemory)
+
+	registry.Release("agent-1", sandbox)
+	agent, _ = registry.GetAgentByID("agent-1")
+	assert.Equal(t, int64(0), agent.AllocatedCPU)
+	assert.Equal(t, int64(0), agent.AllocatedMemory)
+
+	// Double release does not go negative
+	registry.Release("agent-1", sandbox)
+	agent, _ = registry.GetAgentByID("agent-1")
+	assert.Equal(t, int64(0), agent.AllocatedCPU)
+}
+
 // ============================================================================
 // 4. GetAllAgents Tests
 // ============================================================================
@@ -1010,3 +1189,28 @@ func TestInMemoryRegistry_ConcurrentRelease(t *testing.T) {
 	agent, _ := registry.GetAgentByID("agent-1")
 	assert.Equal(t, 0, agent.Allocated, "All allocations should be released")
 }
+
+func TestInMemoryRegistry_Allocate_FailureReason(t *testing.T) {
+	// A-22: A port conflict is only reported when an agent with room was rejected for its ports
+	registry := NewInMemoryRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-full", withPoolName("test-pool"), withCapacity(1)))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-free", withPoolName("test-pool"), withCapacity(2)))
+
+	_, err := registry.Allocate(newTestSandbox("sb-1", withSandboxPorts(8080)))
+	require.NoError(t, err)
+	_, err = registry.Allocate(newTestSandbox("sb-2", withSandboxPorts(8080)))
+	require.NoError(t, err)
+
+	_, err = registry.Allocate(newTestSandbox("sb-3", withSandboxPorts(8080, 9090)))
+	var conflict *PortConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []int32{8080}, conflict.Ports)
+
+	_, err = registry.Allocate(newTestSandbox("sb-4"))
+	require.NoError(t, err)
+	_, err = registry.Allocate(newTestSandbox("sb-5", withSandboxPorts(8080)))
+	assert.ErrorIs(t, err, ErrInsufficientCapacity, "Full agents are a capacity problem even if the ports conflict too")
+
+	_, err = NewInMemoryRegistry().Allocate(newTestSandbox("sb-6"))
+	assert.ErrorIs(t, err, ErrInsufficientCapacity)
+}
@@ -0,0 +1,51 @@
+package agentpool
+
+import (
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SandboxRequests returns the CPU (millicores) and memory (bytes) requested by a sandbox.
+// As in Kubernetes, a missing request defaults to the limit.
+func SandboxRequests(sb *apiv1alpha1.Sandbox) (cpu, memory int64) {
+	res := sb.Spec.Resources
+	if q, ok := res.Requests[corev1.ResourceCPU]; ok {
+		cpu = q.MilliValue()
+	} else if q, ok := res.Limits[corev1.ResourceCPU]; ok {
+		cpu = q.MilliValue()
+	}
+	if q, ok := res.Requests[corev1.ResourceMemory]; ok {
+		memory = q.Value()
+	} else if q, ok := res.Limits[corev1.ResourceMemory]; ok {
+		memory = q.Value()
+	}
+	return cpu, memory
+}
+
+// fitsResources reports whether the agent can hold the requested resources.
+// An allocatable value of 0 means the agent did not report it and is not limited.
+func fitsResources(info *AgentInfo, cpu, memory int64) bool {
+	if info.AllocatableCPU > 0 && info.AllocatedCPU+cpu > info.AllocatableCPU {
+		return false
+	}
+	if info.AllocatableMemory > 0 && info.AllocatedMemory+memory > info.AllocatableMemory {
+		return false
+	}
+	return true
+}
+
+// resourceUsagePercent returns the higher of CPU and memory usage (0-100) the agent
+// would have after placing the request. Unreported resources count as 0.
+func resourceUsagePercent(info *AgentInfo, cpu, memory int64) int {
+	usage := 0
+	if info.AllocatableCPU > 0 {
+		usage = int((info.AllocatedCPU + cpu) * 100 / info.AllocatableCPU)
+	}
+	if info.AllocatableMemory > 0 {
+		if m := int((info.AllocatedMemory + memory) * 100 / info.AllocatableMemory); m > usage {
+			usage = m
+		}
+	}
+	return usage
+}
@@ -0,0 +1,244 @@
+package agentpool
+
+import (
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/common"
+
+	"k8s.io/klog/v2"
+)
+
+// MaxScore is the highest score a ScorePlugin may return.
+const MaxScore = 100.0
+
+// SchedulingState carries per-allocation data shared by all plugins.
+type SchedulingState struct {
+	// CPURequest（毫核）与 MemoryRequest（字节）为 sandbox 的资源请求，requests 缺省等于 limits
+	CPURequest    int64
+	MemoryRequest int64
+	// SandboxesPerNode 为池内每个节点上已分配的 sandbox 数
+	SandboxesPerNode map[string]int
+	// MaxSandboxesPerNode 为 SandboxesPerNode 中的最大值
+	MaxSandboxesPerNode int
+}
+
+// newSchedulingState computes the shared state from a snapshot of the pool's agents.
+func newSchedulingState(sb *apiv1alpha1.Sandbox, agents []AgentInfo) *SchedulingState {
+	state := &SchedulingState{SandboxesPerNode: make(map[string]int)}
+	state.CPURequest, state.MemoryRequest = SandboxRequests(sb)
+	for i := range agents {
+		n := state.SandboxesPerNode[agents[i].NodeName] + agents[i].Allocated
+		state.SandboxesPerNode[agents[i].NodeName] = n
+		state.MaxSandboxesPerNode = max(state.MaxSandboxesPerNode, n)
+	}
+	return state
+}
+
+// FilterPlugin rejects agents that cannot host a sandbox.
+type FilterPlugin interface {
+	Name() string
+	Filter(state *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) bool
+}
+
+// ScorePlugin rates a feasible agent from 0 (worst) to MaxScore (best).
+type ScorePlugin interface {
+	Name() string
+	Score(state *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) float64
+}
+
+// WeightedScore is a ScorePlugin together with its weight in a profile.
+type WeightedScore struct {
+	Plugin ScorePlugin
+	Weight float64
+}
+
+// Profile is the set of plugins run for one scheduling strategy.
+type Profile struct {
+	Filters []FilterPlugin
+	Scores  []WeightedScore
+}
+
+// Feasible reports whether all filters accept the agent.
+func (p *Profile) Feasible(state *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) bool {
+	return p.rejectedBy(state, sb, agent) == nil
+}
+
+// rejectedBy returns the first filter that rejects the agent, or nil if all accept it.
+func (p *Profile) rejectedBy(state *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) FilterPlugin {
+	for _, f := range p.Filters {
+		if !f.Filter(state, sb, agent) {
+			klog.V(5).InfoS("Agent filtered out", "sandbox", sb.Name, "agent", agent.ID, "filter", f.Name())
+			return f
+		}
+	}
+	return nil
+}
+
+// Score returns the weighted sum of all score plugins, higher is better.
+func (p *Profile) Score(state *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) float64 {
+	var total float64
+	for _, s := range p.Scores {
+		total += s.Weight * s.Plugin.Score(state, sb, agent)
+	}
+	return total
+}
+
+var defaultFilters = []FilterPlugin{nodeFilter{}, capacityFilter{}, resourceFilter{}, portFilter{}}
+
+// profiles maps every strategy to its plugins. The primary plugin carries weight 10,
+// tie-breakers weight 1, so a primary score gap of 10 points outweighs any tie-breaker.
+var profiles = map[apiv1alpha1.SchedulingStrategy]*Profile{
+	apiv1alpha1.SchedulingImageLocality: {
+		Filters: defaultFilters,
+		Scores:  []WeightedScore{{imageLocality{}, 10}, {leastLoaded{}, 1}},
+	},
+	apiv1alpha1.SchedulingLeastAllocated: {
+		Filters: defaultFilters,
+		Scores:  []WeightedScore{{leastAllocated{}, 10}, {imageLocality{}, 1}},
+	},
+	apiv1alpha1.SchedulingMostAllocated: {
+		Filters: defaultFilters,
+		Scores:  []WeightedScore{{mostAllocated{}, 10}, {imageLocality{}, 1}},
+	},
+	apiv1alpha1.SchedulingNodeSpread: {
+		Filters: defaultFilters,
+		Scores:  []WeightedScore{{nodeSpread{}, 10}, {leastLoaded{}, 1}},
+	},
+}
+
+// ProfileFor returns the plugins of a strategy, unknown or empty strategies use ImageLocality.
+func ProfileFor(strategy apiv1alpha1.SchedulingStrategy) *Profile {
+	if p, ok := profiles[strategy]; ok {
+		return p
+	}
+	return profiles[apiv1alpha1.SchedulingImageLocality]
+}
+
+// ============================================================================
+// Filters
+// ============================================================================
+
+// nodeFilter rejects agents on other nodes than the one holding the local checkpoint the
+// sandbox is restored from.
+type nodeFilter struct{}
+
+func (nodeFilter) Name() string { return "Node" }
+
+func (nodeFilter) Filter(_ *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) bool {
+	node := sb.Annotations[common.AnnotationRestoreNode]
+	return node == "" || agent.NodeName == node
+}
+
+// capacityFilter rejects agents whose sandbox slots are full, a capacity of 0 is unlimited.
+type capacityFilter struct{}
+
+func (capacityFilter) Name() string { return "Capacity" }
+
+func (capacityFilter) Filter(_ *SchedulingState, _ *apiv1alpha1.Sandbox, agent *AgentInfo) bool {
+	return agent.Capacity <= 0 || agent.Allocated < agent.Capacity
+}
+
+// resourceFilter rejects agents without enough allocatable CPU or memory.
+type resourceFilter struct{}
+
+func (resourceFilter) Name() string { return "Resources" }
+
+func (resourceFilter) Filter(state *SchedulingState, _ *apiv1alpha1.Sandbox, agent *AgentInfo) bool {
+	return fitsResources(agent, state.CPURequest, state.MemoryRequest)
+}
+
+// portFilter rejects agents where one of the exposed ports is already taken.
+type portFilter struct{}
+
+func (portFilter) Name() string { return "Ports" }
+
+func (portFilter) Filter(_ *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) bool {
+	for _, p := range sb.Spec.ExposedPorts {
+		if agent.UsedPorts[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================================
+// Scores
+// ============================================================================
+
+// imageLocality prefers agents that already have the sandbox image.
+type imageLocality struct{}
+
+func (imageLocality) Name() string { return "ImageLocality" }
+
+func (imageLocality) Score(_ *SchedulingState, sb *apiv1alpha1.Sandbox, agent *AgentInfo) float64 {
+	for _, img := range agent.Images {
+		if img == sb.Spec.Image {
+			return MaxScore
+		}
+	}
+	return 0
+}
+
+// leastLoaded prefers agents running the fewest sandboxes, then the lowest CPU/memory
+// usage. Unlike leastAllocated it ignores how many slots an agent has.
+type leastLoaded struct{}
+
+func (leastLoaded) Name() string { return "LeastLoaded" }
+
+func (leastLoaded) Score(state *SchedulingState, _ *apiv1alpha1.Sandbox, agent *AgentInfo) float64 {
+	load := agent.Allocated + resourceUsagePercent(agent, state.CPURequest, state.MemoryRequest)
+	return MaxScore * 100 / float64(100+load)
+}
+
+// leastAllocated prefers agents that stay the emptiest after placement (spread).
+type leastAllocated struct{}
+
+func (leastAllocated) Name() string { return "LeastAllocated" }
+
+func (leastAllocated) Score(state *SchedulingState, _ *apiv1alpha1.Sandbox, agent *AgentInfo) float64 {
+	return MaxScore * (1 - allocationRatio(state, agent))
+}
+
+// mostAllocated prefers agents that become the fullest after placement (bin-pack).
+type mostAllocated struct{}
+
+func (mostAllocated) Name() string { return "MostAllocated" }
+
+func (mostAllocated) Score(state *SchedulingState, _ *apiv1alpha1.Sandbox, agent *AgentInfo) float64 {
+	return MaxScore * allocationRatio(state, agent)
+}
+
+// nodeSpread prefers agents on nodes that run the fewest sandboxes of the pool.
+type nodeSpread struct{}
+
+func (nodeSpread) Name() string { return "NodeSpread" }
+
+func (nodeSpread) Score(state *SchedulingState, _ *apiv1alpha1.Sandbox, agent *AgentInfo) float64 {
+	if state.MaxSandboxesPerNode == 0 {
+		return MaxScore
+	}
+	return MaxScore * (1 - float64(state.SandboxesPerNode[agent.NodeName])/float64(state.MaxSandboxesPerNode))
+}
+
+// allocationRatio returns how full (0-1) the agent would be after placing the sandbox,
+// the highest of slot, CPU and memory usage. Agents without any limit fall back to
+// a ratio that only grows with the number of sandboxes.
+func allocationRatio(state *SchedulingState, agent *AgentInfo) float64 {
+	limited := false
+	ratio := 0.0
+	if agent.Capacity > 0 {
+		limited = true
+		ratio = float64(agent.Allocated+1) / float64(agent.Capacity)
+	}
+	if agent.AllocatableCPU > 0 {
+		limited = true
+		ratio = max(ratio, float64(agent.AllocatedCPU+state.CPURequest)/float64(agent.AllocatableCPU))
+	}
+	if agent.AllocatableMemory > 0 {
+		limited = true
+		ratio = max(ratio, float64(agent.AllocatedMemory+state.MemoryRequest)/float64(agent.AllocatableMemory))
+	}
+	if !limited {
+		ratio = float64(agent.Allocated) / float64(agent.Allocated+1)
+	}
+	return min(ratio, 1)
+}
@@ -0,0 +1,171 @@
+package agentpool
+
+import (
+	"testing"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withNodeName(node string) func(*AgentInfo) {
+	return func(a *AgentInfo) { a.NodeName = node }
+}
+
+// ============================================================================
+// 1. Plugin Tests
+// ============================================================================
+
+func TestProfileFor_Default(t *testing.T) {
+	// SC-01: Empty and unknown strategies fall back to ImageLocality
+	assert.Same(t, profiles[apiv1alpha1.SchedulingImageLocality], ProfileFor(""))
+	assert.Same(t, profiles[apiv1alpha1.SchedulingImageLocality], ProfileFor("Random"))
+	assert.Same(t, profiles[apiv1alpha1.SchedulingMostAllocated], ProfileFor(apiv1alpha1.SchedulingMostAllocated))
+}
+
+func TestDefaultFilters(t *testing.T) {
+	// SC-02: Capacity, resource and port filters reject infeasible agents
+	sb := newTestSandbox("sb", withSandboxPorts(8080), withSandboxLimits("1", "1Gi"))
+	state := newSchedulingState(sb, nil)
+	profile := ProfileFor("")
+
+	ok := newTestAgentInfo("ok", withAllocatable(2000, 2<<30))
+	full := newTestAgentInfo("full", withCapacity(1), withAllocated(1))
+	small := newTestAgentInfo("small", withAllocatable(500, 2<<30))
+	busyPort := newTestAgentInfo("port", withUsedPorts(8080))
+
+	assert.True(t, profile.Feasible(state, sb, &ok))
+	assert.False(t, profile.Feasible(state, sb, &full))
+	assert.False(t, profile.Feasible(state, sb, &small))
+	assert.False(t, profile.Feasible(state, sb, &busyPort))
+}
+
+func TestAllocationRatio(t *testing.T) {
+	// SC-03: The ratio is the highest of slot, CPU and memory usage after placement
+	state := &SchedulingState{CPURequest: 1000, MemoryRequest: 1 << 30}
+
+	slots := newTestAgentInfo("a", withCapacity(4), withAllocated(1))
+	assert.InDelta(t, 0.5, allocationRatio(state, &slots), 0.001)
+
+	cpu := newTestAgentInfo("b", withCapacity(100), withAllocatable(2000, 0))
+	assert.InDelta(t, 0.5, allocationRatio(state, &cpu), 0.001)
+
+	unlimited := newTestAgentInfo("c", withCapacity(0), withAllocated(3))
+	assert.InDelta(t, 0.75, allocationRatio(state, &unlimited), 0.001)
+
+	over := newTestAgentInfo("d", withCapacity(1), withAllocated(5))
+	assert.InDelta(t, 1.0, allocationRatio(state, &over), 0.001)
+}
+
+func TestNodeSpreadScore(t *testing.T) {
+	// SC-04: Nodes running fewer sandboxes of the pool score higher
+	agents := []AgentInfo{
+		newTestAgentInfo("a1", withNodeName("node-1"), withAllocated(2)),
+		newTestAgentInfo("a2", withNodeName("node-1"), withAllocated(2)),
+		newTestAgentInfo("a3", withNodeName("node-2"), withAllocated(1)),
+	}
+	state := newSchedulingState(newTestSandbox("sb"), agents)
+	require.Equal(t, 4, state.MaxSandboxesPerNode)
+
+	assert.InDelta(t, 0.0, nodeSpread{}.Score(state, nil, &agents[0]), 0.001)
+	assert.InDelta(t, 75.0, nodeSpread{}.Score(state, nil, &agents[2]), 0.001)
+
+	empty := newSchedulingState(newTestSandbox("sb"), nil)
+	assert.Equal(t, MaxScore, nodeSpread{}.Score(empty, nil, &agents[0]))
+}
+
+// ============================================================================
+// 2. Strategy Tests
+// ============================================================================
+
+func TestInMemoryRegistry_Strategy_MostAllocated(t *testing.T) {
+	// SC-05: MostAllocated packs sandboxes onto the fullest agent that still fits
+	registry := NewInMemoryRegistry()
+	registry.SetPoolStrategy("default", "test-pool", apiv1alpha1.SchedulingMostAllocated)
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withCapacity(3)))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-2", withCapacity(3)))
+
+	first, err := registry.Allocate(newTestSandbox("sb-0"))
+	require.NoError(t, err)
+	for i := 1; i < 3; i++ {
+		agent, err := registry.Allocate(newTestSandbox("sb-" + string(rune('0'+i))))
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, agent.ID, "Should keep packing the same agent")
+	}
+
+	// The first agent is full, the next sandbox overflows
+	agent, err := registry.Allocate(newTestSandbox("sb-3"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, agent.ID)
+}
+
+func TestInMemoryRegistry_Strategy_LeastAllocated(t *testing.T) {
+	// SC-06: LeastAllocated compares usage relative to each agent's capacity
+	registry := NewInMemoryRegistry()
+	registry.SetPoolStrategy("default", "test-pool", apiv1alpha1.SchedulingLeastAllocated)
+	registry.RegisterOrUpdate(newTestAgentInfo("small-agent", withCapacity(2)))
+	registry.RegisterOrUpdate(newTestAgentInfo("big-agent", withCapacity(20)))
+
+	for i := 0; i < 3; i++ {
+		agent, err := registry.Allocate(newTestSandbox("sb-" + string(rune('0'+i))))
+		require.NoError(t, err)
+		assert.Equal(t, AgentID("big-agent"), agent.ID, "big-agent stays relatively emptier")
+	}
+}
+
+func TestInMemoryRegistry_Strategy_NodeSpread(t *testing.T) {
+	// SC-07: NodeSpread alternates across nodes even when one node hosts more agents
+	registry := NewInMemoryRegistry()
+	registry.SetPoolStrategy("default", "test-pool", apiv1alpha1.SchedulingNodeSpread)
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1a", withNodeName("node-1")))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1b", withNodeName("node-1")))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-2", withNodeName("node-2")))
+
+	nodes := map[string]int{}
+	for i := 0; i < 4; i++ {
+		agent, err := registry.Allocate(newTestSandbox("sb-" + string(rune('0'+i))))
+		require.NoError(t, err)
+		nodes[agent.NodeName]++
+	}
+	assert.Equal(t, 2, nodes["node-1"])
+	assert.Equal(t, 2, nodes["node-2"])
+}
+
+func TestInMemoryRegistry_SetPoolStrategy_Scoped(t *testing.T) {
+	// SC-08: Strategies are per pool and can be reset to the default
+	registry := NewInMemoryRegistry()
+	registry.SetPoolStrategy("default", "pack-pool", apiv1alpha1.SchedulingMostAllocated)
+
+	assert.Equal(t, apiv1alpha1.SchedulingMostAllocated, registry.strategies["default/pack-pool"])
+	assert.Empty(t, registry.strategies["default/test-pool"])
+	assert.Empty(t, registry.strategies["other/pack-pool"])
+
+	registry.SetPoolStrategy("default", "pack-pool", "")
+	assert.NotContains(t, registry.strategies, "default/pack-pool")
+}
+
+func TestInMemoryRegistry_RestoreNode(t *testing.T) {
+	// SC-09: Sandboxes restored from a local checkpoint only run on its node, without preemption when the node is gone
+	registry := NewInMemoryRegistry()
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-1", withNodeName("node-1"), withCapacity(1)))
+	registry.RegisterOrUpdate(newTestAgentInfo("agent-2", withNodeName("node-2")))
+
+	sb := newTestSandbox("sb-0")
+	sb.Annotations = map[string]string{common.AnnotationRestoreNode: "node-1"}
+	agent, err := registry.Allocate(sb)
+	require.NoError(t, err)
+	assert.Equal(t, AgentID("agent-1"), agent.ID)
+
+	full := newTestSandbox("sb-1")
+	full.Annotations = map[string]string{common.AnnotationRestoreNode: "node-1"}
+	_, err = registry.Allocate(full)
+	assert.True(t, IsUnschedulable(err), "The node is full, preemption can help")
+
+	gone := newTestSandbox("sb-2")
+	gone.Annotations = map[string]string{common.AnnotationRestoreNode: "node-3"}
+	_, err = registry.Allocate(gone)
+	assert.ErrorIs(t, err, ErrNodeUnavailable)
+	assert.False(t, IsUnschedulable(err))
+}
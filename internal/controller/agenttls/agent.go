@@ -0,0 +1,219 @@
+package agenttls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EnvTLSDir 告诉 Agent 证书的挂载目录，目录中有 tls.crt、tls.key 与 ca.crt
+	EnvTLSDir = "AGENT_TLS_DIR"
+	// MountPath 是 Agent 容器中证书 Secret 的挂载目录
+	MountPath = "/etc/fast-sandbox/tls"
+	// PodIPIndex 是 Pod 按 status.podIP 建立的缓存索引，用于按 IP 找到 Agent Pod
+	PodIPIndex = "status.podIP"
+
+	volumeName = "agent-tls"
+)
+
+// SecretName is the name of the Secret holding the certificate of an agent pod.
+func SecretName(pod string) string {
+	return pod + "-tls"
+}
+
+// InjectPod mounts the certificate Secret of the pod into its agent container, the
+// first one. The pod must already have its final name; it starts once the Secret exists.
+func InjectPod(pod *corev1.Pod) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name:         volumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: SecretName(pod.Name)}},
+	})
+	if len(pod.Spec.Containers) == 0 {
+		return
+	}
+	c := &pod.Spec.Containers[0]
+	c.Env = append(c.Env, corev1.EnvVar{Name: EnvTLSDir, Value: MountPath})
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: MountPath, ReadOnly: true})
+}
+
+// Issuer writes the certificates of agent pods into the Secrets they mount.
+type Issuer struct {
+	CA *CA
+	// CASecret 是保存 CA 的 Secret，CA 临近过期时在其中轮换
+	CASecret types.NamespacedName
+	Client   client.Client
+	// Reader 直接读 API Server，避免为 Secret 建立 informer 缓存
+	Reader client.Reader
+
+	rotateMu sync.Mutex
+	mu       sync.Mutex
+	// renewAt 记录已检查的 Agent 证书下次需要续签的时间，到期前不再读 Secret
+	renewAt map[types.UID]time.Time
+}
+
+// RotateCA rotates the CA once it expires within caRenewBefore. The agent Secrets are
+// then re-issued with the new trust bundle on their next EnsureSecret. It is cheap
+// while the CA is not due and is called on every pool reconcile.
+func (i *Issuer) RotateCA(ctx context.Context) error {
+	i.rotateMu.Lock()
+	defer i.rotateMu.Unlock()
+	rotated, err := i.CA.RotateIfExpiring(ctx, i.Client, i.Reader, i.CASecret)
+	if err != nil || !rotated {
+		return err
+	}
+	// 信任包已变化，所有 Agent 证书需要重新检查
+	i.mu.Lock()
+	i.renewAt = nil
+	i.mu.Unlock()
+	return nil
+}
+
+// EnsureSecret creates the certificate Secret of an agent pod, and issues a new
+// certificate once the current one is within certRenewBefore of expiry or was issued
+// with another CA trust bundle. The agent reloads it when the kubelet updates the
+// mounted Secret. The Secret is owned by the pod and is garbage collected with it.
+func (i *Issuer) EnsureSecret(ctx context.Context, pod *corev1.Pod) error {
+	now := time.Now()
+	i.mu.Lock()
+	renewAt, checked := i.renewAt[pod.UID]
+	i.mu.Unlock()
+	if checked && now.Before(renewAt) {
+		return nil
+	}
+
+	key := client.ObjectKey{Namespace: pod.Namespace, Name: SecretName(pod.Name)}
+	var secret corev1.Secret
+	err := i.Reader.Get(ctx, key, &secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists {
+		if renewAt, ok := i.renewalTime(&secret); ok && now.Before(renewAt) {
+			i.remember(pod.UID, renewAt, now)
+			return nil
+		}
+	}
+
+	certPEM, keyPEM, err := i.CA.IssueAgent(pod.Namespace, pod.Name)
+	if err != nil {
+		return fmt.Errorf("failed to issue certificate for agent %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	data := map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		CACertKey:               i.CA.CertPEM(),
+	}
+	if exists {
+		secret.Data = data
+		if err := i.Client.Update(ctx, &secret); err != nil {
+			return fmt.Errorf("failed to renew certificate secret for agent %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		klog.InfoS("Renewed agent certificate", "pod", pod.Name, "namespace", pod.Namespace)
+	} else {
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "v1",
+					Kind:       "Pod",
+					Name:       pod.Name,
+					UID:        pod.UID,
+				}},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		if err := i.Client.Create(ctx, &secret); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				// 并发创建，下次调谐时再检查
+				return nil
+			}
+			return fmt.Errorf("failed to create certificate secret for agent %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		klog.InfoS("Issued agent certificate", "pod", pod.Name, "namespace", pod.Namespace)
+	}
+	if renewAt, ok := i.renewalTime(&secret); ok {
+		i.remember(pod.UID, renewAt, now)
+	}
+	return nil
+}
+
+// renewalTime returns when the certificate in an agent Secret must be renewed, or false
+// when it must be renewed now because it is unreadable or carries another trust bundle.
+func (i *Issuer) renewalTime(secret *corev1.Secret) (time.Time, bool) {
+	if !bytes.Equal(secret.Data[CACertKey], i.CA.CertPEM()) {
+		return time.Time{}, false
+	}
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.Leaf.NotAfter.Add(-certRenewBefore), true
+}
+
+// remember records when the certificate of a pod must be renewed. Entries that are due
+// are dropped, the ones of pods that still exist are recorded again on their next check.
+func (i *Issuer) remember(uid types.UID, renewAt, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.renewAt == nil {
+		i.renewAt = make(map[types.UID]time.Time)
+	}
+	for id, at := range i.renewAt {
+		if !now.Before(at) {
+			delete(i.renewAt, id)
+		}
+	}
+	i.renewAt[uid] = renewAt
+}
+
+// IndexPodIP registers PodIPIndex on pods.
+func IndexPodIP(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &corev1.Pod{}, PodIPIndex, func(obj client.Object) []string {
+		if ip := obj.(*corev1.Pod).Status.PodIP; ip != "" {
+			return []string{ip}
+		}
+		return nil
+	})
+}
+
+// ServerNameResolver returns the server name to verify the agent at an IP against. It
+// looks the IP up among the agent pods, so a connection is only trusted when the
+// certificate belongs to the pod that currently holds the IP.
+func ServerNameResolver(c client.Reader) func(ctx context.Context, ip string) (string, error) {
+	return func(ctx context.Context, ip string) (string, error) {
+		var pods corev1.PodList
+		if err := c.List(ctx, &pods, client.MatchingFields{PodIPIndex: ip}, client.MatchingLabels{"app": "sandbox-agent"}); err != nil {
+			return "", err
+		}
+		var found *corev1.Pod
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			// 已结束的 Pod 可能仍保留旧 IP
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if found != nil {
+				return "", fmt.Errorf("agent pods %s and %s both have IP %s", found.Name, pod.Name, ip)
+			}
+			found = pod
+		}
+		if found == nil {
+			return "", fmt.Errorf("no agent pod has IP %s", ip)
+		}
+		return ServerName(found.Namespace, found.Name), nil
+	}
+}
@@ -0,0 +1,445 @@
+package agenttls
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	agentv1 "fast-sandbox/api/proto/agent/v1"
+	"fast-sandbox/internal/agent/server"
+	"fast-sandbox/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var caKey = types.NamespacedName{Namespace: "default", Name: "agent-ca"}
+
+func TestLoadOrCreateCA(t *testing.T) {
+	// AT-01: The CA is created on first start and loaded from the Secret afterwards
+	c := fake.NewClientBuilder().Build()
+	ca, err := LoadOrCreateCA(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), caKey, &secret))
+	assert.Equal(t, ca.CertPEM(), secret.Data[CACertKey])
+
+	loaded, err := LoadOrCreateCA(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.Equal(t, ca.CertPEM(), loaded.CertPEM())
+	assert.True(t, ca.key.Equal(loaded.key))
+}
+
+func TestLoadOrCreateCA_Race(t *testing.T) {
+	// AT-02: When another controller creates the CA first, its CA is used
+	other := fake.NewClientBuilder().Build()
+	otherCA, err := LoadOrCreateCA(context.Background(), other, other, caKey)
+	require.NoError(t, err)
+	var otherSecret corev1.Secret
+	require.NoError(t, other.Get(context.Background(), caKey, &otherSecret))
+
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			otherSecret.ResourceVersion = ""
+			require.NoError(t, c.Create(ctx, &otherSecret))
+			return apierrors.NewAlreadyExists(schema.GroupResource{Resource: "secrets"}, caKey.Name)
+		},
+	}).Build()
+	ca, err := LoadOrCreateCA(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.Equal(t, otherCA.CertPEM(), ca.CertPEM())
+}
+
+func TestIssuer_EnsureSecret(t *testing.T) {
+	// AT-03: Each agent pod mounts a Secret with its own certificate, owned by the pod
+	ca, err := newCA()
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().Build()
+	issuer := &Issuer{CA: ca, Client: c, Reader: c}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-agent-abcde", Namespace: "default", UID: "uid-1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent"}}},
+	}
+	InjectPod(pod)
+	assert.Equal(t, "pool-agent-abcde-tls", pod.Spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, corev1.EnvVar{Name: EnvTLSDir, Value: MountPath}, pod.Spec.Containers[0].Env[0])
+
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "pool-agent-abcde-tls"}, &secret))
+	assert.Equal(t, corev1.SecretTypeTLS, secret.Type)
+	assert.Equal(t, types.UID("uid-1"), secret.OwnerReferences[0].UID)
+
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool-agent-abcde.default.agent.sandbox.fast.io"}, cert.Leaf.DNSNames)
+
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+	var after corev1.Secret
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(&secret), &after))
+	assert.Equal(t, secret.Data, after.Data, "An existing certificate is kept")
+}
+
+func TestIssuer_EnsureSecret_Renew(t *testing.T) {
+	// AT-07: Certificates close to expiry or issued with an older trust bundle are re-issued
+	ca, err := newCA()
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().Build()
+	issuer := &Issuer{CA: ca, Client: c, Reader: c}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pool-agent-abcde", Namespace: "default", UID: "uid-1"}}
+	key := client.ObjectKey{Namespace: "default", Name: SecretName(pod.Name)}
+
+	// 距过期不足 certRenewBefore 的证书
+	keyPEM, err := encodeKey(ca.key)
+	require.NoError(t, err)
+	expiring := &x509.Certificate{
+		SerialNumber: newSerial(),
+		Subject:      pkix.Name{CommonName: ServerName("default", pod.Name)},
+		DNSNames:     []string{ServerName("default", pod.Name)},
+		NotBefore:    time.Now().Add(-certValidity),
+		NotAfter:     time.Now().Add(certRenewBefore - time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, expiring, ca.cert, &ca.key.PublicKey, ca.key)
+	require.NoError(t, err)
+	require.NoError(t, c.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: keyPEM,
+			CACertKey:               ca.CertPEM(),
+		},
+	}))
+
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+	var renewed corev1.Secret
+	require.NoError(t, c.Get(context.Background(), key, &renewed))
+	cert, err := tls.X509KeyPair(renewed.Data[corev1.TLSCertKey], renewed.Data[corev1.TLSPrivateKeyKey])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(certValidity), cert.Leaf.NotAfter, time.Hour, "The certificate is renewed for a full validity")
+
+	// 有效期内的证书在续签时间前不再读取 Secret
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+	var kept corev1.Secret
+	require.NoError(t, c.Get(context.Background(), key, &kept))
+	assert.Equal(t, renewed.Data, kept.Data)
+
+	// CA 轮换后信任包变化，新的 Issuer（如 Controller 重启后）重新签发
+	rotated, err := newCA()
+	require.NoError(t, err)
+	rotated.certPEM = append(append([]byte{}, rotated.certPEM...), ca.CertPEM()...)
+	issuer = &Issuer{CA: rotated, Client: c, Reader: c}
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+	require.NoError(t, c.Get(context.Background(), key, &renewed))
+	assert.Equal(t, rotated.CertPEM(), renewed.Data[CACertKey])
+	cert, err = tls.X509KeyPair(renewed.Data[corev1.TLSCertKey], renewed.Data[corev1.TLSPrivateKeyKey])
+	require.NoError(t, err)
+	assert.Equal(t, rotated.cert.Subject, cert.Leaf.Issuer)
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{Roots: rotated.CertPool(), DNSName: ServerName("default", pod.Name)})
+	assert.NoError(t, err)
+}
+
+func TestCA_RotateIfExpiring(t *testing.T) {
+	// AT-08: A CA close to expiry is rotated, the old one stays trusted until it expires
+	old, err := newCAValidFor(caRenewBefore - time.Hour)
+	require.NoError(t, err)
+	keyPEM, err := encodeKey(old.key)
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: caKey.Name, Namespace: caKey.Namespace},
+		Data:       map[string][]byte{CACertKey: old.CertPEM(), CAKeyKey: keyPEM},
+	}).Build()
+
+	ca, err := LoadOrCreateCA(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.True(t, ca.cert.Equal(old.cert), "Loading does not rotate")
+
+	rotated, err := ca.RotateIfExpiring(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	assert.False(t, ca.cert.Equal(old.cert))
+	assert.WithinDuration(t, time.Now().Add(caValidity), ca.cert.NotAfter, time.Hour)
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), caKey, &secret))
+	assert.Equal(t, ca.CertPEM(), secret.Data[CACertKey])
+	assert.True(t, bytes.HasSuffix(secret.Data[CACertKey], old.CertPEM()), "The new CA comes first")
+
+	// 旧 CA 签发的证书仍可校验，新证书由新 CA 签发
+	certPEM, _, err := old.IssueAgent("default", "agent-a")
+	require.NoError(t, err)
+	block, _ := pem.Decode(certPEM)
+	oldCert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	_, err = oldCert.Verify(x509.VerifyOptions{Roots: ca.CertPool(), DNSName: ServerName("default", "agent-a")})
+	assert.NoError(t, err)
+
+	rotated, err = ca.RotateIfExpiring(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.False(t, rotated, "A fresh CA is not rotated again")
+	loaded, err := LoadOrCreateCA(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.True(t, loaded.cert.Equal(ca.cert))
+
+	// 另一个 Controller 实例已轮换时沿用它的 CA
+	stale, err := parseCA(old.CertPEM(), keyPEM)
+	require.NoError(t, err)
+	rotated, err = stale.RotateIfExpiring(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	assert.True(t, stale.cert.Equal(ca.cert))
+}
+
+func TestIssuer_RotateCA(t *testing.T) {
+	// AT-10: A running controller rotates an expiring CA and re-issues the agent certificates
+	old, err := newCAValidFor(caRenewBefore - time.Hour)
+	require.NoError(t, err)
+	keyPEM, err := encodeKey(old.key)
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: caKey.Name, Namespace: caKey.Namespace},
+		Data:       map[string][]byte{CACertKey: old.CertPEM(), CAKeyKey: keyPEM},
+	}).Build()
+	ca, err := LoadOrCreateCA(context.Background(), c, c, caKey)
+	require.NoError(t, err)
+	issuer := &Issuer{CA: ca, CASecret: caKey, Client: c, Reader: c}
+	clientConfig := ca.ClientTLSConfig()
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "agent-a", Namespace: "default", UID: "uid-1"}}
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+
+	require.NoError(t, issuer.RotateCA(context.Background()))
+	// 客户端证书由旧 CA 签发，尚未更新的 Agent 仍然接受 Controller
+	controllerCert, err := ca.ClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, old.cert.SubjectKeyId, controllerCert.Leaf.AuthorityKeyId)
+	var caSecret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), caKey, &caSecret))
+	assert.Equal(t, ca.CertPEM(), caSecret.Data[CACertKey])
+	assert.False(t, ca.cert.Equal(old.cert))
+	assert.True(t, bytes.HasSuffix(ca.CertPEM(), old.CertPEM()), "The old CA stays trusted")
+
+	// 轮换后 Agent 证书立即按新的信任包重新签发，不等待续签缓存过期
+	var agentSecret corev1.Secret
+	require.NoError(t, issuer.EnsureSecret(context.Background(), pod))
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: SecretName(pod.Name)}, &agentSecret))
+	assert.Equal(t, ca.CertPEM(), agentSecret.Data[CACertKey])
+
+	// 未到期的 CA 不再轮换
+	require.NoError(t, issuer.RotateCA(context.Background()))
+	require.NoError(t, c.Get(context.Background(), caKey, &caSecret))
+	assert.Equal(t, ca.CertPEM(), caSecret.Data[CACertKey])
+
+	// 轮换前创建的客户端配置信任新 CA 签发的 Agent 证书，客户端证书保留到自身续签
+	port := startAgent(t, ca, "agent-a")
+	agentClient := api.NewAgentClient(port)
+	agentClient.SetTLS(clientConfig, expectAgent("agent-a"))
+	status, err := agentClient.GetAgentStatus(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-a", status.AgentID)
+	current, err := ca.ClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Same(t, controllerCert, current)
+}
+
+func TestServerNameResolver(t *testing.T) {
+	// AT-04: The expected agent identity is the running agent pod holding the IP
+	agentPod := func(name, ip string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "sandbox-agent"}},
+			Status:     corev1.PodStatus{PodIP: ip, Phase: phase},
+		}
+	}
+	c := fake.NewClientBuilder().
+		WithObjects(
+			agentPod("agent-old", "10.0.0.1", corev1.PodSucceeded),
+			agentPod("agent-new", "10.0.0.1", corev1.PodRunning),
+			agentPod("agent-a", "10.0.0.2", corev1.PodRunning),
+			agentPod("agent-b", "10.0.0.2", corev1.PodPending),
+		).
+		WithIndex(&corev1.Pod{}, PodIPIndex, func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Status.PodIP}
+		}).
+		Build()
+	resolve := ServerNameResolver(c)
+
+	name, err := resolve(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, ServerName("default", "agent-new"), name, "Finished pods keep their IP and are skipped")
+
+	_, err = resolve(context.Background(), "10.0.0.2")
+	assert.ErrorContains(t, err, "both have IP", "Ambiguous IPs are not trusted")
+	_, err = resolve(context.Background(), "10.0.0.3")
+	assert.ErrorContains(t, err, "no agent pod")
+}
+
+// writeAgentSecret writes the certificate Secret files of an agent issued by ca to dir.
+func writeAgentSecret(t *testing.T, ca *CA, pod, dir string) {
+	certPEM, keyPEM, err := ca.IssueAgent("default", pod)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), ca.CertPEM(), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), certPEM, 0o600))
+}
+
+// agentTLSConfig loads the TLS configuration of an agent from its certificate Secret files.
+func agentTLSConfig(t *testing.T, ca *CA, pod, dir string) *tls.Config {
+	writeAgentSecret(t, ca, pod, dir)
+	tlsConfig, err := server.LoadTLSConfig(t.Context(), dir)
+	require.NoError(t, err)
+	return tlsConfig
+}
+
+// startAgent serves a status endpoint with the TLS configuration of the agent.
+func startAgent(t *testing.T, ca *CA, pod string) int {
+	return startAgentIn(t, ca, pod, t.TempDir())
+}
+
+func startAgentIn(t *testing.T, ca *CA, pod, dir string) int {
+	tlsConfig := agentTLSConfig(t, ca, pod, dir)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"agentId":"` + pod + `"}`))
+	}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return p
+}
+
+func expectAgent(pod string) func(context.Context, string) (string, error) {
+	return func(context.Context, string) (string, error) { return ServerName("default", pod), nil }
+}
+
+func TestMutualTLS(t *testing.T) {
+	// AT-05: The controller only talks to the expected agent, agents only accept the controller
+	ca, err := newCA()
+	require.NoError(t, err)
+	port := startAgent(t, ca, "agent-a")
+
+	agentClient := api.NewAgentClient(port)
+	agentClient.SetTLS(ca.ClientTLSConfig(), expectAgent("agent-a"))
+	status, err := agentClient.GetAgentStatus(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-a", status.AgentID)
+
+	impostor := api.NewAgentClient(port)
+	impostor.SetTLS(ca.ClientTLSConfig(), expectAgent("agent-b"))
+	_, err = impostor.GetAgentStatus(context.Background(), "127.0.0.1")
+	assert.ErrorContains(t, err, "certificate is valid for agent-a.default", "The certificate of another pod is rejected")
+
+	// 不带客户端证书，或以另一个 Agent 的证书连接都会被拒绝
+	noCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:    ca.CertPool(),
+		ServerName: ServerName("default", "agent-a"),
+	}}}
+	_, err = noCert.Get("https://127.0.0.1:" + strconv.Itoa(port) + "/api/v1/agent/status")
+	assert.Error(t, err)
+
+	certPEM, keyPEM, err := ca.IssueAgent("default", "agent-b")
+	require.NoError(t, err)
+	agentCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	asAgent := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      ca.CertPool(),
+		ServerName:   ServerName("default", "agent-a"),
+		Certificates: []tls.Certificate{agentCert},
+	}}}
+	_, err = asAgent.Get("https://127.0.0.1:" + strconv.Itoa(port) + "/api/v1/agent/status")
+	assert.Error(t, err)
+}
+
+type statusAgent struct {
+	agentv1.UnimplementedAgentServiceServer
+}
+
+func (statusAgent) GetStatus(context.Context, *agentv1.GetStatusRequest) (*agentv1.AgentStatus, error) {
+	return &agentv1.AgentStatus{AgentId: "agent-a"}, nil
+}
+
+func TestMutualTLS_Reload(t *testing.T) {
+	// AT-09: The agent serves a renewed certificate and trusts a rotated CA without restarting
+	old, err := newCA()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	port := startAgentIn(t, old, "agent-a", dir)
+
+	next, err := newCA()
+	require.NoError(t, err)
+	rotated, err := parseBundle(append(append([]byte{}, next.certPEM...), old.CertPEM()...))
+	require.NoError(t, err)
+	rotated.key = next.key
+	writeAgentSecret(t, rotated, "agent-a", dir)
+
+	assert.Eventually(t, func() bool {
+		agentClient := api.NewAgentClient(port)
+		agentClient.SetTLS(rotated.ClientTLSConfig(), expectAgent("agent-a"))
+		status, err := agentClient.GetAgentStatus(context.Background(), "127.0.0.1")
+		return err == nil && status.AgentID == "agent-a"
+	}, 15*time.Second, 100*time.Millisecond, "The controller certificate of the rotated CA is accepted")
+
+	// 新证书由轮换后的 CA 签发
+	conn, err := tls.Dial("tcp", "127.0.0.1:"+strconv.Itoa(port), &tls.Config{
+		RootCAs:              next.CertPool(),
+		ServerName:           ServerName("default", "agent-a"),
+		GetClientCertificate: rotated.ClientCertificate,
+	})
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestMutualTLS_GRPC(t *testing.T) {
+	// AT-06: The gRPC agent API verifies both sides the same way
+	ca, err := newCA()
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(agentTLSConfig(t, ca, "agent-a", t.TempDir()))))
+	agentv1.RegisterAgentServiceServer(srv, statusAgent{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	agentClient := api.NewAgentClient(0)
+	agentClient.UseGRPC(port)
+	agentClient.SetTLS(ca.ClientTLSConfig(), expectAgent("agent-a"))
+	status, err := agentClient.GetAgentStatus(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-a", status.AgentID)
+
+	impostor := api.NewAgentClient(0)
+	impostor.UseGRPC(port)
+	impostor.SetTLS(ca.ClientTLSConfig(), expectAgent("agent-b"))
+	_, err = impostor.GetAgentStatus(context.Background(), "127.0.0.1")
+	assert.ErrorContains(t, err, "certificate is valid for agent-a.default")
+}
@@ -0,0 +1,371 @@
+package agenttls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ControllerName 是 Controller 客户端证书的 CommonName
+	ControllerName = "fast-sandbox-controller"
+	// CACertKey / CAKeyKey 是 CA Secret 中证书与私钥的 key，Agent 证书 Secret 也以 CACertKey 携带 CA 证书
+	CACertKey = "ca.crt"
+	CAKeyKey  = "ca.key"
+
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+	// caRenewBefore CA 剩余有效期不足该时长时轮换，大于 certValidity，旧 CA 比它签发的证书更晚过期
+	caRenewBefore = 2 * certValidity
+	// certRenewBefore 证书剩余有效期不足该时长时重新签发
+	certRenewBefore = certValidity / 3
+	// clockSkew 证书生效时间提前量，容忍节点间时钟偏差
+	clockSkew = 5 * time.Minute
+)
+
+// ServerName is the DNS name in the certificate of an agent pod. The controller
+// verifies agents against it, so a certificate only vouches for the pod it was issued to.
+func ServerName(namespace, pod string) string {
+	return pod + "." + namespace + ".agent.sandbox.fast.io"
+}
+
+// CA is the certificate authority the controller uses to issue agent server
+// certificates and its own client certificate. It is replaced in place when rotated,
+// so holders of the CA issue and verify with the new one right away.
+type CA struct {
+	// stateMu 保护 cert、key、certPEM 与 pool，轮换时整体替换
+	stateMu sync.RWMutex
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	// certPEM 是信任包：当前 CA 证书在前，其后是轮换前仍有效的旧 CA 证书
+	certPEM []byte
+	pool    *x509.CertPool
+
+	mu     sync.Mutex
+	client *tls.Certificate
+}
+
+// LoadOrCreateCA reads the CA from a Secret, creating the Secret with a new self-signed
+// CA on first start. The CA is rotated by RotateIfExpiring. reader should read from the
+// API server so that Secrets are not cached.
+func LoadOrCreateCA(ctx context.Context, c client.Client, reader client.Reader, key types.NamespacedName) (*CA, error) {
+	var secret corev1.Secret
+	err := reader.Get(ctx, key, &secret)
+	if apierrors.IsNotFound(err) {
+		ca, caErr := newCA()
+		if caErr != nil {
+			return nil, caErr
+		}
+		keyPEM, caErr := encodeKey(ca.key)
+		if caErr != nil {
+			return nil, caErr
+		}
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Data: map[string][]byte{
+				CACertKey: ca.certPEM,
+				CAKeyKey:  keyPEM,
+			},
+		}
+		err = c.Create(ctx, &secret)
+		if err == nil {
+			klog.InfoS("Created agent CA", "secret", key)
+			return ca, nil
+		}
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create agent CA secret %s: %w", key, err)
+		}
+		// 另一个 Controller 实例先创建了 CA，使用它的
+		err = reader.Get(ctx, key, &secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent CA secret %s: %w", key, err)
+	}
+	return parseCA(secret.Data[CACertKey], secret.Data[CAKeyKey])
+}
+
+// RotateIfExpiring rotates the CA in the Secret at key once it expires within
+// caRenewBefore and switches to the new one, or to the one another controller instance
+// rotated to. It reports whether the CA changed.
+func (ca *CA) RotateIfExpiring(ctx context.Context, c client.Client, reader client.Reader, key types.NamespacedName) (bool, error) {
+	if !ca.expiresWithin(caRenewBefore) {
+		return false, nil
+	}
+	var secret corev1.Secret
+	if err := reader.Get(ctx, key, &secret); err != nil {
+		return false, fmt.Errorf("failed to get agent CA secret %s: %w", key, err)
+	}
+	next, err := parseCA(secret.Data[CACertKey], secret.Data[CAKeyKey])
+	if err != nil {
+		return false, err
+	}
+	if next.expiresWithin(caRenewBefore) {
+		if next, err = rotateCA(ctx, c, reader, &secret, next); err != nil {
+			return false, err
+		}
+	}
+	// 切换前先由旧 CA 签发 Controller 客户端证书并沿用到其自身续签：
+	// 尚未拿到新信任包的 Agent 只信任旧 CA，旧 CA 也一直留在新信任包中
+	if _, err := ca.ClientCertificate(nil); err != nil {
+		return false, err
+	}
+	ca.stateMu.Lock()
+	defer ca.stateMu.Unlock()
+	ca.cert, ca.key, ca.certPEM, ca.pool = next.cert, next.key, next.certPEM, next.pool
+	return true, nil
+}
+
+// expiresWithin reports whether the signing CA certificate expires within d.
+func (ca *CA) expiresWithin(d time.Duration) bool {
+	ca.stateMu.RLock()
+	defer ca.stateMu.RUnlock()
+	return time.Until(ca.cert.NotAfter) <= d
+}
+
+// rotateCA replaces a CA close to expiry in its Secret with a new one. The old CA
+// certificate stays in the trust bundle until it expires, so the certificates it issued
+// remain valid until the agents get renewed ones.
+func rotateCA(ctx context.Context, c client.Client, reader client.Reader, secret *corev1.Secret, old *CA) (*CA, error) {
+	next, err := newCA()
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeKey(next.key)
+	if err != nil {
+		return nil, err
+	}
+	bundle := next.certPEM
+	for rest := old.certPEM; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil && time.Now().Before(cert.NotAfter) {
+			bundle = append(bundle, pem.EncodeToMemory(block)...)
+		}
+	}
+
+	secret.Data = map[string][]byte{CACertKey: bundle, CAKeyKey: keyPEM}
+	key := client.ObjectKeyFromObject(secret)
+	if err := c.Update(ctx, secret); err != nil {
+		if !apierrors.IsConflict(err) {
+			return nil, fmt.Errorf("failed to rotate agent CA secret %s: %w", key, err)
+		}
+		// 另一个 Controller 实例先完成了轮换，使用它的
+		if err := reader.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get agent CA secret %s: %w", key, err)
+		}
+		return parseCA(secret.Data[CACertKey], secret.Data[CAKeyKey])
+	}
+	klog.InfoS("Rotated agent CA", "secret", key, "previousNotAfter", old.cert.NotAfter)
+	return parseCA(bundle, keyPEM)
+}
+
+func newCA() (*CA, error) {
+	return newCAValidFor(caValidity)
+}
+
+func newCAValidFor(validity time.Duration) (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          newSerial(),
+		Subject:               pkix.Name{CommonName: "fast-sandbox-agent-ca"},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := parseBundle(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if err != nil {
+		return nil, err
+	}
+	ca.key = key
+	return ca, nil
+}
+
+// parseCA builds a CA from its PEM encoded trust bundle, whose first certificate is
+// the signing one, and the PEM encoded key of that certificate.
+func parseCA(certPEM, keyPEM []byte) (*CA, error) {
+	ca, err := parseBundle(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("agent CA key is not PEM encoded")
+	}
+	if ca.key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("invalid agent CA key: %w", err)
+	}
+	return ca, nil
+}
+
+// parseBundle builds a CA without key from its PEM encoded trust bundle.
+func parseBundle(certPEM []byte) (*CA, error) {
+	ca := &CA{certPEM: certPEM, pool: x509.NewCertPool()}
+	for rest := certPEM; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid agent CA certificate: %w", err)
+		}
+		if ca.cert == nil {
+			ca.cert = cert
+		}
+		ca.pool.AddCert(cert)
+	}
+	if ca.cert == nil {
+		return nil, errors.New("agent CA certificate is not PEM encoded")
+	}
+	return ca, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+func newSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return serial
+}
+
+// CertPEM returns the PEM encoded trust bundle, the CA certificate followed by the
+// previous ones that are still valid.
+func (ca *CA) CertPEM() []byte {
+	ca.stateMu.RLock()
+	defer ca.stateMu.RUnlock()
+	return ca.certPEM
+}
+
+// CertPool returns a pool holding the certificates of the trust bundle.
+func (ca *CA) CertPool() *x509.CertPool {
+	ca.stateMu.RLock()
+	defer ca.stateMu.RUnlock()
+	return ca.pool.Clone()
+}
+
+// issue signs a certificate for a new key and returns both PEM encoded.
+func (ca *CA) issue(tmpl *x509.Certificate) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	ca.stateMu.RLock()
+	caCert, caKey := ca.cert, ca.key
+	ca.stateMu.RUnlock()
+	now := time.Now()
+	tmpl.SerialNumber = newSerial()
+	tmpl.NotBefore = now.Add(-clockSkew)
+	tmpl.NotAfter = now.Add(certValidity)
+	if tmpl.NotAfter.After(caCert.NotAfter) {
+		// 证书不超出签发它的 CA 的有效期
+		tmpl.NotAfter = caCert.NotAfter
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// IssueAgent issues the server certificate of an agent pod.
+func (ca *CA) IssueAgent(namespace, pod string) (certPEM, keyPEM []byte, err error) {
+	return ca.issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: ServerName(namespace, pod)},
+		DNSNames:    []string{ServerName(namespace, pod)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+// ClientCertificate returns the client certificate of the controller, issued on first
+// use and again once it is within certRenewBefore of expiry.
+func (ca *CA) ClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.client != nil && time.Now().Before(ca.client.Leaf.NotAfter.Add(-certRenewBefore)) {
+		return ca.client, nil
+	}
+	certPEM, keyPEM, err := ca.issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: ControllerName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	ca.client = &cert
+	return ca.client, nil
+}
+
+// ClientTLSConfig returns the TLS configuration the controller dials agents with. The
+// server name is set per connection to the identity of the agent pod, the agent
+// certificate is verified against the current trust bundle, which changes on rotation.
+func (ca *CA) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: ca.ClientCertificate,
+		// 默认校验使用固定的 RootCAs，改为在 VerifyConnection 中按当前信任包校验
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("agent presented no certificate")
+			}
+			// 没有 ServerName 时 Verify 不校验身份，任何 Agent 的证书都会被接受
+			if cs.ServerName == "" {
+				return errors.New("agent server name is not set")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         ca.CertPool(),
+				Intermediates: intermediates,
+				DNSName:       cs.ServerName,
+			})
+			return err
+		},
+	}
+}
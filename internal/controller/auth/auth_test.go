@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// reviewClient answers TokenReviews and SubjectAccessReviews with review and counts them.
+func reviewClient(calls *int, review func(obj client.Object)) client.Client {
+	return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			*calls++
+			review(obj)
+			return nil
+		},
+	}).Build()
+}
+
+func TestStaticTokens(t *testing.T) {
+	// AU-01: Static token file in the kube-apiserver format
+	a, err := parseStaticTokens(strings.NewReader("# ci tokens\nsecret-1,alice,1001,\"dev,ops\"\nsecret-2,bob,1002\n"))
+	require.NoError(t, err)
+
+	user, err := a.Authenticate(context.Background(), "secret-1")
+	require.NoError(t, err)
+	assert.Equal(t, &User{Name: "alice", UID: "1001", Groups: []string{"dev", "ops"}}, user)
+
+	user, err = a.Authenticate(context.Background(), "secret-2")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.Name)
+
+	_, err = a.Authenticate(context.Background(), "secret")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = parseStaticTokens(strings.NewReader("secret-1,alice\n"))
+	assert.Error(t, err, "uid is required")
+	_, err = parseStaticTokens(strings.NewReader("secret-1,alice,1\nsecret-1,bob,2\n"))
+	assert.Error(t, err, "tokens must be unique")
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	// AU-02: Tokens are reviewed with the configured audiences, successes are cached
+	var calls int
+	var audiences []string
+	c := reviewClient(&calls, func(obj client.Object) {
+		review := obj.(*authenticationv1.TokenReview)
+		audiences = review.Spec.Audiences
+		if review.Spec.Token == "good" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{
+				Username: "system:serviceaccount:team:ci",
+				Groups:   []string{"system:serviceaccounts"},
+				Extra:    map[string]authenticationv1.ExtraValue{"authentication.kubernetes.io/pod-name": {"ci-0"}},
+			}
+		}
+	})
+	a := NewTokenReviewAuthenticator(c, []string{"fast-sandbox"})
+
+	user, err := a.Authenticate(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:team:ci", user.Name)
+	assert.Equal(t, []string{"ci-0"}, user.Extra["authentication.kubernetes.io/pod-name"])
+	assert.Equal(t, []string{"fast-sandbox"}, audiences)
+
+	_, err = a.Authenticate(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "The second call is served from the cache")
+
+	_, err = a.Authenticate(context.Background(), "bad")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = a.Authenticate(context.Background(), "bad")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, 3, calls, "Failures are not cached")
+}
+
+func TestSubjectAccessReviewAuthorizer(t *testing.T) {
+	// AU-03: Accesses are reviewed on sandboxes of the sandbox.fast.io group and cached
+	var calls int
+	var last authorizationv1.SubjectAccessReviewSpec
+	c := reviewClient(&calls, func(obj client.Object) {
+		review := obj.(*authorizationv1.SubjectAccessReview)
+		last = review.Spec
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "team"
+		if !review.Status.Allowed {
+			review.Status.Reason = "no RBAC policy matched"
+		}
+	})
+	a := NewSubjectAccessReviewAuthorizer(c)
+	user := &User{Name: "alice", Groups: []string{"dev"}}
+
+	d, err := a.Authorize(context.Background(), user, Attributes{Verb: "create", Namespace: "team", Subresource: "exec", Name: "sb-1"})
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, "alice", last.User)
+	assert.Equal(t, []string{"dev"}, last.Groups)
+	assert.Equal(t, authorizationv1.ResourceAttributes{
+		Namespace: "team", Verb: "create", Group: "sandbox.fast.io", Version: "v1alpha1",
+		Resource: "sandboxes", Subresource: "exec", Name: "sb-1",
+	}, *last.ResourceAttributes)
+
+	d, err = a.Authorize(context.Background(), user, Attributes{Verb: "create", Namespace: "other"})
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, "no RBAC policy matched", d.Reason)
+
+	_, err = a.Authorize(context.Background(), user, Attributes{Verb: "create", Namespace: "team", Subresource: "exec", Name: "sb-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "Repeated accesses are served from the cache")
+
+	_, err = a.Authorize(context.Background(), &User{Name: "alice", Groups: []string{"admins"}}, Attributes{Verb: "create", Namespace: "team", Subresource: "exec", Name: "sb-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "Other groups are reviewed again")
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// tokenCacheTTL 是 TokenReview 认证成功结果的缓存时间，失败结果不缓存
+const tokenCacheTTL = time.Minute
+
+// ErrInvalidToken is returned when a bearer token is not recognised.
+var ErrInvalidToken = errors.New("invalid bearer token")
+
+// User is the identity of an authenticated caller, as used in a SubjectAccessReview.
+type User struct {
+	Name   string
+	UID    string
+	Groups []string
+	Extra  map[string][]string
+}
+
+// Authenticator resolves a bearer token to the user presenting it.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*User, error)
+}
+
+// TokenReviewAuthenticator authenticates Kubernetes tokens, such as ServiceAccount
+// tokens, with a TokenReview against the API server.
+type TokenReviewAuthenticator struct {
+	client client.Client
+	// audiences 非空时 token 必须签发给其中之一
+	audiences []string
+	cache     ttlCache[*User]
+}
+
+// NewTokenReviewAuthenticator creates an authenticator that reviews tokens through c.
+// When audiences are set, tokens must have been issued for one of them.
+func NewTokenReviewAuthenticator(c client.Client, audiences []string) *TokenReviewAuthenticator {
+	return &TokenReviewAuthenticator{client: c, audiences: audiences}
+}
+
+func (a *TokenReviewAuthenticator) Authenticate(ctx context.Context, token string) (*User, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	if user, ok := a.cache.get(key, time.Now()); ok {
+		return user, nil
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: a.audiences},
+	}
+	if err := a.client.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, review.Status.Error)
+		}
+		return nil, ErrInvalidToken
+	}
+
+	info := review.Status.User
+	user := &User{Name: info.Username, UID: info.UID, Groups: info.Groups}
+	if len(info.Extra) > 0 {
+		user.Extra = make(map[string][]string, len(info.Extra))
+		for k, v := range info.Extra {
+			user.Extra[k] = v
+		}
+	}
+	a.cache.set(key, user, tokenCacheTTL, time.Now())
+	return user, nil
+}
+
+// staticToken is one line of a static token file.
+type staticToken struct {
+	token string
+	user  *User
+}
+
+// StaticTokenAuthenticator authenticates tokens listed in a file, in the format of the
+// kube-apiserver --token-auth-file: token,user,uid[,"group1,group2"].
+type StaticTokenAuthenticator struct {
+	tokens []staticToken
+}
+
+// LoadStaticTokens reads a static token file.
+func LoadStaticTokens(path string) (*StaticTokenAuthenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	a, err := parseStaticTokens(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token file %s: %w", path, err)
+	}
+	return a, nil
+}
+
+func parseStaticTokens(r io.Reader) (*StaticTokenAuthenticator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &StaticTokenAuthenticator{}
+	seen := make(map[string]bool, len(records))
+	for i, record := range records {
+		if len(record) < 3 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("line %d: expected token,user,uid[,groups]", i+1)
+		}
+		if seen[record[0]] {
+			return nil, fmt.Errorf("line %d: duplicate token", i+1)
+		}
+		seen[record[0]] = true
+		user := &User{Name: record[1], UID: record[2]}
+		if len(record) > 3 && record[3] != "" {
+			for _, g := range strings.Split(record[3], ",") {
+				if g = strings.TrimSpace(g); g != "" {
+					user.Groups = append(user.Groups, g)
+				}
+			}
+		}
+		a.tokens = append(a.tokens, staticToken{token: record[0], user: user})
+	}
+	return a, nil
+}
+
+func (a *StaticTokenAuthenticator) Authenticate(_ context.Context, token string) (*User, error) {
+	// 逐个常量时间比较，避免通过响应时间猜测 token
+	var found *User
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.token), []byte(token)) == 1 {
+			found = t.user
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidToken
+	}
+	return found, nil
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// allowedCacheTTL / deniedCacheTTL 是 SubjectAccessReview 结果的缓存时间，拒绝的结果缓存更短，授权变更后尽快生效
+	allowedCacheTTL = time.Minute
+	deniedCacheTTL  = 10 * time.Second
+)
+
+// Attributes describe an access to sandboxes, checked as the RBAC rule on
+// sandboxes[/Subresource] in the sandbox.fast.io group.
+type Attributes struct {
+	Verb        string
+	Namespace   string
+	Subresource string
+	// Name 为空表示访问 namespace 下的全部 sandbox
+	Name string
+}
+
+func (a Attributes) String() string {
+	resource := "sandboxes"
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	if a.Name != "" {
+		resource += " " + a.Name
+	}
+	if a.Namespace == "" {
+		return fmt.Sprintf("%s %s in all namespaces", a.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", a.Verb, resource, a.Namespace)
+}
+
+// Decision is the outcome of an authorization check.
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// Authorizer decides whether a user may access sandboxes.
+type Authorizer interface {
+	Authorize(ctx context.Context, user *User, attrs Attributes) (Decision, error)
+}
+
+// SubjectAccessReviewAuthorizer checks accesses with a SubjectAccessReview, so FastPath
+// callers are held to the same RBAC rules as clients of the Sandbox CRD.
+type SubjectAccessReviewAuthorizer struct {
+	client client.Client
+	cache  ttlCache[Decision]
+}
+
+// NewSubjectAccessReviewAuthorizer creates an authorizer that creates reviews through c.
+func NewSubjectAccessReviewAuthorizer(c client.Client) *SubjectAccessReviewAuthorizer {
+	return &SubjectAccessReviewAuthorizer{client: c}
+}
+
+func (a *SubjectAccessReviewAuthorizer) Authorize(ctx context.Context, user *User, attrs Attributes) (Decision, error) {
+	key := reviewKey(user, attrs)
+	if d, ok := a.cache.get(key, time.Now()); ok {
+		return d, nil
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   attrs.Namespace,
+				Verb:        attrs.Verb,
+				Group:       apiv1alpha1.GroupVersion.Group,
+				Version:     apiv1alpha1.GroupVersion.Version,
+				Resource:    "sandboxes",
+				Subresource: attrs.Subresource,
+				Name:        attrs.Name,
+			},
+			User:   user.Name,
+			UID:    user.UID,
+			Groups: user.Groups,
+		},
+	}
+	if len(user.Extra) > 0 {
+		review.Spec.Extra = make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			review.Spec.Extra[k] = v
+		}
+	}
+	if err := a.client.Create(ctx, review); err != nil {
+		return Decision{}, fmt.Errorf("failed to review access: %w", err)
+	}
+
+	d := Decision{Allowed: review.Status.Allowed && !review.Status.Denied, Reason: review.Status.Reason}
+	ttl := allowedCacheTTL
+	if !d.Allowed {
+		ttl = deniedCacheTTL
+	}
+	a.cache.set(key, d, ttl, time.Now())
+	return d, nil
+}
+
+// reviewKey identifies a review by everything that goes into it.
+func reviewKey(user *User, attrs Attributes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q %q %q %q %q %q %q", user.Name, user.UID, strings.Join(user.Groups, ","), attrs.Verb, attrs.Namespace, attrs.Subresource, attrs.Name)
+	for _, k := range slices.Sorted(maps.Keys(user.Extra)) {
+		fmt.Fprintf(&b, " %q=%q", k, strings.Join(user.Extra[k], ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheSweepInterval 清理过期缓存项的最小间隔
+const cacheSweepInterval = time.Minute
+
+type cacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// ttlCache keeps review results for a while so that the fast path does not pay an API
+// server round trip on every call. The zero value is ready to use.
+type ttlCache[V any] struct {
+	mu        sync.Mutex
+	entries   map[string]cacheEntry[V]
+	lastSweep time.Time
+}
+
+func (c *ttlCache[V]) get(key string, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.After(e.expires) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *ttlCache[V]) set(key string, value V, ttl time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cacheEntry[V])
+	}
+	if now.Sub(c.lastSweep) > cacheSweepInterval {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = cacheEntry[V]{value: value, expires: now.Add(ttl)}
+}
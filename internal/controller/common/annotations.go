@@ -1,6 +1,8 @@
 package common
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"time"
 )
@@ -10,13 +12,28 @@ const (
 	LabelCreatedBy = "sandbox.fast.io/created-by"
 	// CreatedByFastPathFast 标识由 FastPath Fast 模式创建
 	CreatedByFastPathFast = "fastpath-fast"
+	// LabelIdempotencyKey 存储 CreateRequest.idempotency_key 的哈希，用于重启后查找已创建的 sandbox
+	LabelIdempotencyKey = "sandbox.fast.io/idempotency-key"
 
 	// AnnotationAllocation 临时存储 FastPath 的分配信息，Controller 会搬运到 status 后删除
 	AnnotationAllocation = "sandbox.fast.io/allocation"
 	// AnnotationCreateTimestamp 存储 Fast 模式创建时的时间戳，用于重新生成 sandboxID
 	AnnotationCreateTimestamp = "sandbox.fast.io/createTimestamp"
+	// AnnotationIdempotencyKey 存储原始的 idempotency_key，用于排除哈希冲突
+	AnnotationIdempotencyKey = "sandbox.fast.io/idempotency-key"
+
+	// AnnotationRestoreImage 存储 Spec.RestoreFrom 解析出的 checkpoint 镜像，创建时交给 Agent 恢复
+	AnnotationRestoreImage = "sandbox.fast.io/restore-image"
+	// AnnotationRestoreNode 存储未推送的 checkpoint 所在节点，只能调度到该节点的 Agent
+	AnnotationRestoreNode = "sandbox.fast.io/restore-node"
 )
 
+// IdempotencyKeyHash 返回可作为 label 值的 idempotency key 哈希
+func IdempotencyKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
 // AllocationInfo 临时分配信息
 type AllocationInfo struct {
 	AssignedPod  string `json:"assignedPod"`  // 分配的 Agent Pod
@@ -0,0 +1,139 @@
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+
+	"github.com/distribution/reference"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrCheckpointNotReady 表示 checkpoint 仍在进行中，稍后重试即可
+var ErrCheckpointNotReady = errors.New("checkpoint is not ready")
+
+// ResolveCheckpoint 读取 Spec.RestoreFrom 指向的 SandboxCheckpoint，并写入恢复所需的 annotation
+func ResolveCheckpoint(ctx context.Context, c client.Reader, sb *apiv1alpha1.Sandbox) error {
+	var ckpt apiv1alpha1.SandboxCheckpoint
+	if err := c.Get(ctx, client.ObjectKey{Namespace: sb.Namespace, Name: sb.Spec.RestoreFrom}, &ckpt); err != nil {
+		return fmt.Errorf("failed to get checkpoint %s: %w", sb.Spec.RestoreFrom, err)
+	}
+	return ApplyCheckpoint(sb, &ckpt)
+}
+
+// ApplyCheckpoint 校验 checkpoint 可用于恢复该 sandbox，并写入 AnnotationRestoreImage 与
+// AnnotationRestoreNode。未就绪时返回 ErrCheckpointNotReady，其他错误表示无法恢复
+func ApplyCheckpoint(sb *apiv1alpha1.Sandbox, ckpt *apiv1alpha1.SandboxCheckpoint) error {
+	switch ckpt.Status.Phase {
+	case apiv1alpha1.CheckpointPhaseReady:
+	case apiv1alpha1.CheckpointPhaseFailed:
+		return fmt.Errorf("checkpoint %s failed: %s", ckpt.Name, ckpt.Status.Message)
+	default:
+		return fmt.Errorf("%w: %s", ErrCheckpointNotReady, ckpt.Name)
+	}
+	// 同一池的 Agent 使用相同的运行时，CRIU 与 runsc 的 checkpoint 互不兼容
+	if ckpt.Status.PoolRef != sb.Spec.PoolRef {
+		return fmt.Errorf("checkpoint %s was taken in pool %s, the sandbox uses pool %s", ckpt.Name, ckpt.Status.PoolRef, sb.Spec.PoolRef)
+	}
+
+	if sb.Annotations == nil {
+		sb.Annotations = make(map[string]string)
+	}
+	sb.Annotations[AnnotationRestoreImage] = ckpt.Status.Ref
+	if ckpt.Spec.Image == "" {
+		sb.Annotations[AnnotationRestoreNode] = ckpt.Status.NodeName
+	} else {
+		delete(sb.Annotations, AnnotationRestoreNode)
+	}
+	return nil
+}
+
+// RestoreCredentials returns the registry credentials an agent needs to pull the
+// checkpoint the sandbox is restored from, nil when the sandbox is not restored or the
+// checkpoint needs none. The Secret is read with secrets, see CheckpointCredentials.
+func RestoreCredentials(ctx context.Context, c, secrets client.Reader, sb *apiv1alpha1.Sandbox) (*api.RegistryCredentials, error) {
+	if sb.Spec.RestoreFrom == "" {
+		return nil, nil
+	}
+	var ckpt apiv1alpha1.SandboxCheckpoint
+	if err := c.Get(ctx, client.ObjectKey{Namespace: sb.Namespace, Name: sb.Spec.RestoreFrom}, &ckpt); err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint %s: %w", sb.Spec.RestoreFrom, err)
+	}
+	return CheckpointCredentials(ctx, secrets, &ckpt)
+}
+
+// CheckpointCredentials reads the credentials for the registry of a pushed checkpoint
+// from its RegistrySecret, nil when the checkpoint is local or has no secret. secrets
+// should read the API server directly, a cached client would watch every Secret. Errors
+// other than those of the API server do not go away by retrying.
+func CheckpointCredentials(ctx context.Context, secrets client.Reader, ckpt *apiv1alpha1.SandboxCheckpoint) (*api.RegistryCredentials, error) {
+	if ckpt.Spec.Image == "" || ckpt.Spec.RegistrySecret == "" {
+		return nil, nil
+	}
+	var secret corev1.Secret
+	if err := secrets.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.RegistrySecret}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get registry secret %s: %w", ckpt.Spec.RegistrySecret, err)
+	}
+	return registryCredentials(&secret, ckpt.Spec.Image)
+}
+
+// dockerConfigJSON 是 kubernetes.io/dockerconfigjson Secret 的内容
+type dockerConfigJSON struct {
+	Auths map[string]struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	} `json:"auths"`
+}
+
+// registryCredentials picks the entry of a dockerconfigjson Secret for the registry of image.
+func registryCredentials(secret *corev1.Secret, image string) (*api.RegistryCredentials, error) {
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return nil, fmt.Errorf("registry secret %s is %s, not %s", secret.Name, secret.Type, corev1.SecretTypeDockerConfigJson)
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image %s: %w", image, err)
+	}
+	host := reference.Domain(named)
+
+	var config dockerConfigJSON
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		return nil, fmt.Errorf("invalid registry secret %s: %v", secret.Name, err)
+	}
+	for server, entry := range config.Auths {
+		if registryHost(server) != host {
+			continue
+		}
+		creds := &api.RegistryCredentials{Username: entry.Username, Password: entry.Password}
+		if creds.Username == "" && entry.Auth != "" {
+			// auth 为 base64 编码的 username:password
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth for %s in registry secret %s: %v", server, secret.Name, err)
+			}
+			creds.Username, creds.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		return creds, nil
+	}
+	return nil, fmt.Errorf("registry secret %s has no credentials for %s", secret.Name, host)
+}
+
+// registryHost normalizes a server of a docker config, which may be a URL such as
+// https://index.docker.io/v1/, to the registry domain of image references.
+func registryHost(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	server, _, _ = strings.Cut(server, "/")
+	switch server {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return server
+}
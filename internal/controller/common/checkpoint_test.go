@@ -0,0 +1,126 @@
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyCheckpoint(t *testing.T) {
+	newCheckpoint := func(phase apiv1alpha1.CheckpointPhase, image string) *apiv1alpha1.SandboxCheckpoint {
+		return &apiv1alpha1.SandboxCheckpoint{
+			ObjectMeta: metav1.ObjectMeta{Name: "warm"},
+			Spec:       apiv1alpha1.SandboxCheckpointSpec{SandboxName: "sb", Image: image},
+			Status: apiv1alpha1.SandboxCheckpointStatus{
+				Phase: phase, Ref: "checkpoint/warm", NodeName: "node-1", PoolRef: "pool-a",
+			},
+		}
+	}
+	newSandbox := func(pool string) *apiv1alpha1.Sandbox {
+		return &apiv1alpha1.Sandbox{Spec: apiv1alpha1.SandboxSpec{PoolRef: pool, RestoreFrom: "warm"}}
+	}
+
+	t.Run("local checkpoint pins the node", func(t *testing.T) {
+		sb := newSandbox("pool-a")
+		require.NoError(t, ApplyCheckpoint(sb, newCheckpoint(apiv1alpha1.CheckpointPhaseReady, "")))
+		assert.Equal(t, "checkpoint/warm", sb.Annotations[AnnotationRestoreImage])
+		assert.Equal(t, "node-1", sb.Annotations[AnnotationRestoreNode])
+	})
+
+	t.Run("pushed checkpoint restores anywhere", func(t *testing.T) {
+		sb := newSandbox("pool-a")
+		ckpt := newCheckpoint(apiv1alpha1.CheckpointPhaseReady, "registry.example.com/warm:1")
+		ckpt.Status.Ref = "registry.example.com/warm:1"
+		require.NoError(t, ApplyCheckpoint(sb, ckpt))
+		assert.Equal(t, "registry.example.com/warm:1", sb.Annotations[AnnotationRestoreImage])
+		assert.NotContains(t, sb.Annotations, AnnotationRestoreNode)
+	})
+
+	t.Run("pending checkpoint is retried", func(t *testing.T) {
+		err := ApplyCheckpoint(newSandbox("pool-a"), newCheckpoint("", ""))
+		assert.ErrorIs(t, err, ErrCheckpointNotReady)
+	})
+
+	t.Run("failed checkpoint or other pool cannot be restored", func(t *testing.T) {
+		err := ApplyCheckpoint(newSandbox("pool-a"), newCheckpoint(apiv1alpha1.CheckpointPhaseFailed, ""))
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCheckpointNotReady)
+
+		err = ApplyCheckpoint(newSandbox("pool-b"), newCheckpoint(apiv1alpha1.CheckpointPhaseReady, ""))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pool pool-a")
+	})
+}
+
+func TestCheckpointCredentials(t *testing.T) {
+	dockerConfig := func(config string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(config)},
+		}
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte("hub-user:hub:pass"))
+	secret := dockerConfig(`{"auths": {
+		"registry.example.com:5000": {"username": "robot", "password": "s3cret"},
+		"https://index.docker.io/v1/": {"auth": "` + auth + `"}
+	}}`)
+	newCheckpoint := func(image, secret string) *apiv1alpha1.SandboxCheckpoint {
+		return &apiv1alpha1.SandboxCheckpoint{
+			ObjectMeta: metav1.ObjectMeta{Name: "warm", Namespace: "default"},
+			Spec:       apiv1alpha1.SandboxCheckpointSpec{SandboxName: "sb", Image: image, RegistrySecret: secret},
+		}
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiv1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	ctx := context.Background()
+
+	creds, err := CheckpointCredentials(ctx, c, newCheckpoint("registry.example.com:5000/ckpt:warm", "regcred"))
+	require.NoError(t, err)
+	assert.Equal(t, &api.RegistryCredentials{Username: "robot", Password: "s3cret"}, creds)
+
+	creds, err = CheckpointCredentials(ctx, c, newCheckpoint("team/ckpt:warm", "regcred"))
+	require.NoError(t, err)
+	assert.Equal(t, &api.RegistryCredentials{Username: "hub-user", Password: "hub:pass"}, creds, "docker.io images use the Docker Hub entry")
+
+	creds, err = CheckpointCredentials(ctx, c, newCheckpoint("registry.example.com:5000/ckpt:warm", ""))
+	require.NoError(t, err)
+	assert.Nil(t, creds, "without a secret the registry is used anonymously")
+
+	_, err = CheckpointCredentials(ctx, c, newCheckpoint("ghcr.io/team/ckpt:warm", "regcred"))
+	assert.ErrorContains(t, err, "no credentials for ghcr.io")
+
+	_, err = CheckpointCredentials(ctx, c, newCheckpoint("ghcr.io/team/ckpt:warm", "missing"))
+	assert.True(t, apierrors.IsNotFound(err))
+
+	opaque := dockerConfig(`{}`)
+	opaque.Name, opaque.Type = "opaque", corev1.SecretTypeOpaque
+	require.NoError(t, c.Create(ctx, opaque))
+	_, err = CheckpointCredentials(ctx, c, newCheckpoint("ghcr.io/team/ckpt:warm", "opaque"))
+	assert.ErrorContains(t, err, "not kubernetes.io/dockerconfigjson")
+
+	// 恢复时沿用 checkpoint 的 Secret，本地 checkpoint 与非恢复的 sandbox 不需要凭据
+	require.NoError(t, c.Create(ctx, newCheckpoint("registry.example.com:5000/ckpt:warm", "regcred")))
+	sb := &apiv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "fork", Namespace: "default"}, Spec: apiv1alpha1.SandboxSpec{RestoreFrom: "warm"}}
+	creds, err = RestoreCredentials(ctx, c, c, sb)
+	require.NoError(t, err)
+	assert.Equal(t, "robot", creds.Username)
+	sb.Spec.RestoreFrom = ""
+	creds, err = RestoreCredentials(ctx, c, c, sb)
+	require.NoError(t, err)
+	assert.Nil(t, creds)
+}
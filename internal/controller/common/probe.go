@@ -0,0 +1,85 @@
+package common
+
+import (
+	"fmt"
+
+	"fast-sandbox/internal/api"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ReadinessProbe 将 Sandbox 的就绪探测转换为发给 Agent 的 probe，nil 表示没有探测。
+// Agent 只支持 exec、httpGet 与 tcpSocket，端口必须是数字，host 为空时探测 Agent Pod IP。
+func ReadinessProbe(probe *corev1.Probe) (*api.Probe, error) {
+	if probe == nil {
+		return nil, nil
+	}
+	out := &api.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+	if out.InitialDelaySeconds < 0 || out.PeriodSeconds < 0 || out.TimeoutSeconds < 0 || out.SuccessThreshold < 0 || out.FailureThreshold < 0 {
+		return nil, fmt.Errorf("readiness probe delays, periods and thresholds must not be negative")
+	}
+
+	handlers := 0
+	if probe.Exec != nil {
+		handlers++
+		if len(probe.Exec.Command) == 0 {
+			return nil, fmt.Errorf("readiness probe exec command is empty")
+		}
+		out.Exec = probe.Exec.Command
+	}
+	if probe.HTTPGet != nil {
+		handlers++
+		port, err := probePort(probe.HTTPGet.Port)
+		if err != nil {
+			return nil, err
+		}
+		if s := probe.HTTPGet.Scheme; s != "" && s != corev1.URISchemeHTTP && s != corev1.URISchemeHTTPS {
+			return nil, fmt.Errorf("readiness probe scheme %q must be HTTP or HTTPS", s)
+		}
+		out.HTTPGet = &api.HTTPGetProbe{
+			Scheme: string(probe.HTTPGet.Scheme),
+			Host:   probe.HTTPGet.Host,
+			Port:   port,
+			Path:   probe.HTTPGet.Path,
+		}
+		for _, h := range probe.HTTPGet.HTTPHeaders {
+			if out.HTTPGet.Headers == nil {
+				out.HTTPGet.Headers = make(map[string]string)
+			}
+			out.HTTPGet.Headers[h.Name] = h.Value
+		}
+	}
+	if probe.TCPSocket != nil {
+		handlers++
+		if probe.TCPSocket.Host != "" {
+			return nil, fmt.Errorf("readiness probe tcpSocket host is not supported")
+		}
+		port, err := probePort(probe.TCPSocket.Port)
+		if err != nil {
+			return nil, err
+		}
+		out.TCPPort = port
+	}
+	if probe.GRPC != nil {
+		return nil, fmt.Errorf("readiness probe grpc is not supported, use exec, httpGet or tcpSocket")
+	}
+	if handlers != 1 {
+		return nil, fmt.Errorf("readiness probe must have exactly one of exec, httpGet or tcpSocket")
+	}
+	return out, nil
+}
+
+// probePort 校验探测端口，sandbox 没有容器端口名，只接受数字
+func probePort(port intstr.IntOrString) (int32, error) {
+	if port.Type != intstr.Int || port.IntVal < 1 || port.IntVal > 65535 {
+		return 0, fmt.Errorf("readiness probe port %s must be a number between 1 and 65535", port.String())
+	}
+	return port.IntVal, nil
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"testing"
+
+	"fast-sandbox/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestReadinessProbe(t *testing.T) {
+	probe, err := ReadinessProbe(&corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+			Path:        "/healthz",
+			Port:        intstr.FromInt32(8080),
+			HTTPHeaders: []corev1.HTTPHeader{{Name: "X-Probe", Value: "1"}},
+		}},
+		PeriodSeconds:    2,
+		FailureThreshold: 5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &api.Probe{
+		HTTPGet:          &api.HTTPGetProbe{Port: 8080, Path: "/healthz", Headers: map[string]string{"X-Probe": "1"}},
+		PeriodSeconds:    2,
+		FailureThreshold: 5,
+	}, probe)
+
+	probe, err = ReadinessProbe(&corev1.Probe{ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(6379)}}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6379), probe.TCPPort)
+
+	probe, err = ReadinessProbe(nil)
+	require.NoError(t, err)
+	assert.Nil(t, probe)
+}
+
+func TestReadinessProbe_Invalid(t *testing.T) {
+	invalid := map[string]*corev1.Probe{
+		"no handler":   {},
+		"named port":   {ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("http")}}},
+		"grpc":         {ProbeHandler: corev1.ProbeHandler{GRPC: &corev1.GRPCAction{Port: 9090}}},
+		"empty exec":   {ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{}}},
+		"two handlers": {ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}, TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(80)}}},
+		"negative":     {ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}, PeriodSeconds: -1},
+	}
+	for name, p := range invalid {
+		_, err := ReadinessProbe(p)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,20 @@
+package common
+
+import (
+	"fast-sandbox/internal/api"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ApplyResources 将 Sandbox 的 CPU/内存 limits 与 CPU request 写入发给 Agent 的 spec
+func ApplyResources(spec *api.SandboxSpec, res corev1.ResourceRequirements) {
+	if q, ok := res.Limits[corev1.ResourceCPU]; ok {
+		spec.CPU = q.String()
+	}
+	if q, ok := res.Limits[corev1.ResourceMemory]; ok {
+		spec.Memory = q.String()
+	}
+	if q, ok := res.Requests[corev1.ResourceCPU]; ok {
+		spec.CPURequest = q.String()
+	}
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"testing"
+
+	"fast-sandbox/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestApplyResources(t *testing.T) {
+	spec := api.SandboxSpec{SandboxID: "sb-1"}
+	ApplyResources(&spec, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	})
+
+	assert.Equal(t, "1", spec.CPU)
+	assert.Equal(t, "512Mi", spec.Memory)
+	assert.Equal(t, "250m", spec.CPURequest)
+}
+
+func TestApplyResources_Empty(t *testing.T) {
+	spec := api.SandboxSpec{SandboxID: "sb-1"}
+	ApplyResources(&spec, corev1.ResourceRequirements{})
+
+	assert.Empty(t, spec.CPU)
+	assert.Empty(t, spec.Memory)
+	assert.Empty(t, spec.CPURequest)
+}
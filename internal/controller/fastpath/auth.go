@@ -0,0 +1,197 @@
+package fastpath
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	"fast-sandbox/internal/controller/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// accessRule is the RBAC verb and sandboxes subresource an RPC is checked against.
+type accessRule struct {
+	verb        string
+	subresource string
+}
+
+// accessRules 与 kubectl 对 Pod 的权限模型对应：exec、attach 与 cp 需要 create sandboxes/exec 等子资源权限，
+// 恢复出的是新沙箱，与 CreateSandbox 一样只需 create sandboxes
+var accessRules = map[string]accessRule{
+	fastpathv1.FastPathService_CreateSandbox_FullMethodName:     {verb: "create"},
+	fastpathv1.FastPathService_CreateSandboxes_FullMethodName:   {verb: "create"},
+	fastpathv1.FastPathService_DeleteSandbox_FullMethodName:     {verb: "delete"},
+	fastpathv1.FastPathService_UpdateSandbox_FullMethodName:     {verb: "update"},
+	fastpathv1.FastPathService_ListSandboxes_FullMethodName:     {verb: "list"},
+	fastpathv1.FastPathService_GetSandbox_FullMethodName:        {verb: "get"},
+	fastpathv1.FastPathService_WatchSandboxes_FullMethodName:    {verb: "watch"},
+	fastpathv1.FastPathService_ExecSandbox_FullMethodName:       {verb: "create", subresource: "exec"},
+	fastpathv1.FastPathService_AttachSandbox_FullMethodName:     {verb: "create", subresource: "attach"},
+	fastpathv1.FastPathService_CopyToSandbox_FullMethodName:     {verb: "create", subresource: "exec"},
+	fastpathv1.FastPathService_CopyFromSandbox_FullMethodName:   {verb: "create", subresource: "exec"},
+	fastpathv1.FastPathService_StreamLogs_FullMethodName:        {verb: "get", subresource: "log"},
+	fastpathv1.FastPathService_CheckpointSandbox_FullMethodName: {verb: "create", subresource: "checkpoint"},
+	fastpathv1.FastPathService_RestoreSandbox_FullMethodName:    {verb: "create"},
+}
+
+// target is a sandbox, or with an empty name all sandboxes of a namespace, that a
+// request accesses.
+type target struct {
+	namespace string
+	name      string
+}
+
+// requestTargets returns what a request message accesses.
+func requestTargets(req any) []target {
+	switch r := req.(type) {
+	case *fastpathv1.CreateRequest:
+		return []target{{namespace: r.Namespace}}
+	case *fastpathv1.CreateSandboxesRequest:
+		targets := make([]target, 0, len(r.Items))
+		for _, item := range r.Items {
+			if t := (target{namespace: item.GetNamespace()}); !slices.Contains(targets, t) {
+				targets = append(targets, t)
+			}
+		}
+		return targets
+	case *fastpathv1.DeleteRequest:
+		return []target{{namespace: r.Namespace, name: r.SandboxName}}
+	case *fastpathv1.UpdateRequest:
+		return []target{{namespace: r.Namespace, name: r.SandboxName}}
+	case *fastpathv1.ListRequest:
+		return []target{{namespace: r.Namespace}}
+	case *fastpathv1.GetRequest:
+		return []target{{namespace: r.Namespace, name: r.SandboxName}}
+	case *fastpathv1.WatchRequest:
+		return []target{{namespace: r.Namespace, name: r.SandboxName}}
+	case *fastpathv1.ExecRequest:
+		return []target{{namespace: r.GetStart().GetNamespace(), name: r.GetStart().GetSandboxName()}}
+	case *fastpathv1.AttachRequest:
+		return []target{{namespace: r.GetStart().GetNamespace(), name: r.GetStart().GetSandboxName()}}
+	case *fastpathv1.CopyToRequest:
+		return []target{{namespace: r.GetTarget().GetNamespace(), name: r.GetTarget().GetSandboxName()}}
+	case *fastpathv1.CopyFromRequest:
+		return []target{{namespace: r.GetTarget().GetNamespace(), name: r.GetTarget().GetSandboxName()}}
+	case *fastpathv1.LogsRequest:
+		return []target{{namespace: r.Namespace, name: r.SandboxName}}
+	case *fastpathv1.CheckpointRequest:
+		return []target{{namespace: r.Namespace, name: r.SandboxName}}
+	case *fastpathv1.RestoreRequest:
+		return []target{{namespace: r.Namespace}}
+	}
+	// 未知的消息按访问全部 namespace 检查
+	return []target{{}}
+}
+
+// Auth authenticates FastPath callers by the bearer token in the authorization metadata
+// and authorizes every request against the RBAC rules on sandboxes, so that the fast
+// path grants no more than the Sandbox CRD does.
+type Auth struct {
+	Authenticator auth.Authenticator
+	Authorizer    auth.Authorizer
+}
+
+// UnaryInterceptor authenticates and authorizes unary RPCs.
+func (a *Auth) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		user, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.authorize(ctx, user, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor authenticates streaming RPCs when they start and authorizes their
+// first request message, which names the sandbox.
+func (a *Auth) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		user, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, auth: a, user: user, method: info.FullMethod})
+	}
+}
+
+// authorizedStream authorizes the first message received on a stream; the following
+// ones carry data for the sandbox already authorized.
+type authorizedStream struct {
+	grpc.ServerStream
+	auth       *Auth
+	user       *auth.User
+	method     string
+	authorized bool
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authorized {
+		return nil
+	}
+	if err := s.auth.authorize(s.Context(), s.user, s.method, m); err != nil {
+		return err
+	}
+	s.authorized = true
+	return nil
+}
+
+// authenticate resolves the caller from the "authorization: Bearer <token>" metadata.
+func (a *Auth) authenticate(ctx context.Context) (*auth.User, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata must be a bearer token")
+	}
+
+	user, err := a.Authenticator.Authenticate(ctx, strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		klog.ErrorS(err, "Failed to authenticate FastPath caller")
+		return nil, status.Error(codes.Unavailable, "failed to authenticate: token review unavailable")
+	}
+	return user, nil
+}
+
+// authorize checks every target of a request against the rule of the method.
+func (a *Auth) authorize(ctx context.Context, user *auth.User, method string, req any) error {
+	rule, ok := accessRules[method]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "method %s is not allowed", method)
+	}
+	for _, t := range requestTargets(req) {
+		attrs := auth.Attributes{Verb: rule.verb, Namespace: t.namespace, Subresource: rule.subresource, Name: t.name}
+		d, err := a.Authorizer.Authorize(ctx, user, attrs)
+		if err != nil {
+			klog.ErrorS(err, "Failed to authorize FastPath caller", "user", user.Name, "method", method)
+			return status.Error(codes.Unavailable, "failed to authorize: access review unavailable")
+		}
+		if !d.Allowed {
+			klog.V(2).InfoS("FastPath request denied", "user", user.Name, "method", method, "access", attrs.String(), "reason", d.Reason)
+			msg := "user " + user.Name + " cannot " + attrs.String()
+			if d.Reason != "" {
+				msg += ": " + d.Reason
+			}
+			return status.Error(codes.PermissionDenied, msg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package fastpath
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	"fast-sandbox/internal/controller/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeAuthenticator map[string]*auth.User
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, token string) (*auth.User, error) {
+	if user, ok := a[token]; ok {
+		return user, nil
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+// fakeAuthorizer allows accesses to the namespace "team" and records every check.
+type fakeAuthorizer struct {
+	mu     sync.Mutex
+	checks []auth.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, _ *auth.User, attrs auth.Attributes) (auth.Decision, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.checks = append(a.checks, attrs)
+	return auth.Decision{Allowed: attrs.Namespace == "team"}, nil
+}
+
+// newAuthTestClient serves s behind the auth interceptors on an in-memory listener.
+func newAuthTestClient(t *testing.T, s *Server) (fastpathv1.FastPathServiceClient, *fakeAuthorizer) {
+	authorizer := &fakeAuthorizer{}
+	a := &Auth{Authenticator: fakeAuthenticator{"secret": {Name: "alice"}}, Authorizer: authorizer}
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(a.UnaryInterceptor()), grpc.StreamInterceptor(a.StreamInterceptor()))
+	fastpathv1.RegisterFastPathServiceServer(grpcServer, s)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return fastpathv1.NewFastPathServiceClient(conn), authorizer
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuth_Authentication(t *testing.T) {
+	// FA-01: Calls without a valid bearer token are rejected before reaching the server
+	server, _, _ := newBatchTestServer(t, 1)
+	client, authorizer := newAuthTestClient(t, server)
+
+	_, err := client.ListSandboxes(context.Background(), &fastpathv1.ListRequest{Namespace: "team"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.ListSandboxes(withToken("wrong"), &fastpathv1.ListRequest{Namespace: "team"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Empty(t, authorizer.checks)
+
+	_, err = client.ListSandboxes(withToken("secret"), &fastpathv1.ListRequest{Namespace: "team"})
+	assert.NoError(t, err)
+}
+
+func TestAuth_Authorization(t *testing.T) {
+	// FA-02: Each request is checked against the namespace and name it accesses
+	server, _, _ := newBatchTestServer(t, 1)
+	client, authorizer := newAuthTestClient(t, server)
+	ctx := withToken("secret")
+
+	_, err := client.GetSandbox(ctx, &fastpathv1.GetRequest{SandboxName: "sb-1", Namespace: "other"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "user alice cannot get sandboxes sb-1 in namespace other")
+
+	_, err = client.ListSandboxes(ctx, &fastpathv1.ListRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "An empty namespace lists all namespaces")
+
+	_, err = client.CreateSandboxes(ctx, &fastpathv1.CreateSandboxesRequest{Items: []*fastpathv1.CreateRequest{
+		{Name: "sb-1", Image: "alpine", PoolRef: "test-pool", Namespace: "team"},
+		{Name: "sb-2", Image: "alpine", PoolRef: "test-pool", Namespace: "other"},
+	}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "Every namespace of a batch must be allowed")
+
+	assert.Equal(t, []auth.Attributes{
+		{Verb: "get", Namespace: "other", Name: "sb-1"},
+		{Verb: "list"},
+		{Verb: "create", Namespace: "team"},
+		{Verb: "create", Namespace: "other"},
+	}, authorizer.checks)
+}
+
+func TestAuth_Streams(t *testing.T) {
+	// FA-03: Streams are authorized on their first message with the subresource of the RPC
+	server, _, _ := newBatchTestServer(t, 1)
+	client, authorizer := newAuthTestClient(t, server)
+	ctx := withToken("secret")
+
+	exec, err := client.ExecSandbox(ctx)
+	require.NoError(t, err)
+	require.NoError(t, exec.Send(&fastpathv1.ExecRequest{Payload: &fastpathv1.ExecRequest_Start{Start: &fastpathv1.ExecStart{SandboxName: "sb-1", Namespace: "other", Command: []string{"sh"}}}}))
+	_, err = exec.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	logs, err := client.StreamLogs(ctx, &fastpathv1.LogsRequest{SandboxName: "sb-1", Namespace: "team"})
+	require.NoError(t, err)
+	_, err = logs.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err), "Allowed streams reach the server")
+
+	assert.Equal(t, []auth.Attributes{
+		{Verb: "create", Namespace: "other", Subresource: "exec", Name: "sb-1"},
+		{Verb: "get", Namespace: "team", Subresource: "log", Name: "sb-1"},
+	}, authorizer.checks)
+}
@@ -0,0 +1,235 @@
+package fastpath
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxBatchSize 单次 CreateSandboxes 允许的最大条数
+	maxBatchSize = 1000
+	// batchConcurrency 单个批次同时进行的 Agent 创建调用数
+	batchConcurrency = 32
+)
+
+// batchItem is one sandbox of a CreateSandboxes call.
+type batchItem struct {
+	req   *fastpathv1.CreateRequest
+	sb    *apiv1alpha1.Sandbox
+	agent *agentpool.AgentInfo
+	mode  api.ConsistencyMode
+	resp  *fastpathv1.CreateResponse
+	err   error
+	// existing 表示 idempotency_key 命中了之前创建的沙箱，不会重复创建
+	existing bool
+	// releaseQuota 撤销该条目的配额预留，创建失败时调用
+	releaseQuota func()
+}
+
+// CreateSandboxes creates a batch of sandboxes. All items are allocated in one pass over
+// the registry, then created on their agents concurrently. With all_or_nothing, any
+// failure rolls back the sandboxes already created and the whole batch fails.
+func (s *Server) CreateSandboxes(ctx context.Context, req *fastpathv1.CreateSandboxesRequest) (*fastpathv1.CreateSandboxesResponse, error) {
+	start := time.Now()
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items is required")
+	}
+	if len(req.Items) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch of %d items exceeds the limit of %d", len(req.Items), maxBatchSize)
+	}
+	klog.InfoS("FastPath CreateSandboxes called", "count", len(req.Items), "allOrNothing", req.AllOrNothing)
+
+	// 1. 校验并构造 Sandbox，未指定名称时按序号生成，避免同一批次内重名
+	items := make([]*batchItem, len(req.Items))
+	names := make(map[types.NamespacedName]bool, len(req.Items))
+	keys := make(map[string]bool)
+	now := time.Now().UnixNano()
+	for i, r := range req.Items {
+		item := &batchItem{req: r, mode: s.consistencyMode(r)}
+		items[i] = item
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("sb-%d-%d", now, i)
+		}
+		key := types.NamespacedName{Namespace: r.Namespace, Name: name}
+		if names[key] {
+			item.err = status.Errorf(codes.InvalidArgument, "duplicate sandbox name %q in batch", name)
+			continue
+		}
+		names[key] = true
+		if r.IdempotencyKey != "" {
+			storeKey := idempotencyStoreKey(r.Namespace, r.IdempotencyKey)
+			if keys[storeKey] {
+				item.err = status.Errorf(codes.InvalidArgument, "duplicate idempotency key %q in batch", r.IdempotencyKey)
+				continue
+			}
+			keys[storeKey] = true
+			if item.resp, item.err = s.lookupIdempotent(ctx, r); item.resp != nil || item.err != nil {
+				item.existing = item.resp != nil
+				continue
+			}
+		}
+		if item.sb, item.err = newSandbox(r, name); item.err == nil {
+			item.releaseQuota, item.err = s.admitQuota(ctx, item.sb)
+		}
+	}
+	if req.AllOrNothing && abortOnFailure(items) {
+		releaseQuotas(items)
+		return batchResponse(items), nil
+	}
+
+	// 2. 一次性分配全部 Agent
+	var pending []*batchItem
+	var sbs []*apiv1alpha1.Sandbox
+	for _, item := range items {
+		if item.err == nil && !item.existing {
+			pending = append(pending, item)
+			sbs = append(sbs, item.sb)
+		}
+	}
+	agents, errs := s.Registry.AllocateBatch(sbs)
+	for i, item := range pending {
+		item.agent = agents[i]
+		if errs[i] != nil {
+			item.err = allocationError(errs[i])
+		}
+	}
+	klog.InfoS("Batch allocated", "count", len(pending), "duration", time.Since(start))
+	if req.AllOrNothing && abortOnFailure(items) {
+		for _, item := range items {
+			if item.agent != nil {
+				s.Registry.Release(item.agent.ID, item.sb)
+			}
+		}
+		releaseQuotas(items)
+		return batchResponse(items), nil
+	}
+
+	// 3. 并发调用 Agent；all-or-nothing 时 Fast 模式的 CRD 等整批成功后再写，便于回滚
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchConcurrency)
+	for _, item := range items {
+		if item.agent == nil {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(item *batchItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			switch {
+			case item.mode == api.ConsistencyModeStrong:
+				item.resp, item.err = s.createStrong(ctx, item.sb, item.agent, item.req)
+			case req.AllOrNothing:
+				item.resp, item.err = s.createFastOnAgent(ctx, item.sb, item.agent, item.req)
+			default:
+				item.resp, item.err = s.createFast(ctx, item.sb, item.agent, item.req)
+			}
+		}(item)
+	}
+	wg.Wait()
+
+	if req.AllOrNothing {
+		if abortOnFailure(items) {
+			s.rollbackBatch(ctx, items)
+		} else {
+			for _, item := range items {
+				if item.agent != nil && item.mode != api.ConsistencyModeStrong {
+					s.startAsyncCRDCreate(item.sb)
+				}
+			}
+		}
+	}
+
+	expires := time.Now().Add(s.idempotencyWindow())
+	for _, item := range items {
+		if item.err == nil && !item.existing && item.req.IdempotencyKey != "" {
+			s.idempotency.record(idempotencyStoreKey(item.req.Namespace, item.req.IdempotencyKey), item.resp, expires)
+		}
+	}
+
+	releaseQuotas(items)
+	resp := batchResponse(items)
+	klog.InfoS("FastPath CreateSandboxes completed", "count", len(items), "duration", time.Since(start))
+	return resp, nil
+}
+
+// abortOnFailure reports whether any item failed, and if so marks every other item as
+// aborted. Items that were already created keep their response for the rollback,
+// sandboxes of earlier requests found by idempotency key are left as they are.
+func abortOnFailure(items []*batchItem) bool {
+	var failed *batchItem
+	for _, item := range items {
+		if item.err != nil {
+			failed = item
+			break
+		}
+	}
+	if failed == nil {
+		return false
+	}
+	for _, item := range items {
+		if item.err == nil && !item.existing {
+			item.err = status.Errorf(codes.Aborted, "batch aborted: %s", status.Convert(failed.err).Message())
+		}
+	}
+	return true
+}
+
+// releaseQuotas drops the quota reservations of the items that were not created.
+func releaseQuotas(items []*batchItem) {
+	for _, item := range items {
+		if item.err != nil && item.releaseQuota != nil {
+			item.releaseQuota()
+		}
+	}
+}
+
+// rollbackBatch removes the sandboxes of an aborted all-or-nothing batch. Strong mode
+// sandboxes are deleted through their CRD, fast mode ones have no CRD yet and are
+// deleted on the agent directly.
+func (s *Server) rollbackBatch(ctx context.Context, items []*batchItem) {
+	for _, item := range items {
+		if item.resp == nil || item.existing {
+			continue
+		}
+		klog.InfoS("Rolling back batch sandbox", "name", item.sb.Name, "namespace", item.sb.Namespace, "agentPod", item.agent.PodName)
+		if item.mode == api.ConsistencyModeStrong {
+			if err := s.K8sClient.Delete(ctx, item.sb); err != nil {
+				klog.ErrorS(err, "Failed to delete sandbox CRD during rollback", "name", item.sb.Name, "namespace", item.sb.Namespace)
+			}
+			continue
+		}
+		if _, err := s.AgentClient.DeleteSandbox(item.agent.PodIP, &api.DeleteSandboxRequest{SandboxID: item.resp.SandboxId}); err != nil {
+			// Agent 上残留的沙箱没有 CRD，会被 Janitor 当作孤儿回收
+			klog.ErrorS(err, "Failed to delete sandbox on agent during rollback", "name", item.sb.Name, "agentPodIP", item.agent.PodIP)
+		}
+		s.Registry.Release(item.agent.ID, item.sb)
+	}
+}
+
+func batchResponse(items []*batchItem) *fastpathv1.CreateSandboxesResponse {
+	resp := &fastpathv1.CreateSandboxesResponse{Results: make([]*fastpathv1.CreateResult, len(items))}
+	for i, item := range items {
+		if item.err != nil {
+			resp.Results[i] = &fastpathv1.CreateResult{Error: status.Convert(item.err).Message(), Code: int32(status.Code(item.err))}
+			continue
+		}
+		resp.Results[i] = &fastpathv1.CreateResult{Success: true, Sandbox: item.resp}
+	}
+	return resp
+}
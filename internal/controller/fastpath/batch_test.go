@@ -0,0 +1,157 @@
+package fastpath
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeAgent is an agent HTTP API that records created and deleted sandboxes.
+type fakeAgent struct {
+	mu      sync.Mutex
+	created []api.SandboxSpec
+	deleted []string
+	// failClaim makes the create call of this sandbox name fail
+	failClaim string
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch r.URL.Path {
+	case "/api/v1/agent/create":
+		var req api.CreateSandboxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Sandbox.ClaimName == a.failClaim {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(api.CreateSandboxResponse{Message: "image pull failed"})
+			return
+		}
+		a.created = append(a.created, req.Sandbox)
+		json.NewEncoder(w).Encode(api.CreateSandboxResponse{Success: true, SandboxID: req.Sandbox.SandboxID})
+	case "/api/v1/agent/delete":
+		var req api.DeleteSandboxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		a.deleted = append(a.deleted, req.SandboxID)
+		json.NewEncoder(w).Encode(api.DeleteSandboxResponse{Success: true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newBatchTestServer starts a fake agent on localhost and registers it in a real registry.
+func newBatchTestServer(t *testing.T, capacity int) (*Server, *fakeAgent, *agentpool.InMemoryRegistry) {
+	agent := &fakeAgent{}
+	ts := httptest.NewServer(agent)
+	t.Cleanup(ts.Close)
+	host, portStr, err := net.SplitHostPort(ts.Listener.Addr().String())
+	require.NoError(t, err)
+	port, _ := strconv.Atoi(portStr)
+
+	registry := agentpool.NewInMemoryRegistry()
+	registry.RegisterOrUpdate(agentpool.AgentInfo{
+		ID: "agent-1", Namespace: "default", PodName: "agent-1", PodIP: host, PoolName: "test-pool", Capacity: capacity,
+	})
+	server := &Server{
+		K8sClient:              fake.NewClientBuilder().WithScheme(setupTestScheme(t)).Build(),
+		Registry:               registry,
+		AgentClient:            api.NewAgentClient(port),
+		DefaultConsistencyMode: api.ConsistencyModeFast,
+	}
+	return server, agent, registry
+}
+
+func batchItems(names ...string) []*fastpathv1.CreateRequest {
+	items := make([]*fastpathv1.CreateRequest, len(names))
+	for i, name := range names {
+		items[i] = &fastpathv1.CreateRequest{Name: name, Image: "alpine", PoolRef: "test-pool", Namespace: "default", ExposedPorts: []int32{int32(8000 + i)}}
+	}
+	return items
+}
+
+func TestServer_CreateSandboxes_PartialFailure(t *testing.T) {
+	// B-01: Items are reported individually, failures do not affect the others
+	server, agent, registry := newBatchTestServer(t, 3)
+	agent.failClaim = "sb-2"
+
+	resp, err := server.CreateSandboxes(context.Background(), &fastpathv1.CreateSandboxesRequest{
+		Items: batchItems("sb-0", "sb-1", "sb-2", "sb-3", "sb-1"),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 5)
+
+	assert.True(t, resp.Results[0].Success)
+	assert.Equal(t, "sb-0", resp.Results[0].Sandbox.SandboxName)
+	assert.Equal(t, "agent-1", resp.Results[0].Sandbox.AgentPod)
+	assert.Len(t, resp.Results[0].Sandbox.Endpoints, 1)
+	assert.True(t, resp.Results[1].Success)
+	assert.False(t, resp.Results[2].Success)
+	assert.Contains(t, resp.Results[2].Error, "image pull failed")
+	assert.False(t, resp.Results[3].Success, "Capacity is exhausted by the first three items")
+	assert.Contains(t, resp.Results[3].Error, "insufficient capacity")
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[4].Code, "Duplicate names are rejected")
+
+	info, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 2, info.Allocated, "The failed agent call releases its slot")
+	assert.Len(t, agent.created, 2)
+}
+
+func TestServer_CreateSandboxes_AllOrNothing(t *testing.T) {
+	// B-02: One failure rolls back every created sandbox
+	server, agent, registry := newBatchTestServer(t, 10)
+	agent.failClaim = "sb-1"
+
+	resp, err := server.CreateSandboxes(context.Background(), &fastpathv1.CreateSandboxesRequest{
+		Items:        batchItems("sb-0", "sb-1", "sb-2"),
+		AllOrNothing: true,
+	})
+	require.NoError(t, err)
+	for i, r := range resp.Results {
+		assert.False(t, r.Success, "item %d", i)
+	}
+	assert.Equal(t, int32(codes.Aborted), resp.Results[0].Code)
+	assert.Contains(t, resp.Results[0].Error, "image pull failed")
+
+	assert.Len(t, agent.created, 2)
+	assert.ElementsMatch(t, []string{agent.created[0].SandboxID, agent.created[1].SandboxID}, agent.deleted)
+	info, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 0, info.Allocated)
+	assert.Empty(t, info.UsedPorts)
+}
+
+func TestServer_CreateSandboxes_AllOrNothingAllocationFailure(t *testing.T) {
+	// B-03: An allocation failure aborts the batch before any agent call
+	server, agent, registry := newBatchTestServer(t, 1)
+
+	resp, err := server.CreateSandboxes(context.Background(), &fastpathv1.CreateSandboxesRequest{
+		Items:        batchItems("sb-0", "sb-1"),
+		AllOrNothing: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.Aborted), resp.Results[0].Code)
+	assert.False(t, resp.Results[1].Success)
+	assert.Empty(t, agent.created)
+	info, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 0, info.Allocated)
+}
+
+func TestServer_CreateSandboxes_InvalidRequest(t *testing.T) {
+	// B-04: Empty batches are rejected
+	server, _, _ := newBatchTestServer(t, 1)
+	_, err := server.CreateSandboxes(context.Background(), &fastpathv1.CreateSandboxesRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package fastpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/common"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// checkpointWaitTimeout 是 CheckpointSandbox 等待 checkpoint 完成的上限，推送大镜像可能需要数分钟
+	checkpointWaitTimeout = 5 * time.Minute
+	// checkpointPollInterval 轮询 SandboxCheckpoint status 的间隔
+	checkpointPollInterval = 200 * time.Millisecond
+)
+
+// CheckpointSandbox creates a SandboxCheckpoint for a sandbox and waits until the
+// controller has taken it. A Failed checkpoint is kept so that its message can be read.
+func (s *Server) CheckpointSandbox(ctx context.Context, req *fastpathv1.CheckpointRequest) (*fastpathv1.CheckpointInfo, error) {
+	if req.SandboxName == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_name is required")
+	}
+	if req.RegistrySecret != "" && req.Image == "" {
+		return nil, status.Error(codes.InvalidArgument, "registry_secret requires image")
+	}
+	var sb apiv1alpha1.Sandbox
+	if err := s.K8sClient.Get(ctx, client.ObjectKey{Name: req.SandboxName, Namespace: req.Namespace}, &sb); err != nil {
+		return nil, k8sError(err, req.Namespace, req.SandboxName)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-%d", req.SandboxName, time.Now().Unix())
+	}
+	klog.InfoS("FastPath CheckpointSandbox called", "name", name, "namespace", req.Namespace, "sandbox", req.SandboxName, "image", req.Image, "registrySecret", req.RegistrySecret)
+
+	ckpt := &apiv1alpha1.SandboxCheckpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: req.Namespace},
+		Spec:       apiv1alpha1.SandboxCheckpointSpec{SandboxName: req.SandboxName, Image: req.Image, RegistrySecret: req.RegistrySecret},
+	}
+	if err := s.K8sClient.Create(ctx, ckpt); err != nil {
+		klog.ErrorS(err, "Failed to create checkpoint", "name", name, "namespace", req.Namespace)
+		return nil, checkpointError(err, req.Namespace, name)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, checkpointWaitTimeout)
+	defer cancel()
+	ticker := time.NewTicker(checkpointPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.K8sClient.Get(waitCtx, client.ObjectKeyFromObject(ckpt), ckpt); err != nil && waitCtx.Err() == nil {
+			return nil, checkpointError(err, req.Namespace, name)
+		}
+		switch ckpt.Status.Phase {
+		case apiv1alpha1.CheckpointPhaseReady:
+			klog.InfoS("Sandbox checkpointed", "name", name, "namespace", req.Namespace, "ref", ckpt.Status.Ref)
+			return checkpointInfo(ckpt), nil
+		case apiv1alpha1.CheckpointPhaseFailed:
+			return nil, status.Errorf(codes.Internal, "checkpoint %s/%s failed: %s", req.Namespace, name, ckpt.Status.Message)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+			return nil, status.Errorf(codes.DeadlineExceeded, "checkpoint %s/%s is still in progress after %s", req.Namespace, name, checkpointWaitTimeout)
+		}
+	}
+}
+
+// RestoreSandbox creates a sandbox from a Ready checkpoint. The sandbox gets the spec the
+// checkpointed sandbox had and is scheduled like any other FastPath sandbox, pinned to
+// the checkpoint node when the checkpoint was not pushed.
+func (s *Server) RestoreSandbox(ctx context.Context, req *fastpathv1.RestoreRequest) (*fastpathv1.CreateResponse, error) {
+	if req.CheckpointName == "" {
+		return nil, status.Error(codes.InvalidArgument, "checkpoint_name is required")
+	}
+	var ckpt apiv1alpha1.SandboxCheckpoint
+	if err := s.K8sClient.Get(ctx, client.ObjectKey{Name: req.CheckpointName, Namespace: req.Namespace}, &ckpt); err != nil {
+		return nil, checkpointError(err, req.Namespace, req.CheckpointName)
+	}
+
+	sandboxName := req.Name
+	if sandboxName == "" {
+		sandboxName = fmt.Sprintf("sb-%d", time.Now().UnixNano())
+	}
+	klog.InfoS("FastPath RestoreSandbox called", "name", sandboxName, "namespace", req.Namespace, "checkpoint", req.CheckpointName)
+
+	tempSB := &apiv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: sandboxName, Namespace: req.Namespace}}
+	if ckpt.Status.Sandbox != nil {
+		tempSB.Spec = *ckpt.Status.Sandbox
+	}
+	tempSB.Spec.RestoreFrom = ckpt.Name
+	if err := common.ApplyCheckpoint(tempSB, &ckpt); err != nil {
+		if errors.Is(err, common.ErrCheckpointNotReady) {
+			return nil, status.Errorf(codes.FailedPrecondition, "checkpoint %s/%s is still in progress", req.Namespace, req.CheckpointName)
+		}
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	// 环境变量与工作目录由 create 请求传给 Agent，沿用 checkpoint 时的值
+	createReq := &fastpathv1.CreateRequest{
+		Namespace:       req.Namespace,
+		Name:            sandboxName,
+		ConsistencyMode: req.ConsistencyMode,
+		Envs:            envVarToMap(tempSB.Spec.Envs),
+		WorkingDir:      tempSB.Spec.WorkingDir,
+	}
+	return s.allocateAndCreate(ctx, tempSB, createReq)
+}
+
+// checkpointInfo converts a SandboxCheckpoint to its API representation.
+func checkpointInfo(ckpt *apiv1alpha1.SandboxCheckpoint) *fastpathv1.CheckpointInfo {
+	info := &fastpathv1.CheckpointInfo{
+		Name:        ckpt.Name,
+		Namespace:   ckpt.Namespace,
+		SandboxName: ckpt.Spec.SandboxName,
+		Phase:       string(ckpt.Status.Phase),
+		Message:     ckpt.Status.Message,
+		Ref:         ckpt.Status.Ref,
+		NodeName:    ckpt.Status.NodeName,
+		PoolRef:     ckpt.Status.PoolRef,
+	}
+	if ckpt.Status.CheckpointedAt != nil {
+		info.CheckpointedAt = ckpt.Status.CheckpointedAt.Unix()
+	}
+	return info
+}
@@ -0,0 +1,115 @@
+package fastpath
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCheckpointTestClient(t *testing.T, objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(setupTestScheme(t)).WithObjects(objs...).
+		WithStatusSubresource(&apiv1alpha1.SandboxCheckpoint{}).Build()
+}
+
+// finishCheckpoint plays the checkpoint controller: it sets the phase of the checkpoint
+// once it has been created.
+func finishCheckpoint(ctx context.Context, c client.Client, name string, phase apiv1alpha1.CheckpointPhase) {
+	for ctx.Err() == nil {
+		var ckpt apiv1alpha1.SandboxCheckpoint
+		if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, &ckpt); err == nil {
+			ckpt.Status = apiv1alpha1.SandboxCheckpointStatus{Phase: phase, Message: "criu failed", Ref: "checkpoint/sb", NodeName: "node-a", PoolRef: "test-pool"}
+			c.Status().Update(ctx, &ckpt)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_CheckpointSandbox(t *testing.T) {
+	// FC-01: CheckpointSandbox creates the SandboxCheckpoint and returns once it is Ready or Failed
+	sb := &apiv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"}}
+	c := newCheckpointTestClient(t, sb)
+	server := &Server{K8sClient: c}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go finishCheckpoint(ctx, c, "warm", apiv1alpha1.CheckpointPhaseReady)
+	info, err := server.CheckpointSandbox(ctx, &fastpathv1.CheckpointRequest{SandboxName: "sb", Namespace: "default", Name: "warm", Image: "registry.example.com/ckpt:warm"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ready", info.Phase)
+	assert.Equal(t, "checkpoint/sb", info.Ref)
+	assert.Equal(t, "node-a", info.NodeName)
+	var ckpt apiv1alpha1.SandboxCheckpoint
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "warm"}, &ckpt))
+	assert.Equal(t, apiv1alpha1.SandboxCheckpointSpec{SandboxName: "sb", Image: "registry.example.com/ckpt:warm"}, ckpt.Spec)
+
+	go finishCheckpoint(ctx, c, "broken", apiv1alpha1.CheckpointPhaseFailed)
+	_, err = server.CheckpointSandbox(ctx, &fastpathv1.CheckpointRequest{SandboxName: "sb", Namespace: "default", Name: "broken"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "criu failed")
+
+	_, err = server.CheckpointSandbox(ctx, &fastpathv1.CheckpointRequest{SandboxName: "sb", Namespace: "default", Name: "warm"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = server.CheckpointSandbox(ctx, &fastpathv1.CheckpointRequest{SandboxName: "missing", Namespace: "default"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// 客户端超时早于 checkpoint 完成
+	shortCtx, shortCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer shortCancel()
+	_, err = server.CheckpointSandbox(shortCtx, &fastpathv1.CheckpointRequest{SandboxName: "sb", Namespace: "default", Name: "slow"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestServer_RestoreSandbox(t *testing.T) {
+	// FC-02: RestoreSandbox starts from the checkpointed spec and only accepts Ready checkpoints
+	spec := apiv1alpha1.SandboxSpec{Image: "python:3", PoolRef: "test-pool", Args: []string{"-i"}}
+	newCkpt := func(name, image string, phase apiv1alpha1.CheckpointPhase, node string) *apiv1alpha1.SandboxCheckpoint {
+		return &apiv1alpha1.SandboxCheckpoint{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       apiv1alpha1.SandboxCheckpointSpec{SandboxName: "sb", Image: image},
+			Status:     apiv1alpha1.SandboxCheckpointStatus{Phase: phase, Ref: "checkpoint/" + name, NodeName: node, PoolRef: "test-pool", Sandbox: &spec},
+		}
+	}
+	server, agent, registry := newBatchTestServer(t, 2)
+	server.K8sClient = newCheckpointTestClient(t,
+		newCkpt("pushed", "registry.example.com/ckpt:warm", apiv1alpha1.CheckpointPhaseReady, "node-a"),
+		newCkpt("local", "", apiv1alpha1.CheckpointPhaseReady, "node-a"),
+		newCkpt("pending", "", "", ""),
+	)
+	ctx := context.Background()
+
+	// 测试用 Agent 只提供 HTTP API，恢复需要 gRPC Agent，调度仍按 checkpoint 的 spec 进行
+	_, err := server.RestoreSandbox(ctx, &fastpathv1.RestoreRequest{CheckpointName: "pushed", Namespace: "default", Name: "fork"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	assert.Empty(t, agent.created)
+	info, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 0, info.Allocated, "The failed agent call releases its slot")
+
+	_, err = server.RestoreSandbox(ctx, &fastpathv1.RestoreRequest{CheckpointName: "local", Namespace: "default"})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "No agent on the checkpoint node")
+	assert.Contains(t, err.Error(), "node-a")
+	registry.RegisterOrUpdate(agentpool.AgentInfo{
+		ID: "agent-2", Namespace: "default", PodName: "agent-2", PodIP: info.PodIP, NodeName: "node-a", PoolName: "test-pool", Capacity: 1,
+	})
+	_, err = server.RestoreSandbox(ctx, &fastpathv1.RestoreRequest{CheckpointName: "local", Namespace: "default"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "Scheduled to the agent on the checkpoint node")
+
+	_, err = server.RestoreSandbox(ctx, &fastpathv1.RestoreRequest{CheckpointName: "pending", Namespace: "default"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = server.RestoreSandbox(ctx, &fastpathv1.RestoreRequest{CheckpointName: "missing", Namespace: "default"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.RestoreSandbox(ctx, &fastpathv1.RestoreRequest{Namespace: "default"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
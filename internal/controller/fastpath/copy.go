@@ -0,0 +1,111 @@
+package fastpath
+
+import (
+	"errors"
+	"io"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// copyChunkSize bounds the size of a single CopyFromResponse message.
+const copyChunkSize = 32 * 1024
+
+// CopyToSandbox streams a client tar archive into a directory of the sandbox.
+func (s *Server) CopyToSandbox(stream fastpathv1.FastPathService_CopyToSandboxServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	target := first.GetTarget()
+	if target == nil {
+		return status.Error(codes.InvalidArgument, "first copy message must be target")
+	}
+	if target.Path == "" {
+		return status.Error(codes.InvalidArgument, "path is required")
+	}
+
+	ctx := stream.Context()
+	sb, agent, err := s.lookupRunningSandbox(ctx, target.SandboxName, target.Namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkNotPaused(sb); err != nil {
+		return err
+	}
+
+	klog.InfoS("FastPath CopyToSandbox called", "name", sb.Name, "namespace", sb.Namespace, "sandboxID", sb.Status.SandboxID, "agentPod", agent.PodName, "path", target.Path)
+
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					pw.Close()
+				} else {
+					pw.CloseWithError(err)
+				}
+				return
+			}
+			if _, err := pw.Write(msg.GetData()); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = s.AgentClient.CopyTo(ctx, agent.PodIP, sb.Status.SandboxID, target.Path, pr)
+	pr.Close()
+	if err != nil {
+		klog.ErrorS(err, "Failed to copy into sandbox", "name", sb.Name, "agentPodIP", agent.PodIP)
+		return agentError(err, &agent)
+	}
+	return stream.SendAndClose(&fastpathv1.CopyToResponse{Success: true})
+}
+
+// CopyFromSandbox streams a tar archive of a sandbox path back to the client.
+func (s *Server) CopyFromSandbox(req *fastpathv1.CopyFromRequest, stream fastpathv1.FastPathService_CopyFromSandboxServer) error {
+	target := req.GetTarget()
+	if target == nil || target.Path == "" {
+		return status.Error(codes.InvalidArgument, "path is required")
+	}
+
+	ctx := stream.Context()
+	sb, agent, err := s.lookupRunningSandbox(ctx, target.SandboxName, target.Namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkNotPaused(sb); err != nil {
+		return err
+	}
+
+	klog.InfoS("FastPath CopyFromSandbox called", "name", sb.Name, "namespace", sb.Namespace, "sandboxID", sb.Status.SandboxID, "agentPod", agent.PodName, "path", target.Path)
+
+	body, err := s.AgentClient.CopyFrom(ctx, agent.PodIP, sb.Status.SandboxID, target.Path)
+	if err != nil {
+		klog.ErrorS(err, "Failed to copy from sandbox", "name", sb.Name, "agentPodIP", agent.PodIP)
+		return agentError(err, &agent)
+	}
+	defer body.Close()
+
+	for {
+		// gRPC may hold on to a sent message, so every chunk gets its own buffer
+		buf := make([]byte, copyChunkSize)
+		n, err := body.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&fastpathv1.CopyFromResponse{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			klog.ErrorS(err, "Copy from sandbox aborted", "name", sb.Name, "path", target.Path)
+			return status.Errorf(codes.Internal, "copy from sandbox failed: %v", err)
+		}
+	}
+}
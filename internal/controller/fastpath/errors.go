@@ -0,0 +1,183 @@
+package fastpath
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/quota"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// errorDomain 是 ErrorInfo 中的 domain，客户端按 domain + reason 区分错误
+const errorDomain = "sandbox.fast.io"
+
+// ErrorInfo reasons attached to FastPath errors.
+const (
+	ReasonInsufficientCapacity = "INSUFFICIENT_CAPACITY"
+	ReasonPortConflict         = "PORT_CONFLICT"
+	ReasonSandboxNotFound      = "SANDBOX_NOT_FOUND"
+	ReasonAgentUnavailable     = "AGENT_UNAVAILABLE"
+	ReasonQuotaExceeded        = "QUOTA_EXCEEDED"
+	ReasonCheckpointNotFound   = "CHECKPOINT_NOT_FOUND"
+)
+
+const (
+	// capacityRetryDelay 资源不足时建议客户端的重试间隔
+	capacityRetryDelay = 2 * time.Second
+	// agentRetryDelay Agent 不可达时建议客户端的重试间隔
+	agentRetryDelay = time.Second
+)
+
+// withDetails attaches error details to a status. Details that fail to marshal are
+// dropped, the code and message alone are still meaningful.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if ds, err := st.WithDetails(details...); err == nil {
+		return ds.Err()
+	}
+	return st.Err()
+}
+
+// allocationError maps a Registry.Allocate failure to a gRPC status.
+func allocationError(err error) error {
+	var conflict *agentpool.PortConflictError
+	switch {
+	case errors.As(err, &conflict):
+		violations := make([]*errdetails.PreconditionFailure_Violation, len(conflict.Ports))
+		for i, p := range conflict.Ports {
+			violations[i] = &errdetails.PreconditionFailure_Violation{
+				Type:        "PORT",
+				Subject:     strconv.Itoa(int(p)),
+				Description: fmt.Sprintf("port %d is in use on every agent with free capacity in pool %s", p, conflict.Pool),
+			}
+		}
+		return withDetails(status.New(codes.FailedPrecondition, err.Error()),
+			&errdetails.ErrorInfo{Reason: ReasonPortConflict, Domain: errorDomain, Metadata: map[string]string{"pool": conflict.Pool}},
+			&errdetails.PreconditionFailure{Violations: violations})
+	case errors.Is(err, agentpool.ErrInsufficientCapacity):
+		return withDetails(status.New(codes.ResourceExhausted, err.Error()),
+			&errdetails.ErrorInfo{Reason: ReasonInsufficientCapacity, Domain: errorDomain},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(capacityRetryDelay)})
+	case errors.Is(err, agentpool.ErrInvalidPort):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, agentpool.ErrNodeUnavailable):
+		// 本地 checkpoint 所在节点的 Agent 恢复后即可重试
+		return withDetails(status.New(codes.Unavailable, err.Error()),
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(capacityRetryDelay)})
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// quotaError maps a failed SandboxQuota admission to a gRPC status.
+func quotaError(err error) error {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return status.Errorf(codes.Internal, "failed to check sandbox quota: %v", err)
+	}
+	return withDetails(status.New(codes.ResourceExhausted, err.Error()),
+		&errdetails.ErrorInfo{Reason: ReasonQuotaExceeded, Domain: errorDomain, Metadata: map[string]string{"quota": exceeded.Quota, "resource": exceeded.Resource}},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     exceeded.Namespace + "/" + exceeded.Quota,
+			Description: fmt.Sprintf("%s used %s of %s", exceeded.Resource, exceeded.Used, exceeded.Limit),
+		}}})
+}
+
+// agentError maps a failed call to an agent to a gRPC status. Errors the agent reported
+// keep their meaning, everything else means the agent could not be reached.
+func agentError(err error, agent *agentpool.AgentInfo) error {
+	var statusErr *api.StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadRequest:
+			return status.Error(codes.InvalidArgument, statusErr.Message)
+		case http.StatusNotFound:
+			return status.Error(codes.NotFound, statusErr.Message)
+		case http.StatusConflict:
+			return status.Error(codes.FailedPrecondition, statusErr.Message)
+		case http.StatusInternalServerError:
+			return status.Error(codes.Internal, statusErr.Message)
+		case http.StatusNotImplemented:
+			return status.Error(codes.Unimplemented, statusErr.Message)
+		}
+	}
+	metadata := map[string]string{}
+	if agent != nil {
+		metadata["agent"] = agent.PodName
+	}
+	return withDetails(status.Newf(codes.Unavailable, "failed to reach agent: %v", err),
+		&errdetails.ErrorInfo{Reason: ReasonAgentUnavailable, Domain: errorDomain, Metadata: metadata},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(agentRetryDelay)})
+}
+
+// k8sError maps an API server error on a sandbox to a gRPC status.
+func k8sError(err error, namespace, name string) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return withDetails(status.Newf(codes.NotFound, "sandbox %s/%s not found", namespace, name),
+			&errdetails.ErrorInfo{Reason: ReasonSandboxNotFound, Domain: errorDomain},
+			&errdetails.ResourceInfo{ResourceType: "Sandbox", ResourceName: namespace + "/" + name})
+	case apierrors.IsAlreadyExists(err):
+		return status.Errorf(codes.AlreadyExists, "sandbox %s/%s already exists", namespace, name)
+	case apierrors.IsConflict(err):
+		return status.Error(codes.Aborted, err.Error())
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case apierrors.IsForbidden(err):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsTooManyRequests(err), apierrors.IsServiceUnavailable(err):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// notReadyError attaches the identity of a created sandbox to the error of waiting for
+// it to become ready. The sandbox is kept, the client needs its name to use or delete it.
+func notReadyError(err error, namespace string, resp *fastpathv1.CreateResponse) error {
+	return withDetails(status.Convert(err),
+		&errdetails.ResourceInfo{
+			ResourceType: "Sandbox",
+			ResourceName: namespace + "/" + resp.SandboxName,
+			Owner:        resp.AgentPod,
+			Description:  fmt.Sprintf("sandbox %s was created on agent %s and is kept", resp.SandboxId, resp.AgentPod),
+		})
+}
+
+// credentialsError maps a failure to read the registry credentials of a checkpoint. A
+// missing or invalid Secret has to be fixed first, API server errors are worth a retry.
+func credentialsError(err error) error {
+	var apiStatus apierrors.APIStatus
+	if errors.As(err, &apiStatus) && !apierrors.IsNotFound(err) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
+// checkpointError maps an API server error on a SandboxCheckpoint to a gRPC status.
+func checkpointError(err error, namespace, name string) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return withDetails(status.Newf(codes.NotFound, "checkpoint %s/%s not found", namespace, name),
+			&errdetails.ErrorInfo{Reason: ReasonCheckpointNotFound, Domain: errorDomain},
+			&errdetails.ResourceInfo{ResourceType: "SandboxCheckpoint", ResourceName: namespace + "/" + name})
+	case apierrors.IsAlreadyExists(err):
+		return status.Errorf(codes.AlreadyExists, "checkpoint %s/%s already exists", namespace, name)
+	}
+	return k8sError(err, namespace, name)
+}
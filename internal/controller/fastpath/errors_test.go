@@ -0,0 +1,86 @@
+package fastpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// statusDetail returns the first detail of type T attached to err.
+func statusDetail[T any](t *testing.T, err error) T {
+	t.Helper()
+	var zero T
+	for _, d := range status.Convert(err).Details() {
+		if v, ok := d.(T); ok {
+			return v
+		}
+	}
+	t.Fatalf("no %T detail in %v", zero, err)
+	return zero
+}
+
+func TestAllocationError(t *testing.T) {
+	// E-01: Allocation failures map to typed codes with details
+	err := allocationError(fmt.Errorf("%w in pool p", agentpool.ErrInsufficientCapacity))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, capacityRetryDelay, statusDetail[*errdetails.RetryInfo](t, err).RetryDelay.AsDuration())
+	assert.Equal(t, ReasonInsufficientCapacity, statusDetail[*errdetails.ErrorInfo](t, err).Reason)
+
+	err = allocationError(&agentpool.PortConflictError{Pool: "p", Ports: []int32{8080}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	violations := statusDetail[*errdetails.PreconditionFailure](t, err).Violations
+	require.Len(t, violations, 1)
+	assert.Equal(t, "8080", violations[0].Subject)
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(allocationError(fmt.Errorf("%w 0", agentpool.ErrInvalidPort))))
+	assert.Equal(t, codes.Internal, status.Code(allocationError(errors.New("boom"))))
+}
+
+func TestK8sError(t *testing.T) {
+	// E-02: API server errors keep their meaning
+	gr := schema.GroupResource{Group: "sandbox.fast.io", Resource: "sandboxes"}
+	err := k8sError(apierrors.NewNotFound(gr, "sb"), "default", "sb")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "default/sb", statusDetail[*errdetails.ResourceInfo](t, err).ResourceName)
+	assert.Equal(t, codes.AlreadyExists, status.Code(k8sError(apierrors.NewAlreadyExists(gr, "sb"), "default", "sb")))
+	assert.Equal(t, codes.Unavailable, status.Code(k8sError(apierrors.NewServiceUnavailable("down"), "default", "sb")))
+}
+
+func TestServer_CreateSandbox_ErrorCodes(t *testing.T) {
+	// E-03: CreateSandbox reports capacity, port conflicts and unreachable agents distinctly
+	server, _, _ := newBatchTestServer(t, 2)
+	ctx := context.Background()
+
+	_, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-0", Image: "alpine", PoolRef: "test-pool", Namespace: "default", ExposedPorts: []int32{8080}})
+	require.NoError(t, err)
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-1", Image: "alpine", PoolRef: "test-pool", Namespace: "default", ExposedPorts: []int32{8080}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-2", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	require.NoError(t, err)
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-3", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = server.GetSandbox(ctx, &fastpathv1.GetRequest{SandboxName: "missing", Namespace: "default"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// 指向一个没有监听的端口，模拟 Agent 不可达
+	server.AgentClient = api.NewAgentClient(1)
+	server.AgentClient.SetTimeout(time.Second)
+	server.Registry.RegisterOrUpdate(agentpool.AgentInfo{ID: "agent-2", Namespace: "default", PodName: "agent-2", PodIP: "127.0.0.1", PoolName: "other-pool", Capacity: 1})
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-4", Image: "alpine", PoolRef: "other-pool", Namespace: "default"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "agent-2", statusDetail[*errdetails.ErrorInfo](t, err).Metadata["agent"])
+}
@@ -0,0 +1,219 @@
+package fastpath
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ExecSandbox proxies an interactive exec session to the agent hosting the sandbox.
+func (s *Server) ExecSandbox(stream fastpathv1.FastPathService_ExecSandboxServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "first exec message must be start")
+	}
+	if len(start.Command) == 0 {
+		return status.Error(codes.InvalidArgument, "command is required")
+	}
+
+	ctx := stream.Context()
+	sb, agent, err := s.lookupRunningSandbox(ctx, start.SandboxName, start.Namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkNotPaused(sb); err != nil {
+		return err
+	}
+
+	klog.InfoS("FastPath ExecSandbox called", "name", sb.Name, "namespace", sb.Namespace, "sandboxID", sb.Status.SandboxID, "agentPod", agent.PodName, "command", start.Command)
+
+	conn, err := s.AgentClient.Exec(ctx, agent.PodIP, &api.ExecRequest{
+		SandboxID:  sb.Status.SandboxID,
+		Command:    start.Command,
+		Env:        start.Envs,
+		WorkingDir: start.WorkingDir,
+		Stdin:      start.Stdin,
+		TTY:        start.Tty,
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to start exec on agent", "name", sb.Name, "agentPodIP", agent.PodIP)
+		return agentError(err, &agent)
+	}
+	defer conn.Close()
+
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				// Client half-close means no more stdin; anything else tears the session down
+				if errors.Is(err, io.EOF) {
+					_ = conn.Send(api.StreamStdinClose, nil)
+					return
+				}
+				conn.Close()
+				return
+			}
+			switch p := msg.Payload.(type) {
+			case *fastpathv1.ExecRequest_Stdin:
+				if err := conn.Send(api.StreamStdin, p.Stdin); err != nil {
+					return
+				}
+			case *fastpathv1.ExecRequest_CloseStdin:
+				if err := conn.Send(api.StreamStdinClose, nil); err != nil {
+					return
+				}
+			case *fastpathv1.ExecRequest_Resize:
+				if err := sendResize(conn, p.Resize); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return relayAgentOutput(conn, stream.Send, sb, agent)
+}
+
+// AttachSandbox proxies the main process streams of a sandbox from its agent.
+func (s *Server) AttachSandbox(stream fastpathv1.FastPathService_AttachSandboxServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "first attach message must be start")
+	}
+
+	ctx := stream.Context()
+	sb, agent, err := s.lookupRunningSandbox(ctx, start.SandboxName, start.Namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkNotPaused(sb); err != nil {
+		return err
+	}
+
+	klog.InfoS("FastPath AttachSandbox called", "name", sb.Name, "namespace", sb.Namespace, "sandboxID", sb.Status.SandboxID, "agentPod", agent.PodName, "stdin", start.Stdin)
+
+	conn, err := s.AgentClient.Attach(ctx, agent.PodIP, &api.AttachRequest{
+		SandboxID: sb.Status.SandboxID,
+		Stdin:     start.Stdin,
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to attach on agent", "name", sb.Name, "agentPodIP", agent.PodIP)
+		return agentError(err, &agent)
+	}
+	defer conn.Close()
+
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				// Client half-close only detaches stdin; anything else tears the session down
+				if errors.Is(err, io.EOF) {
+					_ = conn.Send(api.StreamStdinClose, nil)
+					return
+				}
+				conn.Close()
+				return
+			}
+			switch p := msg.Payload.(type) {
+			case *fastpathv1.AttachRequest_Stdin:
+				if err := conn.Send(api.StreamStdin, p.Stdin); err != nil {
+					return
+				}
+			case *fastpathv1.AttachRequest_CloseStdin:
+				if err := conn.Send(api.StreamStdinClose, nil); err != nil {
+					return
+				}
+			case *fastpathv1.AttachRequest_Resize:
+				if err := sendResize(conn, p.Resize); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return relayAgentOutput(conn, stream.Send, sb, agent)
+}
+
+// sendResize forwards a terminal size change to the agent.
+func sendResize(conn *api.StreamConn, size *fastpathv1.TerminalSize) error {
+	if size == nil {
+		return nil
+	}
+	data, err := json.Marshal(api.TerminalSize{Width: size.Width, Height: size.Height})
+	if err != nil {
+		return err
+	}
+	return conn.Send(api.StreamResize, data)
+}
+
+// relayAgentOutput copies agent output frames to the client until the exit frame arrives.
+func relayAgentOutput(conn *api.StreamConn, send func(*fastpathv1.ExecResponse) error, sb *apiv1alpha1.Sandbox, agent agentpool.AgentInfo) error {
+	for {
+		t, data, err := conn.Recv()
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "exec stream to agent %s broken: %v", agent.PodName, err)
+		}
+		switch t {
+		case api.StreamStdout:
+			err = send(&fastpathv1.ExecResponse{Payload: &fastpathv1.ExecResponse_Stdout{Stdout: data}})
+		case api.StreamStderr:
+			err = send(&fastpathv1.ExecResponse{Payload: &fastpathv1.ExecResponse_Stderr{Stderr: data}})
+		case api.StreamExit:
+			var exit api.ExecExitStatus
+			if err := json.Unmarshal(data, &exit); err != nil {
+				return status.Errorf(codes.Internal, "invalid exit status from agent: %v", err)
+			}
+			klog.InfoS("FastPath stream finished", "name", sb.Name, "namespace", sb.Namespace, "exitCode", exit.ExitCode)
+			return send(&fastpathv1.ExecResponse{Payload: &fastpathv1.ExecResponse_Exit{Exit: &fastpathv1.ExecExit{
+				ExitCode: int32(exit.ExitCode),
+				Message:  exit.Message,
+			}}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkNotPaused rejects starting processes or copying files in a paused sandbox, whose
+// processes are frozen. Logs stay readable.
+func checkNotPaused(sb *apiv1alpha1.Sandbox) error {
+	if sb.Spec.Paused || sb.Status.Phase == string(apiv1alpha1.PhasePaused) {
+		return status.Errorf(codes.FailedPrecondition, "sandbox %s/%s is paused, resume it first", sb.Namespace, sb.Name)
+	}
+	return nil
+}
+
+// lookupRunningSandbox resolves a sandbox by name and the agent currently hosting it.
+func (s *Server) lookupRunningSandbox(ctx context.Context, name, namespace string) (*apiv1alpha1.Sandbox, agentpool.AgentInfo, error) {
+	var sb apiv1alpha1.Sandbox
+	if err := s.K8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &sb); err != nil {
+		return nil, agentpool.AgentInfo{}, k8sError(err, namespace, name)
+	}
+	if sb.Status.AssignedPod == "" || sb.Status.SandboxID == "" {
+		return nil, agentpool.AgentInfo{}, status.Errorf(codes.FailedPrecondition, "sandbox %s/%s is not running (phase %q)", namespace, name, sb.Status.Phase)
+	}
+	agent, ok := s.Registry.GetAgentByID(agentpool.AgentID(sb.Status.AssignedPod))
+	if !ok || agent.PodIP == "" {
+		return nil, agentpool.AgentInfo{}, status.Errorf(codes.Unavailable, "agent %s for sandbox %s/%s is not available", sb.Status.AssignedPod, namespace, name)
+	}
+	return &sb, agent, nil
+}
@@ -0,0 +1,204 @@
+package fastpath
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/common"
+	"fast-sandbox/pkg/util/idgen"
+
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultIdempotencyWindow 未配置时 idempotency_key 的去重窗口
+	defaultIdempotencyWindow = 10 * time.Minute
+	// idempotencySweepInterval 清理过期记录的最小间隔
+	idempotencySweepInterval = time.Minute
+)
+
+// idempotencyEntry is the outcome of the first request of an idempotency key.
+// done is closed once resp or err is set.
+type idempotencyEntry struct {
+	done    chan struct{}
+	resp    *fastpathv1.CreateResponse
+	err     error
+	expires time.Time
+}
+
+// idempotencyStore remembers the responses of recent idempotent creates. The zero value
+// is ready to use. Only successful creates are kept, a failed create may be retried.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+func idempotencyStoreKey(namespace, key string) string {
+	return namespace + "/" + key
+}
+
+// begin returns the entry of the key. owner is true when the caller registered a new
+// entry and must complete it with finish; otherwise the entry belongs to an earlier
+// request, which may still be in flight.
+func (st *idempotencyStore) begin(key string, now time.Time) (entry *idempotencyEntry, owner bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.entries == nil {
+		st.entries = make(map[string]*idempotencyEntry)
+	}
+	if now.Sub(st.lastSweep) > idempotencySweepInterval {
+		for k, e := range st.entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(st.entries, k)
+			}
+		}
+		st.lastSweep = now
+	}
+
+	if e, ok := st.entries[key]; ok && (e.expires.IsZero() || now.Before(e.expires)) {
+		return e, false
+	}
+	e := &idempotencyEntry{done: make(chan struct{})}
+	st.entries[key] = e
+	return e, true
+}
+
+// finish completes an entry created by begin. Failed entries are dropped so that the
+// next retry creates the sandbox again.
+func (st *idempotencyStore) finish(key string, entry *idempotencyEntry, resp *fastpathv1.CreateResponse, err error, expires time.Time) {
+	st.mu.Lock()
+	entry.resp, entry.err = resp, err
+	entry.expires = expires
+	if err != nil && st.entries[key] == entry {
+		delete(st.entries, key)
+	}
+	st.mu.Unlock()
+	close(entry.done)
+}
+
+// completed returns the stored response of the key, if any.
+func (st *idempotencyStore) completed(key string, now time.Time) *fastpathv1.CreateResponse {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if e, ok := st.entries[key]; ok && !e.expires.IsZero() && now.Before(e.expires) {
+		return e.resp
+	}
+	return nil
+}
+
+// record stores a successful response, as finish does for entries not created by begin.
+func (st *idempotencyStore) record(key string, resp *fastpathv1.CreateResponse, expires time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.entries == nil {
+		st.entries = make(map[string]*idempotencyEntry)
+	}
+	done := make(chan struct{})
+	close(done)
+	st.entries[key] = &idempotencyEntry{done: done, resp: resp, expires: expires}
+}
+
+func (s *Server) idempotencyWindow() time.Duration {
+	if s.IdempotencyWindow > 0 {
+		return s.IdempotencyWindow
+	}
+	return defaultIdempotencyWindow
+}
+
+// createIdempotent creates a sandbox at most once per idempotency key within the window.
+// Concurrent retries wait for the first request, later retries get its response. After a
+// controller restart the response is rebuilt from the Sandbox CRD labelled with the key.
+func (s *Server) createIdempotent(ctx context.Context, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
+	key := idempotencyStoreKey(req.Namespace, req.IdempotencyKey)
+	for {
+		entry, owner := s.idempotency.begin(key, time.Now())
+		if !owner {
+			select {
+			case <-entry.done:
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+			if entry.err == nil {
+				klog.InfoS("Returning response of earlier idempotent create", "idempotencyKey", req.IdempotencyKey, "name", entry.resp.SandboxName, "namespace", req.Namespace)
+				return entry.resp, nil
+			}
+			// 首次请求失败，重新抢占执行
+			continue
+		}
+
+		resp, err := s.findIdempotentSandbox(ctx, req.Namespace, req.IdempotencyKey)
+		if err == nil && resp == nil {
+			resp, err = s.createSandbox(ctx, req)
+		} else if resp != nil {
+			klog.InfoS("Found sandbox of idempotency key", "idempotencyKey", req.IdempotencyKey, "name", resp.SandboxName, "namespace", req.Namespace)
+		}
+		s.idempotency.finish(key, entry, resp, err, time.Now().Add(s.idempotencyWindow()))
+		return resp, err
+	}
+}
+
+// lookupIdempotent returns the response of an earlier create with the same idempotency
+// key, from memory or from the Sandbox CRD. It does not wait for creates in flight.
+func (s *Server) lookupIdempotent(ctx context.Context, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
+	if resp := s.idempotency.completed(idempotencyStoreKey(req.Namespace, req.IdempotencyKey), time.Now()); resp != nil {
+		return resp, nil
+	}
+	return s.findIdempotentSandbox(ctx, req.Namespace, req.IdempotencyKey)
+}
+
+// findIdempotentSandbox looks up a sandbox created within the window for the key.
+func (s *Server) findIdempotentSandbox(ctx context.Context, namespace, key string) (*fastpathv1.CreateResponse, error) {
+	var list apiv1alpha1.SandboxList
+	if err := s.K8sClient.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels{common.LabelIdempotencyKey: common.IdempotencyKeyHash(key)}); err != nil {
+		klog.ErrorS(err, "Failed to look up sandbox by idempotency key", "namespace", namespace)
+		return nil, err
+	}
+	cutoff := time.Now().Add(-s.idempotencyWindow())
+	for i := range list.Items {
+		sb := &list.Items[i]
+		if sb.Annotations[common.AnnotationIdempotencyKey] != key || sb.DeletionTimestamp != nil || sb.CreationTimestamp.Time.Before(cutoff) {
+			continue
+		}
+		return s.createResponseFromSandbox(sb), nil
+	}
+	return nil, nil
+}
+
+// createResponseFromSandbox rebuilds the CreateResponse of an existing sandbox, falling
+// back to its annotations while the controller has not synced the status yet.
+func (s *Server) createResponseFromSandbox(sb *apiv1alpha1.Sandbox) *fastpathv1.CreateResponse {
+	resp := &fastpathv1.CreateResponse{
+		SandboxId:   sb.Status.SandboxID,
+		SandboxName: sb.Name,
+		AgentPod:    sb.Status.AssignedPod,
+		Endpoints:   sb.Status.Endpoints,
+	}
+	if resp.SandboxId == "" {
+		if sb.Labels[common.LabelCreatedBy] == common.CreatedByFastPathFast {
+			if ts, err := strconv.ParseInt(sb.Annotations[common.AnnotationCreateTimestamp], 10, 64); err == nil {
+				resp.SandboxId = idgen.GenerateHashID(sb.Name, sb.Namespace, ts)
+			}
+		} else {
+			resp.SandboxId = string(sb.UID)
+		}
+	}
+	if resp.AgentPod == "" {
+		if alloc, err := common.ParseAllocationInfo(sb.Annotations); err == nil && alloc != nil {
+			resp.AgentPod = alloc.AssignedPod
+		}
+	}
+	if len(resp.Endpoints) == 0 && resp.AgentPod != "" {
+		if agent, ok := s.Registry.GetAgentByID(agentpool.AgentID(resp.AgentPod)); ok {
+			resp.Endpoints = s.getEndpoints(agent.PodIP, sb)
+		}
+	}
+	return resp
+}
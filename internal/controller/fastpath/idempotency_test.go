@@ -0,0 +1,156 @@
+package fastpath
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// idempotentRequest builds an unnamed create request, port keeps sandboxes of different
+// keys from conflicting on the single test agent.
+func idempotentRequest(key string, port int32) *fastpathv1.CreateRequest {
+	return &fastpathv1.CreateRequest{Image: "alpine", PoolRef: "test-pool", Namespace: "default", ExposedPorts: []int32{port}, IdempotencyKey: key}
+}
+
+func TestServer_CreateSandbox_IdempotentRetry(t *testing.T) {
+	// I-01: A retry without a name returns the original sandbox instead of a new one
+	server, agent, registry := newBatchTestServer(t, 10)
+
+	first, err := server.CreateSandbox(context.Background(), idempotentRequest("retry-1", 8080))
+	require.NoError(t, err)
+	second, err := server.CreateSandbox(context.Background(), idempotentRequest("retry-1", 8080))
+	require.NoError(t, err)
+
+	assert.Equal(t, first.SandboxId, second.SandboxId)
+	assert.Equal(t, first.SandboxName, second.SandboxName)
+	assert.Len(t, agent.created, 1)
+	info, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 1, info.Allocated)
+
+	other, err := server.CreateSandbox(context.Background(), idempotentRequest("retry-2", 8081))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.SandboxName, other.SandboxName, "Different keys create different sandboxes")
+}
+
+func TestServer_CreateSandbox_IdempotentConcurrent(t *testing.T) {
+	// I-02: Concurrent requests with one key create a single sandbox
+	server, agent, _ := newBatchTestServer(t, 10)
+
+	var wg sync.WaitGroup
+	ids := make([]string, 8)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := server.CreateSandbox(context.Background(), idempotentRequest("concurrent", 8080))
+			if assert.NoError(t, err) {
+				ids[i] = resp.SandboxId
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	assert.Len(t, agent.created, 1)
+}
+
+func TestServer_CreateSandbox_IdempotentAfterFailure(t *testing.T) {
+	// I-03: A failed create is not remembered, the retry creates the sandbox
+	server, agent, _ := newBatchTestServer(t, 10)
+	req := idempotentRequest("after-failure", 8080)
+	req.Name = "sb-fail"
+	agent.failClaim = "sb-fail"
+
+	_, err := server.CreateSandbox(context.Background(), req)
+	require.Error(t, err)
+
+	agent.mu.Lock()
+	agent.failClaim = ""
+	agent.mu.Unlock()
+	resp, err := server.CreateSandbox(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "sb-fail", resp.SandboxName)
+	assert.Len(t, agent.created, 1)
+}
+
+func TestServer_CreateSandbox_IdempotentFromCRD(t *testing.T) {
+	// I-04: After a controller restart the key is found on the Sandbox CRD
+	server, agent, _ := newBatchTestServer(t, 10)
+	sb := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "sb-existing",
+			Namespace:         "default",
+			CreationTimestamp: metav1.Now(),
+			Labels: map[string]string{
+				common.LabelIdempotencyKey: common.IdempotencyKeyHash("restart"),
+				common.LabelCreatedBy:      common.CreatedByFastPathFast,
+			},
+			Annotations: map[string]string{
+				common.AnnotationIdempotencyKey:  "restart",
+				common.AnnotationCreateTimestamp: "1700000000000000000",
+				common.AnnotationAllocation:      common.BuildAllocationJSON("agent-1", "node-1"),
+			},
+		},
+		Spec: apiv1alpha1.SandboxSpec{Image: "alpine", PoolRef: "test-pool", ExposedPorts: []int32{8080}},
+	}
+	require.NoError(t, server.K8sClient.Create(context.Background(), sb))
+
+	resp, err := server.CreateSandbox(context.Background(), idempotentRequest("restart", 8080))
+	require.NoError(t, err)
+	assert.Equal(t, "sb-existing", resp.SandboxName)
+	assert.Equal(t, "agent-1", resp.AgentPod)
+	assert.NotEmpty(t, resp.SandboxId)
+	assert.Len(t, resp.Endpoints, 1)
+	assert.Empty(t, agent.created)
+}
+
+func TestServer_CreateSandbox_IdempotencyWindowExpired(t *testing.T) {
+	// I-05: Once the window has passed the key creates a new sandbox
+	server, agent, _ := newBatchTestServer(t, 10)
+	server.IdempotencyWindow = 10 * time.Millisecond
+
+	first, err := server.CreateSandbox(context.Background(), idempotentRequest("expire", 8080))
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	second, err := server.CreateSandbox(context.Background(), idempotentRequest("expire", 8081))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.SandboxName, second.SandboxName)
+	assert.Len(t, agent.created, 2)
+}
+
+func TestServer_CreateSandboxes_Idempotent(t *testing.T) {
+	// I-06: Batch items reuse earlier sandboxes of their key and reject duplicate keys
+	server, agent, _ := newBatchTestServer(t, 10)
+	first, err := server.CreateSandbox(context.Background(), idempotentRequest("batch-0", 8080))
+	require.NoError(t, err)
+
+	items := batchItems("sb-0", "sb-1", "sb-2")
+	items[0].IdempotencyKey = "batch-0"
+	items[1].IdempotencyKey = "batch-1"
+	items[2].IdempotencyKey = "batch-1"
+	resp, err := server.CreateSandboxes(context.Background(), &fastpathv1.CreateSandboxesRequest{Items: items})
+	require.NoError(t, err)
+
+	assert.True(t, resp.Results[0].Success)
+	assert.Equal(t, first.SandboxId, resp.Results[0].Sandbox.SandboxId)
+	assert.True(t, resp.Results[1].Success)
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[2].Code)
+	assert.Len(t, agent.created, 2)
+
+	retry, err := server.CreateSandbox(context.Background(), idempotentRequest("batch-1", 8001))
+	require.NoError(t, err)
+	assert.Equal(t, resp.Results[1].Sandbox.SandboxId, retry.SandboxId)
+}
@@ -0,0 +1,57 @@
+package fastpath
+
+import (
+	"errors"
+	"io"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// logChunkSize bounds the size of a single LogsResponse message.
+const logChunkSize = 32 * 1024
+
+// StreamLogs proxies the log stream of a sandbox from the agent hosting it.
+func (s *Server) StreamLogs(req *fastpathv1.LogsRequest, stream fastpathv1.FastPathService_StreamLogsServer) error {
+	if req.SandboxName == "" {
+		return status.Error(codes.InvalidArgument, "sandbox_name is required")
+	}
+
+	ctx := stream.Context()
+	sb, agent, err := s.lookupRunningSandbox(ctx, req.SandboxName, req.Namespace)
+	if err != nil {
+		return err
+	}
+
+	klog.InfoS("FastPath StreamLogs called", "name", sb.Name, "namespace", sb.Namespace, "sandboxID", sb.Status.SandboxID, "agentPod", agent.PodName, "follow", req.Follow)
+
+	body, err := s.AgentClient.StreamLogs(ctx, agent.PodIP, sb.Status.SandboxID, req.Follow)
+	if err != nil {
+		klog.ErrorS(err, "Failed to stream logs from agent", "name", sb.Name, "agentPodIP", agent.PodIP)
+		return agentError(err, &agent)
+	}
+	defer body.Close()
+
+	for {
+		buf := make([]byte, logChunkSize)
+		n, err := body.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&fastpathv1.LogsResponse{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			// Client went away while following
+			if ctx.Err() != nil {
+				return nil
+			}
+			return status.Errorf(codes.Unavailable, "log stream from agent %s broken: %v", agent.PodName, err)
+		}
+	}
+}
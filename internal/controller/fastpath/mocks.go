@@ -68,6 +68,15 @@ func (m *MockRegistryForTest) Allocate(sb *apiv1alpha1.Sandbox) (*agentpool.Agen
 	}, nil
 }
 
+func (m *MockRegistryForTest) AllocateBatch(sbs []*apiv1alpha1.Sandbox) ([]*agentpool.AgentInfo, []error) {
+	agents := make([]*agentpool.AgentInfo, len(sbs))
+	errs := make([]error, len(sbs))
+	for i, sb := range sbs {
+		agents[i], errs[i] = m.Allocate(sb)
+	}
+	return agents, errs
+}
+
 func (m *MockRegistryForTest) Release(id agentpool.AgentID, sb *apiv1alpha1.Sandbox) {
 	m.ReleasedID = id
 	m.ReleasedSb = sb
@@ -76,6 +85,10 @@ func (m *MockRegistryForTest) Release(id agentpool.AgentID, sb *apiv1alpha1.Sand
 	}
 }
 
+func (m *MockRegistryForTest) SelectVictims(sb *apiv1alpha1.Sandbox, running []apiv1alpha1.Sandbox) []*apiv1alpha1.Sandbox {
+	return nil
+}
+
 func (m *MockRegistryForTest) Restore(ctx context.Context, c client.Reader) error {
 	return nil
 }
@@ -90,6 +103,9 @@ func (m *MockRegistryForTest) CleanupStaleAgents(timeout time.Duration) int {
 	return 0
 }
 
+func (m *MockRegistryForTest) SetPoolStrategy(namespace, pool string, strategy apiv1alpha1.SchedulingStrategy) {
+}
+
 // MockAgentClientForTest is a mock implementation of AgentAPIClient for testing.
 type MockAgentClientForTest struct {
 	CreateSandboxFunc  func(endpoint string, req *api.CreateSandboxRequest) (*api.CreateSandboxResponse, error)
@@ -137,6 +153,14 @@ func (m *MockAgentClientForTest) DeleteSandbox(endpoint string, req *api.DeleteS
 	}, nil
 }
 
+func (m *MockAgentClientForTest) PauseSandbox(ctx context.Context, endpoint, sandboxID string) error {
+	return nil
+}
+
+func (m *MockAgentClientForTest) ResumeSandbox(ctx context.Context, endpoint, sandboxID string) error {
+	return nil
+}
+
 func (m *MockAgentClientForTest) GetAgentStatus(ctx context.Context, endpoint string) (*api.AgentStatus, error) {
 	if m.GetAgentStatusFunc != nil {
 		return m.GetAgentStatusFunc(ctx, endpoint)
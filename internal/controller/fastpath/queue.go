@@ -0,0 +1,52 @@
+package fastpath
+
+import (
+	"context"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxWaitTimeout 是 wait_timeout_seconds 允许的上限
+	maxWaitTimeout = 10 * time.Minute
+	// preemptionWaitTimeout 抢占后等待被抢占者释放资源的最短时间
+	preemptionWaitTimeout = 30 * time.Second
+)
+
+// waitForCapacity parks the sandbox in the pool queue until an agent with room
+// registers or a sandbox is released, at most for timeout.
+func (s *Server) waitForCapacity(ctx context.Context, sb *apiv1alpha1.Sandbox, timeout time.Duration) (*agentpool.AgentInfo, error) {
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	agent, err := s.Queue.Wait(waitCtx, sb, s.Registry.Allocate)
+	switch {
+	case err == nil:
+		klog.InfoS("Allocated after waiting for capacity", "name", sb.Name, "namespace", sb.Namespace, "pool", sb.Spec.PoolRef, "waited", time.Since(start))
+		return agent, nil
+	case ctx.Err() != nil:
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case waitCtx.Err() != nil:
+		return nil, status.Errorf(codes.DeadlineExceeded, "no capacity in pool %s after waiting %s", sb.Spec.PoolRef, timeout)
+	}
+	return nil, err
+}
+
+// preempt evicts lower-priority sandboxes of the pool for sb and reports whether the
+// caller should wait for the room being made. Failures only cost the preemption, the
+// original allocation error is returned to the client.
+func (s *Server) preempt(ctx context.Context, sb *apiv1alpha1.Sandbox) bool {
+	preempting, err := agentpool.Preempt(ctx, s.K8sClient, s.Registry, s.Recorder, sb)
+	if err != nil {
+		klog.ErrorS(err, "Preemption failed", "name", sb.Name, "namespace", sb.Namespace, "pool", sb.Spec.PoolRef)
+		return false
+	}
+	return preempting
+}
@@ -0,0 +1,122 @@
+package fastpath
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestServer_CreateSandbox_WaitForCapacity(t *testing.T) {
+	// W-01: With wait_timeout_seconds the create waits for a new agent instead of failing
+	server, _, registry := newBatchTestServer(t, 1)
+	server.Queue = agentpool.NewCapacityQueue()
+	registry.SetCapacityListener(server.Queue.Notify)
+	ctx := context.Background()
+
+	_, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-0", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	require.NoError(t, err)
+
+	type result struct {
+		resp *fastpathv1.CreateResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-1", Image: "alpine", PoolRef: "test-pool", Namespace: "default", WaitTimeoutSeconds: 5})
+		done <- result{resp, err}
+	}()
+	require.Eventually(t, func() bool { return server.Queue.Pending("default", "test-pool") == 1 }, time.Second, time.Millisecond)
+
+	// 扩容出的新 Agent 注册后，排队的请求立即完成
+	agent1, _ := registry.GetAgentByID("agent-1")
+	registry.RegisterOrUpdate(agentpool.AgentInfo{ID: "agent-2", Namespace: "default", PodName: "agent-2", PodIP: agent1.PodIP, PoolName: "test-pool", Capacity: 1})
+	select {
+	case r := <-done:
+		require.NoError(t, r.err)
+		assert.Equal(t, "agent-2", r.resp.AgentPod)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Queued create did not complete after the agent registered")
+	}
+}
+
+func TestServer_CreateSandbox_WaitTimeout(t *testing.T) {
+	// W-02: The wait is bounded by wait_timeout_seconds, without it the create fails at once
+	server, _, _ := newBatchTestServer(t, 0)
+	server.Queue = agentpool.NewCapacityQueue()
+	server.Registry.Remove("agent-1")
+	ctx := context.Background()
+
+	_, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	start := time.Now()
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Image: "alpine", PoolRef: "test-pool", Namespace: "default", WaitTimeoutSeconds: 1})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, 0, server.Queue.Pending("default", "test-pool"))
+
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Image: "alpine", PoolRef: "test-pool", Namespace: "default", WaitTimeoutSeconds: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_CreateSandbox_Preemption(t *testing.T) {
+	// W-03: A full pool evicts a lower-priority sandbox and the create completes once it is released
+	server, _, registry := newBatchTestServer(t, 1)
+	server.K8sClient = fake.NewClientBuilder().WithScheme(setupTestScheme(t)).WithStatusSubresource(&apiv1alpha1.Sandbox{}).Build()
+	server.Queue = agentpool.NewCapacityQueue()
+	registry.SetCapacityListener(server.Queue.Notify)
+	recorder := record.NewFakeRecorder(10)
+	server.Recorder = recorder
+	ctx := context.Background()
+
+	victim := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb-low", Namespace: "default"},
+		Spec:       apiv1alpha1.SandboxSpec{Image: "alpine", PoolRef: "test-pool"},
+	}
+	_, err := registry.Allocate(victim)
+	require.NoError(t, err)
+	require.NoError(t, server.K8sClient.Create(ctx, victim))
+	victim.Status = apiv1alpha1.SandboxStatus{Phase: string(apiv1alpha1.PhaseRunning), AssignedPod: "agent-1"}
+	require.NoError(t, server.K8sClient.Status().Update(ctx, victim))
+
+	// 同优先级不抢占
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-same", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// 模拟 Sandbox Controller：被抢占者进入 Preempted 后释放其槽位
+	go func() {
+		for range 200 {
+			var latest apiv1alpha1.Sandbox
+			if server.K8sClient.Get(ctx, client.ObjectKeyFromObject(victim), &latest) == nil && latest.Status.Phase == string(apiv1alpha1.PhasePreempted) {
+				registry.Release("agent-1", &latest)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	resp, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-high", Image: "alpine", PoolRef: "test-pool", Namespace: "default", Priority: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", resp.AgentPod)
+
+	var latest apiv1alpha1.Sandbox
+	require.NoError(t, server.K8sClient.Get(ctx, client.ObjectKeyFromObject(victim), &latest))
+	assert.Equal(t, string(apiv1alpha1.PhasePreempted), latest.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(latest.Status.Conditions, agentpool.ConditionPreempted))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Preempted by sandbox sb-high")
+}
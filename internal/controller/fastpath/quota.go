@@ -0,0 +1,20 @@
+package fastpath
+
+import (
+	"context"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+)
+
+// admitQuota reserves sb against the SandboxQuotas of its namespace. The returned
+// release undoes the reservation when the sandbox is not created after all.
+func (s *Server) admitQuota(ctx context.Context, sb *apiv1alpha1.Sandbox) (func(), error) {
+	if s.Quotas == nil {
+		return func() {}, nil
+	}
+	release, err := s.Quotas.Admit(ctx, sb)
+	if err != nil {
+		return nil, quotaError(err)
+	}
+	return release, nil
+}
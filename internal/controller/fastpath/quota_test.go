@@ -0,0 +1,85 @@
+package fastpath
+
+import (
+	"context"
+	"testing"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/quota"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// withQuota backs the server with a client holding a SandboxQuota of maxSandboxes for
+// the default namespace.
+func withQuota(t *testing.T, server *Server, maxSandboxes int32) {
+	sq := &apiv1alpha1.SandboxQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+		Spec:       apiv1alpha1.SandboxQuotaSpec{MaxSandboxes: ptr.To(maxSandboxes)},
+	}
+	server.K8sClient = fake.NewClientBuilder().WithScheme(setupTestScheme(t)).WithObjects(sq).Build()
+	server.Quotas = quota.NewTracker(server.K8sClient)
+}
+
+func TestServer_CreateSandbox_QuotaExceeded(t *testing.T) {
+	// QE-01: Creates beyond the quota fail with QuotaFailure details before allocation
+	server, _, registry := newBatchTestServer(t, 10)
+	withQuota(t, server, 1)
+	ctx := context.Background()
+
+	_, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-0", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	require.NoError(t, err)
+
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-1", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, ReasonQuotaExceeded, statusDetail[*errdetails.ErrorInfo](t, err).Reason)
+	violations := statusDetail[*errdetails.QuotaFailure](t, err).Violations
+	require.Len(t, violations, 1)
+	assert.Equal(t, "default/team", violations[0].Subject)
+
+	info, _ := registry.GetAgentByID("agent-1")
+	assert.Equal(t, 1, info.Allocated, "The rejected sandbox is never allocated")
+}
+
+func TestServer_CreateSandbox_QuotaReleasedOnFailure(t *testing.T) {
+	// QE-02: A create failing on the agent gives its quota reservation back
+	server, agent, _ := newBatchTestServer(t, 10)
+	withQuota(t, server, 1)
+	agent.failClaim = "sb-0"
+	ctx := context.Background()
+
+	_, err := server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-0", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	require.Error(t, err)
+	_, err = server.CreateSandbox(ctx, &fastpathv1.CreateRequest{Name: "sb-1", Image: "alpine", PoolRef: "test-pool", Namespace: "default"})
+	assert.NoError(t, err)
+}
+
+func TestServer_CreateSandboxes_Quota(t *testing.T) {
+	// QE-03: Batch items beyond the quota fail individually, an aborted batch releases
+	// every reservation
+	server, _, _ := newBatchTestServer(t, 10)
+	withQuota(t, server, 2)
+	ctx := context.Background()
+
+	resp, err := server.CreateSandboxes(ctx, &fastpathv1.CreateSandboxesRequest{
+		Items:        batchItems("sb-0", "sb-1", "sb-2"),
+		AllOrNothing: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.ResourceExhausted), resp.Results[2].Code)
+	assert.Equal(t, int32(codes.Aborted), resp.Results[0].Code)
+
+	resp, err = server.CreateSandboxes(ctx, &fastpathv1.CreateSandboxesRequest{Items: batchItems("sb-3", "sb-4", "sb-5")})
+	require.NoError(t, err)
+	assert.True(t, resp.Results[0].Success)
+	assert.True(t, resp.Results[1].Success)
+	assert.Equal(t, int32(codes.ResourceExhausted), resp.Results[2].Code)
+}
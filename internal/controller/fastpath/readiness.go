@@ -0,0 +1,90 @@
+package fastpath
+
+import (
+	"context"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxReadyTimeout 是 ready_timeout_seconds 允许的上限
+	maxReadyTimeout = 10 * time.Minute
+	// readyPollInterval 轮询 Agent 上报的就绪状态的间隔
+	readyPollInterval = 100 * time.Millisecond
+)
+
+// readinessProbeFromProto converts the readiness probe of a create request, it is
+// validated with the Sandbox spec.
+func readinessProbeFromProto(probe *fastpathv1.ReadinessProbe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	out := &corev1.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+	if len(probe.Exec) > 0 {
+		out.Exec = &corev1.ExecAction{Command: probe.Exec}
+	}
+	if h := probe.HttpGet; h != nil {
+		out.HTTPGet = &corev1.HTTPGetAction{
+			Port:   intstr.FromInt32(h.Port),
+			Path:   h.Path,
+			Scheme: corev1.URIScheme(h.Scheme),
+		}
+		for name, value := range h.Headers {
+			out.HTTPGet.HTTPHeaders = append(out.HTTPGet.HTTPHeaders, corev1.HTTPHeader{Name: name, Value: value})
+		}
+	}
+	if probe.TcpPort != 0 {
+		out.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt32(probe.TcpPort)}
+	}
+	return out
+}
+
+// waitForReady polls the status the agent reports for a created sandbox until it is
+// ready. The sandbox is kept when it does not become ready in time.
+func (s *Server) waitForReady(ctx context.Context, namespace string, resp *fastpathv1.CreateResponse, timeout time.Duration) error {
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		agent, ok := s.Registry.GetAgentByID(agentpool.AgentID(resp.AgentPod))
+		if !ok {
+			return status.Errorf(codes.Unavailable, "agent %s of sandbox %s/%s is gone", resp.AgentPod, namespace, resp.SandboxName)
+		}
+		if st, ok := agent.SandboxStatuses[resp.SandboxId]; ok {
+			if st.Ready {
+				klog.InfoS("Sandbox is ready", "name", resp.SandboxName, "namespace", namespace, "duration", time.Since(start))
+				return nil
+			}
+			switch apiv1alpha1.AgentSandboxPhase(st.Phase) {
+			case apiv1alpha1.AgentPhaseSucceeded, apiv1alpha1.AgentPhaseFailed:
+				return status.Errorf(codes.FailedPrecondition, "sandbox %s/%s exited before it became ready: %s", namespace, resp.SandboxName, st.Reason)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return status.Errorf(codes.DeadlineExceeded, "sandbox %s/%s is not ready after %s", namespace, resp.SandboxName, timeout)
+		}
+	}
+}
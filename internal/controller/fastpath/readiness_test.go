@@ -0,0 +1,114 @@
+package fastpath
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNewSandbox_ReadinessProbe(t *testing.T) {
+	sb, err := newSandbox(&fastpathv1.CreateRequest{
+		Image:   "nginx:latest",
+		PoolRef: "test-pool",
+		ReadinessProbe: &fastpathv1.ReadinessProbe{
+			HttpGet:       &fastpathv1.HTTPGetProbe{Port: 8080, Path: "/healthz", Headers: map[string]string{"X-Probe": "1"}},
+			PeriodSeconds: 2,
+		},
+		ReadyTimeoutSeconds: 30,
+	}, "web")
+	require.NoError(t, err)
+	require.NotNil(t, sb.Spec.ReadinessProbe)
+	require.NotNil(t, sb.Spec.ReadinessProbe.HTTPGet)
+	assert.Equal(t, intstr.FromInt32(8080), sb.Spec.ReadinessProbe.HTTPGet.Port)
+	assert.Equal(t, "/healthz", sb.Spec.ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, []corev1.HTTPHeader{{Name: "X-Probe", Value: "1"}}, sb.Spec.ReadinessProbe.HTTPGet.HTTPHeaders)
+	assert.Equal(t, int32(2), sb.Spec.ReadinessProbe.PeriodSeconds)
+
+	sb, err = newSandbox(&fastpathv1.CreateRequest{Image: "nginx:latest", PoolRef: "test-pool"}, "web")
+	require.NoError(t, err)
+	assert.Nil(t, sb.Spec.ReadinessProbe)
+
+	invalid := map[string]*fastpathv1.CreateRequest{
+		"no handler":        {Image: "nginx", ReadinessProbe: &fastpathv1.ReadinessProbe{PeriodSeconds: 1}},
+		"two handlers":      {Image: "nginx", ReadinessProbe: &fastpathv1.ReadinessProbe{TcpPort: 80, Exec: []string{"true"}}},
+		"port out of range": {Image: "nginx", ReadinessProbe: &fastpathv1.ReadinessProbe{TcpPort: 70000}},
+		"negative timeout":  {Image: "nginx", ReadyTimeoutSeconds: -1},
+		"timeout too long":  {Image: "nginx", ReadyTimeoutSeconds: int32(maxReadyTimeout.Seconds()) + 1},
+	}
+	for name, req := range invalid {
+		_, err := newSandbox(req, "web")
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+	}
+}
+
+func TestServer_WaitForReady(t *testing.T) {
+	// The agent reported status decides readiness, the sandbox is kept on failure
+	registry := &MockRegistryForTest{}
+	registry.RegisterOrUpdate(agentpool.AgentInfo{
+		ID:      "agent-1",
+		PodName: "agent-1",
+		SandboxStatuses: map[string]api.SandboxStatus{
+			"sb-ready":   {SandboxID: "sb-ready", Phase: "running", Ready: true},
+			"sb-running": {SandboxID: "sb-running", Phase: "running"},
+			"sb-failed":  {SandboxID: "sb-failed", Phase: "failed", Reason: api.ReasonError},
+		},
+	})
+	server := newTestServer(t, registry, nil)
+	wait := func(agentPod, sandboxID string) error {
+		resp := &fastpathv1.CreateResponse{SandboxId: sandboxID, SandboxName: sandboxID, AgentPod: agentPod}
+		return server.waitForReady(context.Background(), "default", resp, 300*time.Millisecond)
+	}
+
+	assert.NoError(t, wait("agent-1", "sb-ready"))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(wait("agent-1", "sb-running")))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(wait("agent-1", "sb-failed")))
+	assert.Equal(t, codes.Unavailable, status.Code(wait("agent-2", "sb-ready")))
+
+	// 客户端取消时返回其错误
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := server.waitForReady(ctx, "default", &fastpathv1.CreateResponse{SandboxId: "sb-running", AgentPod: "agent-1"}, time.Minute)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestServer_CreateSandbox_NotReadyReturnsIdentity(t *testing.T) {
+	// 未在超时内就绪时沙箱保留，错误中带上自动生成的名称和 ID
+	server, agent, _ := newBatchTestServer(t, 10)
+	req := &fastpathv1.CreateRequest{Image: "alpine", PoolRef: "test-pool", Namespace: "default", ReadyTimeoutSeconds: 1}
+
+	_, err := server.CreateSandbox(context.Background(), req)
+	require.Error(t, err)
+	st := status.Convert(err)
+	assert.Equal(t, codes.DeadlineExceeded, st.Code())
+
+	var info *errdetails.ResourceInfo
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.ResourceInfo); ok {
+			info = ri
+		}
+	}
+	require.NotNil(t, info, "the error carries the sandbox identity")
+	assert.Equal(t, "Sandbox", info.ResourceType)
+	assert.Regexp(t, `^default/sb-\d+$`, info.ResourceName)
+	assert.Equal(t, "agent-1", info.Owner)
+
+	var sbList apiv1alpha1.SandboxList
+	require.NoError(t, server.K8sClient.List(context.Background(), &sbList))
+	require.Len(t, sbList.Items, 1)
+	assert.Equal(t, info.ResourceName, "default/"+sbList.Items[0].Name)
+	require.Len(t, agent.created, 1)
+	assert.Contains(t, info.Description, agent.created[0].SandboxID)
+}
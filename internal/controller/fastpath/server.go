@@ -2,6 +2,7 @@ package fastpath
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -11,10 +12,17 @@ import (
 	"fast-sandbox/internal/api"
 	"fast-sandbox/internal/controller/agentpool"
 	"fast-sandbox/internal/controller/common"
+	"fast-sandbox/internal/controller/quota"
 	"fast-sandbox/pkg/util/idgen"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,25 +41,127 @@ func envMapToEnvVar(envs map[string]string) []corev1.EnvVar {
 	return result
 }
 
+// envVarToMap converts K8s EnvVar slice to map[string]string
+func envVarToMap(envs []corev1.EnvVar) map[string]string {
+	result := make(map[string]string, len(envs))
+	for _, e := range envs {
+		result[e.Name] = e.Value
+	}
+	return result
+}
+
+// resourcesFromProto converts proto resources to K8s ResourceRequirements.
+// Only cpu and memory are supported, and a request may not exceed its limit.
+func resourcesFromProto(res *fastpathv1.ResourceRequirements) (corev1.ResourceRequirements, error) {
+	var out corev1.ResourceRequirements
+	if res == nil {
+		return out, nil
+	}
+	parse := func(kind string, in map[string]string) (corev1.ResourceList, error) {
+		if len(in) == 0 {
+			return nil, nil
+		}
+		list := corev1.ResourceList{}
+		for name, value := range in {
+			rn := corev1.ResourceName(name)
+			if rn != corev1.ResourceCPU && rn != corev1.ResourceMemory {
+				return nil, fmt.Errorf("unsupported resource %q in %s", name, kind)
+			}
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s %q: %v", name, kind, value, err)
+			}
+			if q.Sign() <= 0 {
+				return nil, fmt.Errorf("%s %s must be positive", name, kind)
+			}
+			list[rn] = q
+		}
+		return list, nil
+	}
+
+	var err error
+	if out.Requests, err = parse("request", res.Requests); err != nil {
+		return out, err
+	}
+	if out.Limits, err = parse("limit", res.Limits); err != nil {
+		return out, err
+	}
+	for name, req := range out.Requests {
+		if limit, ok := out.Limits[name]; ok && req.Cmp(limit) > 0 {
+			return out, fmt.Errorf("%s request %s exceeds limit %s", name, req.String(), limit.String())
+		}
+	}
+	return out, nil
+}
+
+// restartPolicyFromProto validates the restart policy, empty means Never.
+func restartPolicyFromProto(policy string) (apiv1alpha1.RestartPolicy, error) {
+	switch p := apiv1alpha1.RestartPolicy(policy); p {
+	case "":
+		return apiv1alpha1.RestartPolicyNever, nil
+	case apiv1alpha1.RestartPolicyNever, apiv1alpha1.RestartPolicyOnFailure, apiv1alpha1.RestartPolicyAlways:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported restart policy %q, must be Never, OnFailure or Always", policy)
+	}
+}
+
+// idlePolicyFromProto validates the idle policy, empty means Pause.
+func idlePolicyFromProto(policy string) (apiv1alpha1.IdlePolicy, error) {
+	switch p := apiv1alpha1.IdlePolicy(policy); p {
+	case "":
+		return apiv1alpha1.IdlePolicyPause, nil
+	case apiv1alpha1.IdlePolicyPause, apiv1alpha1.IdlePolicyExpire, apiv1alpha1.IdlePolicyDelete:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported idle policy %q, must be Pause, Expire or Delete", policy)
+	}
+}
+
 type Server struct {
 	fastpathv1.UnimplementedFastPathServiceServer
 	K8sClient              client.Client
 	Registry               agentpool.AgentRegistry
 	AgentClient            *api.AgentClient
 	DefaultConsistencyMode api.ConsistencyMode
+	// Watches 可选，为 WatchSandboxes 提供 informer 缓存中的沙箱变化
+	Watches *WatchHub
+	// IdempotencyWindow 是 idempotency_key 的去重时间窗口，0 表示使用默认值
+	IdempotencyWindow time.Duration
+	// Queue 可选，容量不足且请求设置了 wait_timeout_seconds 时在此排队等待扩容
+	Queue *agentpool.CapacityQueue
+	// Recorder 可选，记录被抢占 sandbox 上的事件
+	Recorder record.EventRecorder
+	// Quotas 可选，按 namespace 的 SandboxQuota 准入创建请求
+	Quotas *quota.Tracker
+	// Reader 直接读 API Server，读取 checkpoint 的镜像仓库 Secret 时避免为 Secret 建立 informer 缓存
+	Reader client.Reader
+
+	idempotency idempotencyStore
 }
 
 // 强制编译时检查接口实现情况
 var _ fastpathv1.FastPathServiceServer = &Server{}
 
 func (s *Server) CreateSandbox(ctx context.Context, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
-	start := time.Now()
-
-	mode := s.DefaultConsistencyMode
-	if req.ConsistencyMode == fastpathv1.ConsistencyMode_STRONG {
-		mode = api.ConsistencyModeStrong
+	var resp *fastpathv1.CreateResponse
+	var err error
+	if req.IdempotencyKey != "" {
+		resp, err = s.createIdempotent(ctx, req)
+	} else {
+		resp, err = s.createSandbox(ctx, req)
 	}
+	if err != nil || req.ReadyTimeoutSeconds <= 0 {
+		return resp, err
+	}
+	// 重试同一 idempotency_key 的请求也等待已创建的沙箱就绪
+	if err := s.waitForReady(ctx, req.Namespace, resp, time.Duration(req.ReadyTimeoutSeconds)*time.Second); err != nil {
+		return nil, notReadyError(err, req.Namespace, resp)
+	}
+	return resp, nil
+}
 
+func (s *Server) createSandbox(ctx context.Context, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
 	sandboxName := req.Name
 	if sandboxName == "" {
 		sandboxName = fmt.Sprintf("sb-%d", time.Now().UnixNano())
@@ -59,37 +169,147 @@ func (s *Server) CreateSandbox(ctx context.Context, req *fastpathv1.CreateReques
 
 	klog.InfoS("FastPath CreateSandbox called", "name", sandboxName, "namespace", req.Namespace)
 
-	tempSB := &apiv1alpha1.Sandbox{
+	tempSB, err := newSandbox(req, sandboxName)
+	if err != nil {
+		return nil, err
+	}
+	return s.allocateAndCreate(ctx, tempSB, req)
+}
+
+// allocateAndCreate admits and schedules a validated sandbox, then creates it in the
+// consistency mode of the request.
+func (s *Server) allocateAndCreate(ctx context.Context, tempSB *apiv1alpha1.Sandbox, req *fastpathv1.CreateRequest) (resp *fastpathv1.CreateResponse, err error) {
+	start := time.Now()
+	sandboxName := tempSB.Name
+	releaseQuota, err := s.admitQuota(ctx, tempSB)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			releaseQuota()
+		}
+	}()
+
+	agent, err := s.Registry.Allocate(tempSB)
+	wait := time.Duration(req.WaitTimeoutSeconds) * time.Second
+	queue := errors.Is(err, agentpool.ErrInsufficientCapacity)
+	if err != nil && s.Queue != nil && agentpool.IsUnschedulable(err) && s.preempt(ctx, tempSB) {
+		// 被抢占的 sandbox 释放后才有容量，至少等待 preemptionWaitTimeout
+		wait = max(wait, preemptionWaitTimeout)
+		queue = true
+	}
+	if err != nil && wait > 0 && s.Queue != nil && queue {
+		agent, err = s.waitForCapacity(ctx, tempSB, wait)
+	}
+	if err != nil {
+		klog.Error(err, "Failed to allocate agent for sandbox", "name", sandboxName, "namespace", req.Namespace)
+		return nil, allocationError(err)
+	}
+
+	klog.InfoS("Agent allocated", "agentID", agent.ID, "duration", time.Since(start))
+
+	if s.consistencyMode(req) == api.ConsistencyModeStrong {
+		return s.createStrong(ctx, tempSB, agent, req)
+	}
+	return s.createFast(ctx, tempSB, agent, req)
+}
+
+// consistencyMode returns the mode requested by the client, or the controller default.
+func (s *Server) consistencyMode(req *fastpathv1.CreateRequest) api.ConsistencyMode {
+	if req.ConsistencyMode == fastpathv1.ConsistencyMode_STRONG {
+		return api.ConsistencyModeStrong
+	}
+	return s.DefaultConsistencyMode
+}
+
+// newSandbox validates a create request and builds the Sandbox to allocate.
+func newSandbox(req *fastpathv1.CreateRequest, sandboxName string) (*apiv1alpha1.Sandbox, error) {
+	resources, err := resourcesFromProto(req.Resources)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	restartPolicy, err := restartPolicyFromProto(req.RestartPolicy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	idlePolicy, err := idlePolicyFromProto(req.IdlePolicy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.IdleTimeoutSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "idle_timeout_seconds must not be negative")
+	}
+	if req.GetTtlSecondsAfterFinished() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds_after_finished must not be negative")
+	}
+	if wait := time.Duration(req.WaitTimeoutSeconds) * time.Second; wait < 0 || wait > maxWaitTimeout {
+		return nil, status.Errorf(codes.InvalidArgument, "wait_timeout_seconds must be between 0 and %d", int(maxWaitTimeout.Seconds()))
+	}
+	if ready := time.Duration(req.ReadyTimeoutSeconds) * time.Second; ready < 0 || ready > maxReadyTimeout {
+		return nil, status.Errorf(codes.InvalidArgument, "ready_timeout_seconds must be between 0 and %d", int(maxReadyTimeout.Seconds()))
+	}
+	readinessProbe := readinessProbeFromProto(req.ReadinessProbe)
+	if _, err := common.ReadinessProbe(readinessProbe); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sb := &apiv1alpha1.Sandbox{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      sandboxName,
 			Namespace: req.Namespace,
 		},
 		Spec: apiv1alpha1.SandboxSpec{
-			Image:        req.Image,
-			PoolRef:      req.PoolRef,
-			ExposedPorts: req.ExposedPorts,
-			Command:      req.Command,
-			Args:         req.Args,
-			Envs:         envMapToEnvVar(req.Envs),
-			WorkingDir:   req.WorkingDir,
+			Image:              req.Image,
+			PoolRef:            req.PoolRef,
+			ExposedPorts:       req.ExposedPorts,
+			Command:            req.Command,
+			Args:               req.Args,
+			Envs:               envMapToEnvVar(req.Envs),
+			WorkingDir:         req.WorkingDir,
+			TTY:                req.Tty,
+			Stdin:              req.Stdin,
+			Resources:          resources,
+			RestartPolicy:      restartPolicy,
+			Priority:           req.Priority,
+			IdleTimeoutSeconds: req.IdleTimeoutSeconds,
+			IdlePolicy:         idlePolicy,
+			ReadinessProbe:     readinessProbe,
 		},
 	}
+	if req.TtlSecondsAfterFinished != nil {
+		ttl := *req.TtlSecondsAfterFinished
+		sb.Spec.TTLSecondsAfterFinished = &ttl
+	}
+	if req.IdempotencyKey != "" {
+		// label 只存 key 的哈希用于查询，原始 key 存在 annotation 中
+		metav1.SetMetaDataLabel(&sb.ObjectMeta, common.LabelIdempotencyKey, common.IdempotencyKeyHash(req.IdempotencyKey))
+		metav1.SetMetaDataAnnotation(&sb.ObjectMeta, common.AnnotationIdempotencyKey, req.IdempotencyKey)
+	}
+	return sb, nil
+}
 
-	agent, err := s.Registry.Allocate(tempSB)
+func (s *Server) createFast(ctx context.Context, tempSB *apiv1alpha1.Sandbox, agent *agentpool.AgentInfo, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
+	resp, err := s.createFastOnAgent(ctx, tempSB, agent, req)
 	if err != nil {
-		klog.Error(err, "Failed to allocate agent for sandbox", "name", sandboxName, "namespace", req.Namespace)
 		return nil, err
 	}
+	s.startAsyncCRDCreate(tempSB)
+	return resp, nil
+}
 
-	klog.InfoS("Agent allocated", "agentID", agent.ID, "duration", time.Since(start))
-
-	if mode == api.ConsistencyModeStrong {
-		return s.createStrong(ctx, tempSB, agent, req)
-	}
-	return s.createFast(tempSB, agent, req)
+// startAsyncCRDCreate writes the CRD of a fast mode sandbox in the background.
+func (s *Server) startAsyncCRDCreate(sb *apiv1alpha1.Sandbox) {
+	asyncCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	go func() {
+		defer cancel()
+		s.asyncCreateCRDWithRetry(asyncCtx, sb)
+	}()
 }
 
-func (s *Server) createFast(tempSB *apiv1alpha1.Sandbox, agent *agentpool.AgentInfo, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
+// createFastOnAgent creates the sandbox on the agent and prepares the CRD metadata,
+// the caller is responsible for writing the CRD.
+func (s *Server) createFastOnAgent(ctx context.Context, tempSB *apiv1alpha1.Sandbox, agent *agentpool.AgentInfo, req *fastpathv1.CreateRequest) (*fastpathv1.CreateResponse, error) {
 	start := time.Now()
 	var err error
 	defer func() {
@@ -110,37 +330,45 @@ func (s *Server) createFast(tempSB *apiv1alpha1.Sandbox, agent *agentpool.AgentI
 
 	klog.InfoS("Creating sandbox via agent (fast mode)", "name", tempSB.Name, "namespace", tempSB.Namespace, "agentPodIP", agent.PodIP, "agentPod", agent.PodName, "sandboxID", sandboxID)
 
-	_, err = s.AgentClient.CreateSandbox(agent.PodIP, &api.CreateSandboxRequest{
-		Sandbox: api.SandboxSpec{
-			SandboxID:  sandboxID,
-			ClaimName:  tempSB.Name,
-			Image:      tempSB.Spec.Image,
-			Command:    tempSB.Spec.Command,
-			Args:       tempSB.Spec.Args,
-			Env:        req.Envs,
-			WorkingDir: req.WorkingDir,
-		},
-	})
+	spec := api.SandboxSpec{
+		SandboxID:     sandboxID,
+		ClaimName:     tempSB.Name,
+		Image:         tempSB.Spec.Image,
+		Command:       tempSB.Spec.Command,
+		Args:          tempSB.Spec.Args,
+		Env:           req.Envs,
+		WorkingDir:    req.WorkingDir,
+		TTY:           tempSB.Spec.TTY,
+		Stdin:         tempSB.Spec.Stdin,
+		RestartPolicy: api.RestartPolicy(tempSB.Spec.RestartPolicy),
+		Checkpoint:    tempSB.Annotations[common.AnnotationRestoreImage],
+		ExposedPorts:  tempSB.Spec.ExposedPorts,
+	}
+	common.ApplyResources(&spec, tempSB.Spec.Resources)
+	if spec.ReadinessProbe, err = common.ReadinessProbe(tempSB.Spec.ReadinessProbe); err != nil {
+		s.Registry.Release(agent.ID, tempSB)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if spec.CheckpointCredentials, err = common.RestoreCredentials(ctx, s.K8sClient, s.Reader, tempSB); err != nil {
+		s.Registry.Release(agent.ID, tempSB)
+		return nil, credentialsError(err)
+	}
+
+	_, err = s.AgentClient.CreateSandbox(agent.PodIP, &api.CreateSandboxRequest{Sandbox: spec})
 	if err != nil {
 		klog.ErrorS(err, "Failed to create sandbox on agent", "name", tempSB.Name, "namespace", tempSB.Namespace, "agentPodIP", agent.PodIP)
 		s.Registry.Release(agent.ID, tempSB)
-		return nil, err
+		return nil, agentError(err, agent)
 	}
 
 	klog.InfoS("Sandbox created on agent, setting label and annotations", "name", tempSB.Name, "namespace", tempSB.Namespace, "agentPod", agent.PodName, "node", agent.NodeName, "sandboxID", sandboxID)
 
 	// 设置 label 标识 Fast 模式创建
-	tempSB.SetLabels(map[string]string{
-		common.LabelCreatedBy: common.CreatedByFastPathFast,
-	})
+	metav1.SetMetaDataLabel(&tempSB.ObjectMeta, common.LabelCreatedBy, common.CreatedByFastPathFast)
 	// 设置 annotations：allocation 和 createTimestamp（用于重新生成 sandboxID）
-	tempSB.SetAnnotations(map[string]string{
-		common.AnnotationAllocation:      common.BuildAllocationJSON(agent.PodName, agent.NodeName),
-		common.AnnotationCreateTimestamp: strconv.FormatInt(createTimestamp, 10),
-	})
+	metav1.SetMetaDataAnnotation(&tempSB.ObjectMeta, common.AnnotationAllocation, common.BuildAllocationJSON(agent.PodName, agent.NodeName))
+	metav1.SetMetaDataAnnotation(&tempSB.ObjectMeta, common.AnnotationCreateTimestamp, strconv.FormatInt(createTimestamp, 10))
 
-	asyncCtx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-	go s.asyncCreateCRDWithRetry(asyncCtx, tempSB)
 	return &fastpathv1.CreateResponse{SandboxId: sandboxID, SandboxName: tempSB.Name, AgentPod: agent.PodName, Endpoints: s.getEndpoints(agent.PodIP, tempSB)}, nil
 }
 
@@ -162,15 +390,13 @@ func (s *Server) createStrong(ctx context.Context, tempSB *apiv1alpha1.Sandbox,
 	klog.InfoS("Creating sandbox CRD first (strong mode)", "name", tempSB.Name, "namespace", tempSB.Namespace, "agentPod", agent.PodName, "node", agent.NodeName)
 
 	// 设置 allocation annotation，与 CRD 创建同步
-	tempSB.SetAnnotations(map[string]string{
-		common.AnnotationAllocation: common.BuildAllocationJSON(agent.PodName, agent.NodeName),
-	})
+	metav1.SetMetaDataAnnotation(&tempSB.ObjectMeta, common.AnnotationAllocation, common.BuildAllocationJSON(agent.PodName, agent.NodeName))
 	// Status 留空，由 Controller 从 annotation 同步
 
 	if err = s.K8sClient.Create(ctx, tempSB); err != nil {
 		klog.ErrorS(err, "Failed to create sandbox CRD", "name", tempSB.Name, "namespace", tempSB.Namespace)
 		s.Registry.Release(agent.ID, tempSB)
-		return nil, err
+		return nil, k8sError(err, tempSB.Namespace, tempSB.Name)
 	}
 
 	klog.InfoS("Sandbox CRD created, proceeding to create on agent", "name", tempSB.Name, "namespace", tempSB.Namespace, "uid", tempSB.UID)
@@ -179,23 +405,39 @@ func (s *Server) createStrong(ctx context.Context, tempSB *apiv1alpha1.Sandbox,
 	sandboxID := string(tempSB.UID)
 	tempSB.Status.SandboxID = sandboxID
 
-	_, err = s.AgentClient.CreateSandbox(agent.PodIP, &api.CreateSandboxRequest{
-		Sandbox: api.SandboxSpec{
-			SandboxID:  sandboxID, // Changed from tempSB.Name to use UID
-			ClaimUID:   string(tempSB.UID),
-			ClaimName:  tempSB.Name,
-			Image:      tempSB.Spec.Image,
-			Command:    tempSB.Spec.Command,
-			Args:       tempSB.Spec.Args,
-			Env:        req.Envs,
-			WorkingDir: req.WorkingDir,
-		},
-	})
+	spec := api.SandboxSpec{
+		SandboxID:     sandboxID, // Changed from tempSB.Name to use UID
+		ClaimUID:      string(tempSB.UID),
+		ClaimName:     tempSB.Name,
+		Image:         tempSB.Spec.Image,
+		Command:       tempSB.Spec.Command,
+		Args:          tempSB.Spec.Args,
+		Env:           req.Envs,
+		WorkingDir:    req.WorkingDir,
+		TTY:           tempSB.Spec.TTY,
+		Stdin:         tempSB.Spec.Stdin,
+		RestartPolicy: api.RestartPolicy(tempSB.Spec.RestartPolicy),
+		Checkpoint:    tempSB.Annotations[common.AnnotationRestoreImage],
+		ExposedPorts:  tempSB.Spec.ExposedPorts,
+	}
+	common.ApplyResources(&spec, tempSB.Spec.Resources)
+	if spec.ReadinessProbe, err = common.ReadinessProbe(tempSB.Spec.ReadinessProbe); err != nil {
+		s.K8sClient.Delete(ctx, tempSB)
+		s.Registry.Release(agent.ID, tempSB)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if spec.CheckpointCredentials, err = common.RestoreCredentials(ctx, s.K8sClient, s.Reader, tempSB); err != nil {
+		s.K8sClient.Delete(ctx, tempSB)
+		s.Registry.Release(agent.ID, tempSB)
+		return nil, credentialsError(err)
+	}
+
+	_, err = s.AgentClient.CreateSandbox(agent.PodIP, &api.CreateSandboxRequest{Sandbox: spec})
 	if err != nil {
 		klog.ErrorS(err, "Failed to create sandbox on agent, rolling back CRD", "name", tempSB.Name, "namespace", tempSB.Namespace, "agentPodIP", agent.PodIP)
 		s.K8sClient.Delete(ctx, tempSB)
 		s.Registry.Release(agent.ID, tempSB)
-		return nil, err
+		return nil, agentError(err, agent)
 	}
 
 	// After Agent call succeeds, update CRD status with sandboxID
@@ -240,27 +482,47 @@ func (s *Server) ListSandboxes(ctx context.Context, req *fastpathv1.ListRequest)
 	var sbList apiv1alpha1.SandboxList
 	if err := s.K8sClient.List(ctx, &sbList, client.InNamespace(namespace)); err != nil {
 		klog.ErrorS(err, "Failed to list sandboxes", "namespace", namespace)
-		return nil, err
+		return nil, k8sError(err, namespace, "")
 	}
 
 	res := &fastpathv1.ListResponse{}
 	for _, sb := range sbList.Items {
-		res.Items = append(res.Items, &fastpathv1.SandboxInfo{
-			SandboxId:   sb.Status.SandboxID,
-			SandboxName: sb.Name,
-			Phase:       sb.Status.Phase,
-			AgentPod:    sb.Status.AssignedPod,
-			Endpoints:   sb.Status.Endpoints,
-			Image:       sb.Spec.Image,
-			PoolRef:     sb.Spec.PoolRef,
-			CreatedAt:   sb.CreationTimestamp.Unix(),
-		})
+		res.Items = append(res.Items, sandboxInfo(&sb))
 	}
 
 	klog.InfoS("Listed sandboxes successfully", "namespace", namespace, "count", len(res.Items))
 	return res, nil
 }
 
+// sandboxInfo converts a Sandbox CRD to its API representation.
+func sandboxInfo(sb *apiv1alpha1.Sandbox) *fastpathv1.SandboxInfo {
+	info := &fastpathv1.SandboxInfo{
+		SandboxId:    sb.Status.SandboxID,
+		SandboxName:  sb.Name,
+		Namespace:    sb.Namespace,
+		Phase:        sb.Status.Phase,
+		AgentPod:     sb.Status.AssignedPod,
+		Endpoints:    sb.Status.Endpoints,
+		Image:        sb.Spec.Image,
+		PoolRef:      sb.Spec.PoolRef,
+		CreatedAt:    sb.CreationTimestamp.Unix(),
+		Reason:       sb.Status.Reason,
+		RestartCount: sb.Status.RestartCount,
+		Priority:     sb.Spec.Priority,
+		Ready:        meta.IsStatusConditionTrue(sb.Status.Conditions, apiv1alpha1.SandboxConditionReady),
+	}
+	if sb.Status.ExitCode != nil {
+		info.ExitCode = *sb.Status.ExitCode
+	}
+	if sb.Status.FinishedAt != nil {
+		info.FinishedAt = sb.Status.FinishedAt.Unix()
+	}
+	if sb.Status.LastActivityTime != nil {
+		info.LastActivityAt = sb.Status.LastActivityTime.Unix()
+	}
+	return info
+}
+
 func (s *Server) GetSandbox(ctx context.Context, req *fastpathv1.GetRequest) (*fastpathv1.SandboxInfo, error) {
 	namespace := req.Namespace
 	klog.InfoS("Getting sandbox", "name", req.SandboxName, "namespace", namespace)
@@ -268,19 +530,10 @@ func (s *Server) GetSandbox(ctx context.Context, req *fastpathv1.GetRequest) (*f
 	var sb apiv1alpha1.Sandbox
 	if err := s.K8sClient.Get(ctx, client.ObjectKey{Name: req.SandboxName, Namespace: namespace}, &sb); err != nil {
 		klog.ErrorS(err, "Failed to get sandbox", "name", req.SandboxName, "namespace", namespace)
-		return nil, err
+		return nil, k8sError(err, namespace, req.SandboxName)
 	}
 
-	return &fastpathv1.SandboxInfo{
-		SandboxId:   sb.Status.SandboxID,
-		SandboxName: sb.Name,
-		Phase:       sb.Status.Phase,
-		AgentPod:    sb.Status.AssignedPod,
-		Endpoints:   sb.Status.Endpoints,
-		Image:       sb.Spec.Image,
-		PoolRef:     sb.Spec.PoolRef,
-		CreatedAt:   sb.CreationTimestamp.Unix(),
-	}, nil
+	return sandboxInfo(&sb), nil
 }
 
 func (s *Server) DeleteSandbox(ctx context.Context, req *fastpathv1.DeleteRequest) (*fastpathv1.DeleteResponse, error) {
@@ -290,7 +543,7 @@ func (s *Server) DeleteSandbox(ctx context.Context, req *fastpathv1.DeleteReques
 	sb := &apiv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: req.SandboxName, Namespace: ns}}
 	if err := s.K8sClient.Delete(ctx, sb); err != nil {
 		klog.ErrorS(err, "Failed to delete sandbox", "name", req.SandboxName, "namespace", ns)
-		return &fastpathv1.DeleteResponse{Success: false}, err
+		return &fastpathv1.DeleteResponse{Success: false}, k8sError(err, ns, req.SandboxName)
 	}
 
 	klog.InfoS("Sandbox deleted successfully", "name", req.SandboxName, "namespace", ns)
@@ -303,6 +556,9 @@ func (s *Server) UpdateSandbox(ctx context.Context, req *fastpathv1.UpdateReques
 	var sb apiv1alpha1.Sandbox
 	if err := s.K8sClient.Get(ctx, client.ObjectKey{Name: req.SandboxName, Namespace: req.Namespace}, &sb); err != nil {
 		klog.ErrorS(err, "Failed to get sandbox for update", "name", req.SandboxName, "namespace", req.Namespace)
+		if apierrors.IsNotFound(err) {
+			return nil, k8sError(err, req.Namespace, req.SandboxName)
+		}
 		return &fastpathv1.UpdateResponse{
 			Success: false,
 			Message: fmt.Sprintf("failed to get sandbox: %v", err),
@@ -337,6 +593,16 @@ func (s *Server) UpdateSandbox(ctx context.Context, req *fastpathv1.UpdateReques
 		case *fastpathv1.UpdateRequest_RecoveryTimeoutSeconds:
 			klog.InfoS("Updating RecoveryTimeoutSeconds", "name", req.SandboxName, "recoveryTimeoutSeconds", v.RecoveryTimeoutSeconds)
 			latest.Spec.RecoveryTimeoutSeconds = v.RecoveryTimeoutSeconds
+		case *fastpathv1.UpdateRequest_Paused:
+			// Controller 随后在 Agent 上冻结或解冻沙箱，Phase 变为 Paused 或 Running
+			klog.InfoS("Updating Paused", "name", req.SandboxName, "paused", v.Paused)
+			latest.Spec.Paused = v.Paused
+		case *fastpathv1.UpdateRequest_IdleTimeoutSeconds:
+			klog.InfoS("Updating IdleTimeoutSeconds", "name", req.SandboxName, "idleTimeoutSeconds", v.IdleTimeoutSeconds)
+			if v.IdleTimeoutSeconds < 0 {
+				return fmt.Errorf("idle_timeout_seconds must not be negative")
+			}
+			latest.Spec.IdleTimeoutSeconds = v.IdleTimeoutSeconds
 		}
 
 		// 更新标签
@@ -368,16 +634,7 @@ func (s *Server) UpdateSandbox(ctx context.Context, req *fastpathv1.UpdateReques
 	return &fastpathv1.UpdateResponse{
 		Success: true,
 		Message: "sandbox updated successfully",
-		Sandbox: &fastpathv1.SandboxInfo{
-			SandboxId:   sb.Status.SandboxID,
-			SandboxName: sb.Name,
-			Phase:       sb.Status.Phase,
-			AgentPod:    sb.Status.AssignedPod,
-			Endpoints:   sb.Status.Endpoints,
-			Image:       sb.Spec.Image,
-			PoolRef:     sb.Spec.PoolRef,
-			CreatedAt:   sb.CreationTimestamp.Unix(),
-		},
+		Sandbox: sandboxInfo(&sb),
 	}, nil
 }
 
@@ -15,6 +15,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -522,6 +524,115 @@ func TestEnvMapToEnvVar(t *testing.T) {
 	}
 }
 
+func TestResourcesFromProto(t *testing.T) {
+	// Valid requests and limits are converted to K8s quantities
+	res, err := resourcesFromProto(&fastpathv1.ResourceRequirements{
+		Requests: map[string]string{"cpu": "250m", "memory": "128Mi"},
+		Limits:   map[string]string{"cpu": "1", "memory": "256Mi"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "250m", res.Requests.Cpu().String())
+	assert.Equal(t, "128Mi", res.Requests.Memory().String())
+	assert.Equal(t, "1", res.Limits.Cpu().String())
+	assert.Equal(t, "256Mi", res.Limits.Memory().String())
+
+	// nil means no resources
+	res, err = resourcesFromProto(nil)
+	require.NoError(t, err)
+	assert.Nil(t, res.Requests)
+	assert.Nil(t, res.Limits)
+
+	invalid := []*fastpathv1.ResourceRequirements{
+		{Limits: map[string]string{"gpu": "1"}},
+		{Limits: map[string]string{"cpu": "fast"}},
+		{Requests: map[string]string{"memory": "0"}},
+		{Requests: map[string]string{"cpu": "2"}, Limits: map[string]string{"cpu": "1"}},
+	}
+	for _, r := range invalid {
+		_, err := resourcesFromProto(r)
+		assert.Error(t, err, "resources %v should be rejected", r)
+	}
+}
+
+func TestRestartPolicyFromProto(t *testing.T) {
+	// Empty means Never, unknown policies are rejected
+	policy, err := restartPolicyFromProto("")
+	require.NoError(t, err)
+	assert.Equal(t, apiv1alpha1.RestartPolicyNever, policy)
+
+	policy, err = restartPolicyFromProto("OnFailure")
+	require.NoError(t, err)
+	assert.Equal(t, apiv1alpha1.RestartPolicyOnFailure, policy)
+
+	_, err = restartPolicyFromProto("always")
+	assert.Error(t, err)
+}
+
+func TestIdlePolicyFromProto(t *testing.T) {
+	// Empty means Pause, unknown policies are rejected
+	policy, err := idlePolicyFromProto("")
+	require.NoError(t, err)
+	assert.Equal(t, apiv1alpha1.IdlePolicyPause, policy)
+
+	policy, err = idlePolicyFromProto("Expire")
+	require.NoError(t, err)
+	assert.Equal(t, apiv1alpha1.IdlePolicyExpire, policy)
+
+	_, err = idlePolicyFromProto("hibernate")
+	assert.Error(t, err)
+}
+
+func TestNewSandbox_Idle(t *testing.T) {
+	// The idle timeout and policy are copied to the spec, invalid values are rejected
+	sb, err := newSandbox(&fastpathv1.CreateRequest{Image: "jupyter", PoolRef: "test-pool", IdleTimeoutSeconds: 1800, IdlePolicy: "Delete"}, "nb")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1800), sb.Spec.IdleTimeoutSeconds)
+	assert.Equal(t, apiv1alpha1.IdlePolicyDelete, sb.Spec.IdlePolicy)
+
+	_, err = newSandbox(&fastpathv1.CreateRequest{Image: "jupyter", IdleTimeoutSeconds: -1}, "nb")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = newSandbox(&fastpathv1.CreateRequest{Image: "jupyter", IdlePolicy: "hibernate"}, "nb")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestNewSandbox_TTLAfterFinished(t *testing.T) {
+	// An unset TTL keeps the finished sandbox, 0 deletes it right away
+	sb, err := newSandbox(&fastpathv1.CreateRequest{Image: "job", PoolRef: "test-pool"}, "job")
+	require.NoError(t, err)
+	assert.Nil(t, sb.Spec.TTLSecondsAfterFinished)
+
+	ttl := int32(0)
+	sb, err = newSandbox(&fastpathv1.CreateRequest{Image: "job", PoolRef: "test-pool", TtlSecondsAfterFinished: &ttl}, "job")
+	require.NoError(t, err)
+	require.NotNil(t, sb.Spec.TTLSecondsAfterFinished)
+	assert.Equal(t, int32(0), *sb.Spec.TTLSecondsAfterFinished)
+
+	ttl = -1
+	_, err = newSandbox(&fastpathv1.CreateRequest{Image: "job", TtlSecondsAfterFinished: &ttl}, "job")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_CreateSandbox_InvalidResources(t *testing.T) {
+	// Invalid resources are rejected before scheduling
+	allocateCalled := false
+	registry := &MockRegistryForTest{
+		AllocateFunc: func(sb *apiv1alpha1.Sandbox) (*agentpool.AgentInfo, error) {
+			allocateCalled = true
+			return nil, errors.New("should not be called")
+		},
+	}
+	server := newTestServer(t, registry, nil)
+
+	_, err := server.CreateSandbox(context.Background(), &fastpathv1.CreateRequest{
+		Image:     "nginx:latest",
+		PoolRef:   "test-pool",
+		Resources: &fastpathv1.ResourceRequirements{Limits: map[string]string{"memory": "lots"}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.False(t, allocateCalled, "Allocate should not be called for invalid resources")
+}
+
 func TestServer_GetEndpoints(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -612,6 +723,9 @@ func TestServer_CreateSandbox_AllocateCalledWithCorrectSandbox(t *testing.T) {
 		Args:         []string{"-c", "echo hi"},
 		Envs:         map[string]string{"ENV1": "value1"},
 		WorkingDir:   "/app",
+		Resources: &fastpathv1.ResourceRequirements{
+			Limits: map[string]string{"cpu": "500m", "memory": "256Mi"},
+		},
 	}
 
 	_, _ = server.CreateSandbox(context.Background(), req)
@@ -628,6 +742,8 @@ func TestServer_CreateSandbox_AllocateCalledWithCorrectSandbox(t *testing.T) {
 	assert.Equal(t, []string{"/bin/sh"}, allocatedSandbox.Spec.Command, "Command should match request")
 	assert.Equal(t, []string{"-c", "echo hi"}, allocatedSandbox.Spec.Args, "Args should match request")
 	assert.Equal(t, "/app", allocatedSandbox.Spec.WorkingDir, "WorkingDir should match request")
+	assert.Equal(t, "500m", allocatedSandbox.Spec.Resources.Limits.Cpu().String(), "CPU limit should match request")
+	assert.Equal(t, "256Mi", allocatedSandbox.Spec.Resources.Limits.Memory().String(), "Memory limit should match request")
 
 	// Verify environment variables are converted correctly
 	require.Len(t, allocatedSandbox.Spec.Envs, 1, "Should have 1 environment variable")
@@ -1024,6 +1140,38 @@ func TestServer_DeleteSandbox(t *testing.T) {
 	assert.Error(t, err, "Sandbox should be deleted")
 }
 
+func TestServer_UpdateSandbox_Paused(t *testing.T) {
+	// Pausing sets Spec.Paused, exec and copy are refused until the sandbox is resumed
+	scheme := setupTestScheme(t)
+	sb := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sb", Namespace: "default"},
+		Spec:       apiv1alpha1.SandboxSpec{Image: "nginx", PoolRef: "pool-1"},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sb).Build()
+	server := &Server{K8sClient: k8sClient}
+
+	update := func(paused bool) *apiv1alpha1.Sandbox {
+		resp, err := server.UpdateSandbox(context.Background(), &fastpathv1.UpdateRequest{
+			SandboxName: "test-sb",
+			Namespace:   "default",
+			Update:      &fastpathv1.UpdateRequest_Paused{Paused: paused},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Success, resp.Message)
+		var latest apiv1alpha1.Sandbox
+		require.NoError(t, k8sClient.Get(context.Background(), types.NamespacedName{Name: "test-sb", Namespace: "default"}, &latest))
+		return &latest
+	}
+
+	paused := update(true)
+	assert.True(t, paused.Spec.Paused)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(checkNotPaused(paused)))
+
+	resumed := update(false)
+	assert.False(t, resumed.Spec.Paused)
+	assert.NoError(t, checkNotPaused(resumed))
+}
+
 func TestServer_DeleteSandbox_NotFound(t *testing.T) {
 	// Test DeleteSandbox with non-existent sandbox
 
@@ -1094,7 +1242,7 @@ func TestServer_createFast_SetsAllocationAnnotation(t *testing.T) {
 	// 所以这里我们测试失败场景，验证不会设置 annotation
 	registry.DefaultAgent.PodIP = ""
 
-	resp, err := server.createFast(tempSB, registry.DefaultAgent, req)
+	resp, err := server.createFast(context.Background(), tempSB, registry.DefaultAgent, req)
 
 	// 验证调用失败
 	assert.Error(t, err)
@@ -1189,3 +1337,60 @@ func TestServer_AllocationAnnotationFormat(t *testing.T) {
 	assert.Equal(t, assignedNode, allocInfo["assignedNode"])
 	assert.NotEmpty(t, allocInfo["allocatedAt"])
 }
+
+// ============================================================================
+// Exec Tests
+// ============================================================================
+
+func TestServer_lookupRunningSandbox(t *testing.T) {
+	scheme := setupTestScheme(t)
+	scheduled := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb-running", Namespace: "default"},
+		Status:     apiv1alpha1.SandboxStatus{AssignedPod: "agent-1", SandboxID: "sb-id-1", Phase: "Running"},
+	}
+	pending := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb-pending", Namespace: "default"},
+		Status:     apiv1alpha1.SandboxStatus{Phase: "Pending"},
+	}
+	orphan := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sb-orphan", Namespace: "default"},
+		Status:     apiv1alpha1.SandboxStatus{AssignedPod: "agent-gone", SandboxID: "sb-id-2", Phase: "Running"},
+	}
+
+	server := &Server{
+		K8sClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(scheduled, pending, orphan).Build(),
+		Registry: &MockRegistryForTest{Agents: map[agentpool.AgentID]agentpool.AgentInfo{
+			"agent-1": {ID: "agent-1", PodName: "agent-1", PodIP: "10.0.0.1"},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		sandbox  string
+		wantCode codes.Code
+	}{
+		{name: "running", sandbox: "sb-running", wantCode: codes.OK},
+		{name: "not found", sandbox: "missing", wantCode: codes.NotFound},
+		{name: "not scheduled", sandbox: "sb-pending", wantCode: codes.FailedPrecondition},
+		{name: "agent gone", sandbox: "sb-orphan", wantCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, agent, err := server.lookupRunningSandbox(context.Background(), tt.sandbox, "default")
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, "sb-id-1", sb.Status.SandboxID)
+				assert.Equal(t, "10.0.0.1", agent.PodIP)
+			}
+		})
+	}
+}
+
+func TestAgentError(t *testing.T) {
+	assert.Equal(t, codes.NotFound, status.Code(agentError(&api.StatusError{StatusCode: 404, Message: "gone"}, nil)))
+	assert.Equal(t, codes.InvalidArgument, status.Code(agentError(&api.StatusError{StatusCode: 400, Message: "bad"}, nil)))
+	assert.Equal(t, codes.Internal, status.Code(agentError(&api.StatusError{StatusCode: 500, Message: "tar failed"}, nil)))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(agentError(&api.StatusError{StatusCode: 409, Message: "sandbox exited"}, nil)))
+	assert.Equal(t, codes.Unavailable, status.Code(agentError(errors.New("connection refused"), nil)))
+}
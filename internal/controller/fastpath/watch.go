@@ -0,0 +1,324 @@
+package fastpath
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultWatchHistory 保留的最近变化条数，决定断线后可以续传的时间窗口
+	defaultWatchHistory = 1000
+	// watcherBuffer 单个 watch 的事件缓冲，写满说明客户端过慢，断开后由客户端续传
+	watcherBuffer = 256
+)
+
+// WatchHub keeps the latest Sandbox objects seen by the informer together with a bounded
+// history of changes, and fans the changes out to WatchSandboxes streams. It is registered
+// on the Sandbox informer of the manager cache, so watches never hit the API server.
+type WatchHub struct {
+	mu          sync.Mutex
+	objects     map[types.NamespacedName]*apiv1alpha1.Sandbox
+	history     []sandboxEvent
+	historySize int
+	// compacted 是已不在 history 中的最大 resourceVersion，更早的版本无法续传
+	compacted uint64
+	latest    uint64
+	watchers  map[int]chan sandboxEvent
+	nextID    int
+	hasSynced func() bool
+}
+
+// sandboxEvent is a change of a Sandbox as recorded by the hub.
+type sandboxEvent struct {
+	Type   fastpathv1.WatchEventType
+	Object *apiv1alpha1.Sandbox
+	// Old 是 MODIFIED 事件的上一个版本，用于判断对象是否移入/移出过滤范围
+	Old *apiv1alpha1.Sandbox
+	RV  uint64
+}
+
+// hubWatch is a registered watcher: the events to replay first, then the live channel.
+type hubWatch struct {
+	Initial []sandboxEvent
+	// ResourceVersion is the version of the hub state the initial events correspond to.
+	ResourceVersion uint64
+	Events          <-chan sandboxEvent
+	Stop            func()
+}
+
+var _ toolscache.ResourceEventHandler = &WatchHub{}
+
+// NewWatchHub creates an empty hub, register it with AddEventHandler on the Sandbox informer.
+func NewWatchHub() *WatchHub {
+	return &WatchHub{
+		objects:     make(map[types.NamespacedName]*apiv1alpha1.Sandbox),
+		historySize: defaultWatchHistory,
+		watchers:    make(map[int]chan sandboxEvent),
+	}
+}
+
+// SetSynced sets the function reporting whether the hub has received the initial list,
+// usually the HasSynced of the informer registration.
+func (h *WatchHub) SetSynced(hasSynced func() bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hasSynced = hasSynced
+}
+
+// HasSynced reports whether the hub holds the full initial list.
+func (h *WatchHub) HasSynced() bool {
+	h.mu.Lock()
+	hasSynced := h.hasSynced
+	h.mu.Unlock()
+	return hasSynced == nil || hasSynced()
+}
+
+func (h *WatchHub) OnAdd(obj interface{}, isInInitialList bool) {
+	sb, ok := obj.(*apiv1alpha1.Sandbox)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := client.ObjectKeyFromObject(sb)
+	old := h.objects[key]
+	h.objects[key] = sb
+
+	rv := parseResourceVersion(sb.ResourceVersion)
+	if isInInitialList {
+		// 初始列表不是变化，只推进可续传的起点
+		h.compacted = max(h.compacted, rv)
+		h.latest = max(h.latest, rv)
+		return
+	}
+	ev := sandboxEvent{Type: fastpathv1.WatchEventType_ADDED, Object: sb, RV: rv}
+	if old != nil {
+		// informer 重新 list 时已存在的对象
+		ev.Type = fastpathv1.WatchEventType_MODIFIED
+		ev.Old = old
+	}
+	h.appendLocked(ev)
+}
+
+func (h *WatchHub) OnUpdate(oldObj, newObj interface{}) {
+	sb, ok := newObj.(*apiv1alpha1.Sandbox)
+	if !ok {
+		return
+	}
+	if old, ok := oldObj.(*apiv1alpha1.Sandbox); ok && old.ResourceVersion == sb.ResourceVersion {
+		// 周期 resync，对象没有变化
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := client.ObjectKeyFromObject(sb)
+	old := h.objects[key]
+	h.objects[key] = sb
+	h.appendLocked(sandboxEvent{Type: fastpathv1.WatchEventType_MODIFIED, Object: sb, Old: old, RV: parseResourceVersion(sb.ResourceVersion)})
+}
+
+func (h *WatchHub) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	sb, ok := obj.(*apiv1alpha1.Sandbox)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.objects, client.ObjectKeyFromObject(sb))
+	h.appendLocked(sandboxEvent{Type: fastpathv1.WatchEventType_DELETED, Object: sb, RV: parseResourceVersion(sb.ResourceVersion)})
+}
+
+// appendLocked records the event and delivers it to all watchers. Must be called with h.mu held.
+func (h *WatchHub) appendLocked(ev sandboxEvent) {
+	// 墓碑对象的版本可能落后，保证 history 中的版本单调递增
+	ev.RV = max(ev.RV, h.latest)
+	h.latest = ev.RV
+
+	h.history = append(h.history, ev)
+	if len(h.history) > h.historySize {
+		h.compacted = h.history[0].RV
+		h.history = h.history[1:]
+	}
+
+	for id, ch := range h.watchers {
+		select {
+		case ch <- ev:
+		default:
+			klog.InfoS("Sandbox watcher fell behind, closing", "watcher", id)
+			delete(h.watchers, id)
+			close(ch)
+		}
+	}
+}
+
+// Watch registers a watcher. An empty resourceVersion replays the current objects as
+// ADDED events, otherwise the recorded changes after that version are replayed.
+func (h *WatchHub) Watch(resourceVersion string) (*hubWatch, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w := &hubWatch{ResourceVersion: h.latest}
+	if resourceVersion == "" {
+		for _, sb := range h.objects {
+			w.Initial = append(w.Initial, sandboxEvent{Type: fastpathv1.WatchEventType_ADDED, Object: sb, RV: parseResourceVersion(sb.ResourceVersion)})
+		}
+		sort.Slice(w.Initial, func(i, j int) bool {
+			a, b := w.Initial[i].Object, w.Initial[j].Object
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.Name < b.Name
+		})
+	} else {
+		rv, err := strconv.ParseUint(resourceVersion, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid resource_version %q", resourceVersion)
+		}
+		if rv < h.compacted {
+			return nil, status.Errorf(codes.OutOfRange, "resource_version %d is too old, oldest available is %d, restart the watch without resource_version", rv, h.compacted)
+		}
+		for _, ev := range h.history {
+			if ev.RV > rv {
+				w.Initial = append(w.Initial, ev)
+			}
+		}
+	}
+
+	id := h.nextID
+	h.nextID++
+	ch := make(chan sandboxEvent, watcherBuffer)
+	h.watchers[id] = ch
+	w.Events = ch
+	w.Stop = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if ch, ok := h.watchers[id]; ok {
+			delete(h.watchers, id)
+			close(ch)
+		}
+	}
+	return w, nil
+}
+
+// parseResourceVersion returns the numeric resourceVersion, 0 when it is not a number.
+func parseResourceVersion(rv string) uint64 {
+	v, _ := strconv.ParseUint(rv, 10, 64)
+	return v
+}
+
+// watchFilter selects the sandboxes a WatchSandboxes stream is interested in.
+type watchFilter struct {
+	namespace string
+	pool      string
+	name      string
+	selector  labels.Selector
+}
+
+func (f *watchFilter) matches(sb *apiv1alpha1.Sandbox) bool {
+	if sb == nil {
+		return false
+	}
+	return (f.namespace == "" || sb.Namespace == f.namespace) &&
+		(f.pool == "" || sb.Spec.PoolRef == f.pool) &&
+		(f.name == "" || sb.Name == f.name) &&
+		f.selector.Matches(labels.Set(sb.Labels))
+}
+
+// eventType maps an event to the type seen through the filter: an object moving into the
+// filter is ADDED, one moving out of it is DELETED. ok is false when the event is filtered out.
+func (f *watchFilter) eventType(ev sandboxEvent) (t fastpathv1.WatchEventType, ok bool) {
+	now := f.matches(ev.Object)
+	if ev.Type != fastpathv1.WatchEventType_MODIFIED {
+		return ev.Type, now
+	}
+	before := f.matches(ev.Old)
+	switch {
+	case now && before:
+		return fastpathv1.WatchEventType_MODIFIED, true
+	case now:
+		return fastpathv1.WatchEventType_ADDED, true
+	case before:
+		return fastpathv1.WatchEventType_DELETED, true
+	default:
+		return 0, false
+	}
+}
+
+// WatchSandboxes streams the changes of the sandboxes matching the request from the informer cache.
+func (s *Server) WatchSandboxes(req *fastpathv1.WatchRequest, stream fastpathv1.FastPathService_WatchSandboxesServer) error {
+	if s.Watches == nil {
+		return status.Error(codes.Unimplemented, "sandbox watch is not enabled on this controller")
+	}
+	if !s.Watches.HasSynced() {
+		return status.Error(codes.Unavailable, "sandbox cache is not synced yet, retry later")
+	}
+	selector, err := labels.Parse(req.LabelSelector)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid label_selector: %v", err)
+	}
+	filter := &watchFilter{namespace: req.Namespace, pool: req.PoolRef, name: req.SandboxName, selector: selector}
+
+	w, err := s.Watches.Watch(req.ResourceVersion)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+	klog.InfoS("FastPath WatchSandboxes started", "namespace", req.Namespace, "pool", req.PoolRef, "selector", req.LabelSelector, "name", req.SandboxName, "resourceVersion", req.ResourceVersion)
+
+	send := func(ev sandboxEvent) error {
+		t, ok := filter.eventType(ev)
+		if !ok {
+			return nil
+		}
+		return stream.Send(&fastpathv1.WatchEvent{
+			Type:            t,
+			Sandbox:         sandboxInfo(ev.Object),
+			ResourceVersion: strconv.FormatUint(ev.RV, 10),
+		})
+	}
+
+	for _, ev := range w.Initial {
+		if err := send(ev); err != nil {
+			return err
+		}
+	}
+	if req.ResourceVersion == "" {
+		// 标记初始列表结束，客户端可从该版本续传
+		if err := stream.Send(&fastpathv1.WatchEvent{
+			Type:            fastpathv1.WatchEventType_BOOKMARK,
+			ResourceVersion: strconv.FormatUint(w.ResourceVersion, 10),
+		}); err != nil {
+			return err
+		}
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return status.Error(codes.Aborted, "watcher fell behind, resume from the last resource_version")
+			}
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
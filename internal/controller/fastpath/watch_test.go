@@ -0,0 +1,229 @@
+package fastpath
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fastpathv1 "fast-sandbox/api/proto/v1"
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func watchSandbox(name, rv, pool string, lbls map[string]string) *apiv1alpha1.Sandbox {
+	return &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: rv, Labels: lbls},
+		Spec:       apiv1alpha1.SandboxSpec{PoolRef: pool},
+	}
+}
+
+// fakeWatchStream collects the events sent by WatchSandboxes.
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *fastpathv1.WatchEvent
+}
+
+func newFakeWatchStream(ctx context.Context) *fakeWatchStream {
+	return &fakeWatchStream{ctx: ctx, events: make(chan *fastpathv1.WatchEvent, 64)}
+}
+
+func (f *fakeWatchStream) Context() context.Context { return f.ctx }
+
+func (f *fakeWatchStream) Send(ev *fastpathv1.WatchEvent) error {
+	f.events <- ev
+	return nil
+}
+
+func (f *fakeWatchStream) next(t *testing.T) *fastpathv1.WatchEvent {
+	t.Helper()
+	select {
+	case ev := <-f.events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no watch event received")
+		return nil
+	}
+}
+
+// ============================================================================
+// 1. WatchHub
+// ============================================================================
+
+func TestWatchHub_InitialListAndChanges(t *testing.T) {
+	// W-01: A new watch replays current objects, then receives live changes
+	hub := NewWatchHub()
+	hub.OnAdd(watchSandbox("b", "10", "", nil), true)
+	hub.OnAdd(watchSandbox("a", "11", "", nil), true)
+
+	w, err := hub.Watch("")
+	require.NoError(t, err)
+	defer w.Stop()
+	require.Len(t, w.Initial, 2)
+	assert.Equal(t, "a", w.Initial[0].Object.Name, "Initial events are sorted by name")
+	assert.Equal(t, uint64(11), w.ResourceVersion)
+
+	hub.OnUpdate(watchSandbox("a", "11", "", nil), watchSandbox("a", "11", "", nil))
+	hub.OnUpdate(watchSandbox("a", "11", "", nil), watchSandbox("a", "12", "", nil))
+	hub.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "default/b", Obj: watchSandbox("b", "10", "", nil)})
+
+	ev := <-w.Events
+	assert.Equal(t, fastpathv1.WatchEventType_MODIFIED, ev.Type, "Resync without change is skipped")
+	assert.Equal(t, uint64(12), ev.RV)
+	ev = <-w.Events
+	assert.Equal(t, fastpathv1.WatchEventType_DELETED, ev.Type)
+	assert.Equal(t, uint64(12), ev.RV, "Stale tombstone versions do not go backwards")
+}
+
+func TestWatchHub_Resume(t *testing.T) {
+	// W-02: Resuming replays only the changes after the given version
+	hub := NewWatchHub()
+	hub.OnAdd(watchSandbox("a", "5", "", nil), true)
+	hub.OnAdd(watchSandbox("b", "6", "", nil), false)
+	hub.OnUpdate(watchSandbox("b", "6", "", nil), watchSandbox("b", "7", "", nil))
+
+	w, err := hub.Watch("6")
+	require.NoError(t, err)
+	defer w.Stop()
+	require.Len(t, w.Initial, 1)
+	assert.Equal(t, fastpathv1.WatchEventType_MODIFIED, w.Initial[0].Type)
+	assert.Equal(t, uint64(7), w.Initial[0].RV)
+
+	_, err = hub.Watch("4")
+	assert.Equal(t, codes.OutOfRange, status.Code(err), "Versions before the initial list cannot be resumed")
+	_, err = hub.Watch("abc")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWatchHub_HistoryCompaction(t *testing.T) {
+	// W-03: Versions evicted from the history are reported as too old
+	hub := NewWatchHub()
+	hub.historySize = 2
+	hub.OnAdd(watchSandbox("a", "1", "", nil), false)
+	hub.OnAdd(watchSandbox("b", "2", "", nil), false)
+	hub.OnAdd(watchSandbox("c", "3", "", nil), false)
+
+	_, err := hub.Watch("0")
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	w, err := hub.Watch("1")
+	require.NoError(t, err)
+	defer w.Stop()
+	assert.Len(t, w.Initial, 2)
+}
+
+func TestWatchHub_SlowWatcherClosed(t *testing.T) {
+	// W-04: A watcher whose buffer is full is closed instead of blocking the informer
+	hub := NewWatchHub()
+	w, err := hub.Watch("")
+	require.NoError(t, err)
+	defer w.Stop()
+
+	for i := 0; i <= watcherBuffer; i++ {
+		hub.OnAdd(watchSandbox("sb", "", "", nil), false)
+	}
+	received := 0
+	for range w.Events {
+		received++
+	}
+	assert.Equal(t, watcherBuffer, received)
+}
+
+func TestWatchFilter_EventType(t *testing.T) {
+	// W-05: Objects moving in or out of the filter become ADDED or DELETED
+	selector, err := labels.Parse("app=web")
+	require.NoError(t, err)
+	f := &watchFilter{pool: "pool-a", selector: selector}
+	web := watchSandbox("sb", "2", "pool-a", map[string]string{"app": "web"})
+	db := watchSandbox("sb", "1", "pool-a", map[string]string{"app": "db"})
+
+	modified := func(old, obj *apiv1alpha1.Sandbox) sandboxEvent {
+		return sandboxEvent{Type: fastpathv1.WatchEventType_MODIFIED, Object: obj, Old: old}
+	}
+	tests := []struct {
+		name   string
+		ev     sandboxEvent
+		want   fastpathv1.WatchEventType
+		wantOK bool
+	}{
+		{"added match", sandboxEvent{Type: fastpathv1.WatchEventType_ADDED, Object: web}, fastpathv1.WatchEventType_ADDED, true},
+		{"added other pool", sandboxEvent{Type: fastpathv1.WatchEventType_ADDED, Object: watchSandbox("x", "1", "pool-b", map[string]string{"app": "web"})}, 0, false},
+		{"modified match", modified(web, web), fastpathv1.WatchEventType_MODIFIED, true},
+		{"moved in", modified(db, web), fastpathv1.WatchEventType_ADDED, true},
+		{"moved out", modified(web, db), fastpathv1.WatchEventType_DELETED, true},
+		{"never matched", modified(db, db), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := f.eventType(tt.ev)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// 2. Server.WatchSandboxes
+// ============================================================================
+
+func TestServer_WatchSandboxes(t *testing.T) {
+	// W-06: Initial list, bookmark, then filtered live events
+	hub := NewWatchHub()
+	hub.OnAdd(watchSandbox("a", "3", "pool-a", nil), true)
+	hub.OnAdd(watchSandbox("b", "4", "pool-b", nil), true)
+	server := &Server{Watches: hub}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newFakeWatchStream(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- server.WatchSandboxes(&fastpathv1.WatchRequest{PoolRef: "pool-a"}, stream)
+	}()
+
+	ev := stream.next(t)
+	assert.Equal(t, fastpathv1.WatchEventType_ADDED, ev.Type)
+	assert.Equal(t, "a", ev.Sandbox.SandboxName)
+	assert.Equal(t, "default", ev.Sandbox.Namespace)
+	ev = stream.next(t)
+	assert.Equal(t, fastpathv1.WatchEventType_BOOKMARK, ev.Type)
+	assert.Equal(t, "4", ev.ResourceVersion)
+
+	// watcher 在发送初始列表之前注册，收到 BOOKMARK 后的变化不会丢失
+	hub.OnUpdate(watchSandbox("b", "4", "pool-b", nil), watchSandbox("b", "5", "pool-b", nil))
+	running := watchSandbox("a", "6", "pool-a", nil)
+	running.Status.Phase = "Running"
+	hub.OnUpdate(watchSandbox("a", "3", "pool-a", nil), running)
+
+	ev = stream.next(t)
+	assert.Equal(t, fastpathv1.WatchEventType_MODIFIED, ev.Type)
+	assert.Equal(t, "Running", ev.Sandbox.Phase)
+	assert.Equal(t, "6", ev.ResourceVersion)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestServer_WatchSandboxes_Errors(t *testing.T) {
+	// W-07: Disabled, unsynced and invalid requests are rejected with status codes
+	ctx := context.Background()
+	err := (&Server{}).WatchSandboxes(&fastpathv1.WatchRequest{}, newFakeWatchStream(ctx))
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	hub := NewWatchHub()
+	hub.SetSynced(func() bool { return false })
+	err = (&Server{Watches: hub}).WatchSandboxes(&fastpathv1.WatchRequest{}, newFakeWatchStream(ctx))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	hub.SetSynced(nil)
+	err = (&Server{Watches: hub}).WatchSandboxes(&fastpathv1.WatchRequest{LabelSelector: "a in (b"}, newFakeWatchStream(ctx))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
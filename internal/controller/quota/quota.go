@@ -0,0 +1,188 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/common"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pendingTTL 是已准入但尚未出现在缓存中的 sandbox 的保留时间，覆盖 Fast 模式异步写 CRD 的重试
+const pendingTTL = 2 * time.Minute
+
+// ExceededError is returned when admitting a sandbox would exceed a SandboxQuota.
+type ExceededError struct {
+	Namespace string
+	Quota     string
+	// Resource 是超出的限制：sandboxes、cpu、memory 或 pool/<name>
+	Resource string
+	Used     string
+	Limit    string
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("sandbox quota %s/%s exceeded for %s: used %s, limited to %s", e.Namespace, e.Quota, e.Resource, e.Used, e.Limit)
+}
+
+// Counts reports whether a sandbox counts against the quotas of its namespace: it was
+// placed on an agent and its runtime has not been cleaned up.
+func Counts(sb *apiv1alpha1.Sandbox) bool {
+	if sb.Status.AssignedPod == "" && sb.Annotations[common.AnnotationAllocation] == "" {
+		return false
+	}
+	switch apiv1alpha1.SandboxPhase(sb.Status.Phase) {
+	case apiv1alpha1.PhaseExpired, apiv1alpha1.PhasePreempted, apiv1alpha1.PhaseLost:
+		return false
+	}
+	return true
+}
+
+// usage accumulates consumption, CPU in millicores and memory in bytes.
+type usage struct {
+	sandboxes int32
+	cpu       int64
+	memory    int64
+	pools     map[string]int32
+}
+
+func (u *usage) add(sb *apiv1alpha1.Sandbox) {
+	cpu, memory := agentpool.SandboxRequests(sb)
+	u.sandboxes++
+	u.cpu += cpu
+	u.memory += memory
+	if u.pools == nil {
+		u.pools = make(map[string]int32)
+	}
+	u.pools[sb.Spec.PoolRef]++
+}
+
+func (u *usage) status() apiv1alpha1.QuotaUsage {
+	return apiv1alpha1.QuotaUsage{
+		Sandboxes: u.sandboxes,
+		CPU:       *resource.NewMilliQuantity(u.cpu, resource.DecimalSI),
+		Memory:    *resource.NewQuantity(u.memory, resource.BinarySI),
+		PerPool:   u.pools,
+	}
+}
+
+// Usage sums what the counted sandboxes consume.
+func Usage(sandboxes []apiv1alpha1.Sandbox) apiv1alpha1.QuotaUsage {
+	var u usage
+	for i := range sandboxes {
+		if Counts(&sandboxes[i]) {
+			u.add(&sandboxes[i])
+		}
+	}
+	return u.status()
+}
+
+// check returns an error for the first limit of q that u exceeds.
+func check(q *apiv1alpha1.SandboxQuota, u *usage, pool string) error {
+	exceeded := func(resource, used, limit string) error {
+		return &ExceededError{Namespace: q.Namespace, Quota: q.Name, Resource: resource, Used: used, Limit: limit}
+	}
+	spec := q.Spec
+	if spec.MaxSandboxes != nil && u.sandboxes > *spec.MaxSandboxes {
+		return exceeded("sandboxes", fmt.Sprint(u.sandboxes), fmt.Sprint(*spec.MaxSandboxes))
+	}
+	if spec.MaxCPU != nil && u.cpu > spec.MaxCPU.MilliValue() {
+		return exceeded("cpu", resource.NewMilliQuantity(u.cpu, resource.DecimalSI).String(), spec.MaxCPU.String())
+	}
+	if spec.MaxMemory != nil && u.memory > spec.MaxMemory.Value() {
+		return exceeded("memory", resource.NewQuantity(u.memory, resource.BinarySI).String(), spec.MaxMemory.String())
+	}
+	if limit, ok := spec.MaxPerPool[pool]; ok && u.pools[pool] > limit {
+		return exceeded("pool/"+pool, fmt.Sprint(u.pools[pool]), fmt.Sprint(limit))
+	}
+	return nil
+}
+
+type pendingSandbox struct {
+	sb       *apiv1alpha1.Sandbox
+	admitted time.Time
+}
+
+// Tracker admits sandboxes against the SandboxQuotas of their namespace. Sandboxes
+// admitted but not yet visible in the cache, such as fast mode ones whose CRD is
+// written asynchronously, are remembered for a while so that concurrent creates
+// cannot overshoot a quota.
+type Tracker struct {
+	reader client.Reader
+
+	mu      sync.Mutex
+	pending map[types.NamespacedName]pendingSandbox
+}
+
+// NewTracker creates a tracker that reads quotas and sandboxes from c.
+func NewTracker(c client.Reader) *Tracker {
+	return &Tracker{reader: c, pending: make(map[types.NamespacedName]pendingSandbox)}
+}
+
+// Admit checks that sb fits in every SandboxQuota of its namespace and reserves its
+// usage. The caller must call release if the sandbox is not created after all; once
+// created, the reservation is dropped when the sandbox shows up in the cache.
+func (t *Tracker) Admit(ctx context.Context, sb *apiv1alpha1.Sandbox) (release func(), err error) {
+	key := client.ObjectKeyFromObject(sb)
+	release = func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.pending, key)
+	}
+
+	var quotas apiv1alpha1.SandboxQuotaList
+	if err := t.reader.List(ctx, &quotas, client.InNamespace(sb.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			// 未安装 SandboxQuota CRD 时不做限制
+			return release, nil
+		}
+		return nil, err
+	}
+	if len(quotas.Items) == 0 {
+		return release, nil
+	}
+	var sandboxes apiv1alpha1.SandboxList
+	if err := t.reader.List(ctx, &sandboxes, client.InNamespace(sb.Namespace)); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var u usage
+	counted := make(map[types.NamespacedName]bool, len(sandboxes.Items))
+	for i := range sandboxes.Items {
+		item := &sandboxes.Items[i]
+		if Counts(item) && item.Name != sb.Name {
+			counted[client.ObjectKeyFromObject(item)] = true
+			u.add(item)
+		}
+	}
+	now := time.Now()
+	for k, p := range t.pending {
+		// 缓存中已按分配结果计数或超时的预留不再单独计数
+		if counted[k] || now.Sub(p.admitted) > pendingTTL {
+			delete(t.pending, k)
+			continue
+		}
+		if k.Namespace == sb.Namespace && k != key {
+			u.add(p.sb)
+		}
+	}
+	u.add(sb)
+
+	for i := range quotas.Items {
+		if err := check(&quotas.Items[i], &u, sb.Spec.PoolRef); err != nil {
+			return nil, err
+		}
+	}
+	t.pending[key] = pendingSandbox{sb: sb, admitted: now}
+	return release, nil
+}
@@ -0,0 +1,124 @@
+package quota
+
+import (
+	"context"
+	"testing"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSandbox(name, pool, cpu string) *apiv1alpha1.Sandbox {
+	sb := &apiv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team"},
+		Spec:       apiv1alpha1.SandboxSpec{Image: "alpine", PoolRef: pool},
+	}
+	if cpu != "" {
+		sb.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+	}
+	return sb
+}
+
+// placed returns a copy of sb as stored once it runs on an agent.
+func placed(sb *apiv1alpha1.Sandbox) *apiv1alpha1.Sandbox {
+	out := sb.DeepCopyObject().(*apiv1alpha1.Sandbox)
+	out.Status = apiv1alpha1.SandboxStatus{Phase: string(apiv1alpha1.PhaseRunning), AssignedPod: "agent-1"}
+	return out
+}
+
+func newTracker(t *testing.T, objs ...client.Object) *Tracker {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1alpha1.AddToScheme(scheme))
+	return NewTracker(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+}
+
+func newQuota(spec apiv1alpha1.SandboxQuotaSpec) *apiv1alpha1.SandboxQuota {
+	return &apiv1alpha1.SandboxQuota{ObjectMeta: metav1.ObjectMeta{Name: "q", Namespace: "team"}, Spec: spec}
+}
+
+func TestTracker_MaxSandboxes(t *testing.T) {
+	// QT-01: Placed sandboxes count, unplaced and cleaned up ones do not
+	expired := placed(newSandbox("expired", "p", ""))
+	expired.Status.Phase = string(apiv1alpha1.PhaseExpired)
+	tracker := newTracker(t,
+		newQuota(apiv1alpha1.SandboxQuotaSpec{MaxSandboxes: ptr.To[int32](2)}),
+		placed(newSandbox("running", "p", "")),
+		newSandbox("unscheduled", "p", ""),
+		expired,
+	)
+
+	_, err := tracker.Admit(context.Background(), newSandbox("sb-1", "p", ""))
+	require.NoError(t, err)
+	_, err = tracker.Admit(context.Background(), newSandbox("sb-2", "p", ""))
+	var exceeded *ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, "sandboxes", exceeded.Resource)
+	assert.Equal(t, "2", exceeded.Limit)
+}
+
+func TestTracker_PendingAndRelease(t *testing.T) {
+	// QT-02: Admitted sandboxes count before their CRD is cached, release gives the room back
+	tracker := newTracker(t, newQuota(apiv1alpha1.SandboxQuotaSpec{MaxSandboxes: ptr.To[int32](1)}))
+
+	release, err := tracker.Admit(context.Background(), newSandbox("sb-1", "p", ""))
+	require.NoError(t, err)
+	_, err = tracker.Admit(context.Background(), newSandbox("sb-2", "p", ""))
+	require.Error(t, err)
+
+	// 同一个 sandbox 重新准入（例如 reconcile 重试）不重复计数
+	_, err = tracker.Admit(context.Background(), newSandbox("sb-1", "p", ""))
+	require.NoError(t, err)
+
+	release()
+	_, err = tracker.Admit(context.Background(), newSandbox("sb-2", "p", ""))
+	require.NoError(t, err)
+}
+
+func TestTracker_ResourcesAndPools(t *testing.T) {
+	// QT-03: CPU requests and per-pool counts are limited independently
+	tracker := newTracker(t,
+		newQuota(apiv1alpha1.SandboxQuotaSpec{MaxCPU: ptr.To(resource.MustParse("1")), MaxPerPool: map[string]int32{"small": 1}}),
+		placed(newSandbox("running", "big", "500m")),
+	)
+
+	_, err := tracker.Admit(context.Background(), newSandbox("too-big", "big", "600m"))
+	var exceeded *ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, "cpu", exceeded.Resource)
+
+	_, err = tracker.Admit(context.Background(), newSandbox("sb-1", "small", "100m"))
+	require.NoError(t, err)
+	_, err = tracker.Admit(context.Background(), newSandbox("sb-2", "small", "100m"))
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, "pool/small", exceeded.Resource)
+}
+
+func TestTracker_NoQuota(t *testing.T) {
+	// QT-04: Namespaces without a SandboxQuota are not limited
+	tracker := newTracker(t)
+	for _, name := range []string{"sb-1", "sb-2", "sb-3"} {
+		_, err := tracker.Admit(context.Background(), newSandbox(name, "p", ""))
+		require.NoError(t, err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	// QT-05: Usage sums requests of placed sandboxes, fast mode ones count by their allocation annotation
+	fastMode := newSandbox("fast", "p", "250m")
+	fastMode.Annotations = map[string]string{common.AnnotationAllocation: common.BuildAllocationJSON("agent-1", "node-1")}
+	used := Usage([]apiv1alpha1.Sandbox{*placed(newSandbox("running", "p", "500m")), *fastMode, *newSandbox("unscheduled", "q", "1")})
+
+	assert.Equal(t, int32(2), used.Sandboxes)
+	assert.Equal(t, "750m", used.CPU.String())
+	assert.Equal(t, map[string]int32{"p": 2}, used.PerPool)
+}
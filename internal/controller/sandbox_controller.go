@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -10,16 +11,23 @@ import (
 	"fast-sandbox/internal/api"
 	"fast-sandbox/internal/controller/agentpool"
 	"fast-sandbox/internal/controller/common"
+	"fast-sandbox/internal/controller/quota"
 	"fast-sandbox/pkg/util/idgen"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Constants for controller configuration
@@ -38,6 +46,14 @@ const (
 
 	// ExpirationCheckThreshold is the threshold for scheduling expiration check
 	ExpirationCheckThreshold = 30 * time.Second
+
+	// MinIdleTimeout is the shortest idle timeout applied, the Agent reports activity
+	// with a resolution of about 30 seconds.
+	MinIdleTimeout = time.Minute
+
+	// ActivityStatusResolution is how far the Agent-reported activity must move on
+	// before Status.LastActivityTime is updated, to limit status writes.
+	ActivityStatusResolution = time.Minute
 )
 
 // SandboxReconciler reconciles a Sandbox object
@@ -46,6 +62,14 @@ type SandboxReconciler struct {
 	Scheme      *runtime.Scheme
 	Registry    agentpool.AgentRegistry
 	AgentClient api.AgentAPIClient
+	// StatusEvents 可选，Agent 控制循环在 sandbox 状态变化时投递事件
+	StatusEvents <-chan event.GenericEvent
+	// Recorder 可选，记录抢占等事件
+	Recorder record.EventRecorder
+	// Quotas 可选，调度前按 namespace 的 SandboxQuota 准入
+	Quotas *quota.Tracker
+	// Reader 直接读 API Server，读取 checkpoint 的镜像仓库 Secret 时避免为 Secret 建立 informer 缓存
+	Reader client.Reader
 }
 
 // Reconcile is the main entry point for the Sandbox controller.
@@ -77,7 +101,12 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return result, err
 	}
 
-	// Step 5: Main State Machine - reconcile based on current phase
+	// Step 5: Handle finished sandboxes (completion time and TTL after finished)
+	if result, done, err := r.handleFinished(ctx, &sandbox); done {
+		return result, err
+	}
+
+	// Step 6: Main State Machine - reconcile based on current phase
 	return r.reconcilePhase(ctx, &sandbox)
 }
 
@@ -106,11 +135,16 @@ func (r *SandboxReconciler) ensureFinalizer(ctx context.Context, sandbox *apiv1a
 }
 
 // getSandboxID returns the sandboxID to use when calling Agent API.
+func (r *SandboxReconciler) getSandboxID(sandbox *apiv1alpha1.Sandbox) string {
+	return agentSandboxID(sandbox)
+}
+
+// agentSandboxID returns the ID of the sandbox on its Agent.
 // Logic:
 // 1. If status.sandboxID is set, use it (Strong mode or already synced)
 // 2. If label "fastpath-fast" exists, regenerate from annotation timestamp
 // 3. Otherwise, fallback to UID (legacy/test sandboxes)
-func (r *SandboxReconciler) getSandboxID(sandbox *apiv1alpha1.Sandbox) string {
+func agentSandboxID(sandbox *apiv1alpha1.Sandbox) string {
 	// 1. Status already has sandboxID (Strong mode or already synced)
 	if sandbox.Status.SandboxID != "" {
 		return sandbox.Status.SandboxID
@@ -151,8 +185,9 @@ func (r *SandboxReconciler) handleDeletion(ctx context.Context, sandbox *apiv1al
 		logger.V(1).Info("Removing finalizer for expired sandbox")
 		return r.removeFinalizer(ctx, sandbox)
 
-	case apiv1alpha1.PhaseBound, apiv1alpha1.PhaseRunning:
-		// Active sandbox - need to cleanup Agent resources
+	case apiv1alpha1.PhaseBound, apiv1alpha1.PhaseRunning, apiv1alpha1.PhasePaused, apiv1alpha1.PhaseSucceeded, apiv1alpha1.PhaseFailed, apiv1alpha1.PhasePreempted:
+		// Active, exited or not yet evicted sandbox - the container still holds Agent resources
+		// (handleActiveDeletion removes the finalizer directly when nothing was assigned)
 		return r.handleActiveDeletion(ctx, sandbox)
 
 	case apiv1alpha1.PhaseTerminating:
@@ -160,7 +195,7 @@ func (r *SandboxReconciler) handleDeletion(ctx context.Context, sandbox *apiv1al
 		return r.handleTerminatingDeletion(ctx, sandbox)
 
 	default:
-		// Pending, Failed, or unknown phase - no Agent resources to cleanup
+		// Pending or unknown phase - no Agent resources to cleanup
 		logger.V(1).Info("Removing finalizer for sandbox without Agent resources", "phase", phase)
 		return r.removeFinalizer(ctx, sandbox)
 	}
@@ -287,9 +322,9 @@ func (r *SandboxReconciler) handleExpiration(ctx context.Context, sandbox *apiv1
 		return ctrl.Result{}, nil, false
 	}
 
-	// Already expired - nothing to do
+	// Already expired - kept for history, left to the TTL after finished
 	if apiv1alpha1.SandboxPhase(sandbox.Status.Phase) == apiv1alpha1.PhaseExpired {
-		return ctrl.Result{}, nil, true
+		return ctrl.Result{}, nil, false
 	}
 
 	now := time.Now()
@@ -331,6 +366,7 @@ func (r *SandboxReconciler) processExpiration(ctx context.Context, sandbox *apiv
 		latest.Status.Phase = string(apiv1alpha1.PhaseExpired)
 		latest.Status.AssignedPod = ""
 		latest.Status.SandboxID = ""
+		markNotReady(&latest.Status, string(apiv1alpha1.PhaseExpired))
 		return r.Status().Update(ctx, latest)
 	})
 
@@ -378,6 +414,8 @@ func (r *SandboxReconciler) handleReset(ctx context.Context, sandbox *apiv1alpha
 		latest.Status.AssignedPod = ""
 		latest.Status.SandboxID = ""
 		latest.Status.Phase = string(apiv1alpha1.PhasePending)
+		latest.Status.CompletionTime = nil
+		markNotReady(&latest.Status, "Reset")
 		latest.Status.AcceptedResetRevision = sandbox.Spec.ResetRevision
 		return r.Status().Update(ctx, latest)
 	})
@@ -386,6 +424,68 @@ func (r *SandboxReconciler) handleReset(ctx context.Context, sandbox *apiv1alpha
 	return ctrl.Result{Requeue: true}, err, true
 }
 
+// ============================================================================
+// Finished Sandboxes
+// ============================================================================
+
+// IsFinishedPhase reports whether a sandbox in this phase will not run again unless reset.
+func IsFinishedPhase(phase apiv1alpha1.SandboxPhase) bool {
+	switch phase {
+	case apiv1alpha1.PhaseSucceeded, apiv1alpha1.PhaseFailed, apiv1alpha1.PhaseExpired, apiv1alpha1.PhasePreempted:
+		return true
+	}
+	return false
+}
+
+// handleFinished records when a sandbox reached a final phase and deletes it once its
+// TTLSecondsAfterFinished has elapsed.
+// Returns (result, done, error) where done=true means nothing else is left to reconcile.
+func (r *SandboxReconciler) handleFinished(ctx context.Context, sandbox *apiv1alpha1.Sandbox) (ctrl.Result, bool, error) {
+	if !IsFinishedPhase(apiv1alpha1.SandboxPhase(sandbox.Status.Phase)) {
+		return ctrl.Result{}, false, nil
+	}
+	// 被抢占的 sandbox 先完成 Agent 侧清理
+	if apiv1alpha1.SandboxPhase(sandbox.Status.Phase) == apiv1alpha1.PhasePreempted && sandbox.Status.AssignedPod != "" {
+		return ctrl.Result{}, false, nil
+	}
+
+	if sandbox.Status.CompletionTime == nil {
+		// 主进程退出的 sandbox 以退出时间为准，其余以进入终态后首次 reconcile 的时间为准
+		completion := metav1.Now()
+		phase := apiv1alpha1.SandboxPhase(sandbox.Status.Phase)
+		if (phase == apiv1alpha1.PhaseSucceeded || phase == apiv1alpha1.PhaseFailed) && sandbox.Status.FinishedAt != nil {
+			completion = *sandbox.Status.FinishedAt
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			latest := &apiv1alpha1.Sandbox{}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(sandbox), latest); err != nil {
+				return err
+			}
+			latest.Status.CompletionTime = &completion
+			return r.Status().Update(ctx, latest)
+		})
+		if err != nil {
+			return ctrl.Result{}, true, err
+		}
+		sandbox.Status.CompletionTime = &completion
+	}
+
+	if sandbox.Spec.TTLSecondsAfterFinished == nil {
+		return ctrl.Result{}, false, nil
+	}
+	ttl := time.Duration(*sandbox.Spec.TTLSecondsAfterFinished) * time.Second
+	if remaining := time.Until(sandbox.Status.CompletionTime.Add(ttl)); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, true, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	logger.Info("Deleting finished sandbox after its TTL", "phase", sandbox.Status.Phase, "ttl", ttl)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(sandbox, corev1.EventTypeNormal, "TTLExpired", "Sandbox finished %s ago, deleting it", time.Since(sandbox.Status.CompletionTime.Time).Round(time.Second))
+	}
+	return ctrl.Result{}, true, client.IgnoreNotFound(r.Delete(ctx, sandbox))
+}
+
 // ============================================================================
 // Main State Machine
 // ============================================================================
@@ -398,11 +498,19 @@ func (r *SandboxReconciler) reconcilePhase(ctx context.Context, sandbox *apiv1al
 	case "", apiv1alpha1.PhasePending:
 		return r.reconcilePending(ctx, sandbox)
 
-	case apiv1alpha1.PhaseBound, apiv1alpha1.PhaseRunning:
+	case apiv1alpha1.PhaseBound, apiv1alpha1.PhaseRunning, apiv1alpha1.PhasePaused:
 		return r.reconcileRunning(ctx, sandbox)
 
 	case apiv1alpha1.PhaseExpired:
-		// Expired sandboxes are kept for history, no action needed
+		// Expired sandboxes are kept for history until their TTL or the retention policy
+		return ctrl.Result{}, nil
+
+	case apiv1alpha1.PhasePreempted:
+		// Evicted for a higher-priority sandbox, kept for history once cleaned up
+		return r.reconcilePreempted(ctx, sandbox)
+
+	case apiv1alpha1.PhaseSucceeded:
+		// Main process completed, kept until deleted, its TTL or the retention policy
 		return ctrl.Result{}, nil
 
 	case apiv1alpha1.PhaseFailed:
@@ -448,6 +556,16 @@ func (r *SandboxReconciler) reconcilePending(ctx context.Context, sandbox *apiv1
 	// === 原有逻辑保持不变 ===
 	// Step 1: Scheduling (if not yet assigned)
 	if sandbox.Status.AssignedPod == "" {
+		if _, err := common.ReadinessProbe(sandbox.Spec.ReadinessProbe); err != nil {
+			logger.Error(err, "Invalid readiness probe")
+			if r.Recorder != nil {
+				r.Recorder.Event(sandbox, corev1.EventTypeWarning, "InvalidReadinessProbe", err.Error())
+			}
+			return ctrl.Result{}, r.updatePhase(ctx, sandbox, apiv1alpha1.PhaseFailed)
+		}
+		if sandbox.Spec.RestoreFrom != "" && sandbox.Annotations[common.AnnotationRestoreImage] == "" {
+			return r.resolveRestore(ctx, sandbox)
+		}
 		return r.handleScheduling(ctx, sandbox)
 	}
 
@@ -479,8 +597,8 @@ func (r *SandboxReconciler) reconcilePending(ctx context.Context, sandbox *apiv1
 	return ctrl.Result{RequeueAfter: 0}, nil
 }
 
-// reconcileRunning handles sandboxes in Bound/Running phase.
-// Workflow: Sync status from Agent, handle Agent loss
+// reconcileRunning handles sandboxes in Bound/Running/Paused phase.
+// Workflow: Sync status from Agent, pause or resume as requested, handle Agent loss
 func (r *SandboxReconciler) reconcileRunning(ctx context.Context, sandbox *apiv1alpha1.Sandbox) (ctrl.Result, error) {
 	logger := klog.FromContext(ctx)
 
@@ -502,9 +620,99 @@ func (r *SandboxReconciler) reconcileRunning(ctx context.Context, sandbox *apiv1
 		return ctrl.Result{}, err
 	}
 
+	if result, done, err := r.reconcileIdle(ctx, sandbox, &agent); done {
+		return result, err
+	}
+
+	if err := r.reconcilePause(ctx, sandbox, &agent); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
 }
 
+// reconcilePause freezes or thaws the sandbox on its Agent until the Agent-reported phase
+// matches Spec.Paused. The phase change comes back through the Agent status.
+func (r *SandboxReconciler) reconcilePause(ctx context.Context, sandbox *apiv1alpha1.Sandbox, agent *agentpool.AgentInfo) error {
+	status, hasStatus := agent.SandboxStatuses[r.getSandboxID(sandbox)]
+	if !hasStatus {
+		return nil
+	}
+
+	logger := klog.FromContext(ctx)
+	switch phase := apiv1alpha1.AgentSandboxPhase(status.Phase); {
+	case sandbox.Spec.Paused && phase == apiv1alpha1.AgentPhaseRunning:
+		logger.Info("Pausing sandbox", "agent", agent.PodName)
+		if err := r.AgentClient.PauseSandbox(ctx, agent.PodIP, status.SandboxID); err != nil {
+			return fmt.Errorf("failed to pause sandbox on agent %s: %w", agent.PodIP, err)
+		}
+	case !sandbox.Spec.Paused && phase == apiv1alpha1.AgentPhasePaused:
+		logger.Info("Resuming sandbox", "agent", agent.PodName)
+		if err := r.AgentClient.ResumeSandbox(ctx, agent.PodIP, status.SandboxID); err != nil {
+			return fmt.Errorf("failed to resume sandbox on agent %s: %w", agent.PodIP, err)
+		}
+	}
+	return nil
+}
+
+// reconcileIdle applies the IdlePolicy once the Agent-reported last activity is older than
+// IdleTimeoutSeconds. The Pause policy only sets Spec.Paused, reconcilePause then freezes
+// the sandbox. Returns (result, done, error) where done=true means the sandbox was expired
+// or deleted.
+func (r *SandboxReconciler) reconcileIdle(ctx context.Context, sandbox *apiv1alpha1.Sandbox, agent *agentpool.AgentInfo) (ctrl.Result, bool, error) {
+	if sandbox.Spec.IdleTimeoutSeconds <= 0 {
+		return ctrl.Result{}, false, nil
+	}
+	status, hasStatus := agent.SandboxStatuses[r.getSandboxID(sandbox)]
+	if !hasStatus || status.LastActivityAt == 0 {
+		return ctrl.Result{}, false, nil
+	}
+	phase := apiv1alpha1.AgentSandboxPhase(status.Phase)
+	if phase != apiv1alpha1.AgentPhaseRunning && phase != apiv1alpha1.AgentPhasePaused {
+		return ctrl.Result{}, false, nil
+	}
+	timeout := max(time.Duration(sandbox.Spec.IdleTimeoutSeconds)*time.Second, MinIdleTimeout)
+	idle := time.Since(time.Unix(status.LastActivityAt, 0))
+	if idle < timeout {
+		return ctrl.Result{}, false, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	policy := sandbox.Spec.IdlePolicy
+	if policy == "" {
+		policy = apiv1alpha1.IdlePolicyPause
+	}
+	// 已暂停的 sandbox 没有活动，Pause 策略到此为止；Expire 与 Delete 策略同样适用于暂停的 sandbox
+	if policy == apiv1alpha1.IdlePolicyPause && (sandbox.Spec.Paused || phase != apiv1alpha1.AgentPhaseRunning) {
+		return ctrl.Result{}, false, nil
+	}
+	logger.Info("Sandbox idle, applying idle policy", "idle", idle.Round(time.Second), "policy", policy)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(sandbox, corev1.EventTypeNormal, "Idle", "Sandbox idle for %s, applying idle policy %s", idle.Round(time.Second), policy)
+	}
+
+	switch policy {
+	case apiv1alpha1.IdlePolicyExpire:
+		result, err, _ := r.processExpiration(ctx, sandbox)
+		return result, true, err
+	case apiv1alpha1.IdlePolicyDelete:
+		return ctrl.Result{}, true, client.IgnoreNotFound(r.Delete(ctx, sandbox))
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &apiv1alpha1.Sandbox{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(sandbox), latest); err != nil {
+			return err
+		}
+		latest.Spec.Paused = true
+		return r.Update(ctx, latest)
+	})
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	sandbox.Spec.Paused = true
+	return ctrl.Result{}, false, nil
+}
+
 // reconcileLost handles sandboxes in Lost phase.
 // Workflow: Wait for new Agent to become available, then transition to Pending for rescheduling.
 func (r *SandboxReconciler) reconcileLost(ctx context.Context, sandbox *apiv1alpha1.Sandbox) (ctrl.Result, error) {
@@ -544,6 +752,34 @@ func (r *SandboxReconciler) reconcileLost(ctx context.Context, sandbox *apiv1alp
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// reconcilePreempted removes a preempted sandbox from its Agent and releases its slot,
+// the freed capacity goes to the higher-priority sandbox waiting for it.
+func (r *SandboxReconciler) reconcilePreempted(ctx context.Context, sandbox *apiv1alpha1.Sandbox) (ctrl.Result, error) {
+	if sandbox.Status.AssignedPod == "" {
+		return ctrl.Result{}, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	logger.Info("Evicting preempted sandbox", "agent", sandbox.Status.AssignedPod)
+	if err := r.deleteFromAgent(ctx, sandbox); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to delete preempted sandbox from agent: %w", err)
+	}
+	r.Registry.Release(agentpool.AgentID(sandbox.Status.AssignedPod), sandbox)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &apiv1alpha1.Sandbox{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(sandbox), latest); err != nil {
+			return err
+		}
+		latest.Status.AssignedPod = ""
+		latest.Status.SandboxID = ""
+		latest.Status.Endpoints = nil
+		markNotReady(&latest.Status, string(apiv1alpha1.PhasePreempted))
+		return r.Status().Update(ctx, latest)
+	})
+	return ctrl.Result{}, err
+}
+
 // ============================================================================
 // Scheduling
 // ============================================================================
@@ -552,9 +788,29 @@ func (r *SandboxReconciler) reconcileLost(ctx context.Context, sandbox *apiv1alp
 func (r *SandboxReconciler) handleScheduling(ctx context.Context, sandbox *apiv1alpha1.Sandbox) (ctrl.Result, error) {
 	logger := klog.FromContext(ctx)
 
+	releaseQuota := func() {}
+	if r.Quotas != nil {
+		release, err := r.Quotas.Admit(ctx, sandbox)
+		if err != nil {
+			logger.Info("Sandbox not admitted by quota, waiting", "error", err)
+			if r.Recorder != nil {
+				r.Recorder.Event(sandbox, corev1.EventTypeWarning, "QuotaExceeded", err.Error())
+			}
+			return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+		}
+		releaseQuota = release
+	}
+
 	agent, err := r.Registry.Allocate(sandbox)
 	if err != nil {
+		releaseQuota()
 		logger.V(1).Info("No available agent for scheduling", "error", err)
+		if agentpool.IsUnschedulable(err) {
+			// 抢占低优先级 sandbox，释放后在下一次 reconcile 中调度
+			if _, err := agentpool.Preempt(ctx, r.Client, r.Registry, r.Recorder, sandbox); err != nil {
+				logger.Error(err, "Preemption failed")
+			}
+		}
 		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
 	}
 
@@ -577,6 +833,7 @@ func (r *SandboxReconciler) handleScheduling(ctx context.Context, sandbox *apiv1
 	if err != nil {
 		// Scheduling failed - release the allocation
 		r.Registry.Release(agent.ID, sandbox)
+		releaseQuota()
 		return ctrl.Result{Requeue: true}, nil
 	}
 
@@ -584,6 +841,47 @@ func (r *SandboxReconciler) handleScheduling(ctx context.Context, sandbox *apiv1
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// resolveRestore records the checkpoint image and node of Spec.RestoreFrom in the sandbox
+// annotations before it is scheduled. It waits while the checkpoint is being taken or does
+// not exist yet, and fails the sandbox when the checkpoint cannot be restored.
+func (r *SandboxReconciler) resolveRestore(ctx context.Context, sandbox *apiv1alpha1.Sandbox) (ctrl.Result, error) {
+	logger := klog.FromContext(ctx)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &apiv1alpha1.Sandbox{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(sandbox), latest); err != nil {
+			return err
+		}
+		if err := common.ResolveCheckpoint(ctx, r.Client, latest); err != nil {
+			return err
+		}
+		return r.Update(ctx, latest)
+	})
+	switch {
+	case err == nil:
+		logger.Info("Resolved checkpoint to restore from", "checkpoint", sandbox.Spec.RestoreFrom)
+		return ctrl.Result{Requeue: true}, nil
+	case errors.Is(err, common.ErrCheckpointNotReady):
+		logger.V(1).Info("Waiting for checkpoint to become ready", "checkpoint", sandbox.Spec.RestoreFrom)
+		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+	case apierrors.IsNotFound(err):
+		// 可能与 sandbox 同时创建，等待其出现
+		logger.Info("Checkpoint to restore from not found, waiting", "checkpoint", sandbox.Spec.RestoreFrom)
+		if r.Recorder != nil {
+			r.Recorder.Event(sandbox, corev1.EventTypeWarning, "CheckpointNotFound", err.Error())
+		}
+		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+	case apierrors.IsConflict(err):
+		return ctrl.Result{}, err
+	}
+
+	logger.Error(err, "Cannot restore sandbox from checkpoint", "checkpoint", sandbox.Spec.RestoreFrom)
+	if r.Recorder != nil {
+		r.Recorder.Event(sandbox, corev1.EventTypeWarning, "RestoreFailed", err.Error())
+	}
+	return ctrl.Result{}, r.updatePhase(ctx, sandbox, apiv1alpha1.PhaseFailed)
+}
+
 // ============================================================================
 // Agent Interaction
 // ============================================================================
@@ -607,6 +905,7 @@ func (r *SandboxReconciler) handleAgentLost(ctx context.Context, sandbox *apiv1a
 			latest.Status.AssignedPod = ""
 			latest.Status.SandboxID = ""
 			latest.Status.Phase = string(apiv1alpha1.PhasePending)
+			markNotReady(&latest.Status, "AgentLost")
 			return r.Status().Update(ctx, latest)
 		})
 
@@ -627,6 +926,7 @@ func (r *SandboxReconciler) handleAgentLost(ctx context.Context, sandbox *apiv1a
 		latest.Status.Phase = string(apiv1alpha1.PhaseLost)
 		latest.Status.AssignedPod = ""
 		latest.Status.SandboxID = ""
+		markNotReady(&latest.Status, "AgentLost")
 		return r.Status().Update(ctx, latest)
 	})
 
@@ -641,17 +941,31 @@ func (r *SandboxReconciler) handleCreateOnAgent(ctx context.Context, sandbox *ap
 		return fmt.Errorf("agent %s not found in registry", sandbox.Status.AssignedPod)
 	}
 
-	_, err := r.AgentClient.CreateSandbox(agent.PodIP, &api.CreateSandboxRequest{
-		Sandbox: api.SandboxSpec{
-			SandboxID:  r.getSandboxID(sandbox),
-			ClaimName:  sandbox.Name,
-			Image:      sandbox.Spec.Image,
-			Command:    sandbox.Spec.Command,
-			Args:       sandbox.Spec.Args,
-			Env:        envVarToMap(sandbox.Spec.Envs),
-			WorkingDir: sandbox.Spec.WorkingDir,
-		},
-	})
+	spec := api.SandboxSpec{
+		SandboxID:     r.getSandboxID(sandbox),
+		ClaimName:     sandbox.Name,
+		Image:         sandbox.Spec.Image,
+		Command:       sandbox.Spec.Command,
+		Args:          sandbox.Spec.Args,
+		Env:           envVarToMap(sandbox.Spec.Envs),
+		WorkingDir:    sandbox.Spec.WorkingDir,
+		TTY:           sandbox.Spec.TTY,
+		Stdin:         sandbox.Spec.Stdin,
+		RestartPolicy: api.RestartPolicy(sandbox.Spec.RestartPolicy),
+		Checkpoint:    sandbox.Annotations[common.AnnotationRestoreImage],
+		ExposedPorts:  sandbox.Spec.ExposedPorts,
+	}
+	common.ApplyResources(&spec, sandbox.Spec.Resources)
+	probe, err := common.ReadinessProbe(sandbox.Spec.ReadinessProbe)
+	if err != nil {
+		return err
+	}
+	spec.ReadinessProbe = probe
+	if spec.CheckpointCredentials, err = common.RestoreCredentials(ctx, r.Client, r.Reader, sandbox); err != nil {
+		return err
+	}
+
+	_, err = r.AgentClient.CreateSandbox(agent.PodIP, &api.CreateSandboxRequest{Sandbox: spec})
 	if err != nil {
 		return fmt.Errorf("failed to create sandbox on agent %s: %w", agent.PodIP, err)
 	}
@@ -701,7 +1015,10 @@ func (r *SandboxReconciler) syncStatusFromAgent(ctx context.Context, sandbox *ap
 	controllerPhase := mapAgentPhaseToController(status.Phase)
 
 	// Check if update is needed
-	if sandbox.Status.Phase == string(controllerPhase) && sandbox.Status.SandboxID == status.SandboxID {
+	ready := readyCondition(sandbox, &status)
+	if sandbox.Status.Phase == string(controllerPhase) && sandbox.Status.SandboxID == status.SandboxID &&
+		!exitStatusChanged(&sandbox.Status, &status) && !activityChanged(&sandbox.Status, &status) &&
+		!conditionChanged(sandbox.Status.Conditions, ready) {
 		return nil
 	}
 
@@ -713,6 +1030,12 @@ func (r *SandboxReconciler) syncStatusFromAgent(ctx context.Context, sandbox *ap
 
 		latest.Status.Phase = string(controllerPhase)
 		latest.Status.SandboxID = status.SandboxID
+		applyExitStatus(&latest.Status, &status)
+		if activityChanged(&latest.Status, &status) {
+			lastActivity := metav1.Unix(status.LastActivityAt, 0)
+			latest.Status.LastActivityTime = &lastActivity
+		}
+		meta.SetStatusCondition(&latest.Status.Conditions, ready)
 
 		// Update endpoints if ports are exposed
 		if len(latest.Spec.ExposedPorts) > 0 && agent.PodIP != "" {
@@ -727,6 +1050,76 @@ func (r *SandboxReconciler) syncStatusFromAgent(ctx context.Context, sandbox *ap
 	})
 }
 
+// readyCondition builds the Ready condition from the Agent-reported readiness.
+func readyCondition(sandbox *apiv1alpha1.Sandbox, agent *api.SandboxStatus) metav1.Condition {
+	cond := metav1.Condition{Type: apiv1alpha1.SandboxConditionReady, Status: metav1.ConditionFalse}
+	switch {
+	case agent.Ready && sandbox.Spec.ReadinessProbe != nil:
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionTrue, "ReadinessProbePassed", "Readiness probe passed"
+	case agent.Ready:
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionTrue, "Running", "Sandbox is running"
+	case agent.Phase == string(apiv1alpha1.AgentPhaseRunning):
+		cond.Reason, cond.Message = "ReadinessProbeFailed", "Readiness probe has not passed"
+	default:
+		cond.Reason, cond.Message = "NotRunning", fmt.Sprintf("Sandbox is %s on the Agent", agent.Phase)
+	}
+	return cond
+}
+
+// conditionChanged reports whether setting cond would change the status or reason of its type.
+func conditionChanged(conditions []metav1.Condition, cond metav1.Condition) bool {
+	current := meta.FindStatusCondition(conditions, cond.Type)
+	return current == nil || current.Status != cond.Status || current.Reason != cond.Reason
+}
+
+// markNotReady turns an existing Ready condition False once the sandbox left its Agent.
+func markNotReady(status *apiv1alpha1.SandboxStatus, reason string) {
+	if meta.FindStatusCondition(status.Conditions, apiv1alpha1.SandboxConditionReady) == nil {
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    apiv1alpha1.SandboxConditionReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: "Sandbox is not running",
+	})
+}
+
+// exitStatusChanged reports whether the Agent reported a new exit or restart.
+func exitStatusChanged(current *apiv1alpha1.SandboxStatus, agent *api.SandboxStatus) bool {
+	if current.RestartCount != agent.RestartCount || current.Reason != agent.Reason {
+		return true
+	}
+	if agent.ExitedAt == 0 {
+		return false
+	}
+	return current.FinishedAt == nil || current.FinishedAt.Unix() != agent.ExitedAt ||
+		current.ExitCode == nil || *current.ExitCode != agent.ExitCode
+}
+
+// activityChanged reports whether the Agent-reported activity moved on by at least
+// ActivityStatusResolution from Status.LastActivityTime.
+func activityChanged(current *apiv1alpha1.SandboxStatus, agent *api.SandboxStatus) bool {
+	if agent.LastActivityAt == 0 {
+		return false
+	}
+	return current.LastActivityTime == nil ||
+		time.Unix(agent.LastActivityAt, 0).Sub(current.LastActivityTime.Time) >= ActivityStatusResolution
+}
+
+// applyExitStatus copies exit code, finish time, reason and restart count reported by the Agent.
+func applyExitStatus(current *apiv1alpha1.SandboxStatus, agent *api.SandboxStatus) {
+	current.RestartCount = agent.RestartCount
+	current.Reason = agent.Reason
+	if agent.ExitedAt == 0 {
+		return
+	}
+	exitCode := agent.ExitCode
+	finishedAt := metav1.Unix(agent.ExitedAt, 0)
+	current.ExitCode = &exitCode
+	current.FinishedAt = &finishedAt
+}
+
 // mapAgentPhaseToController maps Agent-reported phase to Controller standard phase.
 // Agent uses lowercase (running, terminated), Controller uses TitleCase (Running, Terminated).
 func mapAgentPhaseToController(agentPhase string) apiv1alpha1.SandboxPhase {
@@ -735,6 +1128,12 @@ func mapAgentPhaseToController(agentPhase string) apiv1alpha1.SandboxPhase {
 		return apiv1alpha1.PhaseRunning
 	case apiv1alpha1.AgentPhaseCreating:
 		return apiv1alpha1.PhaseBound // Still creating, keep as Bound
+	case apiv1alpha1.AgentPhaseRestarting:
+		return apiv1alpha1.PhaseRunning // Waiting for restart backoff, as Pod phase in CrashLoopBackOff
+	case apiv1alpha1.AgentPhasePaused:
+		return apiv1alpha1.PhasePaused
+	case apiv1alpha1.AgentPhaseSucceeded:
+		return apiv1alpha1.PhaseSucceeded
 	case apiv1alpha1.AgentPhaseFailed:
 		return apiv1alpha1.PhaseFailed
 	case apiv1alpha1.AgentPhaseStopped:
@@ -820,13 +1219,17 @@ func (r *SandboxReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1alpha1.Sandbox{}).
 		Watches(
 			&corev1.Pod{},
 			handler.EnqueueRequestsFromMapFunc(r.mapPodToSandboxes),
-		).
-		Complete(r)
+		)
+	if r.StatusEvents != nil {
+		// Agent 推送的状态变化，立即 reconcile 对应 Sandbox，而不是等待周期 requeue
+		b = b.WatchesRawSource(source.Channel(r.StatusEvents, &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(r)
 }
 
 // mapPodToSandboxes returns reconcile requests for unassigned sandboxes when an agent pod becomes ready.
@@ -9,12 +9,18 @@ import (
 	apiv1alpha1 "fast-sandbox/api/v1alpha1"
 	"fast-sandbox/internal/api"
 	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/common"
+	"fast-sandbox/internal/controller/quota"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -28,6 +34,11 @@ import (
 type MockAgentClient struct {
 	CreateSandboxFunc func(agentIP string, req *api.CreateSandboxRequest) (*api.CreateSandboxResponse, error)
 	DeleteSandboxFunc func(agentIP string, req *api.DeleteSandboxRequest) (*api.DeleteSandboxResponse, error)
+	// PauseCalls 记录暂停（true）与恢复（false）调用
+	PauseCalls []bool
+	// CheckpointFunc 可选，默认成功；DeletedCheckpoints 记录删除的 checkpoint 镜像
+	CheckpointFunc     func(agentIP string, req *api.CheckpointRequest) error
+	DeletedCheckpoints []string
 }
 
 func (m *MockAgentClient) CreateSandbox(agentIP string, req *api.CreateSandboxRequest) (*api.CreateSandboxResponse, error) {
@@ -44,6 +55,28 @@ func (m *MockAgentClient) DeleteSandbox(agentIP string, req *api.DeleteSandboxRe
 	return &api.DeleteSandboxResponse{Success: true}, nil
 }
 
+func (m *MockAgentClient) PauseSandbox(ctx context.Context, agentIP, sandboxID string) error {
+	m.PauseCalls = append(m.PauseCalls, true)
+	return nil
+}
+
+func (m *MockAgentClient) ResumeSandbox(ctx context.Context, agentIP, sandboxID string) error {
+	m.PauseCalls = append(m.PauseCalls, false)
+	return nil
+}
+
+func (m *MockAgentClient) CheckpointSandbox(ctx context.Context, agentIP string, req *api.CheckpointRequest) error {
+	if m.CheckpointFunc != nil {
+		return m.CheckpointFunc(agentIP, req)
+	}
+	return nil
+}
+
+func (m *MockAgentClient) DeleteCheckpoint(ctx context.Context, agentIP, ref string) error {
+	m.DeletedCheckpoints = append(m.DeletedCheckpoints, ref)
+	return nil
+}
+
 func (m *MockAgentClient) GetAgentStatus(ctx context.Context, agentIP string) (*api.AgentStatus, error) {
 	return nil, nil
 }
@@ -127,6 +160,19 @@ func (m *ConfigurableMockRegistry) Allocate(sb *apiv1alpha1.Sandbox) (*agentpool
 	}, nil
 }
 
+func (m *ConfigurableMockRegistry) AllocateBatch(sbs []*apiv1alpha1.Sandbox) ([]*agentpool.AgentInfo, []error) {
+	agents := make([]*agentpool.AgentInfo, len(sbs))
+	errs := make([]error, len(sbs))
+	for i, sb := range sbs {
+		agents[i], errs[i] = m.Allocate(sb)
+	}
+	return agents, errs
+}
+
+func (m *ConfigurableMockRegistry) SelectVictims(sb *apiv1alpha1.Sandbox, running []apiv1alpha1.Sandbox) []*apiv1alpha1.Sandbox {
+	return nil
+}
+
 func (m *ConfigurableMockRegistry) Release(id agentpool.AgentID, sb *apiv1alpha1.Sandbox) {
 	m.ReleaseCalled = true
 	m.ReleaseAgentID = id
@@ -145,6 +191,9 @@ func (m *ConfigurableMockRegistry) CleanupStaleAgents(timeout time.Duration) int
 	return 0
 }
 
+func (m *ConfigurableMockRegistry) SetPoolStrategy(namespace, pool string, strategy apiv1alpha1.SchedulingStrategy) {
+}
+
 // ============================================================================
 // 测试辅助函数
 // ============================================================================
@@ -152,6 +201,7 @@ func (m *ConfigurableMockRegistry) CleanupStaleAgents(timeout time.Duration) int
 func newTestScheme(t *testing.T) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	require.NoError(t, apiv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
 	return scheme
 }
 
@@ -159,13 +209,15 @@ func newTestReconciler(scheme *runtime.Scheme, objs []client.Object, registry *C
 	builder := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(objs...).
-		WithStatusSubresource(&apiv1alpha1.Sandbox{})
+		WithStatusSubresource(&apiv1alpha1.Sandbox{}, &apiv1alpha1.SandboxCheckpoint{})
 
+	c := builder.Build()
 	return &SandboxReconciler{
-		Client:      builder.Build(),
+		Client:      c,
 		Scheme:      scheme,
 		Registry:    registry,
 		AgentClient: agentClient,
+		Reader:      c,
 	}
 }
 
@@ -403,6 +455,87 @@ func TestSandbox_Creation_AgentCreateFailure(t *testing.T) {
 	assert.Equal(t, "Pending", updated.Status.Phase)
 }
 
+func TestSandbox_Creation_QuotaExceeded(t *testing.T) {
+	// C-07: namespace 的 SandboxQuota 已用完，不调用 Allocate，稍后重试
+	scheme := newTestScheme(t)
+	sb := newBaseSandbox("test-sb", withFinalizer)
+	running := newBaseSandbox("running-sb", withAssignedPod("test-agent"), withPhase("Running"))
+	sq := &apiv1alpha1.SandboxQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+		Spec:       apiv1alpha1.SandboxQuotaSpec{MaxSandboxes: ptr.To[int32](1)},
+	}
+	registry := NewConfigurableMockRegistry()
+
+	r := newTestReconciler(scheme, []client.Object{sb, running, sq}, registry, &MockAgentClient{})
+	r.Quotas = quota.NewTracker(r.Client)
+
+	result, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, result.RequeueAfter)
+	assert.False(t, registry.AllocateCalled, "超出配额不应该调用 Allocate")
+	assert.Empty(t, getSandbox(t, r, "test-sb").Status.AssignedPod)
+}
+
+func TestSandbox_Creation_RestoreFromCheckpoint(t *testing.T) {
+	// C-08: RestoreFrom 先解析 checkpoint 写入 annotation，等待未就绪的 checkpoint，创建时交给 Agent 恢复
+	scheme := newTestScheme(t)
+	sb := newBaseSandbox("test-sb", withFinalizer)
+	sb.Spec.RestoreFrom = "warm"
+	ckpt := &apiv1alpha1.SandboxCheckpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: "warm", Namespace: "default"},
+		Spec:       apiv1alpha1.SandboxCheckpointSpec{SandboxName: "origin"},
+	}
+	registry := NewConfigurableMockRegistry()
+	var restoredFrom string
+	agentClient := &MockAgentClient{
+		CreateSandboxFunc: func(agentIP string, req *api.CreateSandboxRequest) (*api.CreateSandboxResponse, error) {
+			restoredFrom = req.Sandbox.Checkpoint
+			return &api.CreateSandboxResponse{}, nil
+		},
+	}
+	r := newTestReconciler(scheme, []client.Object{sb, ckpt}, registry, agentClient)
+
+	result, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, result.RequeueAfter)
+	assert.False(t, registry.AllocateCalled, "checkpoint 未就绪前不应该调度")
+
+	ckpt.Status = apiv1alpha1.SandboxCheckpointStatus{
+		Phase: apiv1alpha1.CheckpointPhaseReady, Ref: "checkpoint/default/warm", NodeName: "node-1", PoolRef: "test-pool",
+	}
+	require.NoError(t, r.Status().Update(context.Background(), ckpt))
+	_, err = r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	updated := getSandbox(t, r, "test-sb")
+	assert.Equal(t, "checkpoint/default/warm", updated.Annotations[common.AnnotationRestoreImage])
+	assert.Equal(t, "node-1", updated.Annotations[common.AnnotationRestoreNode])
+
+	for i := 0; i < 2; i++ {
+		_, err = r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "Bound", getSandbox(t, r, "test-sb").Status.Phase)
+	assert.Equal(t, "checkpoint/default/warm", restoredFrom)
+}
+
+func TestSandbox_Creation_RestoreFromOtherPool(t *testing.T) {
+	// C-09: 其他池的 checkpoint 无法恢复，sandbox 进入 Failed
+	scheme := newTestScheme(t)
+	sb := newBaseSandbox("test-sb", withFinalizer)
+	sb.Spec.RestoreFrom = "warm"
+	ckpt := &apiv1alpha1.SandboxCheckpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: "warm", Namespace: "default"},
+		Status:     apiv1alpha1.SandboxCheckpointStatus{Phase: apiv1alpha1.CheckpointPhaseReady, PoolRef: "gvisor-pool"},
+	}
+	registry := NewConfigurableMockRegistry()
+	r := newTestReconciler(scheme, []client.Object{sb, ckpt}, registry, &MockAgentClient{})
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Equal(t, "Failed", getSandbox(t, r, "test-sb").Status.Phase)
+	assert.False(t, registry.AllocateCalled)
+}
+
 // ============================================================================
 // 2. 删除流程测试 (Deletion)
 // ============================================================================
@@ -592,6 +725,28 @@ func TestSandbox_Deletion_ExpiredPhase(t *testing.T) {
 	sandboxShouldBeDeleted(t, r, "test-sb")
 }
 
+func TestSandbox_Deletion_SucceededPhase(t *testing.T) {
+	// D-09: Phase=Succeeded，容器仍在 Agent 上，需要调用 Agent 删除
+	scheme := newTestScheme(t)
+	sb := newBaseSandbox("test-sb", withFinalizer, withDeletionTimestamp, withAssignedPod("test-agent"), withPhase("Succeeded"))
+
+	registry := NewConfigurableMockRegistry()
+	deleteCalled := false
+	agentClient := &MockAgentClient{
+		DeleteSandboxFunc: func(agentIP string, req *api.DeleteSandboxRequest) (*api.DeleteSandboxResponse, error) {
+			deleteCalled = true
+			return &api.DeleteSandboxResponse{Success: true}, nil
+		},
+	}
+
+	r := newTestReconciler(scheme, []client.Object{sb}, registry, agentClient)
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.True(t, deleteCalled, "应该调用 DeleteSandbox")
+	assert.Equal(t, "Terminating", getSandbox(t, r, "test-sb").Status.Phase)
+}
+
 func TestSandbox_Deletion_OtherPhase(t *testing.T) {
 	// D-08: Phase 为空或其他值 (非 Bound/Terminating/Expired)
 	scheme := newTestScheme(t)
@@ -733,6 +888,35 @@ func TestSandbox_Expiration_SkipWhenDeleting(t *testing.T) {
 	assert.Equal(t, 2*time.Second, result.RequeueAfter)
 }
 
+func TestSandbox_Preempted_Evicts(t *testing.T) {
+	// E-08: Phase=Preempted 且 AssignedPod 存在，从 Agent 删除并释放，保留 CRD
+	scheme := newTestScheme(t)
+	sb := newBaseSandbox("test-sb", withFinalizer,
+		withAssignedPod("test-agent"),
+		withPhase("Preempted"))
+
+	registry := NewConfigurableMockRegistry()
+	deleteCalled := false
+	agentClient := &MockAgentClient{
+		DeleteSandboxFunc: func(agentIP string, req *api.DeleteSandboxRequest) (*api.DeleteSandboxResponse, error) {
+			deleteCalled = true
+			return &api.DeleteSandboxResponse{Success: true}, nil
+		},
+	}
+
+	r := newTestReconciler(scheme, []client.Object{sb}, registry, agentClient)
+
+	result, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	assert.True(t, deleteCalled, "应该调用 deleteFromAgent")
+	assert.True(t, registry.ReleaseCalled, "应该释放 Registry")
+
+	updated := getSandbox(t, r, "test-sb")
+	assert.Equal(t, "Preempted", updated.Status.Phase)
+	assert.Empty(t, updated.Status.AssignedPod, "AssignedPod 应该清空")
+}
+
 // ============================================================================
 // 4. Reset 流程测试 (ResetRevision)
 // ============================================================================
@@ -1061,6 +1245,347 @@ func TestSandbox_StatusSync_AgentStatusMissing(t *testing.T) {
 	assert.Equal(t, "Bound", updated.Status.Phase)
 }
 
+func TestSandbox_StatusSync_ExitSucceeded(t *testing.T) {
+	// S-05: 主进程正常退出，同步 Succeeded、退出码、结束时间与原因
+	scheme := newTestScheme(t)
+	testUID := "test-uid-exit"
+	sb := newBaseSandbox("test-sb", withFinalizer,
+		withAssignedPod("test-agent"),
+		withPhase("Running"),
+		withUID(testUID))
+
+	registry := NewConfigurableMockRegistry()
+	registry.DefaultAgent = &agentpool.AgentInfo{
+		ID:            "test-agent",
+		PodName:       "test-agent",
+		PodIP:         "10.0.0.1",
+		LastHeartbeat: time.Now(),
+		SandboxStatuses: map[string]api.SandboxStatus{
+			testUID: {SandboxID: testUID, Phase: "succeeded", ExitCode: 0, ExitedAt: 1700000000, Reason: api.ReasonCompleted},
+		},
+	}
+	r := newTestReconciler(scheme, []client.Object{sb}, registry, &MockAgentClient{})
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+
+	updated := getSandbox(t, r, "test-sb")
+	assert.Equal(t, "Succeeded", updated.Status.Phase)
+	require.NotNil(t, updated.Status.ExitCode)
+	assert.Equal(t, int32(0), *updated.Status.ExitCode)
+	require.NotNil(t, updated.Status.FinishedAt)
+	assert.Equal(t, int64(1700000000), updated.Status.FinishedAt.Unix())
+	assert.Equal(t, "Completed", updated.Status.Reason)
+}
+
+func TestSandbox_StatusSync_Restarted(t *testing.T) {
+	// S-06: Agent 已重启主进程，Phase 仍为 Running，但记录重启次数与上次退出
+	scheme := newTestScheme(t)
+	testUID := "test-uid-restart"
+	sb := newBaseSandbox("test-sb", withFinalizer,
+		withAssignedPod("test-agent"),
+		withPhase("Running"),
+		withUID(testUID))
+	sb.Status.SandboxID = testUID
+
+	registry := NewConfigurableMockRegistry()
+	registry.DefaultAgent = &agentpool.AgentInfo{
+		ID:            "test-agent",
+		PodName:       "test-agent",
+		PodIP:         "10.0.0.1",
+		LastHeartbeat: time.Now(),
+		SandboxStatuses: map[string]api.SandboxStatus{
+			testUID: {SandboxID: testUID, Phase: "running", ExitCode: 137, ExitedAt: 1700000000, Reason: api.ReasonOOMKilled, RestartCount: 2},
+		},
+	}
+	r := newTestReconciler(scheme, []client.Object{sb}, registry, &MockAgentClient{})
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+
+	updated := getSandbox(t, r, "test-sb")
+	assert.Equal(t, "Running", updated.Status.Phase)
+	assert.Equal(t, int32(2), updated.Status.RestartCount)
+	assert.Equal(t, "OOMKilled", updated.Status.Reason)
+	require.NotNil(t, updated.Status.ExitCode)
+	assert.Equal(t, int32(137), *updated.Status.ExitCode)
+}
+
+func TestSandbox_Pause(t *testing.T) {
+	// S-08: Spec.Paused 驱动 Agent 暂停与恢复，Agent 上报的 paused 映射为 Paused
+	scheme := newTestScheme(t)
+	testUID := "test-uid-pause"
+	agentWith := func(phase string) *agentpool.AgentInfo {
+		return &agentpool.AgentInfo{
+			ID:            "test-agent",
+			PodName:       "test-agent",
+			PodIP:         "10.0.0.1",
+			LastHeartbeat: time.Now(),
+			SandboxStatuses: map[string]api.SandboxStatus{
+				testUID: {SandboxID: testUID, Phase: phase},
+			},
+		}
+	}
+	newSandbox := func(phase string, paused bool) *apiv1alpha1.Sandbox {
+		sb := newBaseSandbox("test-sb", withFinalizer, withAssignedPod("test-agent"), withPhase(phase), withUID(testUID))
+		sb.Status.SandboxID = testUID
+		sb.Spec.Paused = paused
+		return sb
+	}
+
+	registry := NewConfigurableMockRegistry()
+	registry.DefaultAgent = agentWith("running")
+	agentClient := &MockAgentClient{}
+	r := newTestReconciler(scheme, []client.Object{newSandbox("Running", true)}, registry, agentClient)
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, agentClient.PauseCalls)
+
+	// Agent 确认暂停后不再重复调用
+	registry.DefaultAgent = agentWith("paused")
+	_, err = r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Equal(t, "Paused", getSandbox(t, r, "test-sb").Status.Phase)
+	assert.Equal(t, []bool{true}, agentClient.PauseCalls)
+
+	agentClient = &MockAgentClient{}
+	r = newTestReconciler(scheme, []client.Object{newSandbox("Paused", false)}, registry, agentClient)
+	_, err = r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, agentClient.PauseCalls)
+}
+
+func TestSandbox_Idle(t *testing.T) {
+	// S-09: 空闲超过 IdleTimeoutSeconds 后按 IdlePolicy 暂停、过期或删除，活动时间同步到 status
+	testUID := "test-uid-idle"
+	tests := []struct {
+		name      string
+		policy    apiv1alpha1.IdlePolicy
+		timeout   int32
+		idle      time.Duration
+		agent     string
+		paused    bool
+		wantPause []bool
+		wantPhase string
+		deleted   bool
+	}{
+		{name: "active", timeout: 300, idle: time.Minute, agent: "running", wantPhase: "Running"},
+		{name: "pause by default", timeout: 300, idle: 10 * time.Minute, agent: "running", wantPause: []bool{true}, wantPhase: "Running"},
+		{name: "already paused", policy: apiv1alpha1.IdlePolicyPause, timeout: 300, idle: time.Hour, agent: "paused", paused: true, wantPhase: "Paused"},
+		{name: "below minimum timeout", timeout: 10, idle: 30 * time.Second, agent: "running", wantPhase: "Running"},
+		{name: "expire", policy: apiv1alpha1.IdlePolicyExpire, timeout: 300, idle: 10 * time.Minute, agent: "running", wantPhase: "Expired"},
+		{name: "expire paused", policy: apiv1alpha1.IdlePolicyExpire, timeout: 300, idle: time.Hour, agent: "paused", paused: true, wantPhase: "Expired"},
+		{name: "delete", policy: apiv1alpha1.IdlePolicyDelete, timeout: 300, idle: 10 * time.Minute, agent: "running", deleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastActivity := time.Now().Add(-tt.idle)
+			registry := NewConfigurableMockRegistry()
+			registry.DefaultAgent = &agentpool.AgentInfo{
+				ID:            "test-agent",
+				PodName:       "test-agent",
+				PodIP:         "10.0.0.1",
+				LastHeartbeat: time.Now(),
+				SandboxStatuses: map[string]api.SandboxStatus{
+					testUID: {SandboxID: testUID, Phase: tt.agent, LastActivityAt: lastActivity.Unix()},
+				},
+			}
+			sb := newBaseSandbox("test-sb", withFinalizer, withAssignedPod("test-agent"), withPhase("Running"), withUID(testUID), func(sb *apiv1alpha1.Sandbox) {
+				sb.Status.SandboxID = testUID
+				sb.Spec.IdleTimeoutSeconds = tt.timeout
+				sb.Spec.IdlePolicy = tt.policy
+				sb.Spec.Paused = tt.paused
+			})
+			agentClient := &MockAgentClient{}
+			var deletedFromAgent bool
+			agentClient.DeleteSandboxFunc = func(agentIP string, req *api.DeleteSandboxRequest) (*api.DeleteSandboxResponse, error) {
+				deletedFromAgent = true
+				return &api.DeleteSandboxResponse{Success: true}, nil
+			}
+			r := newTestReconciler(newTestScheme(t), []client.Object{sb}, registry, agentClient)
+
+			_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantPause, agentClient.PauseCalls)
+			updated := getSandbox(t, r, "test-sb")
+			if tt.deleted {
+				assert.NotNil(t, updated.DeletionTimestamp)
+				return
+			}
+			assert.Equal(t, tt.wantPhase, updated.Status.Phase)
+			assert.Equal(t, tt.paused || tt.wantPause != nil, updated.Spec.Paused)
+			assert.Equal(t, tt.wantPhase == "Expired", deletedFromAgent)
+			if tt.wantPhase != "Expired" {
+				require.NotNil(t, updated.Status.LastActivityTime)
+				assert.Equal(t, lastActivity.Unix(), updated.Status.LastActivityTime.Unix())
+			}
+		})
+	}
+}
+
+func TestSandbox_TTLAfterFinished(t *testing.T) {
+	// S-10: 进入终态时记录 CompletionTime，TTLSecondsAfterFinished 到期后删除 Sandbox
+	ttl := func(seconds int32) *int32 { return &seconds }
+	tests := []struct {
+		name           string
+		phase          string
+		ttl            *int32
+		finishedAgo    time.Duration
+		completedAgo   time.Duration
+		wantCompletion bool
+		wantRequeue    bool
+		deleted        bool
+	}{
+		{name: "succeeded without ttl", phase: "Succeeded", finishedAgo: time.Hour, wantCompletion: true},
+		{name: "failed before ttl", phase: "Failed", ttl: ttl(60), finishedAgo: 10 * time.Second, wantCompletion: true, wantRequeue: true},
+		{name: "expired with zero ttl", phase: "Expired", ttl: ttl(0), deleted: true},
+		{name: "succeeded after ttl", phase: "Succeeded", ttl: ttl(60), completedAgo: 2 * time.Minute, deleted: true},
+		{name: "running ignores ttl", phase: "Running", ttl: ttl(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := newBaseSandbox("test-sb", withFinalizer, withPhase(tt.phase), func(sb *apiv1alpha1.Sandbox) {
+				sb.Spec.TTLSecondsAfterFinished = tt.ttl
+				if tt.finishedAgo > 0 {
+					finishedAt := metav1.NewTime(time.Now().Add(-tt.finishedAgo))
+					sb.Status.FinishedAt = &finishedAt
+				}
+				if tt.completedAgo > 0 {
+					completion := metav1.NewTime(time.Now().Add(-tt.completedAgo))
+					sb.Status.CompletionTime = &completion
+				}
+			})
+			r := newTestReconciler(newTestScheme(t), []client.Object{sb}, NewConfigurableMockRegistry(), &MockAgentClient{})
+
+			result, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+			require.NoError(t, err)
+
+			updated := getSandbox(t, r, "test-sb")
+			assert.Equal(t, tt.deleted, updated.DeletionTimestamp != nil)
+			if tt.wantCompletion {
+				require.NotNil(t, updated.Status.CompletionTime)
+				assert.Equal(t, sb.Status.FinishedAt.Unix(), updated.Status.CompletionTime.Unix())
+			}
+			if tt.wantRequeue {
+				assert.InDelta(t, 50*time.Second, result.RequeueAfter, float64(2*time.Second))
+			}
+		})
+	}
+}
+
+func TestSandbox_ResetClearsCompletionTime(t *testing.T) {
+	// S-11: reset 后的 sandbox 重新调度，清除 CompletionTime
+	sb := newBaseSandbox("test-sb", withFinalizer, withPhase("Failed"), withResetRevision(time.Now()), func(sb *apiv1alpha1.Sandbox) {
+		completion := metav1.NewTime(time.Now().Add(-time.Hour))
+		sb.Status.CompletionTime = &completion
+		sb.Spec.TTLSecondsAfterFinished = new(int32)
+	})
+	r := newTestReconciler(newTestScheme(t), []client.Object{sb}, NewConfigurableMockRegistry(), &MockAgentClient{})
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+
+	updated := getSandbox(t, r, "test-sb")
+	assert.Nil(t, updated.DeletionTimestamp)
+	assert.Equal(t, "Pending", updated.Status.Phase)
+	assert.Nil(t, updated.Status.CompletionTime)
+}
+
+func TestSandbox_ReadyCondition(t *testing.T) {
+	// S-12: Agent 上报的就绪状态同步为 Ready condition，离开 Agent 后置为 False
+	scheme := newTestScheme(t)
+	testUID := "test-uid-ready"
+	sb := newBaseSandbox("test-sb", withFinalizer,
+		withAssignedPod("test-agent"),
+		withPhase("Running"),
+		withUID(testUID))
+	sb.Status.SandboxID = testUID
+	sb.Spec.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(8080)}},
+	}
+
+	registry := NewConfigurableMockRegistry()
+	registry.DefaultAgent = &agentpool.AgentInfo{
+		ID:            "test-agent",
+		PodName:       "test-agent",
+		PodIP:         "10.0.0.1",
+		LastHeartbeat: time.Now(),
+		SandboxStatuses: map[string]api.SandboxStatus{
+			testUID: {SandboxID: testUID, Phase: "running"},
+		},
+	}
+	r := newTestReconciler(scheme, []client.Object{sb}, registry, &MockAgentClient{})
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	ready := meta.FindStatusCondition(getSandbox(t, r, "test-sb").Status.Conditions, apiv1alpha1.SandboxConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "ReadinessProbeFailed", ready.Reason)
+
+	registry.DefaultAgent.SandboxStatuses[testUID] = api.SandboxStatus{SandboxID: testUID, Phase: "running", Ready: true}
+	_, err = r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	ready = meta.FindStatusCondition(getSandbox(t, r, "test-sb").Status.Conditions, apiv1alpha1.SandboxConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, "ReadinessProbePassed", ready.Reason)
+
+	// 主进程退出后不再就绪
+	registry.DefaultAgent.SandboxStatuses[testUID] = api.SandboxStatus{SandboxID: testUID, Phase: "failed", ExitCode: 1, Reason: api.ReasonError}
+	_, err = r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+	updated := getSandbox(t, r, "test-sb")
+	assert.Equal(t, "Failed", updated.Status.Phase)
+	ready = meta.FindStatusCondition(updated.Status.Conditions, apiv1alpha1.SandboxConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "NotRunning", ready.Reason)
+}
+
+func TestSandbox_InvalidReadinessProbe(t *testing.T) {
+	// S-13: Agent 不支持的就绪探测在调度前即失败，不创建 sandbox
+	scheme := newTestScheme(t)
+	sb := newBaseSandbox("test-sb", withFinalizer, withPhase("Pending"))
+	sb.Spec.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromString("http")}},
+	}
+	createCalled := false
+	agentClient := &MockAgentClient{
+		CreateSandboxFunc: func(agentIP string, req *api.CreateSandboxRequest) (*api.CreateSandboxResponse, error) {
+			createCalled = true
+			return &api.CreateSandboxResponse{Success: true}, nil
+		},
+	}
+	r := newTestReconciler(scheme, []client.Object{sb}, NewConfigurableMockRegistry(), agentClient)
+
+	_, err := r.Reconcile(context.Background(), reconcileRequest("test-sb"))
+	require.NoError(t, err)
+
+	updated := getSandbox(t, r, "test-sb")
+	assert.Equal(t, "Failed", updated.Status.Phase)
+	assert.Empty(t, updated.Status.AssignedPod)
+	assert.False(t, createCalled)
+}
+
+func TestMapAgentPhaseToController(t *testing.T) {
+	// S-07: Agent phase 映射到 Controller phase
+	tests := map[string]apiv1alpha1.SandboxPhase{
+		"creating":   apiv1alpha1.PhaseBound,
+		"running":    apiv1alpha1.PhaseRunning,
+		"restarting": apiv1alpha1.PhaseRunning,
+		"paused":     apiv1alpha1.PhasePaused,
+		"succeeded":  apiv1alpha1.PhaseSucceeded,
+		"failed":     apiv1alpha1.PhaseFailed,
+		"stopped":    apiv1alpha1.PhaseFailed,
+		"terminated": apiv1alpha1.PhaseTerminating,
+	}
+	for agentPhase, expected := range tests {
+		assert.Equal(t, expected, mapAgentPhaseToController(agentPhase), "agent phase %s", agentPhase)
+	}
+}
+
 // ============================================================================
 // Bug 验证测试 (用于确认和修复潜在 Bug)
 // ============================================================================
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SandboxGC deletes finished sandboxes (Succeeded, Failed, Expired or Preempted) beyond
+// the retention policy of the controller, so that dead Sandbox objects do not pile up in
+// etcd. Sandboxes with a TTLSecondsAfterFinished are deleted by the SandboxReconciler
+// when it elapses, the retention policy still applies to them before that.
+type SandboxGC struct {
+	Client client.Client
+	// MaxAge 为 finished sandbox 完成后最长保留时间，0 表示不按时间回收
+	MaxAge time.Duration
+	// MaxCount 为每个 namespace 最多保留的 finished sandbox 数，超出时先删除最早完成的，0 表示不限
+	MaxCount int
+	// Interval 为回收周期
+	Interval time.Duration
+}
+
+// NewSandboxGC creates a SandboxGC with a default interval.
+func NewSandboxGC(c client.Client, maxAge time.Duration, maxCount int) *SandboxGC {
+	return &SandboxGC{
+		Client:   c,
+		MaxAge:   maxAge,
+		MaxCount: maxCount,
+		Interval: time.Minute,
+	}
+}
+
+// Start collects finished sandboxes every Interval until the context is cancelled.
+// It implements manager.Runnable and only runs on the leader.
+func (g *SandboxGC) Start(ctx context.Context) error {
+	logger := klog.Background().WithName("sandbox-gc")
+	if g.MaxAge <= 0 && g.MaxCount <= 0 {
+		logger.Info("No retention policy for finished sandboxes, sandbox GC disabled")
+		return nil
+	}
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+	for {
+		if err := g.collect(ctx, time.Now()); err != nil {
+			logger.Error(err, "sandbox GC failed")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect deletes the finished sandboxes of every namespace that are older than MaxAge
+// at now, or beyond the MaxCount most recently finished ones. A failed deletion does not
+// stop the others, all failures are returned together.
+func (g *SandboxGC) collect(ctx context.Context, now time.Time) error {
+	var list apiv1alpha1.SandboxList
+	if err := g.Client.List(ctx, &list); err != nil {
+		return err
+	}
+
+	finished := make(map[string][]*apiv1alpha1.Sandbox)
+	for i := range list.Items {
+		sb := &list.Items[i]
+		if sb.DeletionTimestamp != nil || !IsFinishedPhase(apiv1alpha1.SandboxPhase(sb.Status.Phase)) {
+			continue
+		}
+		// 尚未记录完成时间的沙箱留给下一轮，SandboxReconciler 会在终态时记录
+		if _, ok := completionTime(sb); ok {
+			finished[sb.Namespace] = append(finished[sb.Namespace], sb)
+		}
+	}
+
+	var errs []error
+	deleted := 0
+	for namespace, sandboxes := range finished {
+		// 最近完成的在前
+		sort.Slice(sandboxes, func(i, j int) bool {
+			ti, _ := completionTime(sandboxes[i])
+			tj, _ := completionTime(sandboxes[j])
+			return ti.After(tj)
+		})
+		for i, sb := range sandboxes {
+			completed, _ := completionTime(sb)
+			tooMany := g.MaxCount > 0 && i >= g.MaxCount
+			tooOld := g.MaxAge > 0 && now.Sub(completed) > g.MaxAge
+			if !tooMany && !tooOld {
+				continue
+			}
+			if err := g.Client.Delete(ctx, sb); client.IgnoreNotFound(err) != nil {
+				// 单个删除失败不影响其余沙箱与 namespace 的回收
+				klog.ErrorS(err, "Failed to delete finished sandbox", "namespace", namespace, "name", sb.Name)
+				errs = append(errs, err)
+				continue
+			}
+			klog.V(2).InfoS("Deleted finished sandbox", "namespace", namespace, "name", sb.Name, "phase", sb.Status.Phase, "completionTime", completed)
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		klog.InfoS("Sandbox GC deleted finished sandboxes", "count", deleted)
+	}
+	return errors.Join(errs...)
+}
+
+// completionTime returns when a finished sandbox completed. Sandboxes the controller has
+// not reconciled since they finished fall back to when their main process exited, and
+// the time is unknown when neither is recorded.
+func completionTime(sb *apiv1alpha1.Sandbox) (time.Time, bool) {
+	switch {
+	case sb.Status.CompletionTime != nil:
+		return sb.Status.CompletionTime.Time, true
+	case sb.Status.FinishedAt != nil:
+		return sb.Status.FinishedAt.Time, true
+	}
+	return time.Time{}, false
+}
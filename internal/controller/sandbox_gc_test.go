@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSandboxGC_Collect(t *testing.T) {
+	// GC-01: 每个 namespace 只保留 MaxCount 个最近完成且未超过 MaxAge 的 finished sandbox
+	now := time.Now()
+	finished := func(name, namespace, phase string, ago time.Duration) *apiv1alpha1.Sandbox {
+		return newBaseSandbox(name, withPhase(phase), func(sb *apiv1alpha1.Sandbox) {
+			sb.Namespace = namespace
+			completion := metav1.NewTime(now.Add(-ago))
+			sb.Status.CompletionTime = &completion
+		})
+	}
+	objs := []client.Object{
+		finished("newest", "default", "Succeeded", time.Minute),
+		finished("newer", "default", "Failed", 2*time.Minute),
+		finished("older", "default", "Preempted", 3*time.Minute),
+		finished("ancient", "team", "Expired", 48*time.Hour),
+		finished("recent", "team", "Expired", time.Hour),
+		newBaseSandbox("running", withPhase("Running")),
+		newBaseSandbox("pending"),
+		// 运行了很久刚结束、尚未记录 CompletionTime 的沙箱不按创建时间回收
+		newBaseSandbox("just-exited", withPhase("Failed"), func(sb *apiv1alpha1.Sandbox) {
+			sb.Namespace = "team"
+			sb.CreationTimestamp = metav1.NewTime(now.Add(-72 * time.Hour))
+			finishedAt := metav1.NewTime(now.Add(-time.Second))
+			sb.Status.FinishedAt = &finishedAt
+		}),
+		newBaseSandbox("unrecorded", withPhase("Expired"), func(sb *apiv1alpha1.Sandbox) {
+			sb.Namespace = "other"
+			sb.CreationTimestamp = metav1.NewTime(now.Add(-72 * time.Hour))
+		}),
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).Build()
+	gc := NewSandboxGC(c, 24*time.Hour, 2)
+
+	require.NoError(t, gc.collect(context.Background(), now))
+
+	var list apiv1alpha1.SandboxList
+	require.NoError(t, c.List(context.Background(), &list))
+	var kept []string
+	for _, sb := range list.Items {
+		kept = append(kept, sb.Namespace+"/"+sb.Name)
+	}
+	assert.ElementsMatch(t, []string{"default/newest", "default/newer", "team/just-exited", "team/recent", "default/running", "default/pending", "other/unrecorded"}, kept)
+}
+
+func TestSandboxGC_Collect_DeleteError(t *testing.T) {
+	// GC-02: 某个 namespace 删除失败时，其余 namespace 仍被回收，错误最终返回
+	now := time.Now()
+	finished := func(name, namespace string, ago time.Duration) *apiv1alpha1.Sandbox {
+		return newBaseSandbox(name, withPhase("Succeeded"), func(sb *apiv1alpha1.Sandbox) {
+			sb.Namespace = namespace
+			completion := metav1.NewTime(now.Add(-ago))
+			sb.Status.CompletionTime = &completion
+		})
+	}
+	objs := []client.Object{
+		finished("old-a", "broken", 48*time.Hour),
+		finished("old-b", "broken", 48*time.Hour),
+		finished("old", "default", 48*time.Hour),
+		finished("old", "team", 48*time.Hour),
+	}
+	deleteErr := errors.New("etcd unavailable")
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if obj.GetNamespace() == "broken" {
+					return deleteErr
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+	gc := NewSandboxGC(c, 24*time.Hour, 0)
+
+	err := gc.collect(context.Background(), now)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, deleteErr)
+
+	var list apiv1alpha1.SandboxList
+	require.NoError(t, c.List(context.Background(), &list))
+	var kept []string
+	for _, sb := range list.Items {
+		kept = append(kept, sb.Namespace+"/"+sb.Name)
+	}
+	assert.ElementsMatch(t, []string{"broken/old-a", "broken/old-b"}, kept)
+}
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/common"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// CheckpointFinalizerName removes local checkpoint images from their node before the
+// SandboxCheckpoint is deleted.
+const CheckpointFinalizerName = "sandbox.fast.io/checkpoint-cleanup"
+
+// SandboxCheckpointReconciler takes a checkpoint of a sandbox on its Agent once per
+// SandboxCheckpoint. Ready and Failed are final, delete and recreate to checkpoint again.
+type SandboxCheckpointReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Registry    agentpool.AgentRegistry
+	AgentClient api.AgentAPIClient
+	// Recorder 可选，记录 checkpoint 失败事件
+	Recorder record.EventRecorder
+	// Reader 直接读 API Server，读取镜像仓库 Secret 时避免为 Secret 建立 informer 缓存
+	Reader client.Reader
+
+	// outcomes 按 UID 记录 Agent 已返回、尚未写入 status 的 checkpoint 结果
+	outcomes sync.Map
+}
+
+// checkpointOutcome is the result of a checkpoint call on an agent.
+type checkpointOutcome struct {
+	agent string
+	err   error
+}
+
+// Reconcile checkpoints the sandbox of a new SandboxCheckpoint and cleans up on deletion.
+func (r *SandboxCheckpointReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var ckpt apiv1alpha1.SandboxCheckpoint
+	if err := r.Get(ctx, req.NamespacedName, &ckpt); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if ckpt.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.handleDeletion(ctx, &ckpt)
+	}
+	if !controllerutil.ContainsFinalizer(&ckpt, CheckpointFinalizerName) {
+		controllerutil.AddFinalizer(&ckpt, CheckpointFinalizerName)
+		if err := r.Update(ctx, &ckpt); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	switch ckpt.Status.Phase {
+	case "":
+	case apiv1alpha1.CheckpointPhaseCheckpointing:
+		return ctrl.Result{}, r.finish(ctx, &ckpt)
+	default:
+		return ctrl.Result{}, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	var sandbox apiv1alpha1.Sandbox
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.SandboxName}, &sandbox); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.fail(ctx, &ckpt, fmt.Sprintf("sandbox %s not found", ckpt.Spec.SandboxName))
+		}
+		return ctrl.Result{}, err
+	}
+
+	switch phase := apiv1alpha1.SandboxPhase(sandbox.Status.Phase); phase {
+	case apiv1alpha1.PhaseRunning, apiv1alpha1.PhasePaused:
+	case "", apiv1alpha1.PhasePending, apiv1alpha1.PhaseBound:
+		logger.V(1).Info("Waiting for sandbox to run before checkpointing", "sandbox", sandbox.Name, "phase", phase)
+		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+	default:
+		return ctrl.Result{}, r.fail(ctx, &ckpt, fmt.Sprintf("sandbox %s is %s, only Running and Paused sandboxes can be checkpointed", sandbox.Name, phase))
+	}
+
+	agent, ok := r.Registry.GetAgentByID(agentpool.AgentID(sandbox.Status.AssignedPod))
+	if !ok {
+		logger.V(1).Info("Agent of sandbox not available, waiting", "agent", sandbox.Status.AssignedPod)
+		return ctrl.Result{RequeueAfter: DefaultRequeueInterval}, nil
+	}
+	creds, err := common.CheckpointCredentials(ctx, r.Reader, &ckpt)
+	if err != nil {
+		// Secret 不存在或内容无效时重试无用
+		var apiStatus apierrors.APIStatus
+		if errors.As(err, &apiStatus) && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.fail(ctx, &ckpt, err.Error())
+	}
+
+	ref := ckpt.Spec.Image
+	if ref == "" {
+		// 未推送的 checkpoint 以 UID 区分，同名 checkpoint 重建后不会复用旧镜像
+		ref = fmt.Sprintf("checkpoint.fast-sandbox.io/%s/%s:%s", ckpt.Namespace, ckpt.Name, ckpt.UID)
+	}
+	// 恢复时从 checkpoint 时的 spec 出发，去掉只对原 sandbox 有意义的字段
+	spec := sandbox.Spec
+	spec.Paused = false
+	spec.ResetRevision = nil
+	spec.ExpireTime = nil
+	spec.RestoreFrom = ""
+	// 先记录进行中，status 更新失败的重试不会再次 dump
+	err = r.updateStatus(ctx, &ckpt, func(status *apiv1alpha1.SandboxCheckpointStatus) {
+		*status = apiv1alpha1.SandboxCheckpointStatus{
+			Phase:    apiv1alpha1.CheckpointPhaseCheckpointing,
+			Ref:      ref,
+			NodeName: agent.NodeName,
+			PoolRef:  sandbox.Spec.PoolRef,
+			Sandbox:  &spec,
+		}
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Checkpointing sandbox", "sandbox", sandbox.Name, "agent", agent.PodName, "ref", ref)
+	err = r.AgentClient.CheckpointSandbox(ctx, agent.PodIP, &api.CheckpointRequest{
+		SandboxID:   agentSandboxID(&sandbox),
+		Ref:         ref,
+		Push:        ckpt.Spec.Image != "",
+		Credentials: creds,
+	})
+	r.outcomes.Store(ckpt.UID, checkpointOutcome{agent: agent.PodName, err: err})
+	return ctrl.Result{}, r.finish(ctx, &ckpt)
+}
+
+// finish records the result of the checkpoint call on the agent. A checkpoint without a
+// result was interrupted by a controller restart and is failed rather than taken again.
+func (r *SandboxCheckpointReconciler) finish(ctx context.Context, ckpt *apiv1alpha1.SandboxCheckpoint) error {
+	value, ok := r.outcomes.Load(ckpt.UID)
+	if !ok {
+		return r.fail(ctx, ckpt, "checkpoint was interrupted by a controller restart, delete and recreate it")
+	}
+	outcome := value.(checkpointOutcome)
+
+	var err error
+	if outcome.err != nil {
+		err = r.fail(ctx, ckpt, fmt.Sprintf("checkpoint on agent %s failed: %v", outcome.agent, outcome.err))
+	} else {
+		now := metav1.Now()
+		err = r.updateStatus(ctx, ckpt, func(status *apiv1alpha1.SandboxCheckpointStatus) {
+			status.Phase = apiv1alpha1.CheckpointPhaseReady
+			status.CheckpointedAt = &now
+		})
+	}
+	if err != nil {
+		return err
+	}
+	r.outcomes.Delete(ckpt.UID)
+	if outcome.err == nil {
+		klog.FromContext(ctx).Info("Sandbox checkpointed", "sandbox", ckpt.Spec.SandboxName, "ref", ckpt.Status.Ref, "node", ckpt.Status.NodeName)
+	}
+	return nil
+}
+
+// handleDeletion removes a local checkpoint image through an Agent on its node. Pushed
+// checkpoints stay in the registry. Without an Agent on the node the image is left to
+// be cleaned up by hand, the node may be gone.
+func (r *SandboxCheckpointReconciler) handleDeletion(ctx context.Context, ckpt *apiv1alpha1.SandboxCheckpoint) error {
+	if !controllerutil.ContainsFinalizer(ckpt, CheckpointFinalizerName) {
+		return nil
+	}
+
+	logger := klog.FromContext(ctx)
+	// 被中断或失败的 checkpoint 也可能在节点上留下了镜像
+	if ckpt.Spec.Image == "" && ckpt.Status.Ref != "" {
+		deleted := false
+		for _, agent := range r.Registry.GetAllAgents() {
+			if agent.NodeName != ckpt.Status.NodeName {
+				continue
+			}
+			if err := r.AgentClient.DeleteCheckpoint(ctx, agent.PodIP, ckpt.Status.Ref); err != nil {
+				return fmt.Errorf("failed to delete checkpoint on agent %s: %w", agent.PodName, err)
+			}
+			deleted = true
+			break
+		}
+		if !deleted {
+			logger.Info("No agent on the checkpoint node, leaving the checkpoint image", "node", ckpt.Status.NodeName, "ref", ckpt.Status.Ref)
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &apiv1alpha1.SandboxCheckpoint{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(ckpt), latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		controllerutil.RemoveFinalizer(latest, CheckpointFinalizerName)
+		return r.Update(ctx, latest)
+	})
+}
+
+// fail marks the checkpoint Failed with the reason.
+func (r *SandboxCheckpointReconciler) fail(ctx context.Context, ckpt *apiv1alpha1.SandboxCheckpoint, message string) error {
+	klog.FromContext(ctx).Info("Checkpoint failed", "message", message)
+	if r.Recorder != nil {
+		r.Recorder.Event(ckpt, corev1.EventTypeWarning, "CheckpointFailed", message)
+	}
+	return r.updateStatus(ctx, ckpt, func(status *apiv1alpha1.SandboxCheckpointStatus) {
+		status.Phase = apiv1alpha1.CheckpointPhaseFailed
+		status.Message = message
+	})
+}
+
+func (r *SandboxCheckpointReconciler) updateStatus(ctx context.Context, ckpt *apiv1alpha1.SandboxCheckpoint, mutate func(*apiv1alpha1.SandboxCheckpointStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &apiv1alpha1.SandboxCheckpoint{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(ckpt), latest); err != nil {
+			return err
+		}
+		mutate(&latest.Status)
+		if err := r.Status().Update(ctx, latest); err != nil {
+			return err
+		}
+		ckpt.Status = latest.Status
+		return nil
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SandboxCheckpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1alpha1.SandboxCheckpoint{}).
+		Complete(r)
+}
@@ -0,0 +1,257 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/api"
+	"fast-sandbox/internal/controller/agentpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newCheckpointReconciler(t *testing.T, objs []client.Object, agentClient *MockAgentClient) *SandboxCheckpointReconciler {
+	scheme := newTestScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&apiv1alpha1.SandboxCheckpoint{}).Build()
+	registry := NewConfigurableMockRegistry()
+	registry.RegisterOrUpdate(agentpool.AgentInfo{ID: "test-agent", PodName: "test-agent", PodIP: "10.0.0.1", NodeName: "node-a"})
+	return &SandboxCheckpointReconciler{Client: c, Scheme: scheme, Registry: registry, AgentClient: agentClient, Reader: c}
+}
+
+func newCheckpoint(name, sandboxName string) *apiv1alpha1.SandboxCheckpoint {
+	return &apiv1alpha1.SandboxCheckpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: "uid-1"},
+		Spec:       apiv1alpha1.SandboxCheckpointSpec{SandboxName: sandboxName},
+	}
+}
+
+func reconcileCheckpoint(t *testing.T, r *SandboxCheckpointReconciler, name string) (ctrl.Result, *apiv1alpha1.SandboxCheckpoint) {
+	key := types.NamespacedName{Name: name, Namespace: "default"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	var updated apiv1alpha1.SandboxCheckpoint
+	require.NoError(t, r.Get(context.Background(), key, &updated))
+	return result, &updated
+}
+
+func TestSandboxCheckpoint_Ready(t *testing.T) {
+	// CK-01: 运行中的 sandbox 在其 Agent 上打本地 checkpoint，status 记录节点与恢复用的 spec
+	sb := newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Running"), func(sb *apiv1alpha1.Sandbox) {
+		sb.Status.SandboxID = "sb-id"
+		sb.Spec.Paused = true
+		sb.Spec.ExposedPorts = []int32{8080}
+	})
+	var got *api.CheckpointRequest
+	agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+		got = req
+		return nil
+	}}
+	r := newCheckpointReconciler(t, []client.Object{sb, newCheckpoint("warm", "sb")}, agentClient)
+
+	_, ckpt := reconcileCheckpoint(t, r, "warm")
+
+	require.NotNil(t, got)
+	assert.Equal(t, "sb-id", got.SandboxID)
+	assert.Equal(t, "checkpoint.fast-sandbox.io/default/warm:uid-1", got.Ref)
+	assert.False(t, got.Push)
+	assert.Contains(t, ckpt.Finalizers, CheckpointFinalizerName)
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseReady, ckpt.Status.Phase)
+	assert.Equal(t, got.Ref, ckpt.Status.Ref)
+	assert.Equal(t, "node-a", ckpt.Status.NodeName)
+	assert.Equal(t, "test-pool", ckpt.Status.PoolRef)
+	require.NotNil(t, ckpt.Status.Sandbox)
+	assert.False(t, ckpt.Status.Sandbox.Paused, "restored sandboxes start running")
+	assert.Equal(t, []int32{8080}, ckpt.Status.Sandbox.ExposedPorts)
+}
+
+func TestSandboxCheckpoint_Push(t *testing.T) {
+	// CK-02: 指定 image 时推送到该镜像
+	sb := newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Paused"))
+	ckpt := newCheckpoint("warm", "sb")
+	ckpt.Spec.Image = "registry.example.com/ckpt:warm"
+	var got *api.CheckpointRequest
+	agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+		got = req
+		return nil
+	}}
+	r := newCheckpointReconciler(t, []client.Object{sb, ckpt}, agentClient)
+
+	_, updated := reconcileCheckpoint(t, r, "warm")
+
+	require.NotNil(t, got)
+	assert.Equal(t, "registry.example.com/ckpt:warm", got.Ref)
+	assert.True(t, got.Push)
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseReady, updated.Status.Phase)
+}
+
+func TestSandboxCheckpoint_RegistrySecret(t *testing.T) {
+	// CK-08: 推送时使用 RegistrySecret 中镜像仓库的凭据，Secret 不存在时 Failed
+	sb := newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Running"))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"username": "robot", "password": "s3cret"}}}`)},
+	}
+	pushed := newCheckpoint("warm", "sb")
+	pushed.Spec.Image = "registry.example.com/ckpt:warm"
+	pushed.Spec.RegistrySecret = "regcred"
+	missing := newCheckpoint("cold", "sb")
+	missing.Spec.Image = "registry.example.com/ckpt:cold"
+	missing.Spec.RegistrySecret = "gone"
+	var got *api.CheckpointRequest
+	agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+		got = req
+		return nil
+	}}
+	r := newCheckpointReconciler(t, []client.Object{sb, secret, pushed, missing}, agentClient)
+
+	_, updated := reconcileCheckpoint(t, r, "warm")
+	require.NotNil(t, got)
+	assert.Equal(t, &api.RegistryCredentials{Username: "robot", Password: "s3cret"}, got.Credentials)
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseReady, updated.Status.Phase)
+
+	got = nil
+	_, updated = reconcileCheckpoint(t, r, "cold")
+	assert.Nil(t, got, "the agent is not asked without the credentials")
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseFailed, updated.Status.Phase)
+	assert.Contains(t, updated.Status.Message, "registry secret gone")
+}
+
+func TestSandboxCheckpoint_WaitsForRunning(t *testing.T) {
+	// CK-03: sandbox 尚未运行时等待，不调用 Agent
+	sb := newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Bound"))
+	called := false
+	agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+		called = true
+		return nil
+	}}
+	r := newCheckpointReconciler(t, []client.Object{sb, newCheckpoint("warm", "sb")}, agentClient)
+
+	result, ckpt := reconcileCheckpoint(t, r, "warm")
+
+	assert.False(t, called)
+	assert.Equal(t, DefaultRequeueInterval, result.RequeueAfter)
+	assert.Empty(t, ckpt.Status.Phase)
+}
+
+func TestSandboxCheckpoint_Failed(t *testing.T) {
+	// CK-04: sandbox 不存在、已结束或 Agent 打 checkpoint 失败时 Failed
+	tests := []struct {
+		name    string
+		objs    []client.Object
+		agent   error
+		message string
+	}{
+		{name: "missing sandbox", message: "sandbox sb not found"},
+		{name: "finished sandbox", objs: []client.Object{newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Succeeded"))}, message: "only Running and Paused"},
+		{name: "agent error", objs: []client.Object{newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Running"))}, agent: errors.New("criu failed"), message: "criu failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+				return tt.agent
+			}}
+			r := newCheckpointReconciler(t, append(tt.objs, newCheckpoint("warm", "sb")), agentClient)
+
+			_, ckpt := reconcileCheckpoint(t, r, "warm")
+
+			assert.Equal(t, apiv1alpha1.CheckpointPhaseFailed, ckpt.Status.Phase)
+			assert.Contains(t, ckpt.Status.Message, tt.message)
+		})
+	}
+}
+
+func TestSandboxCheckpoint_DeleteLocal(t *testing.T) {
+	// CK-05: 删除本地 checkpoint 时由所在节点的 Agent 删除镜像，推送的 checkpoint 保留在仓库
+	now := metav1.Now()
+	local := newCheckpoint("local", "sb")
+	local.Finalizers = []string{CheckpointFinalizerName}
+	local.DeletionTimestamp = &now
+	local.Status = apiv1alpha1.SandboxCheckpointStatus{Phase: apiv1alpha1.CheckpointPhaseReady, Ref: "checkpoint.fast-sandbox.io/default/local:uid-1", NodeName: "node-a"}
+	pushed := newCheckpoint("pushed", "sb")
+	pushed.Spec.Image = "registry.example.com/ckpt:warm"
+	pushed.Finalizers = []string{CheckpointFinalizerName}
+	pushed.DeletionTimestamp = &now
+	pushed.Status = apiv1alpha1.SandboxCheckpointStatus{Phase: apiv1alpha1.CheckpointPhaseReady, Ref: pushed.Spec.Image, NodeName: "node-a"}
+	agentClient := &MockAgentClient{}
+	r := newCheckpointReconciler(t, []client.Object{local, pushed}, agentClient)
+
+	for _, name := range []string{"local", "pushed"} {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"checkpoint.fast-sandbox.io/default/local:uid-1"}, agentClient.DeletedCheckpoints)
+	var list apiv1alpha1.SandboxCheckpointList
+	require.NoError(t, r.List(context.Background(), &list))
+	assert.Empty(t, list.Items, "finalizers removed")
+}
+
+func TestSandboxCheckpoint_StatusUpdateRetry(t *testing.T) {
+	// CK-06: 写入 Ready 失败后重试只更新 status，不再次调用 Agent
+	sb := newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Running"))
+	calls := 0
+	agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+		calls++
+		return nil
+	}}
+	r := newCheckpointReconciler(t, nil, agentClient)
+	failReady := true
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(sb, newCheckpoint("warm", "sb")).
+		WithStatusSubresource(&apiv1alpha1.SandboxCheckpoint{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if ckpt, ok := obj.(*apiv1alpha1.SandboxCheckpoint); ok && ckpt.Status.Phase == apiv1alpha1.CheckpointPhaseReady && failReady {
+					failReady = false
+					return errors.New("apiserver unavailable")
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		}).Build()
+	key := types.NamespacedName{Name: "warm", Namespace: "default"}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.Error(t, err)
+	var ckpt apiv1alpha1.SandboxCheckpoint
+	require.NoError(t, r.Get(context.Background(), key, &ckpt))
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseCheckpointing, ckpt.Status.Phase)
+	assert.Equal(t, "node-a", ckpt.Status.NodeName, "the node is known while checkpointing")
+
+	_, updated := reconcileCheckpoint(t, r, "warm")
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseReady, updated.Status.Phase)
+	assert.NotNil(t, updated.Status.CheckpointedAt)
+	require.NotNil(t, updated.Status.Sandbox)
+}
+
+func TestSandboxCheckpoint_Interrupted(t *testing.T) {
+	// CK-07: 重启前未完成的 checkpoint 标记为 Failed，不再次调用 Agent
+	sb := newBaseSandbox("sb", withAssignedPod("test-agent"), withPhase("Running"))
+	ckpt := newCheckpoint("warm", "sb")
+	ckpt.Status = apiv1alpha1.SandboxCheckpointStatus{Phase: apiv1alpha1.CheckpointPhaseCheckpointing, Ref: "checkpoint.fast-sandbox.io/default/warm:uid-1", NodeName: "node-a"}
+	called := false
+	agentClient := &MockAgentClient{CheckpointFunc: func(agentIP string, req *api.CheckpointRequest) error {
+		called = true
+		return nil
+	}}
+	r := newCheckpointReconciler(t, []client.Object{sb, ckpt}, agentClient)
+
+	_, updated := reconcileCheckpoint(t, r, "warm")
+
+	assert.False(t, called)
+	assert.Equal(t, apiv1alpha1.CheckpointPhaseFailed, updated.Status.Phase)
+	assert.Contains(t, updated.Status.Message, "interrupted")
+	assert.Equal(t, ckpt.Status.Ref, updated.Status.Ref, "the ref is kept so deletion can remove a partial image")
+}
@@ -7,14 +7,20 @@ import (
 
 	apiv1alpha1 "fast-sandbox/api/v1alpha1"
 	"fast-sandbox/internal/controller/agentpool"
+	"fast-sandbox/internal/controller/agenttls"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // SandboxPoolReconciler reconciles SandboxPool resources.
@@ -22,6 +28,12 @@ type SandboxPoolReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Registry agentpool.AgentRegistry
+	// Queue 可选，排队等待容量的请求计入需求，用于扩容
+	Queue *agentpool.CapacityQueue
+	// ScaleUpEvents 可选，有请求排队时由 Queue 投递，立即触发对应池的 reconcile
+	ScaleUpEvents <-chan event.GenericEvent
+	// AgentCerts 可选，设置后为每个 Agent Pod 签发证书，Agent 只接受 Controller 的 mTLS 连接
+	AgentCerts *agenttls.Issuer
 }
 
 // Reconcile manages the lifecycle of Agent Pods based on the demand from Sandboxes.
@@ -30,8 +42,14 @@ logger := klog.FromContext(ctx)
 
 	var pool apiv1alpha1.SandboxPool
 	if err := r.Get(ctx, req.NamespacedName, &pool); err != nil {
+		if apierrors.IsNotFound(err) && r.Registry != nil {
+			r.Registry.SetPoolStrategy(req.Namespace, req.Name, "")
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if r.Registry != nil {
+		r.Registry.SetPoolStrategy(pool.Namespace, pool.Name, pool.Spec.SchedulingStrategy)
+	}
 
 	var childPods corev1.PodList
 	if err := r.List(ctx, &childPods, client.InNamespace(req.Namespace), client.MatchingLabels(poolLabels(pool.Name))); err != nil {
@@ -52,6 +70,10 @@ logger := klog.FromContext(ctx)
 			}
 		}
 	}
+	if r.Queue != nil {
+		// 排队中的 FastPath 请求还没有 CRD，单独计入
+		pendingCount += int32(r.Queue.Pending(pool.Namespace, pool.Name))
+	}
 	//logger.Info("Load statistics", "pool", pool.Name, "active", activeCount, "pending", pendingCount)
 
 	maxPerPod := getAgentCapacity(&pool)
@@ -69,6 +91,23 @@ logger := klog.FromContext(ctx)
 		desiredPods = pool.Spec.Capacity.PoolMax
 	}
 
+	if r.AgentCerts != nil {
+		// 长期运行的 Controller 也需要在 CA 临近过期时轮换
+		if err := r.AgentCerts.RotateCA(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+		// 补齐创建 Pod 后、创建 Secret 前 Controller 退出而缺失的证书，并续签即将过期的证书
+		for i := range childPods.Items {
+			pod := &childPods.Items[i]
+			if pod.DeletionTimestamp != nil || (pod.Status.Phase != corev1.PodPending && pod.Status.Phase != corev1.PodRunning) {
+				continue
+			}
+			if err := r.AgentCerts.EnsureSecret(ctx, pod); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
 	currentCount := int32(len(childPods.Items))
 	//logger.Info("Scaling analysis", "pool", pool.Name, "current", currentCount, "desired", desiredPods)
 
@@ -81,6 +120,11 @@ logger := klog.FromContext(ctx)
 				logger.Error(err, "Failed to create agent pod")
 				return ctrl.Result{}, err
 			}
+			if r.AgentCerts != nil {
+				if err := r.AgentCerts.EnsureSecret(ctx, pod); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
 		}
 	} else if currentCount > desiredPods {
 		diff := currentCount - desiredPods
@@ -147,7 +191,8 @@ func (r *SandboxPoolReconciler) constructPod(pool *apiv1alpha1.SandboxPool) *cor
 			},
 			corev1.EnvVar{
 				Name:      "CPU_LIMIT",
-				ValueFrom: &corev1.EnvVarSource{ResourceFieldRef: &corev1.ResourceFieldSelector{ContainerName: "agent", Resource: "limits.cpu"}},
+				// 以毫核为单位，Agent 据此上报可分配 CPU
+				ValueFrom: &corev1.EnvVarSource{ResourceFieldRef: &corev1.ResourceFieldSelector{ContainerName: "agent", Resource: "limits.cpu", Divisor: resource.MustParse("1m")}},
 			},
 			corev1.EnvVar{
 				Name:      "MEMORY_LIMIT",
@@ -170,6 +215,8 @@ func (r *SandboxPoolReconciler) constructPod(pool *apiv1alpha1.SandboxPool) *cor
 			corev1.VolumeMount{Name: "containerd-root", MountPath: "/var/lib/containerd"},
 			corev1.VolumeMount{Name: "tmp", MountPath: "/tmp"},
 			corev1.VolumeMount{Name: "infra-tools", MountPath: "/opt/fast-sandbox/infra"},
+			// sandbox 日志放在 emptyDir，Agent 容器重启后仍可读取并继续追加
+			corev1.VolumeMount{Name: "sandbox-logs", MountPath: "/var/log/fast-sandbox"},
 		)
 
 	}
@@ -205,6 +252,12 @@ func (r *SandboxPoolReconciler) constructPod(pool *apiv1alpha1.SandboxPool) *cor
 				EmptyDir: &corev1.EmptyDirVolumeSource{},
 			},
 		},
+		corev1.Volume{
+			Name: "sandbox-logs",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
 	)
 
 	pod := &corev1.Pod{
@@ -216,6 +269,13 @@ func (r *SandboxPoolReconciler) constructPod(pool *apiv1alpha1.SandboxPool) *cor
 		Spec: *podSpec,
 	}
 
+	if r.AgentCerts != nil {
+		// 证书 Secret 按 Pod 名挂载，需要在创建前确定名字
+		pod.GenerateName = ""
+		pod.Name = pool.Name + "-agent-" + utilrand.String(5)
+		agenttls.InjectPod(pod)
+	}
+
 	ctrl.SetControllerReference(pool, pod, r.Scheme)
 	return pod
 }
@@ -246,7 +306,7 @@ func boolPtr(b bool) *bool {
 }
 
 func (r *SandboxPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1alpha1.SandboxPool{}).
 		Owns(&corev1.Pod{}).
 		Watches(&apiv1alpha1.Sandbox{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
@@ -260,6 +320,9 @@ func (r *SandboxPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				}
 			}
 			return nil
-		})).
-		Complete(r)
+		}))
+	if r.ScaleUpEvents != nil {
+		b = b.WatchesRawSource(source.Channel(r.ScaleUpEvents, &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(r)
 }
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+	"fast-sandbox/internal/controller/quota"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// SandboxQuotaReconciler reports the usage of each SandboxQuota in its status.
+// Enforcement happens at admission in FastPath and the SandboxReconciler.
+type SandboxQuotaReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile recomputes the usage of the namespace and stores it in the quota status.
+func (r *SandboxQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var sq apiv1alpha1.SandboxQuota
+	if err := r.Get(ctx, req.NamespacedName, &sq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var sandboxes apiv1alpha1.SandboxList
+	if err := r.List(ctx, &sandboxes, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	used := quota.Usage(sandboxes.Items)
+	if sq.Status.ObservedGeneration == sq.Generation && usageEqual(sq.Status.Used, used) {
+		return ctrl.Result{}, nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &apiv1alpha1.SandboxQuota{}
+		if err := r.Get(ctx, req.NamespacedName, latest); err != nil {
+			return err
+		}
+		latest.Status.ObservedGeneration = latest.Generation
+		latest.Status.Used = used
+		return r.Status().Update(ctx, latest)
+	})
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	klog.FromContext(ctx).V(1).Info("Updated quota usage", "sandboxes", used.Sandboxes, "cpu", used.CPU.String(), "memory", used.Memory.String())
+	return ctrl.Result{}, nil
+}
+
+// usageEqual compares quantities by value, their string forms may differ.
+func usageEqual(a, b apiv1alpha1.QuotaUsage) bool {
+	return a.Sandboxes == b.Sandboxes && a.CPU.Cmp(b.CPU) == 0 && a.Memory.Cmp(b.Memory) == 0 &&
+		(len(a.PerPool) == 0 && len(b.PerPool) == 0 || reflect.DeepEqual(a.PerPool, b.PerPool))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SandboxQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1alpha1.SandboxQuota{}).
+		Watches(&apiv1alpha1.Sandbox{}, handler.EnqueueRequestsFromMapFunc(r.mapSandboxToQuotas)).
+		Complete(r)
+}
+
+// mapSandboxToQuotas returns reconcile requests for the quotas of the sandbox namespace.
+func (r *SandboxQuotaReconciler) mapSandboxToQuotas(ctx context.Context, obj client.Object) []ctrl.Request {
+	var quotas apiv1alpha1.SandboxQuotaList
+	if err := r.List(ctx, &quotas, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(quotas.Items))
+	for _, q := range quotas.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&q)})
+	}
+	return requests
+}
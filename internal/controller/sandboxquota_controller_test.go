@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	apiv1alpha1 "fast-sandbox/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSandboxQuota_ReportsUsage(t *testing.T) {
+	// SQ-01: status 记录 namespace 中已调度且未清理的 sandbox
+	scheme := newTestScheme(t)
+	sq := &apiv1alpha1.SandboxQuota{ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default", Generation: 2}}
+	objs := []client.Object{
+		sq,
+		newBaseSandbox("running", withAssignedPod("test-agent"), withPhase("Running")),
+		newBaseSandbox("expired", withAssignedPod("test-agent"), withPhase("Expired")),
+		newBaseSandbox("pending"),
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&apiv1alpha1.SandboxQuota{}).Build()
+	r := &SandboxQuotaReconciler{Client: c, Scheme: scheme}
+
+	key := types.NamespacedName{Name: "team", Namespace: "default"}
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	var updated apiv1alpha1.SandboxQuota
+	require.NoError(t, c.Get(context.Background(), key, &updated))
+	assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	assert.Equal(t, int32(1), updated.Status.Used.Sandboxes)
+	assert.Equal(t, map[string]int32{"test-pool": 1}, updated.Status.Used.PerPool)
+}